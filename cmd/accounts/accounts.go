@@ -1,8 +1,6 @@
 package accounts
 
 import (
-	"os"
-
 	"github.com/provideservices/provide-cli/cmd/common"
 	"github.com/spf13/cobra"
 )
@@ -18,16 +16,12 @@ var AccountsCmd = &cobra.Command{
 For convenience, it is also possible to generate keypairs with this utility which you (or your application)
 is then responsible for securing. You should securely store any keys generated using this API. If you are
 looking for hierarchical deterministic support, check out the wallets API.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		common.CmdExistsOrExit(cmd, args)
-
-		generalPrompt(cmd, args, "")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
 
-		defer func() {
-			if r := recover(); r != nil {
-				os.Exit(1)
-			}
-		}()
+		return generalPrompt(cmd, args, "")
 	},
 }
 
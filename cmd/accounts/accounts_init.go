@@ -2,9 +2,8 @@ package accounts
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/nchain"
@@ -22,36 +21,44 @@ var accountsInitCmd = &cobra.Command{
 	Use:   "init [--non-custodial|-nc] [--network 024ff1ef-7369-4dee-969c-1918c6edb5d4] [--application 024ff1ef-7369-4dee-969c-1918c6edb5d4] [--organization 024ff1ef-7369-4dee-969c-1918c6edb5d4]",
 	Short: "Generate a new keypair for signing transactions and storing value",
 	Long:  `Initialize a new account, which may be managed by Provide or you`,
-	Run:   CreateAccount,
+	RunE:  CreateAccount,
 }
 
-func CreateAccount(cmd *cobra.Command, args []string) {
+func CreateAccount(cmd *cobra.Command, args []string) error {
 	if nonCustodial {
-		createDecentralizedAccount()
-		return
+		return createDecentralizedAccount()
 	}
 
-	createManagedAccount(cmd, args)
+	return createManagedAccount(cmd, args)
 }
 
-func createDecentralizedAccount() {
+func createDecentralizedAccount() error {
 	publicKey, privateKey, err := providecrypto.EVMGenerateKeyPair()
 	if err != nil {
-		log.Printf("Failed to genereate nonCustodial keypair; %s", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("Failed to genereate nonCustodial keypair; %w", err)
 	}
 	secret := hex.EncodeToString(providecrypto.FromECDSA(privateKey))
 	keypairJSON, err := providecrypto.EVMMarshalEncryptedKey(providecrypto.HexToAddress(*publicKey), privateKey, secret)
 	if err != nil {
-		log.Printf("Failed to genereate nonCustodial keypair; %s", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("Failed to genereate nonCustodial keypair; %w", err)
 	}
-	result := fmt.Sprintf("%s\t%s\n", *publicKey, string(keypairJSON))
-	fmt.Print(result)
+	table := common.NewTable("PUBLIC KEY", "KEYSTORE")
+	table.AddRow(*publicKey, string(keypairJSON))
+	err = common.Render(map[string]interface{}{
+		"public_key": *publicKey,
+		"keystore":   json.RawMessage(keypairJSON),
+	}, table)
+	if err != nil {
+		return fmt.Errorf("Failed to render nonCustodial keypair; %w", err)
+	}
+	return nil
 }
 
-func createManagedAccount(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func createManagedAccount(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{
 		"network_id": common.NetworkID,
 	}
@@ -66,19 +73,22 @@ func createManagedAccount(cmd *cobra.Command, args []string) {
 	}
 	account, err := provide.CreateAccount(token, params)
 	if err != nil {
-		log.Printf("Failed to genereate keypair; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to genereate keypair")
 	}
 
 	common.AccountID = account.ID.String()
-	result := fmt.Sprintf("Account %s\t%s\n", account.ID.String(), account.Address)
-	// FIXME-- when account.Name exists... result = fmt.Sprintf("Account %s\t%s - %s\n", *account.Name, account.ID.String(), *account.Address)
+	table := common.NewTable("ID", "ADDRESS")
+	table.AddRow(account.ID.String(), account.Address)
+	// FIXME-- when account.Name exists... add a NAME column
 	appAccountKey := common.BuildConfigKeyWithApp(common.AccountConfigKeyPartial, common.ApplicationID)
 	if !viper.IsSet(appAccountKey) {
 		viper.Set(appAccountKey, account.ID.String())
 		viper.WriteConfig()
 	}
-	fmt.Print(result)
+	if err := common.Render(account, table); err != nil {
+		return fmt.Errorf("Failed to render account; %w", err)
+	}
+	return nil
 }
 
 func init() {
@@ -2,8 +2,6 @@ package accounts
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/nchain"
@@ -14,30 +12,36 @@ var accountsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Retrieve a list of signing identities",
 	Long:  `Retrieve a list of signing identities (accounts) scoped to the authorized API token`,
-	Run:   listAccounts,
+	RunE:  listAccounts,
 }
 
-func listAccounts(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func listAccounts(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{}
 	if common.ApplicationID != "" {
 		params["application_id"] = common.ApplicationID
 	}
 	resp, err := provide.ListAccounts(token, params)
 	if err != nil {
-		log.Printf("Failed to retrieve accounts list; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to retrieve accounts list")
 	}
 	// if status != 200 {
 	// 	log.Printf("Failed to retrieve accounts list; received status: %d", status)
 	// 	os.Exit(1)
 	// }
+	table := common.NewTable("ID", "ADDRESS")
 	for i := range resp {
 		account := resp[i]
-		result := fmt.Sprintf("%s\t%s\n", account.ID.String(), account.Address)
-		// TODO-- when account.Name exists... result = fmt.Sprintf("%s\t%s - %s\n", name, account, *account.Address)
-		fmt.Print(result)
+		table.AddRow(account.ID.String(), account.Address)
+		// TODO-- when account.Name exists... add a NAME column
+	}
+	if err := common.Render(resp, table); err != nil {
+		return fmt.Errorf("Failed to render accounts list; %w", err)
 	}
+	return nil
 }
 
 func init() {
@@ -21,36 +21,56 @@ var custodyPromptArgs = []string{"No", "Yes"}
 var custodyPromptLabel = "Would you like your wallet to be non-custodial?"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, currentStep string) {
+func generalPrompt(cmd *cobra.Command, args []string, currentStep string) error {
+	var err error
+
 	switch step := currentStep; step {
 	case promptStepInit:
-		common.SelectInput(accountTypePromptArgs, accountTypeLabel)
-		generalPrompt(cmd, args, promptStepCustody)
+		if _, err := common.SelectInput(accountTypePromptArgs, accountTypeLabel); err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, promptStepCustody)
 	case promptStepCustody:
 		if optional {
 			fmt.Println("Optional Flags:")
 			if !nonCustodial {
-				nonCustodial = common.SelectInput(custodyPromptArgs, custodyPromptLabel) == "Yes"
+				result, err := common.SelectInput(custodyPromptArgs, custodyPromptLabel)
+				if err != nil {
+					return err
+				}
+				nonCustodial = result == "Yes"
 			}
 			if accountName == "" {
-				accountName = common.FreeInput("Account Name", "", common.NoValidation)
+				if accountName, err = common.FreeInput("Account Name", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if common.ApplicationID == "" {
-				common.RequireApplication()
+				if err := common.RequireApplication(); err != nil {
+					return err
+				}
 			}
 			if common.OrganizationID == "" {
-				common.RequireOrganization()
+				if err := common.RequireOrganization(); err != nil {
+					return err
+				}
 			}
 		}
-		CreateAccount(cmd, args)
+		return CreateAccount(cmd, args)
 	case promptStepList:
 		if optional {
 			fmt.Println("Optional Flags:")
-			common.RequireApplication()
+			if err := common.RequireApplication(); err != nil {
+				return err
+			}
 		}
-		listAccounts(cmd, args)
+		return listAccounts(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
@@ -1,8 +1,6 @@
 package api_tokens
 
 import (
-	"os"
-
 	"github.com/provideservices/provide-cli/cmd/common"
 	"github.com/spf13/cobra"
 )
@@ -11,16 +9,12 @@ var APITokensCmd = &cobra.Command{
 	Use:   "api_tokens",
 	Short: "Manage API tokens",
 	Long:  `API tokens can be created on behalf of a developer account, application or application user`,
-	Run: func(cmd *cobra.Command, args []string) {
-		common.CmdExistsOrExit(cmd, args)
-
-		generalPrompt(cmd, args, "")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
 
-		defer func() {
-			if r := recover(); r != nil {
-				os.Exit(1)
-			}
-		}()
+		return generalPrompt(cmd, args, "")
 	},
 }
 
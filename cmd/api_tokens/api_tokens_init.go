@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"os"
 	"strings"
 
 	"github.com/dgrijalva/jwt-go"
@@ -16,7 +15,6 @@ import (
 	"golang.org/x/crypto/ssh"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var jwtKeypairs map[string]*util.JWTKeypair
@@ -31,14 +29,19 @@ var apiTokensInitCmd = &cobra.Command{
 	Use:   "init [--application 8fec625c-a8ad-4197-bb77-8b46d7aecd8f] [--organization 2209cf15-2402-4e25-b6b6-1c901b9dde69] [--offline-access] [--refresh-token]",
 	Short: "Authorize a new API access or refresh token",
 	Long:  `Authorize a new API token on behalf of the given application or organization`,
-	Run:   createAPIToken,
+	RunE:  createAPIToken,
 }
 
 // createAPIToken triggers the generation of an API token for the given network.
-func createAPIToken(cmd *cobra.Command, args []string) {
-	RequirePublicJWTVerifiers()
+func createAPIToken(cmd *cobra.Command, args []string) error {
+	if err := RequirePublicJWTVerifiers(); err != nil {
+		return err
+	}
 
-	userToken := common.RequireUserAccessToken()
+	userToken, err := common.RequireUserAccessToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{}
 
 	if scope != "" {
@@ -53,11 +56,12 @@ func createAPIToken(cmd *cobra.Command, args []string) {
 		params["grant_type"] = "refresh_token"
 	}
 
+	table := common.NewTable("TOKEN", "SUBJECT", "VALUE")
+
 	if common.ApplicationID != "" {
 		token, err := provide.CreateApplicationToken(userToken, common.ApplicationID, params)
 		if err != nil {
-			log.Printf("Failed to authorize API token on behalf of application %s; %s", common.ApplicationID, err.Error())
-			os.Exit(1)
+			return common.NewAPIError(err, "Failed to authorize API token on behalf of application %s", common.ApplicationID)
 		}
 
 		appAPITokenKey := common.BuildConfigKeyWithApp(common.APIAccessTokenConfigKeyPartial, common.ApplicationID)
@@ -65,67 +69,63 @@ func createAPIToken(cmd *cobra.Command, args []string) {
 		var tkn string
 
 		if token.Token != nil {
-			fmt.Printf("API token authorized for application: %s\t%s\n", common.ApplicationID, *token.Token)
+			table.AddRow("api", fmt.Sprintf("application:%s", common.ApplicationID), *token.Token)
 			tkn = *token.Token
 		} else if token.AccessToken != nil {
-			fmt.Printf("Access token authorized for application: %s\t%s\n", common.ApplicationID, *token.AccessToken)
+			table.AddRow("access", fmt.Sprintf("application:%s", common.ApplicationID), *token.AccessToken)
 			tkn = *token.AccessToken
 		}
 
 		if tkn != "" {
-			if !viper.IsSet(appAPITokenKey) {
-				viper.Set(appAPITokenKey, tkn)
-				viper.WriteConfig()
+			if err := common.CacheCredentialUnlessCached(appAPITokenKey, tkn); err != nil {
+				return err
 			}
 
 			if token.RefreshToken != nil {
-				fmt.Printf("Refresh token authorized for application: %s\t%s\n", common.ApplicationID, *token.RefreshToken)
-				if !viper.IsSet(appAPIRefreshTokenKey) {
-					viper.Set(appAPIRefreshTokenKey, *token.RefreshToken)
-					viper.WriteConfig()
+				table.AddRow("refresh", fmt.Sprintf("application:%s", common.ApplicationID), *token.RefreshToken)
+				if err := common.CacheCredentialUnlessCached(appAPIRefreshTokenKey, *token.RefreshToken); err != nil {
+					return err
 				}
 			}
 		}
+
+		return renderAPIToken(token, table)
 	} else if common.OrganizationID != "" {
 		params["organization_id"] = common.OrganizationID
 		token, err := provide.CreateToken(userToken, params)
 		if err != nil {
-			log.Printf("failed to authorize API access token on behalf of organization %s; %s", common.OrganizationID, err.Error())
-			os.Exit(1)
+			return common.NewAPIError(err, "failed to authorize API access token on behalf of organization %s", common.OrganizationID)
 		}
 
 		orgAPIAccessTokenKey := common.BuildConfigKeyWithOrg(common.APIAccessTokenConfigKeyPartial, common.OrganizationID)
 		orgAPIRefreshTokenKey := common.BuildConfigKeyWithOrg(common.APIRefreshTokenConfigKeyPartial, common.OrganizationID)
 
 		if token.AccessToken != nil {
-			fmt.Printf("Access token authorized for organization: %s\t%s\n", common.OrganizationID, *token.AccessToken)
-			if !viper.IsSet(orgAPIAccessTokenKey) {
-				viper.Set(orgAPIAccessTokenKey, *token.AccessToken)
-				viper.WriteConfig()
+			table.AddRow("access", fmt.Sprintf("organization:%s", common.OrganizationID), *token.AccessToken)
+			if err := common.CacheCredentialUnlessCached(orgAPIAccessTokenKey, *token.AccessToken); err != nil {
+				return err
 			}
 			if token.RefreshToken != nil {
-				fmt.Printf("Refresh token authorized for organization: %s\t%s\n", common.OrganizationID, *token.RefreshToken)
-				if !viper.IsSet(orgAPIRefreshTokenKey) {
-					viper.Set(orgAPIRefreshTokenKey, *token.RefreshToken)
-					viper.WriteConfig()
+				table.AddRow("refresh", fmt.Sprintf("organization:%s", common.OrganizationID), *token.RefreshToken)
+				if err := common.CacheCredentialUnlessCached(orgAPIRefreshTokenKey, *token.RefreshToken); err != nil {
+					return err
 				}
 			}
 		} else {
-			log.Printf("Failed to authorize API token on behalf of organization %s; no access/refresh pair returned", common.OrganizationID)
-			os.Exit(1)
+			return common.NewAPIError(nil, "Failed to authorize API token on behalf of organization %s; no access/refresh pair returned", common.OrganizationID)
 		}
+
+		return renderAPIToken(token, table)
 	} else {
 		// user token...
 		token, err := provide.CreateToken(userToken, params)
 		if err != nil {
-			log.Printf("failed to authorize API access token on behalf of authorized user; %s", err.Error())
-			os.Exit(1)
+			return common.NewAPIError(err, "failed to authorize API access token on behalf of authorized user")
 		}
 
 		tkn, err := ParseJWT(userToken)
 		if err != nil {
-			log.Printf("failed to parse JWT token on behalf of authorized user; %s", err.Error())
-			os.Exit(1)
+			return fmt.Errorf("failed to parse JWT token on behalf of authorized user; %w", err)
 		}
 		claims, _ := tkn.Claims.(jwt.MapClaims)
 
@@ -134,54 +134,63 @@ func createAPIToken(cmd *cobra.Command, args []string) {
 		userAPIRefreshTokenKey := common.BuildConfigKeyWithUser(common.APIRefreshTokenConfigKeyPartial, userID)
 
 		if token.AccessToken != nil {
-			fmt.Printf("Access token authorized for user: %s\t%s\n", common.OrganizationID, *token.AccessToken)
-			if !viper.IsSet(userAPIAccessTokenKey) {
-				viper.Set(userAPIAccessTokenKey, *token.AccessToken)
-				viper.WriteConfig()
+			table.AddRow("access", fmt.Sprintf("user:%s", userID), *token.AccessToken)
+			if err := common.CacheCredentialUnlessCached(userAPIAccessTokenKey, *token.AccessToken); err != nil {
+				return err
 			}
 			if token.RefreshToken != nil {
-				fmt.Printf("Refresh token authorized for user: %s\t%s\n", common.OrganizationID, *token.RefreshToken)
-				if !viper.IsSet(userAPIRefreshTokenKey) {
-					viper.Set(userAPIRefreshTokenKey, *token.RefreshToken)
-					viper.WriteConfig()
+				table.AddRow("refresh", fmt.Sprintf("user:%s", userID), *token.RefreshToken)
+				if err := common.CacheCredentialUnlessCached(userAPIRefreshTokenKey, *token.RefreshToken); err != nil {
+					return err
 				}
 			}
 		} else {
-			log.Printf("Failed to authorize API token on behalf of authorized user %s; no access/refresh pair returned", userID)
-			os.Exit(1)
+			return common.NewAPIError(nil, "Failed to authorize API token on behalf of authorized user %s; no access/refresh pair returned", userID)
 		}
+
+		return renderAPIToken(token, table)
+	}
+}
+
+func renderAPIToken(token *provide.Token, table *common.Table) error {
+	if err := common.Render(token, table); err != nil {
+		return fmt.Errorf("Failed to render API token; %w", err)
 	}
+	return nil
 }
 
-func RequirePublicJWTVerifiers() {
+// RequirePublicJWTVerifiers resolves the public keys with which ident signs JWTs, so they
+// can be verified using ParseJWT
+func RequirePublicJWTVerifiers() error {
 	jwtKeypairs = map[string]*util.JWTKeypair{}
 
 	keys, err := ident.GetJWKs()
 	if err != nil {
-		log.Printf("failed to resolve ident jwt keys; %s", err.Error())
-	} else {
-		for _, key := range keys {
-			publicKey, err := pgputil.DecodeRSAPublicKeyFromPEM([]byte(key.PublicKey))
-			if err != nil {
-				log.Printf("failed to parse ident JWT public key; %s", err.Error())
-			}
+		return common.NewAPIError(err, "failed to resolve ident jwt keys")
+	}
 
-			sshPublicKey, err := ssh.NewPublicKey(publicKey)
-			if err != nil {
-				log.Printf("failed to resolve JWT public key fingerprint; %s", err.Error())
-			}
-			fingerprint := ssh.FingerprintLegacyMD5(sshPublicKey)
+	for _, key := range keys {
+		publicKey, err := pgputil.DecodeRSAPublicKeyFromPEM([]byte(key.PublicKey))
+		if err != nil {
+			return fmt.Errorf("failed to parse ident JWT public key; %w", err)
+		}
 
-			jwtKeypairs[fingerprint] = &util.JWTKeypair{
-				Fingerprint:  fingerprint,
-				PublicKey:    *publicKey,
-				PublicKeyPEM: &key.PublicKey,
-				SSHPublicKey: &sshPublicKey,
-			}
+		sshPublicKey, err := ssh.NewPublicKey(publicKey)
+		if err != nil {
+			return fmt.Errorf("failed to resolve JWT public key fingerprint; %w", err)
+		}
+		fingerprint := ssh.FingerprintLegacyMD5(sshPublicKey)
 
-			log.Printf("ident jwt public key configured for verification; fingerprint: %s", fingerprint)
+		jwtKeypairs[fingerprint] = &util.JWTKeypair{
+			Fingerprint:  fingerprint,
+			PublicKey:    *publicKey,
+			PublicKeyPEM: &key.PublicKey,
+			SSHPublicKey: &sshPublicKey,
 		}
+
+		log.Printf("ident jwt public key configured for verification; fingerprint: %s", fingerprint)
 	}
+	return nil
 }
 
 func ParseJWT(token string) (*jwt.Token, error) {
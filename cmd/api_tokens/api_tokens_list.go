@@ -2,8 +2,6 @@ package api_tokens
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/ident"
@@ -15,29 +13,35 @@ var apiTokensListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Retrieve a list of API tokens",
 	Long:  `Retrieve a list of API tokens scoped to the authorized API token`,
-	Run:   listAPITokens,
+	RunE:  listAPITokens,
 }
 
-func listAPITokens(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func listAPITokens(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{}
 	if common.ApplicationID != "" {
 		params["application_id"] = common.ApplicationID
 	}
 	resp, err := provide.ListTokens(token, params)
 	if err != nil {
-		log.Printf("Failed to retrieve API tokens list; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to retrieve API tokens list")
 	}
 	// if status != 200 {
 	// 	log.Printf("Failed to retrieve API tokens list; received status: %d", status)
 	// 	os.Exit(1)
 	// }
+	table := common.NewTable("ID", "TOKEN")
 	for i := range resp {
 		apiToken := resp[i]
-		result := fmt.Sprintf("%s\t%s\n", apiToken.ID.String(), *apiToken.Token)
-		fmt.Print(result)
+		table.AddRow(apiToken.ID.String(), common.StringValue(apiToken.Token))
+	}
+	if err := common.Render(resp, table); err != nil {
+		return fmt.Errorf("Failed to render API tokens list; %w", err)
 	}
+	return nil
 }
 
 func init() {
@@ -20,36 +20,52 @@ var offlinePromptArgs = []string{"Yes", "No"}
 var offlinePromptLabel = "Would you like to set offline access"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, currentStep string) {
+func generalPrompt(cmd *cobra.Command, args []string, currentStep string) error {
 	switch step := currentStep; step {
 	case promptStepInit:
 		if optional {
 			if common.ApplicationID == "" {
-				common.RequireApplication()
+				if err := common.RequireApplication(); err != nil {
+					return err
+				}
 			}
 			if common.OrganizationID == "" {
-				common.RequireOrganization()
+				if err := common.RequireOrganization(); err != nil {
+					return err
+				}
 			}
 			if !refreshToken {
-				result := common.SelectInput(refresTokenPromptArgs, refresTokenPromptLabel)
+				result, err := common.SelectInput(refresTokenPromptArgs, refresTokenPromptLabel)
+				if err != nil {
+					return err
+				}
 				refreshToken = result == "Yes"
 			}
 			if !offlineAccess {
-				result := common.SelectInput(offlinePromptArgs, offlinePromptLabel)
+				result, err := common.SelectInput(offlinePromptArgs, offlinePromptLabel)
+				if err != nil {
+					return err
+				}
 				offlineAccess = result == "Yes"
 			}
 			if refreshToken && offlineAccess {
 				fmt.Println("⚠️  WARNING: You currently have both refresh and offline token set, Refresh token will take precedence")
 			}
 		}
-		createAPIToken(cmd, args)
+		return createAPIToken(cmd, args)
 	case promptStepList:
 		if optional {
-			common.RequireApplication()
+			if err := common.RequireApplication(); err != nil {
+				return err
+			}
 		}
-		listAPITokens(cmd, args)
+		return listAPITokens(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
@@ -24,41 +24,70 @@ var walletPromptArgs = []string{"Yes", "No"}
 var walletPromptLabel = "Would you like to set up a wallet"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, step string) {
+func generalPrompt(cmd *cobra.Command, args []string, step string) error {
+	var err error
+
 	switch step {
 	case promptStepInit:
 		if applicationName == "" {
-			applicationName = common.FreeInput("Application Name", "", common.MandatoryValidation)
+			if applicationName, err = common.RequireFreeInput("--name", "Application Name", "", common.MandatoryValidation); err != nil {
+				return err
+			}
 		}
 		if common.NetworkID == "" {
-			common.RequireNetwork()
+			if err := common.RequireNetwork(); err != nil {
+				return err
+			}
 		}
 		if optional {
 			fmt.Println("Optional Flags:")
 			if applicationType == "" {
-				applicationType = common.FreeInput("Application Type", "", common.NoValidation)
+				if applicationType, err = common.FreeInput("Application Type", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if !baseline {
-				result := common.SelectInput(baselinePromptArgs, baselinePromptLabel)
+				result, err := common.SelectInput(baselinePromptArgs, baselinePromptLabel)
+				if err != nil {
+					return err
+				}
 				baseline = result == "Yes"
 			}
 			if !withoutAccount {
-				result := common.SelectInput(accountPromptArgs, accountPromptLabel)
+				result, err := common.SelectInput(accountPromptArgs, accountPromptLabel)
+				if err != nil {
+					return err
+				}
 				baseline = result == "Yes"
 			}
 			if !withoutWallet {
-				result := common.SelectInput(walletPromptArgs, walletPromptLabel)
+				result, err := common.SelectInput(walletPromptArgs, walletPromptLabel)
+				if err != nil {
+					return err
+				}
 				baseline = result == "Yes"
 			}
 		}
-		createApplication(cmd, args)
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return createApplication(cmd, args)
 	case promptStepDetails:
-		common.RequireApplication()
-		fetchApplicationDetails(cmd, args)
+		if err := common.RequireApplication(); err != nil {
+			return err
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return fetchApplicationDetails(cmd, args)
 	case promptStepList:
-		listApplications(cmd, args)
+		return listApplications(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
@@ -1,8 +1,6 @@
 package applications
 
 import (
-	"os"
-
 	"github.com/provideservices/provide-cli/cmd/common"
 	"github.com/spf13/cobra"
 )
@@ -25,16 +23,12 @@ var ApplicationsCmd = &cobra.Command{
 	- Connectors (i.e., IPFS)
 	- Payment Hubs
 	- Transactions`,
-	Run: func(cmd *cobra.Command, args []string) {
-		common.CmdExistsOrExit(cmd, args)
-
-		generalPrompt(cmd, args, "")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
 
-		defer func() {
-			if r := recover(); r != nil {
-				os.Exit(1)
-			}
-		}()
+		return generalPrompt(cmd, args, "")
 	},
 }
 
@@ -2,8 +2,6 @@ package applications
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/ident"
@@ -15,19 +13,25 @@ var applicationsDetailsCmd = &cobra.Command{
 	Use:   "details",
 	Short: "Retrieve a specific application",
 	Long:  `Retrieve details for a specific application by identifier, scoped to the authorized API token`,
-	Run:   fetchApplicationDetails,
+	RunE:  fetchApplicationDetails,
 }
 
-func fetchApplicationDetails(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func fetchApplicationDetails(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{}
 	application, err := provide.GetApplicationDetails(token, common.ApplicationID, params)
 	if err != nil {
-		log.Printf("Failed to retrieve details for application with id: %s; %s", common.ApplicationID, err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to retrieve details for application with id: %s", common.ApplicationID)
+	}
+	table := common.NewTable("ID", "NAME")
+	table.AddRow(application.ID.String(), common.StringValue(application.Name))
+	if err := common.Render(application, table); err != nil {
+		return fmt.Errorf("Failed to render application details; %w", err)
 	}
-	result := fmt.Sprintf("%s\t%s\n", application.ID.String(), *application.Name)
-	fmt.Print(result)
+	return nil
 }
 
 func init() {
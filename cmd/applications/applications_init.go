@@ -2,8 +2,6 @@ package applications
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/accounts"
 	"github.com/provideservices/provide-cli/cmd/common"
@@ -25,7 +23,7 @@ var applicationsInitCmd = &cobra.Command{
 	Use:   "init --name 'my app' --network 024ff1ef-7369-4dee-969c-1918c6edb5d4 [--baseline]",
 	Short: "Initialize a new application",
 	Long:  `Initialize a new application targeting a specified mainnet`,
-	Run:   createApplication,
+	RunE:  createApplication,
 }
 
 func applicationConfigFactory() map[string]interface{} {
@@ -40,12 +38,14 @@ func applicationConfigFactory() map[string]interface{} {
 	return cfg
 }
 
-func createApplication(cmd *cobra.Command, args []string) {
+func createApplication(cmd *cobra.Command, args []string) error {
 	if withoutAPIToken && !withoutWallet {
-		fmt.Println("Cannot create an application that has a wallet but no API token.")
-		os.Exit(1)
+		return common.NewValidationError("cannot create an application that has a wallet but no API token")
+	}
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
 	}
-	token := common.RequireAPIToken()
 	cfg := applicationConfigFactory()
 	if baseline {
 		cfg["baseline"] = true
@@ -58,8 +58,7 @@ func createApplication(cmd *cobra.Command, args []string) {
 
 	application, err := provide.CreateApplication(token, params)
 	if err != nil {
-		log.Printf("Failed to initialize application; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to initialize application")
 	}
 
 	// // FIXME-- authorize app token...
@@ -74,14 +73,22 @@ func createApplication(cmd *cobra.Command, args []string) {
 	// }
 	// fmt.Printf("Application API Token\t%s\n", applicationToken)
 
-	result := fmt.Sprintf("%s\t%s\n", application.ID.String(), *application.Name)
-	fmt.Print(result)
+	table := common.NewTable("ID", "NAME")
+	table.AddRow(application.ID.String(), common.StringValue(application.Name))
+	if err := common.Render(application, table); err != nil {
+		return fmt.Errorf("Failed to render application; %w", err)
+	}
 	if !withoutAccount {
-		accounts.CreateAccount(cmd, args)
+		if err := accounts.CreateAccount(cmd, args); err != nil {
+			return err
+		}
 	}
 	if !withoutWallet {
-		wallets.CreateWallet(cmd, args)
+		if err := wallets.CreateWallet(cmd, args); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 func init() {
@@ -2,8 +2,6 @@ package applications
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/ident"
@@ -15,20 +13,26 @@ var applicationsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Retrieve a list of applications",
 	Long:  `Retrieve a list of applications scoped to the authorized API token`,
-	Run:   listApplications,
+	RunE:  listApplications,
 }
 
-func listApplications(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func listApplications(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{}
 	applications, err := provide.ListApplications(token, params)
 	if err != nil {
-		log.Printf("Failed to retrieve applications list; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to retrieve applications list")
 	}
+	table := common.NewTable("ID", "NAME")
 	for i := range applications {
 		application := applications[i]
-		result := fmt.Sprintf("%s\t%s\n", application.ID.String(), *application.Name)
-		fmt.Print(result)
+		table.AddRow(application.ID.String(), common.StringValue(application.Name))
+	}
+	if err := common.Render(applications, table); err != nil {
+		return fmt.Errorf("Failed to render applications list; %w", err)
 	}
+	return nil
 }
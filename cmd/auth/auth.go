@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var AuthCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Inspect cached credentials",
+	Long: `Inspect the user, organization and application tokens cached in the active context.
+
+Cached tokens are refreshed automatically using their refresh token shortly before they expire.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
+
+		return generalPrompt(cmd, args, "")
+	},
+}
+
+func init() {
+	AuthCmd.AddCommand(authStatusCmd)
+}
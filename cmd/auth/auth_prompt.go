@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+const promptStepStatus = "Status"
+
+var emptyPromptArgs = []string{promptStepStatus}
+var emptyPromptLabel = "What would you like to do"
+
+// General Endpoints
+func generalPrompt(cmd *cobra.Command, args []string, step string) error {
+	switch step {
+	case promptStepStatus:
+		return authStatusRun(cmd, args)
+	case "":
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show cached tokens",
+	Long:  `Show the subject, scope and expiry of each token cached in the active context; tokens themselves are never shown`,
+	RunE:  authStatus,
+}
+
+func authStatus(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepStatus)
+}
+
+func authStatusRun(cmd *cobra.Command, args []string) error {
+	statuses, err := common.CachedTokenStatuses()
+	if err != nil {
+		return fmt.Errorf("failed to read cached tokens; %w", err)
+	}
+
+	table := common.NewTable("KEY", "SUBJECT", "SCOPE", "EXPIRES", "STATUS", "REFRESHABLE")
+	for _, status := range statuses {
+		expires := "never"
+		if status.ExpiresAt != nil {
+			expires = status.ExpiresAt.Local().Format(time.RFC3339)
+		}
+		state := "valid"
+		if status.Expired {
+			state = "expired"
+		}
+		refreshable := "no"
+		if status.Refreshable {
+			refreshable = "yes"
+		}
+		table.AddRow(status.Key, status.Subject, status.Scope, expires, state, refreshable)
+	}
+	if err := common.Render(statuses, table); err != nil {
+		return fmt.Errorf("failed to render cached tokens; %w", err)
+	}
+	return nil
+}
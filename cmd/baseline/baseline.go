@@ -15,10 +15,12 @@ var BaselineCmd = &cobra.Command{
 	Use:   "baseline",
 	Short: "Interact with the baseline protocol",
 	Long:  `Interact with the baseline protocol.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		common.CmdExistsOrExit(cmd, args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
 
-		generalPrompt(cmd, args, "")
+		return generalPrompt(cmd, args, "")
 	},
 }
 
@@ -28,8 +30,8 @@ var proxyCmd = &cobra.Command{
 	Long: `Create, manage and interact with local baseline stack instances.
 
 See: prvd baseline stack --help instead. This command is deprecated and will be removed soon.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		generalPrompt(cmd, args, "")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return generalPrompt(cmd, args, "")
 	},
 }
 
@@ -20,22 +20,27 @@ var emptyPromptArgs = []string{promptStack, promptWorkgroups, promptWorkflows, p
 var emptyPromptLabel = "What would you like to do"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, currentStep string) {
+func generalPrompt(cmd *cobra.Command, args []string, currentStep string) error {
 	switch step := currentStep; step {
 	case promptStack:
 		stack.Optional = Optional
-		stack.StackCmd.Run(cmd, args)
+		return stack.StackCmd.RunE(cmd, args)
 	case promptWorkgroups:
 		workgroups.Optional = Optional
-		workgroups.WorkgroupsCmd.Run(cmd, args)
+		return workgroups.WorkgroupsCmd.RunE(cmd, args)
 	case promptWorkflows:
 		workflows.Optional = Optional
-		workflows.WorkflowsCmd.Run(cmd, args)
+		return workflows.WorkflowsCmd.RunE(cmd, args)
 	case promptParticipant:
 		participants.Optional = Optional
-		participants.ParticipantsCmd.Run(cmd, args)
+		return participants.ParticipantsCmd.RunE(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+
+	return nil
 }
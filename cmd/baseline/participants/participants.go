@@ -9,10 +9,12 @@ var ParticipantsCmd = &cobra.Command{
 	Use:   "participants",
 	Short: "Interact with participants in a baseline workgroup",
 	Long:  `Invite, manage and interact with workgroup participants via the baseline protocol.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		common.CmdExistsOrExit(cmd, args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
 
-		generalPrompt(cmd, args, "")
+		return generalPrompt(cmd, args, "")
 	},
 }
 
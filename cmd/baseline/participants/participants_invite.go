@@ -5,13 +5,11 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/kthomas/go-pgputil"
-	"github.com/manifoldco/promptui"
 	"github.com/provideservices/provide-cli/cmd/common"
 	ident "github.com/provideservices/provide-go/api/ident"
 	"github.com/provideservices/provide-go/api/nchain"
@@ -34,45 +32,72 @@ var inviteBaselineWorkgroupParticipantCmd = &cobra.Command{
 	Long: `Invite an organization to participate in a baseline workgroup.
 
 A verifiable credential is issued which can then be distributed to the invited party out-of-band.`,
-	Run: inviteParticipant,
+	RunE: inviteParticipant,
 }
 
-func inviteParticipant(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepInvite)
+func inviteParticipant(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepInvite)
 }
 
-func inviteParticipantRun(cmd *cobra.Command, args []string) {
+func inviteParticipantRun(cmd *cobra.Command, args []string) error {
 	if name == "" {
-		namePrompt()
+		if err := namePrompt(); err != nil {
+			return err
+		}
 	}
 	if email == "" {
-		emailPrompt()
+		if err := emailPrompt(); err != nil {
+			return err
+		}
+	}
+	if err := common.RequireWorkgroup(); err != nil {
+		return err
+	}
+	if err := common.RequireOrganization(); err != nil {
+		return err
+	}
+	if err := common.MissingInput(); err != nil {
+		return err
+	}
+
+	authorizedBearerToken, err := InviteParticipant(name, email)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("invited baseline workgroup participant: %s\n\n\t%s", email, authorizedBearerToken)
+	return nil
+}
+
+// InviteParticipant invites the named organization to the configured workgroup on behalf of
+// the configured organization and returns the invitation JWT
+func InviteParticipant(name, email string) (string, error) {
+	if err := common.AuthorizeApplicationContext(); err != nil {
+		return "", err
+	}
+	if err := common.AuthorizeOrganizationContext(false); err != nil {
+		return "", err
 	}
-	common.AuthorizeApplicationContext()
-	common.AuthorizeOrganizationContext(false)
 
 	vaults, err := vault.ListVaults(common.OrganizationAccessToken, map[string]interface{}{
 		"organization_id": common.OrganizationID,
 	})
 	if err != nil {
-		log.Printf("failed to resolve vault for organization; %s", err.Error())
-		os.Exit(1)
+		return "", common.NewAPIError(err, "failed to resolve vault for organization")
 	}
 
 	keys, err := vault.ListKeys(common.OrganizationAccessToken, vaults[0].ID.String(), map[string]interface{}{
 		"spec": "secp256k1",
 	})
 	if err != nil {
-		log.Printf("failed to resolve secp256k1 key for organization; %s", err.Error())
-		os.Exit(1)
+		return "", common.NewAPIError(err, "failed to resolve secp256k1 key for organization")
 	}
 
 	contracts, _ := nchain.ListContracts(common.ApplicationAccessToken, map[string]interface{}{
 		"type": "organization-registry",
 	})
 	if err != nil {
-		log.Printf("failed to resolve contract for organization; %s", err.Error())
-		os.Exit(1)
+		return "", common.NewAPIError(err, "failed to resolve contract for organization")
 	}
 
 	invitorAddress := keys[0].Address
@@ -84,7 +109,10 @@ func inviteParticipantRun(cmd *cobra.Command, args []string) {
 		"workgroup_id":                 common.ApplicationID,
 	}
 
-	authorizedBearerToken := vendJWT(vaults[0].ID.String(), params)
+	authorizedBearerToken, err := vendJWT(vaults[0].ID.String(), email, params)
+	if err != nil {
+		return "", err
+	}
 	params["authorized_bearer_token"] = authorizedBearerToken
 
 	if common.OrganizationID != "" {
@@ -106,31 +134,27 @@ func inviteParticipantRun(cmd *cobra.Command, args []string) {
 		"params":         params,
 	})
 	if err != nil {
-		log.Printf("failed to invite baseline workgroup participants; %s", err.Error())
-		os.Exit(1)
+		return "", common.NewAPIError(err, "failed to invite baseline workgroup participants")
 	}
 
-	log.Printf("invited baseline workgroup participant: %s\n\n\t%s", email, authorizedBearerToken)
+	return authorizedBearerToken, nil
 }
 
-func vendJWT(vaultID string, params map[string]interface{}) string {
+func vendJWT(vaultID, email string, params map[string]interface{}) (string, error) {
 	keys, err := vault.ListKeys(common.OrganizationAccessToken, vaultID, map[string]interface{}{
 		"spec": "RSA-4096",
 	})
 	if err != nil {
-		log.Printf("failed to resolve RSA-4096 key for organization; %s", err.Error())
-		os.Exit(1)
+		return "", common.NewAPIError(err, "failed to resolve RSA-4096 key for organization")
 	}
 	if len(keys) == 0 {
-		log.Print("failed to resolve RSA-4096 key for organization")
-		os.Exit(1)
+		return "", common.NewNotFoundError("failed to resolve RSA-4096 key for organization")
 	}
 	key := keys[0]
 
 	org, err := ident.GetOrganizationDetails(common.OrganizationAccessToken, common.OrganizationID, map[string]interface{}{})
 	if err != nil {
-		log.Printf("failed to vend JWT; %s", err.Error())
-		os.Exit(1)
+		return "", common.NewAPIError(err, "failed to vend JWT")
 	}
 
 	issuedAt := time.Now()
@@ -145,8 +169,7 @@ func vendJWT(vaultID string, params map[string]interface{}) string {
 
 	natsClaims, err := encodeJWTNatsClaims()
 	if err != nil {
-		log.Printf("failed to encode NATS claims in JWT; %s", err.Error())
-		os.Exit(1)
+		return "", fmt.Errorf("failed to encode NATS claims in JWT; %w", err)
 	}
 	if natsClaims != nil {
 		claims["nats"] = natsClaims
@@ -154,14 +177,12 @@ func vendJWT(vaultID string, params map[string]interface{}) string {
 
 	publicKey, err := pgputil.DecodeRSAPublicKeyFromPEM([]byte(*key.PublicKey))
 	if err != nil {
-		log.Printf("failed to decode RSA public key from PEM; %s", err.Error())
-		os.Exit(1)
+		return "", fmt.Errorf("failed to decode RSA public key from PEM; %w", err)
 	}
 
 	sshPublicKey, err := ssh.NewPublicKey(publicKey)
 	if err != nil {
-		log.Printf("failed to decode SSH public key for fingerprinting; %s", err.Error())
-		os.Exit(1)
+		return "", fmt.Errorf("failed to decode SSH public key for fingerprinting; %w", err)
 	}
 	fingerprint := ssh.FingerprintLegacyMD5(sshPublicKey)
 
@@ -170,8 +191,7 @@ func vendJWT(vaultID string, params map[string]interface{}) string {
 
 	strToSign, err := jwtToken.SigningString()
 	if err != nil {
-		log.Printf("failed to generate JWT string for signing; %s", err.Error())
-		os.Exit(1)
+		return "", fmt.Errorf("failed to generate JWT string for signing; %w", err)
 	}
 
 	opts := map[string]interface{}{}
@@ -187,18 +207,16 @@ func vendJWT(vaultID string, params map[string]interface{}) string {
 		opts,
 	)
 	if err != nil {
-		log.Printf("WARNING: failed to sign JWT using vault key: %s; %s", key.ID, err.Error())
-		os.Exit(1)
+		return "", common.NewAPIError(err, "failed to sign JWT using vault key: %s", key.ID)
 	}
 
 	sigAsBytes, err := hex.DecodeString(*resp.Signature)
 	if err != nil {
-		log.Printf("failed to decode signature from hex; %s", err.Error())
-		os.Exit(1)
+		return "", fmt.Errorf("failed to decode signature from hex; %w", err)
 	}
 
 	encodedSignature := strings.TrimRight(base64.URLEncoding.EncodeToString(sigAsBytes), "=")
-	return strings.Join([]string{strToSign, encodedSignature}, ".")
+	return strings.Join([]string{strToSign, encodedSignature}, "."), nil
 }
 
 func encodeJWTNatsClaims() (map[string]interface{}, error) {
@@ -271,32 +289,16 @@ func encodeJWTNatsClaims() (map[string]interface{}, error) {
 	return natsClaims, nil
 }
 
-func namePrompt() {
-	prompt := promptui.Prompt{
-		Label: "Invitee Name",
-	}
-
-	result, err := prompt.Run()
-	if err != nil {
-		os.Exit(1)
-		return
-	}
-
-	name = result
+func namePrompt() error {
+	var err error
+	name, err = common.RequireFreeInput("--name", "Invitee Name", "", common.NoValidation)
+	return err
 }
 
-func emailPrompt() {
-	prompt := promptui.Prompt{
-		Label: "Invitee Email",
-	}
-
-	result, err := prompt.Run()
-	if err != nil {
-		os.Exit(1)
-		return
-	}
-
-	email = result
+func emailPrompt() error {
+	var err error
+	email, err = common.RequireFreeInput("--email", "Invitee Email", "", common.NoValidation)
+	return err
 }
 
 func init() {
@@ -2,8 +2,6 @@ package participants
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	"github.com/provideservices/provide-go/api/ident"
@@ -14,35 +12,49 @@ var listBaselineWorkgroupParticipantsCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List workgroup participants",
 	Long:  `List the participating and invited parties in a baseline workgroup`,
-	Run:   listParticipants,
+	RunE:  listParticipants,
 }
 
-func listParticipants(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepList)
+func listParticipants(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepList)
 }
 
-func listParticipantsRun(cmd *cobra.Command, args []string) {
-	common.AuthorizeApplicationContext()
-	common.AuthorizeOrganizationContext(false)
+func listParticipantsRun(cmd *cobra.Command, args []string) error {
+	if err := common.AuthorizeApplicationContext(); err != nil {
+		return err
+	}
+	if err := common.AuthorizeOrganizationContext(false); err != nil {
+		return err
+	}
 
 	participants, err := ident.ListApplicationOrganizations(common.OrganizationAccessToken, common.ApplicationID, map[string]interface{}{
 		"type": "baseline",
 	})
 	if err != nil {
-		log.Printf("failed to retrieve baseline workgroup participants; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "failed to retrieve baseline workgroup participants")
 	}
 
 	invitations, err := ident.ListApplicationInvitations(common.ApplicationAccessToken, common.ApplicationID, map[string]interface{}{})
 	if err != nil {
-		// log.Printf("failed to retrieve invited baseline workgroup participants; %s", err.Error())
-		// os.Exit(1)
+		return common.NewAPIError(err, "failed to retrieve invited baseline workgroup participants")
+	}
+
+	if common.IsStructuredOutput() {
+		err = common.Render(map[string]interface{}{
+			"organizations": participants,
+			"invitations":   invitations,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to render baseline workgroup participants; %w", err)
+		}
+		return nil
 	}
 
 	if len(participants) > 0 {
 		fmt.Print("Organizations:\n")
 	}
 
+	table := common.NewTable("ID", "NAME", "ADDRESS", "MESSAGING ENDPOINT")
 	for i := range participants {
 		participant := participants[i]
 
@@ -55,22 +67,33 @@ func listParticipantsRun(cmd *cobra.Command, args []string) {
 		if msgEndpoint, msgEndpointOk := participant.Metadata["messaging_endpoint"].(string); msgEndpointOk {
 			endpoint = msgEndpoint
 		}
-		result := fmt.Sprintf("%s\t%s\t%s\t%s\n", participant.ID.String(), *participant.Name, address, endpoint)
-		fmt.Print(result)
+		table.AddRow(participant.ID.String(), common.StringValue(participant.Name), address, endpoint)
+	}
+	if len(participants) > 0 {
+		if err := common.Render(participants, table); err != nil {
+			return fmt.Errorf("failed to render baseline workgroup participants; %w", err)
+		}
 	}
 
 	if len(invitations) > 0 {
 		fmt.Print("\nPending Invitations:\n")
 	}
 
+	table = common.NewTable("ID", "EMAIL")
 	for i := range invitations {
 		invitedParticipant := invitations[i]
-		result := fmt.Sprintf("%s\t%s\n", invitedParticipant.ID.String(), invitedParticipant.Email)
-		fmt.Print(result)
+		table.AddRow(invitedParticipant.ID.String(), invitedParticipant.Email)
+	}
+	if len(invitations) > 0 {
+		if err := common.Render(invitations, table); err != nil {
+			return fmt.Errorf("failed to render invited baseline workgroup participants; %w", err)
+		}
 	}
+	return nil
 }
 
 func init() {
 	listBaselineWorkgroupParticipantsCmd.Flags().StringVar(&common.ApplicationID, "workgroup", "", "workgroup identifier")
+	listBaselineWorkgroupParticipantsCmd.Flags().StringVar(&common.OrganizationID, "organization", "", "organization identifier")
 	listBaselineWorkgroupParticipantsCmd.Flags().BoolVarP(&Optional, "Optional", "", false, "List all the Optional flags")
 }
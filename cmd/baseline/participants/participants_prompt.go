@@ -19,41 +19,65 @@ var custodyPromptArgs = []string{"No", "Yes"}
 var custodyPromptLabel = "Would you like the participant to be a managed tenant?"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, step string) {
+func generalPrompt(cmd *cobra.Command, args []string, step string) error {
+	var err error
+
 	switch step {
 	case promptStepInvite:
 		if Optional {
 			fmt.Println("Optional Flags:")
 			if common.ApplicationID == "" {
-				common.RequireApplication()
+				if err := common.RequireApplication(); err != nil {
+					return err
+				}
 			}
 			if common.OrganizationID == "" {
-				common.RequireOrganization()
+				if err := common.RequireOrganization(); err != nil {
+					return err
+				}
 			}
 			if !managedTenant {
-				managedTenant = common.SelectInput(custodyPromptArgs, custodyPromptLabel) == "Yes"
+				result, err := common.SelectInput(custodyPromptArgs, custodyPromptLabel)
+				if err != nil {
+					return err
+				}
+				managedTenant = result == "Yes"
 			}
 			if name == "" {
-				name = common.FreeInput("Wallet Name", "", common.NoValidation)
+				if name, err = common.FreeInput("Wallet Name", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if email == "" {
-				email = common.FreeInput("Wallet Purpose", "", common.NoValidation)
+				if email, err = common.FreeInput("Wallet Purpose", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if permissions == 0 {
-				permissions, _ = strconv.Atoi(common.FreeInput("Wallet Purpose", "0", common.NoValidation))
+				result, err := common.FreeInput("Wallet Purpose", "0", common.NoValidation)
+				if err != nil {
+					return err
+				}
+				permissions, _ = strconv.Atoi(result)
 			}
 		}
-		inviteParticipantRun(cmd, args)
+		return inviteParticipantRun(cmd, args)
 	case promptStepList:
 		if Optional {
 			fmt.Println("Optional Flags:")
 			if common.ApplicationID == "" {
-				common.RequireApplication()
+				if err := common.RequireApplication(); err != nil {
+					return err
+				}
 			}
 		}
-		listParticipantsRun(cmd, args)
+		return listParticipantsRun(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
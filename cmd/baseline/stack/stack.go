@@ -9,16 +9,28 @@ var StackCmd = &cobra.Command{
 	Use:   "stack",
 	Short: "Interact with a local baseline stack",
 	Long:  `Create, manage and interact with local baseline stack instances.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		common.CmdExistsOrExit(cmd, args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
 
-		generalPrompt(cmd, args, "")
+		return generalPrompt(cmd, args, "")
 	},
 }
 
 func init() {
+	StackCmd.AddCommand(backupBaselineStackCmd)
+	StackCmd.AddCommand(configBaselineStackCmd)
+	StackCmd.AddCommand(doctorBaselineStackCmd)
+	StackCmd.AddCommand(exportBaselineStackCmd)
+	StackCmd.AddCommand(imagesBaselineStackCmd)
 	StackCmd.AddCommand(logsBaselineStackCmd)
+	StackCmd.AddCommand(mockSORBaselineStackCmd)
+	StackCmd.AddCommand(restoreBaselineStackCmd)
 	StackCmd.AddCommand(runBaselineStackCmd)
+	StackCmd.AddCommand(simulateBaselineStackCmd)
+	StackCmd.AddCommand(sorBaselineStackCmd)
+	StackCmd.AddCommand(statusBaselineStackCmd)
 	StackCmd.AddCommand(stopBaselineStackCmd)
 	StackCmd.Flags().BoolVarP(&Optional, "optional", "", false, "List all the optional flags")
 }
@@ -0,0 +1,288 @@
+package stack
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/provideservices/provide-cli/cmd/common"
+
+	"github.com/spf13/cobra"
+)
+
+// entries of a stack backup archive
+const backupManifestEntry = "manifest.json"
+const backupPostgresDir = "postgres"
+const backupRedisDir = "redis"
+const backupNATSStreamingDir = "nats-streaming"
+
+const defaultBackupPath = "baseline-stack-backup.tar.gz"
+
+var backupOutputPath string
+
+var backupBaselineStackCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up the state of the baseline stack",
+	Long: `Write the state of a running local baseline stack instance to a gzipped tarball.
+
+The archive contains a pg_dump of each local database, the redis dataset and the NATS streaming file store.`,
+	RunE: backupProxy,
+}
+
+// stackBackupManifest describes the contents of a stack backup archive
+type stackBackupManifest struct {
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Databases []string  `json:"databases"`
+	Services  []string  `json:"services"`
+}
+
+func backupProxy(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepBackup)
+}
+
+func backupProxyRun(cmd *cobra.Command, args []string) error {
+	docker, err := client.NewEnvClient()
+	if err != nil {
+		return fmt.Errorf("failed to initialize docker; %w", err)
+	}
+
+	containers, err := runningServices(docker)
+	if err != nil {
+		return err
+	}
+	if len(containers) == 0 {
+		return common.NewNotFoundError("%s local baseline instance is not running", name)
+	}
+
+	// the archive is written to a temporary file alongside the output path and renamed
+	// into place once complete, so a failed backup never clobbers an existing archive
+	out, err := ioutil.TempFile(filepath.Dir(backupOutputPath), fmt.Sprintf(".%s-*", filepath.Base(backupOutputPath)))
+	if err != nil {
+		return fmt.Errorf("failed to create %s; %w", backupOutputPath, err)
+	}
+	defer func() {
+		out.Close()
+		os.Remove(out.Name())
+	}()
+
+	gz := gzip.NewWriter(out)
+	archive := tar.NewWriter(gz)
+
+	manifest := &stackBackupManifest{
+		Name:      name,
+		Version:   common.Version,
+		CreatedAt: time.Now().UTC(),
+		Databases: make([]string, 0),
+		Services:  make([]string, 0),
+	}
+
+	if id, ok := containers[servicePostgres]; ok {
+		databases, err := listDatabases(docker, id)
+		if err != nil {
+			return err
+		}
+		for _, db := range databases {
+			log.Printf("dumping %s database", db)
+			dump, err := execInContainer(docker, id, nil, "pg_dump", "-U", postgresUser, "--clean", "--if-exists", "--create", db)
+			if err != nil {
+				return fmt.Errorf("failed to dump %s database; %w", db, err)
+			}
+			if err := writeArchiveEntry(archive, path.Join(backupPostgresDir, fmt.Sprintf("%s.sql", db)), dump); err != nil {
+				return err
+			}
+			manifest.Databases = append(manifest.Databases, db)
+		}
+		manifest.Services = append(manifest.Services, servicePostgres)
+	}
+
+	if id, ok := containers[serviceRedis]; ok {
+		log.Printf("saving redis dataset")
+		if _, err := execInContainer(docker, id, nil, "redis-cli", "SAVE"); err != nil {
+			return fmt.Errorf("failed to save redis dataset; %w", err)
+		}
+		if err := copyFromContainer(docker, id, redisDataPath, backupRedisDir, archive); err != nil {
+			return err
+		}
+		manifest.Services = append(manifest.Services, serviceRedis)
+	}
+
+	if id, ok := containers[serviceNATSStreaming]; ok {
+		log.Printf("copying NATS streaming file store")
+		if err := copyFromContainer(docker, id, natsStreamingDataPath, backupNATSStreamingDir, archive); err != nil {
+			return err
+		}
+		manifest.Services = append(manifest.Services, serviceNATSStreaming)
+	}
+
+	raw, _ := json.MarshalIndent(manifest, "", "  ")
+	if err := writeArchiveEntry(archive, backupManifestEntry, raw); err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to write %s; %w", backupOutputPath, err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write %s; %w", backupOutputPath, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write %s; %w", backupOutputPath, err)
+	}
+	if err := os.Rename(out.Name(), backupOutputPath); err != nil {
+		return fmt.Errorf("failed to write %s; %w", backupOutputPath, err)
+	}
+
+	log.Printf("backed up %s local baseline instance: %s", name, backupOutputPath)
+	return nil
+}
+
+// runningServices maps each running service of the named stack to its container id
+func runningServices(docker *client.Client) (map[string]string, error) {
+	containers, err := listContainers(docker)
+	if err != nil {
+		return nil, err
+	}
+
+	services := map[string]string{}
+	for _, container := range containers {
+		if container.State == "running" {
+			services[stackService(container)] = container.ID
+		}
+	}
+	return services, nil
+}
+
+// listDatabases returns the names of the databases in the local postgres service
+func listDatabases(docker *client.Client, containerID string) ([]string, error) {
+	out, err := execInContainer(docker, containerID, nil,
+		"psql", "-U", postgresUser, "-d", postgresUser, "-A", "-t",
+		"-c", "SELECT datname FROM pg_database WHERE NOT datistemplate AND datname <> 'postgres'",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list databases; %w", err)
+	}
+
+	databases := make([]string, 0)
+	for _, db := range strings.Split(string(out), "\n") {
+		if db = strings.TrimSpace(db); db != "" {
+			databases = append(databases, db)
+		}
+	}
+	sort.Strings(databases)
+	return databases, nil
+}
+
+// execInContainer runs the given command in the container and returns its stdout; stdin,
+// if given, is written to the command
+func execInContainer(docker *client.Client, containerID string, stdin []byte, cmd ...string) ([]byte, error) {
+	ctx := context.Background()
+	exec, err := docker.ContainerExecCreate(ctx, containerID, types.ExecConfig{
+		AttachStdin:  stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := docker.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Close()
+
+	if stdin != nil {
+		go func() {
+			resp.Conn.Write(stdin)
+			resp.CloseWrite()
+		}()
+	}
+
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, resp.Reader); err != nil {
+		return nil, err
+	}
+
+	inspect, err := docker.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return nil, err
+	}
+	if inspect.ExitCode != 0 {
+		return nil, fmt.Errorf("%s exited with status %d; %s", cmd[0], inspect.ExitCode, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// copyFromContainer writes the contents of the given directory in the container to the
+// archive beneath dir
+func copyFromContainer(docker *client.Client, containerID, srcPath, dir string, archive *tar.Writer) error {
+	reader, _, err := docker.CopyFromContainer(context.Background(), containerID, srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to copy %s from container; %w", srcPath, err)
+	}
+	defer reader.Close()
+
+	src := tar.NewReader(reader)
+	for {
+		hdr, err := src.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to copy %s from container; %w", srcPath, err)
+		}
+
+		// entries are relative to the parent of srcPath; i.e., data/dump.rdb
+		parts := strings.SplitN(hdr.Name, "/", 2)
+		if len(parts) < 2 || parts[1] == "" {
+			continue
+		}
+
+		hdr.Name = path.Join(dir, parts[1])
+		if err := archive.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(archive, src); err != nil {
+			return err
+		}
+	}
+}
+
+func writeArchiveEntry(archive *tar.Writer, name string, content []byte) error {
+	err := archive.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s to archive; %w", name, err)
+	}
+	if _, err := archive.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s to archive; %w", name, err)
+	}
+	return nil
+}
+
+func init() {
+	backupBaselineStackCmd.Flags().StringVar(&name, "name", "baseline-local", "name of the baseline stack instance")
+	backupBaselineStackCmd.Flags().StringVar(&backupOutputPath, "out", defaultBackupPath, "path of the backup archive to write")
+}
@@ -0,0 +1,429 @@
+package stack
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	uuid "github.com/kthomas/go.uuid"
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+// stackDefinitionVersion is the current version of the stack definition file schema
+const stackDefinitionVersion = 1
+
+const defaultStackDefinitionPath = "baseline-stack.yaml"
+
+// stackDefinitionPath is the stack definition file managed by the config commands
+var stackDefinitionPath string
+
+var stackNameRegex = regexp.MustCompile("^[a-zA-Z0-9][a-zA-Z0-9_.-]*$")
+var stackLogLevels = []string{"TRACE", "DEBUG", "INFO", "WARNING", "ERROR", "CRITICAL"}
+
+var configBaselineStackCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage baseline stack definition files",
+	Long: `Manage declarative baseline stack definition files.
+
+A stack definition file describes the services, ports, hostnames, remote or local
+ident, nchain, privacy and vault services, system of record and NATS settings of a
+local baseline stack instance; run the stack using 'prvd baseline stack run -f baseline-stack.yaml'.
+Flags passed to 'stack run' override the values in the file.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
+
+		return configPrompt(cmd, args, "")
+	},
+}
+
+// stackDefinition is a declarative baseline stack definition; zero values are
+// left unset so the corresponding flag defaults apply
+type stackDefinition struct {
+	Version int    `yaml:"version"`
+	Name    string `yaml:"name,omitempty"`
+
+	Organization struct {
+		ID           string `yaml:"id,omitempty"`
+		Address      string `yaml:"address,omitempty"`
+		RefreshToken string `yaml:"refresh_token,omitempty"`
+	} `yaml:"organization,omitempty"`
+
+	Workgroup struct {
+		ID                      string `yaml:"id,omitempty"`
+		RegistryContractAddress string `yaml:"registry_contract_address,omitempty"`
+		NetworkID               string `yaml:"network_id,omitempty"`
+	} `yaml:"workgroup,omitempty"`
+
+	Endpoints struct {
+		API             string `yaml:"api,omitempty"`
+		Messaging       string `yaml:"messaging,omitempty"`
+		Tunnel          bool   `yaml:"tunnel,omitempty"`
+		APITunnel       bool   `yaml:"api_tunnel,omitempty"`
+		MessagingTunnel bool   `yaml:"messaging_tunnel,omitempty"`
+	} `yaml:"endpoints,omitempty"`
+
+	Services struct {
+		API           stackServiceDefinition `yaml:"api,omitempty"`
+		Consumer      stackServiceDefinition `yaml:"consumer,omitempty"`
+		NATS          stackNATSDefinition    `yaml:"nats,omitempty"`
+		NATSStreaming stackServiceDefinition `yaml:"nats_streaming,omitempty"`
+		Postgres      stackServiceDefinition `yaml:"postgres,omitempty"`
+		Redis         stackRedisDefinition   `yaml:"redis,omitempty"`
+		Ident         stackRemoteDefinition  `yaml:"ident,omitempty"`
+		NChain        stackRemoteDefinition  `yaml:"nchain,omitempty"`
+		Privacy       stackRemoteDefinition  `yaml:"privacy,omitempty"`
+		Vault         stackVaultDefinition   `yaml:"vault,omitempty"`
+	} `yaml:"services,omitempty"`
+
+	SOR struct {
+		ID   string                 `yaml:"id,omitempty"`
+		URL  string                 `yaml:"url,omitempty"`
+		Mock stackMockSORDefinition `yaml:"mock,omitempty"`
+
+		// APIs configures the API of each system of record, keyed by identifier; i.e., sap
+		APIs map[string]stackSORAPIDefinition `yaml:",inline"`
+	} `yaml:"sor,omitempty"`
+
+	LogLevel           string `yaml:"log_level,omitempty"`
+	AutoRemove         bool   `yaml:"autoremove,omitempty"`
+	JWTSignerPublicKey string `yaml:"jwt_signer_public_key,omitempty"`
+}
+
+// stackServiceDefinition is the container hostname and host port of a stack service
+type stackServiceDefinition struct {
+	Hostname string `yaml:"hostname,omitempty"`
+	Port     int    `yaml:"port,omitempty"`
+}
+
+// stackNATSDefinition is the configuration of the stack NATS service
+type stackNATSDefinition struct {
+	Hostname      string                 `yaml:"hostname,omitempty"`
+	Port          int                    `yaml:"port,omitempty"`
+	WebsocketPort int                    `yaml:"websocket_port,omitempty"`
+	AuthToken     string                 `yaml:"auth_token,omitempty"`
+	TLS           stackNATSTLSDefinition `yaml:"tls,omitempty"`
+}
+
+// stackNATSTLSDefinition configures TLS for the stack NATS services; TLS is enabled unless disabled
+type stackNATSTLSDefinition struct {
+	Disabled    bool   `yaml:"disabled,omitempty"`
+	Certificate string `yaml:"certificate,omitempty"`
+	Key         string `yaml:"key,omitempty"`
+	CA          string `yaml:"ca,omitempty"`
+}
+
+// stackRedisDefinition is the configuration of the stack redis service
+type stackRedisDefinition struct {
+	Hostname string `yaml:"hostname,omitempty"`
+	Port     int    `yaml:"port,omitempty"`
+	Hosts    string `yaml:"hosts,omitempty"`
+}
+
+// stackRemoteDefinition configures a Provide service which is either run locally
+// as part of the stack, or used remotely
+type stackRemoteDefinition struct {
+	Local  bool   `yaml:"local,omitempty"`
+	Port   int    `yaml:"port,omitempty"`
+	Host   string `yaml:"host,omitempty"`
+	Scheme string `yaml:"scheme,omitempty"`
+}
+
+// stackVaultDefinition configures the vault service
+type stackVaultDefinition struct {
+	stackRemoteDefinition `yaml:",inline"`
+	RefreshToken          string `yaml:"refresh_token,omitempty"`
+	SealUnsealKey         string `yaml:"seal_unseal_key,omitempty"`
+}
+
+// stackSORAPIDefinition configures the API of a system of record
+type stackSORAPIDefinition struct {
+	Host     string `yaml:"host,omitempty"`
+	Scheme   string `yaml:"scheme,omitempty"`
+	Path     string `yaml:"path,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+// stackMockSORDefinition configures the mock system of record run in place of the system of record API
+type stackMockSORDefinition struct {
+	ID       string `yaml:"id,omitempty"`
+	Port     int    `yaml:"port,omitempty"`
+	Fixtures string `yaml:"fixtures,omitempty"`
+}
+
+// readStackDefinition reads and validates the stack definition file at the given path
+func readStackDefinition(path string) (*stackDefinition, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, common.NewNotFoundError("stack definition file not found: %s", path)
+		}
+		return nil, fmt.Errorf("failed to read stack definition file %s; %w", path, err)
+	}
+
+	def := &stackDefinition{}
+	if err := yaml.UnmarshalStrict(raw, def); err != nil {
+		return nil, common.NewValidationError("failed to parse stack definition file %s; %s", path, err.Error())
+	}
+
+	if errs := def.validate(); len(errs) > 0 {
+		return nil, common.NewValidationError("invalid stack definition file %s:\n  - %s", path, strings.Join(errs, "\n  - "))
+	}
+
+	return def, nil
+}
+
+// validate returns a description of each problem with the definition
+func (d *stackDefinition) validate() []string {
+	errs := make([]string, 0)
+
+	if d.Version == 0 {
+		errs = append(errs, "version: required")
+	} else if d.Version != stackDefinitionVersion {
+		errs = append(errs, fmt.Sprintf("version: unsupported version: %d; this version of prvd supports version %d", d.Version, stackDefinitionVersion))
+	}
+
+	if d.Name != "" && !stackNameRegex.MatchString(d.Name) {
+		errs = append(errs, fmt.Sprintf("name: invalid stack name: %s", d.Name))
+	}
+
+	for key, val := range map[string]string{
+		"organization.id":      d.Organization.ID,
+		"workgroup.id":         d.Workgroup.ID,
+		"workgroup.network_id": d.Workgroup.NetworkID,
+	} {
+		if _, err := uuid.FromString(val); val != "" && err != nil {
+			errs = append(errs, fmt.Sprintf("%s: invalid identifier: %s", key, val))
+		}
+	}
+
+	for key, val := range map[string]string{
+		"organization.address":                d.Organization.Address,
+		"workgroup.registry_contract_address": d.Workgroup.RegistryContractAddress,
+	} {
+		if val != "" && !strings.HasPrefix(val, "0x") {
+			errs = append(errs, fmt.Sprintf("%s: invalid address: %s", key, val))
+		}
+	}
+
+	hostPorts := d.hostPorts()
+	keys := make([]string, 0)
+	for key := range hostPorts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	ports := map[int]string{}
+	for _, key := range keys {
+		port := hostPorts[key]
+		if port == 0 {
+			continue
+		}
+		if port < 1 || port > 65535 {
+			errs = append(errs, fmt.Sprintf("%s: invalid port: %d", key, port))
+		} else if other, ok := ports[port]; ok {
+			errs = append(errs, fmt.Sprintf("%s: port %d is already used by %s", key, port, other))
+		} else {
+			ports[port] = key
+		}
+	}
+
+	for key, scheme := range map[string]string{
+		"services.ident.scheme":   d.Services.Ident.Scheme,
+		"services.nchain.scheme":  d.Services.NChain.Scheme,
+		"services.privacy.scheme": d.Services.Privacy.Scheme,
+		"services.vault.scheme":   d.Services.Vault.Scheme,
+	} {
+		if scheme != "" && scheme != "http" && scheme != "https" {
+			errs = append(errs, fmt.Sprintf("%s: invalid scheme: %s; must be http or https", key, scheme))
+		}
+	}
+
+	if d.SOR.ID != "" && lookupSystemOfRecord(d.SOR.ID) == nil {
+		errs = append(errs, fmt.Sprintf("sor.id: unsupported system of record: %s; must be one of %s", d.SOR.ID, strings.Join(sorIdentifiers(), ", ")))
+	}
+
+	for id, api := range d.SOR.APIs {
+		sor, ok := sorRegistry[id].(*sorAPIConnector)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("sor.%s: unsupported system of record API", id))
+			continue
+		}
+		if api.Scheme != "" && api.Scheme != "http" && api.Scheme != "https" {
+			errs = append(errs, fmt.Sprintf("sor.%s.scheme: invalid scheme: %s; must be http or https", id, api.Scheme))
+		}
+		if !sor.basicAuth && (api.Username != "" || api.Password != "") {
+			errs = append(errs, fmt.Sprintf("sor.%s: basic authorization is not supported", id))
+		}
+	}
+
+	if d.SOR.Mock.ID != "" && !containsString(mockSORIdentifiers, d.SOR.Mock.ID) {
+		errs = append(errs, fmt.Sprintf("sor.mock.id: unsupported mock system of record: %s; must be one of %s", d.SOR.Mock.ID, strings.Join(mockSORIdentifiers, ", ")))
+	}
+
+	if (d.Services.NATS.TLS.Certificate == "") != (d.Services.NATS.TLS.Key == "") {
+		errs = append(errs, "services.nats.tls: certificate and key must be set together")
+	}
+
+	if d.LogLevel != "" && !containsString(stackLogLevels, strings.ToUpper(d.LogLevel)) {
+		errs = append(errs, fmt.Sprintf("log_level: unsupported log level: %s; must be one of %s", d.LogLevel, strings.Join(stackLogLevels, ", ")))
+	}
+
+	// map iteration order is random; report problems in a stable order
+	sort.Strings(errs)
+	return errs
+}
+
+// hostPorts returns the host ports of the definition, keyed by their path in the file
+func (d *stackDefinition) hostPorts() map[string]int {
+	return map[string]int{
+		"services.api.port":            d.Services.API.Port,
+		"services.nats.port":           d.Services.NATS.Port,
+		"services.nats.websocket_port": d.Services.NATS.WebsocketPort,
+		"services.nats_streaming.port": d.Services.NATSStreaming.Port,
+		"services.postgres.port":       d.Services.Postgres.Port,
+		"services.redis.port":          d.Services.Redis.Port,
+		"services.ident.port":          d.Services.Ident.Port,
+		"services.nchain.port":         d.Services.NChain.Port,
+		"services.privacy.port":        d.Services.Privacy.Port,
+		"services.vault.port":          d.Services.Vault.Port,
+		"sor.mock.port":                d.SOR.Mock.Port,
+	}
+}
+
+// flagValues maps the values set in the definition to the corresponding 'stack run' flags
+func (d *stackDefinition) flagValues() map[string]string {
+	values := map[string]string{}
+	set := func(flag string, val interface{}) {
+		switch v := val.(type) {
+		case string:
+			if v != "" {
+				values[flag] = v
+			}
+		case int:
+			if v != 0 {
+				values[flag] = strconv.Itoa(v)
+			}
+		case bool:
+			if v {
+				values[flag] = strconv.FormatBool(v)
+			}
+		}
+	}
+
+	set("name", d.Name)
+	set("organization", d.Organization.ID)
+	set("organization-address", d.Organization.Address)
+	set("organization-refresh-token", d.Organization.RefreshToken)
+	set("workgroup", d.Workgroup.ID)
+	set("registry-contract-address", d.Workgroup.RegistryContractAddress)
+	set("nchain-network-id", d.Workgroup.NetworkID)
+
+	set("api-endpoint", d.Endpoints.API)
+	set("messaging-endpoint", d.Endpoints.Messaging)
+	set("tunnel", d.Endpoints.Tunnel)
+	set("api-tunnel", d.Endpoints.APITunnel)
+	set("messaging-tunnel", d.Endpoints.MessagingTunnel)
+
+	set("hostname", d.Services.API.Hostname)
+	set("port", d.Services.API.Port)
+	set("consumer-hostname", d.Services.Consumer.Hostname)
+	set("nats-hostname", d.Services.NATS.Hostname)
+	set("nats-port", d.Services.NATS.Port)
+	set("nats-ws-port", d.Services.NATS.WebsocketPort)
+	set("nats-auth-token", d.Services.NATS.AuthToken)
+	if d.Services.NATS.TLS.Disabled {
+		values["nats-tls"] = "false"
+	}
+	set("nats-tls-cert", d.Services.NATS.TLS.Certificate)
+	set("nats-tls-key", d.Services.NATS.TLS.Key)
+	set("nats-tls-ca", d.Services.NATS.TLS.CA)
+	set("nats-streaming-hostname", d.Services.NATSStreaming.Hostname)
+	set("nats-streaming-port", d.Services.NATSStreaming.Port)
+	set("postgres-hostname", d.Services.Postgres.Hostname)
+	set("postgres-port", d.Services.Postgres.Port)
+	set("redis-hostname", d.Services.Redis.Hostname)
+	set("redis-port", d.Services.Redis.Port)
+	set("redis-hosts", d.Services.Redis.Hosts)
+
+	set("with-local-ident", d.Services.Ident.Local)
+	set("ident-local-port", d.Services.Ident.Port)
+	set("ident-host", d.Services.Ident.Host)
+	set("ident-scheme", d.Services.Ident.Scheme)
+	set("with-local-nchain", d.Services.NChain.Local)
+	set("nchain-local-port", d.Services.NChain.Port)
+	set("nchain-host", d.Services.NChain.Host)
+	set("nchain-scheme", d.Services.NChain.Scheme)
+	set("with-local-privacy", d.Services.Privacy.Local)
+	set("privacy-local-port", d.Services.Privacy.Port)
+	set("privacy-host", d.Services.Privacy.Host)
+	set("privacy-scheme", d.Services.Privacy.Scheme)
+	set("with-local-vault", d.Services.Vault.Local)
+	set("vault-local-port", d.Services.Vault.Port)
+	set("vault-host", d.Services.Vault.Host)
+	set("vault-scheme", d.Services.Vault.Scheme)
+	set("vault-refresh-token", d.Services.Vault.RefreshToken)
+	set("vault-seal-unseal-key", d.Services.Vault.SealUnsealKey)
+
+	set("sor", d.SOR.ID)
+	set("sor-url", d.SOR.URL)
+	for id, api := range d.SOR.APIs {
+		set(fmt.Sprintf("%s-api-host", id), api.Host)
+		set(fmt.Sprintf("%s-api-scheme", id), api.Scheme)
+		set(fmt.Sprintf("%s-api-path", id), api.Path)
+		set(fmt.Sprintf("%s-api-username", id), api.Username)
+		set(fmt.Sprintf("%s-api-password", id), api.Password)
+	}
+	set("mock-sor", d.SOR.Mock.ID)
+	set("mock-sor-port", d.SOR.Mock.Port)
+	set("mock-sor-fixtures", d.SOR.Mock.Fixtures)
+
+	set("log-level", d.LogLevel)
+	set("autoremove", d.AutoRemove)
+	set("jwt-signer-public-key", d.JWTSignerPublicKey)
+
+	return values
+}
+
+// applyStackDefinition sets each of the given flags which was not explicitly passed
+// to the corresponding value of the stack definition file at the given path
+func applyStackDefinition(flags *pflag.FlagSet, path string) error {
+	def, err := readStackDefinition(path)
+	if err != nil {
+		return err
+	}
+
+	for flag, val := range def.flagValues() {
+		if flags.Changed(flag) {
+			continue
+		}
+		if err := flags.Set(flag, val); err != nil {
+			return common.NewValidationError("invalid value for %s in stack definition file %s; %s", flag, path, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func containsString(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	configBaselineStackCmd.AddCommand(configInitBaselineStackCmd)
+	configBaselineStackCmd.AddCommand(configValidateBaselineStackCmd)
+}
@@ -0,0 +1,204 @@
+package stack
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var overwriteStackDefinition bool
+
+var configInitBaselineStackCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a default baseline stack definition file",
+	Long:  `Write a fully commented baseline stack definition file using the default value of each setting`,
+	RunE:  configInitProxy,
+}
+
+// defaultStackDefinition is written by 'stack config init'; each setting is documented
+// and set to the default of the corresponding 'stack run' flag
+const defaultStackDefinition = `# baseline stack definition; run using 'prvd baseline stack run -f baseline-stack.yaml'
+#
+# Flags passed to 'prvd baseline stack run' override the values in this file. Settings
+# which are omitted or left empty use the default of the corresponding flag.
+
+# version of the stack definition schema
+version: 1
+
+# name of the baseline stack instance; containers and hostnames are prefixed with the name
+name: baseline-local
+
+organization:
+  # organization identifier; defaults to $PROVIDE_ORGANIZATION_ID
+  id: ""
+  # public baseline registry address of the organization; defaults to $BASELINE_ORGANIZATION_ADDRESS
+  address: ""
+  # refresh token to vend access tokens on behalf of the organization; prefer setting
+  # $PROVIDE_ORGANIZATION_REFRESH_TOKEN rather than committing the token
+  refresh_token: ""
+
+workgroup:
+  # baseline workgroup identifier
+  id: ""
+  # public baseline registry contract address; defaults to $BASELINE_REGISTRY_CONTRACT_ADDRESS
+  registry_contract_address: ""
+  # nchain network id of the baseline mainnet; defaults to the network of the workgroup
+  network_id: ""
+
+endpoints:
+  # baseline API endpoint for use by one or more authorized systems of record
+  api: ""
+  # public messaging endpoint used for sending and receiving protocol messages
+  messaging: ""
+  # when true, tunnels are established to expose the API and messaging endpoints to the WAN
+  tunnel: false
+  # when true, a tunnel is established to expose the API endpoint to the WAN
+  api_tunnel: false
+  # when true, a tunnel is established to expose the messaging endpoint to the WAN
+  messaging_tunnel: false
+
+services:
+  # baseline API; the hostname defaults to <name>-api
+  api:
+    hostname: ""
+    port: 8080
+
+  # baseline consumer; the hostname defaults to <name>-consumer
+  consumer:
+    hostname: ""
+
+  # NATS messaging; the hostname defaults to <name>-nats
+  nats:
+    hostname: ""
+    port: 4222
+    websocket_port: 4221
+    # authorization token of the NATS services; a random token is generated for each run when empty
+    auth_token: ""
+    tls:
+      # when true, the NATS services accept connections without TLS
+      disabled: false
+      # paths of the PEM-encoded server certificate and key; a certificate is generated for
+      # the stack when empty
+      certificate: ""
+      key: ""
+      # path of the PEM-encoded CA certificate which issued the server certificate; defaults
+      # to the certificate itself
+      ca: ""
+
+  # NATS streaming; the hostname defaults to <name>-nats-streaming
+  nats_streaming:
+    hostname: ""
+    port: 4220
+
+  # postgres; only run when at least one of ident, nchain, privacy or vault is local
+  postgres:
+    hostname: ""
+    port: 5432
+
+  # redis; the hostname defaults to <name>-redis
+  redis:
+    hostname: ""
+    port: 6379
+    # comma-separated list of clustered redis hosts; defaults to <name>-redis:6379
+    hosts: ""
+
+  # ident, nchain, privacy and vault are used remotely at host and scheme unless local
+  # is true, in which case they are run as part of the stack on the given host port
+  ident:
+    local: false
+    port: 8081
+    host: ident.provide.services
+    scheme: https
+
+  nchain:
+    local: false
+    port: 8082
+    host: nchain.provide.services
+    scheme: https
+
+  privacy:
+    local: false
+    port: 8083
+    host: privacy.provide.services
+    scheme: https
+
+  vault:
+    local: false
+    port: 8084
+    host: vault.provide.services
+    scheme: https
+    # refresh token to vend access tokens for use with vault; defaults to $VAULT_REFRESH_TOKEN
+    refresh_token: ""
+    # seal/unseal key for the vault service; defaults to $VAULT_SEAL_UNSEAL_KEY
+    seal_unseal_key: ""
+
+# primary internal system of record being baselined
+sor:
+  # one of dynamics365, ephemeral, excel, salesforce, sap or servicenow
+  id: ""
+  # url of the system of record API
+  url: ""
+
+  sap:
+    host: ""
+    scheme: https
+    path: ubc
+    username: ""
+    password: ""
+
+  servicenow:
+    host: ""
+    scheme: https
+    path: api/now/table
+    username: ""
+    password: ""
+
+  salesforce:
+    host: ""
+    scheme: https
+    path: ""
+
+  # mock system of record run as part of the stack in place of the system of record API;
+  # the id is one of salesforce, sap or servicenow
+  mock:
+    id: ""
+    port: 8085
+    # directory of JSON business object fixtures pushed using 'prvd baseline stack mock-sor push'
+    fixtures: ""
+
+# log level of the services in the stack; one of TRACE, DEBUG, INFO, WARNING, ERROR or CRITICAL
+log_level: DEBUG
+
+# when true, containers are automatically pruned upon exit
+autoremove: false
+
+# PEM-encoded public key of the authorized JWT signer for verifying inbound connection
+# attempts; defaults to the RSA-4096 key of the organization vault
+jwt_signer_public_key: ""
+`
+
+func configInitProxy(cmd *cobra.Command, args []string) error {
+	return configPrompt(cmd, args, promptStepConfigInit)
+}
+
+func configInitRun(cmd *cobra.Command, args []string) error {
+	if _, err := os.Stat(stackDefinitionPath); err == nil && !overwriteStackDefinition {
+		return common.NewValidationError("stack definition file already exists: %s; pass --force to overwrite it", stackDefinitionPath)
+	}
+
+	if err := ioutil.WriteFile(stackDefinitionPath, []byte(defaultStackDefinition), 0600); err != nil {
+		return fmt.Errorf("failed to write stack definition file %s; %w", stackDefinitionPath, err)
+	}
+
+	log.Printf("wrote baseline stack definition: %s", stackDefinitionPath)
+	return nil
+}
+
+func init() {
+	configInitBaselineStackCmd.Flags().StringVarP(&stackDefinitionPath, "file", "f", defaultStackDefinitionPath, "path of the stack definition file to write")
+	configInitBaselineStackCmd.Flags().BoolVar(&overwriteStackDefinition, "force", false, "overwrite the stack definition file if it exists")
+}
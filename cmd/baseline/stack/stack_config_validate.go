@@ -0,0 +1,31 @@
+package stack
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+var configValidateBaselineStackCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate a baseline stack definition file",
+	Long:  `Parse and validate a baseline stack definition file, reporting each problem found`,
+	RunE:  configValidateProxy,
+}
+
+func configValidateProxy(cmd *cobra.Command, args []string) error {
+	return configPrompt(cmd, args, promptStepConfigValidate)
+}
+
+func configValidateRun(cmd *cobra.Command, args []string) error {
+	if _, err := readStackDefinition(stackDefinitionPath); err != nil {
+		return err
+	}
+
+	log.Printf("baseline stack definition is valid: %s", stackDefinitionPath)
+	return nil
+}
+
+func init() {
+	configValidateBaselineStackCmd.Flags().StringVarP(&stackDefinitionPath, "file", "f", defaultStackDefinitionPath, "path of the stack definition file to validate")
+}
@@ -0,0 +1,606 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/versions"
+	"github.com/docker/docker/client"
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/provideservices/provide-go/api/ident"
+	"github.com/provideservices/provide-go/api/nchain"
+	"github.com/provideservices/provide-go/api/vault"
+	util "github.com/provideservices/provide-go/common"
+
+	"github.com/spf13/cobra"
+)
+
+const doctorStatusPass = "pass"
+const doctorStatusWarn = "warn"
+const doctorStatusFail = "fail"
+const doctorStatusSkip = "skip"
+
+// doctorCheckTimeout bounds each network probe made by the checks
+const doctorCheckTimeout = time.Second * 10
+
+// doctorKeySpecs are the organization keys used by the stack, with the purpose of each
+var doctorKeySpecs = map[string]string{
+	"secp256k1":  "organization address",
+	"babyJubJub": "zero-knowledge proofs",
+	"RSA-4096":   "JWT signer",
+}
+
+var doctorBaselineStackCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the baseline stack configuration",
+	Long: `Run an ordered checklist of preflight and connectivity diagnostics against the configuration of a
+local baseline stack instance, and report the result of each check with a hint to remedy any failure.
+
+The stack is configured using the same flags and stack definition file as 'prvd baseline stack run';
+nothing is started, pulled or created. The checks cover docker, the container images, the host ports,
+the remote Provide services, the API tokens, the organization vault and keys, the registry contract
+and the messaging endpoint.`,
+	RunE: doctorProxy,
+}
+
+// stackDiagnosis is the result of the checklist run by 'stack doctor'
+type stackDiagnosis struct {
+	Name   string         `json:"name"`
+	Passed bool           `json:"passed"`
+	Checks []*doctorCheck `json:"checks"`
+}
+
+// doctorCheck is the result of a single check; the hint suggests how to remedy a failure
+type doctorCheck struct {
+	Check  string `json:"check"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// stackDoctor runs the checks in order; later checks are skipped when the checks
+// on which they depend did not pass
+type stackDoctor struct {
+	diagnosis *stackDiagnosis
+
+	docker  *client.Client
+	running bool // true when containers of the named stack exist
+
+	userAccessToken string
+}
+
+func doctorProxy(cmd *cobra.Command, args []string) error {
+	if runStackDefinitionPath != "" {
+		if err := applyStackDefinition(cmd.Flags(), runStackDefinitionPath); err != nil {
+			return err
+		}
+	}
+	if err := requireImageLock(cmd); err != nil {
+		return err
+	}
+	return generalPrompt(cmd, args, promptStepDoctor)
+}
+
+func doctorProxyRun(cmd *cobra.Command, args []string) error {
+	if err := validateNATSFlags(); err != nil {
+		return err
+	}
+	if err := validateMockSORFlags(); err != nil {
+		return err
+	}
+	if err := requireSystemOfRecord(); err != nil {
+		return err
+	}
+
+	doctor := &stackDoctor{
+		diagnosis: &stackDiagnosis{
+			Name:   name,
+			Checks: make([]*doctorCheck, 0),
+		},
+	}
+
+	doctor.checkDocker()
+	doctor.checkImages()
+	doctor.checkPorts()
+	doctor.checkRemoteServices()
+	doctor.checkUserAccessToken()
+	doctor.checkOrganizationAccessToken()
+	doctor.checkOrganizationRefreshToken()
+	doctor.checkWorkgroup()
+	doctor.checkRegistryContract()
+	doctor.checkVault()
+	doctor.checkKeys()
+	doctor.checkMessagingEndpoint()
+
+	failed := 0
+	for _, check := range doctor.diagnosis.Checks {
+		if check.Status == doctorStatusFail {
+			failed++
+		}
+	}
+	doctor.diagnosis.Passed = failed == 0
+
+	if err := renderStackDiagnosis(doctor.diagnosis); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed for %s local baseline instance", failed, len(doctor.diagnosis.Checks), name)
+	}
+	return nil
+}
+
+func renderStackDiagnosis(diagnosis *stackDiagnosis) error {
+	table := common.NewTable("CHECK", "STATUS", "DETAIL")
+	for _, check := range diagnosis.Checks {
+		table.AddRow(check.Check, check.Status, check.Detail)
+	}
+	if err := common.Render(diagnosis, table); err != nil {
+		return fmt.Errorf("failed to render baseline stack diagnosis; %w", err)
+	}
+
+	if common.IsStructuredOutput() {
+		return nil
+	}
+
+	hints := common.NewTable("CHECK", "HINT")
+	hinted := false
+	for _, check := range diagnosis.Checks {
+		if check.Hint != "" && (check.Status == doctorStatusFail || check.Status == doctorStatusWarn) {
+			hints.AddRow(check.Check, check.Hint)
+			hinted = true
+		}
+	}
+	if !hinted {
+		return nil
+	}
+
+	fmt.Println()
+	return common.Render(diagnosis, hints)
+}
+
+// report records the result of a check
+func (d *stackDoctor) report(check, status, detail, hint string) {
+	d.diagnosis.Checks = append(d.diagnosis.Checks, &doctorCheck{
+		Check:  check,
+		Status: status,
+		Detail: detail,
+		Hint:   hint,
+	})
+}
+
+// passed returns true if the named check was run and passed
+func (d *stackDoctor) passed(check string) bool {
+	for _, c := range d.diagnosis.Checks {
+		if c.Check == check {
+			return c.Status == doctorStatusPass
+		}
+	}
+	return false
+}
+
+// checkDocker verifies the docker daemon is reachable and supports the API version used by prvd
+func (d *stackDoctor) checkDocker() {
+	const check = "docker"
+
+	docker, err := client.NewEnvClient()
+	if err != nil {
+		d.report(check, doctorStatusFail, fmt.Sprintf("failed to initialize docker; %s", err.Error()), "check the DOCKER_HOST, DOCKER_CERT_PATH and DOCKER_TLS_VERIFY environment")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorCheckTimeout)
+	defer cancel()
+
+	version, err := docker.ServerVersion(ctx)
+	if err != nil {
+		d.report(check, doctorStatusFail, fmt.Sprintf("docker daemon unreachable; %s", err.Error()), "start docker, or set DOCKER_HOST to the address of a running docker daemon")
+		return
+	}
+
+	required := docker.ClientVersion()
+	if versions.LessThan(version.APIVersion, required) {
+		d.report(check, doctorStatusFail, fmt.Sprintf("docker %s supports API version %s; API version %s is required", version.Version, version.APIVersion, required), "upgrade docker")
+		return
+	}
+	if version.MinAPIVersion != "" && versions.LessThan(required, version.MinAPIVersion) {
+		d.report(check, doctorStatusFail, fmt.Sprintf("docker %s requires API version %s or later; prvd uses API version %s", version.Version, version.MinAPIVersion, required), fmt.Sprintf("set DOCKER_API_VERSION=%s", version.MinAPIVersion))
+		return
+	}
+
+	containers, err := listContainers(docker)
+	if err != nil {
+		d.report(check, doctorStatusFail, err.Error(), "check the permissions of the current user on the docker socket")
+		return
+	}
+
+	d.docker = docker
+	d.running = len(containers) > 0
+	d.report(check, doctorStatusPass, fmt.Sprintf("docker %s; API version %s", version.Version, version.APIVersion), "")
+}
+
+// checkImages verifies each container image of the enabled services is present, or can be pulled
+func (d *stackDoctor) checkImages() {
+	services, err := stackServices()
+	if err != nil {
+		d.report("images", doctorStatusFail, err.Error(), "")
+		return
+	}
+
+	images := make([]string, 0)
+	for _, svc := range services {
+		if !containsString(images, svc.image) {
+			images = append(images, svc.image)
+		}
+	}
+	sort.Strings(images)
+
+	for _, image := range images {
+		check := fmt.Sprintf("image %s", image)
+		if d.docker == nil {
+			d.report(check, doctorStatusSkip, "docker unavailable", "")
+			continue
+		}
+
+		ref := image
+		locked := imageLock.lookup(image)
+		if locked != nil {
+			ref = locked.ID
+		}
+
+		_, _, err := d.docker.ImageInspectWithRaw(context.Background(), ref)
+		switch {
+		case err == nil && locked != nil:
+			d.report(check, doctorStatusPass, fmt.Sprintf("locked image present: %s", locked.Digest), "")
+		case err == nil:
+			d.report(check, doctorStatusPass, "present; 'stack run' pulls the latest image", "")
+		case !client.IsErrNotFound(err):
+			d.report(check, doctorStatusFail, err.Error(), "")
+		case locked != nil:
+			d.report(check, doctorStatusWarn, fmt.Sprintf("locked image not present; 'stack run' pulls %s", locked.Digest), "without registry access, run 'prvd baseline stack images load'")
+		default:
+			d.report(check, doctorStatusWarn, "not present; 'stack run' pulls the image", "without registry access, run 'prvd baseline stack images load'")
+		}
+	}
+}
+
+// checkPorts verifies each planned host port of the enabled services can be bound
+func (d *stackDoctor) checkPorts() {
+	const check = "ports"
+
+	if d.running {
+		d.report(check, doctorStatusSkip, fmt.Sprintf("%s local baseline instance is running", name), "")
+		return
+	}
+
+	services, err := stackServices()
+	if err != nil {
+		d.report(check, doctorStatusFail, err.Error(), "")
+		return
+	}
+
+	conflicts, planned := portConflicts(services)
+	if len(conflicts) > 0 {
+		reported := make([]string, 0)
+		for _, conflict := range conflicts {
+			reported = append(reported, conflict.String())
+		}
+		d.report(check, doctorStatusFail, strings.Join(reported, "; "), "free the ports, set the listed flags or pass --auto-ports to 'stack run'")
+		return
+	}
+
+	d.report(check, doctorStatusPass, fmt.Sprintf("%d host ports available", len(planned)), "")
+}
+
+// checkRemoteServices resolves and requests the status of each Provide service which is not run locally
+func (d *stackDoctor) checkRemoteServices() {
+	for _, svc := range []struct {
+		service string
+		scheme  string
+		host    string
+		local   bool
+		flag    string
+	}{
+		{"ident", identAPIScheme, identAPIHost, withLocalIdent, "ident"},
+		{"nchain", nchainAPIScheme, nchainAPIHost, withLocalNChain, "nchain"},
+		{"privacy", privacyAPIScheme, privacyAPIHost, withLocalPrivacy, "privacy"},
+		{"vault", vaultAPIScheme, vaultAPIHost, withLocalVault, "vault"},
+	} {
+		dnsCheck := fmt.Sprintf("%s dns", svc.service)
+		httpCheck := fmt.Sprintf("%s status", svc.service)
+
+		if svc.local {
+			d.report(dnsCheck, doctorStatusSkip, fmt.Sprintf("run locally using --with-local-%s", svc.flag), "")
+			d.report(httpCheck, doctorStatusSkip, fmt.Sprintf("run locally using --with-local-%s", svc.flag), "")
+			continue
+		}
+
+		hostname := svc.host
+		if host, _, err := net.SplitHostPort(svc.host); err == nil {
+			hostname = host
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), doctorCheckTimeout)
+		addrs, err := net.DefaultResolver.LookupHost(ctx, hostname)
+		cancel()
+		if err != nil {
+			d.report(dnsCheck, doctorStatusFail, fmt.Sprintf("failed to resolve %s; %s", hostname, err.Error()), fmt.Sprintf("check --%s-host and the DNS configuration of this host", svc.flag))
+			d.report(httpCheck, doctorStatusSkip, fmt.Sprintf("%s unresolved", hostname), "")
+			continue
+		}
+		d.report(dnsCheck, doctorStatusPass, fmt.Sprintf("%s resolves to %s", hostname, strings.Join(addrs, ", ")), "")
+
+		statusURL := fmt.Sprintf("%s://%s/status", svc.scheme, svc.host)
+		resp, err := (&http.Client{Timeout: doctorCheckTimeout}).Get(statusURL)
+		if err != nil {
+			d.report(httpCheck, doctorStatusFail, fmt.Sprintf("GET %s failed; %s", statusURL, err.Error()), fmt.Sprintf("check --%s-scheme, and that no proxy or firewall blocks outbound requests", svc.flag))
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+			d.report(httpCheck, doctorStatusFail, fmt.Sprintf("GET %s returned %s", statusURL, resp.Status), fmt.Sprintf("the %s service is unavailable; retry later or check --%s-host", svc.service, svc.flag))
+			continue
+		}
+		d.report(httpCheck, doctorStatusPass, fmt.Sprintf("GET %s returned %s", statusURL, resp.Status), "")
+	}
+}
+
+// checkUserAccessToken verifies the cached access token of the user is valid, refreshing it if it has expired
+func (d *stackDoctor) checkUserAccessToken() {
+	const check = "user access token"
+
+	token, err := common.RequireUserAccessToken()
+	if err != nil {
+		d.report(check, doctorStatusFail, err.Error(), "run 'prvd authenticate'")
+		return
+	}
+
+	d.userAccessToken = token
+	d.report(check, doctorStatusPass, "authorized", "")
+}
+
+// checkOrganizationAccessToken verifies an access token is vended on behalf of the organization
+func (d *stackDoctor) checkOrganizationAccessToken() {
+	const check = "organization access token"
+
+	if d.userAccessToken == "" {
+		d.report(check, doctorStatusSkip, "user access token unavailable", "")
+		return
+	}
+
+	if err := common.AuthorizeOrganizationContext(false); err != nil {
+		d.report(check, doctorStatusFail, err.Error(), "check --organization, and that the user is a member of the organization")
+		return
+	}
+
+	d.report(check, doctorStatusPass, fmt.Sprintf("authorized for organization %s", common.OrganizationID), "")
+}
+
+// checkOrganizationRefreshToken verifies the refresh token passed to the stack vends access tokens
+func (d *stackDoctor) checkOrganizationRefreshToken() {
+	const check = "organization refresh token"
+	const hint = "pass --organization-refresh-token, or run 'prvd api_tokens init --organization <id> --offline-access'"
+
+	refreshToken := organizationRefreshToken
+	source := "--organization-refresh-token"
+	if refreshToken == "" && common.OrganizationID != "" {
+		cached, err := common.CachedCredential(common.BuildConfigKeyWithOrg(common.APIRefreshTokenConfigKeyPartial, common.OrganizationID))
+		if err != nil {
+			d.report(check, doctorStatusFail, err.Error(), "")
+			return
+		}
+		refreshToken = cached
+		source = "cached refresh token"
+	}
+
+	if refreshToken == "" {
+		d.report(check, doctorStatusFail, fmt.Sprintf("no refresh token for organization %s", common.OrganizationID), hint)
+		return
+	}
+
+	if _, err := ident.CreateToken(refreshToken, map[string]interface{}{
+		"grant_type": "refresh_token",
+	}); err != nil {
+		d.report(check, doctorStatusFail, fmt.Sprintf("%s rejected; %s", source, err.Error()), hint)
+		return
+	}
+
+	d.report(check, doctorStatusPass, fmt.Sprintf("%s vends access tokens", source), "")
+}
+
+// checkWorkgroup verifies an access token is vended on behalf of the workgroup
+func (d *stackDoctor) checkWorkgroup() {
+	const check = "workgroup"
+
+	if d.userAccessToken == "" {
+		d.report(check, doctorStatusSkip, "user access token unavailable", "")
+		return
+	}
+	if baselineWorkgroupID == "" {
+		d.report(check, doctorStatusFail, "no workgroup", "pass --workgroup, or run 'prvd baseline workgroups init'")
+		return
+	}
+
+	token, err := ident.CreateToken(d.userAccessToken, map[string]interface{}{
+		"scope":          "offline_access",
+		"application_id": baselineWorkgroupID,
+	})
+	if err != nil || token.AccessToken == nil {
+		d.report(check, doctorStatusFail, fmt.Sprintf("failed to authorize access token on behalf of workgroup %s", baselineWorkgroupID), "check --workgroup, and that the user is a member of the workgroup")
+		return
+	}
+	workgroupAccessToken = *token.AccessToken
+
+	workgroup, err := ident.GetApplicationDetails(workgroupAccessToken, baselineWorkgroupID, map[string]interface{}{})
+	if err != nil {
+		d.report(check, doctorStatusFail, fmt.Sprintf("failed to resolve workgroup %s; %s", baselineWorkgroupID, err.Error()), "check --workgroup")
+		return
+	}
+
+	d.report(check, doctorStatusPass, fmt.Sprintf("%s (%s)", common.StringValue(workgroup.Name), baselineWorkgroupID), "")
+}
+
+// checkRegistryContract verifies the organization registry contract of the workgroup is deployed
+func (d *stackDoctor) checkRegistryContract() {
+	const check = "registry contract"
+	const hint = "run 'prvd baseline workgroups init' to deploy the registry contract"
+
+	if !d.passed("workgroup") {
+		d.report(check, doctorStatusSkip, "workgroup unavailable", "")
+		return
+	}
+
+	contracts, err := nchain.ListContracts(workgroupAccessToken, map[string]interface{}{
+		"type": "organization-registry",
+	})
+	if err != nil {
+		d.report(check, doctorStatusFail, fmt.Sprintf("failed to resolve organization registry contract; %s", err.Error()), "")
+		return
+	}
+	if len(contracts) == 0 || contracts[0].Address == nil || *contracts[0].Address == "0x" {
+		d.report(check, doctorStatusFail, "organization registry contract not deployed", hint)
+		return
+	}
+
+	address := *contracts[0].Address
+	if baselineRegistryContractAddress != "" && baselineRegistryContractAddress != "0x" && !strings.EqualFold(baselineRegistryContractAddress, address) {
+		d.report(check, doctorStatusFail, fmt.Sprintf("--registry-contract-address %s does not match the registry contract of the workgroup: %s", baselineRegistryContractAddress, address), "remove --registry-contract-address, or set it to the address of the registry contract of the workgroup")
+		return
+	}
+
+	d.report(check, doctorStatusPass, address, "")
+}
+
+// checkVault verifies the organization has a vault; unlike 'stack run', no vault is created
+func (d *stackDoctor) checkVault() {
+	const check = "vault"
+
+	if common.OrganizationAccessToken == "" {
+		d.report(check, doctorStatusSkip, "organization access token unavailable", "")
+		return
+	}
+
+	vaults, err := vault.ListVaults(common.OrganizationAccessToken, map[string]interface{}{
+		"organization_id": common.OrganizationID,
+	})
+	if err != nil {
+		d.report(check, doctorStatusFail, fmt.Sprintf("failed to retrieve vaults for organization %s; %s", common.OrganizationID, err.Error()), "check --vault-host, and that vault is reachable")
+		return
+	}
+	if len(vaults) == 0 {
+		d.report(check, doctorStatusFail, fmt.Sprintf("no vault for organization %s", common.OrganizationID), "run 'prvd vaults init --organization <id>'")
+		return
+	}
+
+	common.VaultID = vaults[0].ID.String()
+	d.report(check, doctorStatusPass, common.VaultID, "")
+}
+
+// checkKeys verifies the organization vault holds a key of each spec used by the stack
+func (d *stackDoctor) checkKeys() {
+	const check = "organization keys"
+
+	if !d.passed("vault") {
+		d.report(check, doctorStatusSkip, "vault unavailable", "")
+		return
+	}
+
+	specs := make([]string, 0)
+	for spec := range doctorKeySpecs {
+		if spec == "RSA-4096" && jwtSignerPublicKey != "" {
+			continue // the JWT signer is given using --jwt-signer-public-key
+		}
+		specs = append(specs, spec)
+	}
+	sort.Strings(specs)
+
+	present := make([]string, 0)
+	missing := make([]string, 0)
+	for _, spec := range specs {
+		keys, err := vault.ListKeys(common.OrganizationAccessToken, common.VaultID, map[string]interface{}{
+			"spec": spec,
+		})
+		if err != nil {
+			d.report(check, doctorStatusFail, fmt.Sprintf("failed to retrieve %s keys; %s", spec, err.Error()), "")
+			return
+		}
+		if len(keys) == 0 {
+			missing = append(missing, fmt.Sprintf("%s (%s)", spec, doctorKeySpecs[spec]))
+		} else {
+			present = append(present, spec)
+		}
+	}
+
+	if len(missing) > 0 {
+		d.report(check, doctorStatusFail, fmt.Sprintf("missing %s", strings.Join(missing, ", ")), fmt.Sprintf("run 'prvd vaults keys init --vault %s --spec <spec> --type asymmetric --usage sign/verify' for each missing key", common.VaultID))
+		return
+	}
+
+	d.report(check, doctorStatusPass, strings.Join(present, ", "), "")
+}
+
+// checkMessagingEndpoint verifies the messaging endpoint advertised by the stack resolves and
+// accepts connections; when no endpoint is given, the endpoint 'stack run' derives is reported
+func (d *stackDoctor) checkMessagingEndpoint() {
+	const check = "messaging endpoint"
+
+	if common.MessagingEndpoint == "" {
+		if common.Tunnel || common.ExposeMessagingTunnel {
+			tunnel, err := common.RequireTunnelProvider()
+			if err != nil {
+				d.report(check, doctorStatusFail, fmt.Sprintf("the messaging tunnel cannot be established; %s", err.Error()), fmt.Sprintf("set %s and the settings of the provider in the prvd configuration, or pass --messaging-endpoint", common.TunnelProviderConfigKey))
+				return
+			}
+			d.report(check, doctorStatusPass, fmt.Sprintf("exposed by a %s tunnel established by 'stack run'", tunnel.Name()), "")
+			return
+		}
+
+		publicIP, err := util.ResolvePublicIP()
+		if err != nil {
+			d.report(check, doctorStatusFail, fmt.Sprintf("failed to resolve public IP; %s", err.Error()), "pass --messaging-endpoint or --messaging-tunnel")
+			return
+		}
+		d.report(check, doctorStatusWarn, fmt.Sprintf("nats://%s:%d, derived from the public IP of this host", *publicIP, natsPort), fmt.Sprintf("allow inbound connections to port %d, or pass --messaging-endpoint or --messaging-tunnel", natsPort))
+		return
+	}
+
+	endpoint, err := url.Parse(common.MessagingEndpoint)
+	if err != nil || endpoint.Hostname() == "" || endpoint.Port() == "" {
+		d.report(check, doctorStatusFail, fmt.Sprintf("invalid messaging endpoint: %s", common.MessagingEndpoint), "pass --messaging-endpoint as a URL with a host and port; i.e., nats://203.0.113.1:4222")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorCheckTimeout)
+	defer cancel()
+
+	if _, err := net.DefaultResolver.LookupHost(ctx, endpoint.Hostname()); err != nil {
+		d.report(check, doctorStatusFail, fmt.Sprintf("failed to resolve %s; %s", endpoint.Hostname(), err.Error()), "check --messaging-endpoint")
+		return
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", endpoint.Host)
+	if err != nil {
+		if !d.running {
+			d.report(check, doctorStatusWarn, fmt.Sprintf("%s does not accept connections until the stack is running", common.MessagingEndpoint), "")
+			return
+		}
+		d.report(check, doctorStatusFail, fmt.Sprintf("failed to connect to %s; %s", common.MessagingEndpoint, err.Error()), fmt.Sprintf("forward %s to host port %d (--nats-port), and allow inbound connections", endpoint.Host, natsPort))
+		return
+	}
+	conn.Close()
+
+	d.report(check, doctorStatusPass, fmt.Sprintf("%s accepts connections", common.MessagingEndpoint), "")
+}
+
+func init() {
+	doctorBaselineStackCmd.Flags().StringVarP(&runStackDefinitionPath, "file", "f", "", "path of a stack definition file; flags override the values in the file")
+	doctorBaselineStackCmd.Flags().StringVar(&imageLockPath, "lockfile", defaultImageLockPath, "path of the image lockfile; when present, the locked container images are checked")
+	addStackRunFlags(doctorBaselineStackCmd.Flags())
+}
@@ -0,0 +1,571 @@
+package stack
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+const exportFormatCompose = "compose"
+const exportFormatK8s = "k8s"
+
+// defaultVolumeClaimSize is the storage requested for each named volume of the stack
+const defaultVolumeClaimSize = "1Gi"
+
+var exportFormats = []string{exportFormatCompose, exportFormatK8s}
+
+var exportFormat string
+var exportOutputPath string
+
+// secretEnvironmentPatterns match the container environment which is rendered as a
+// Kubernetes Secret rather than a ConfigMap
+var secretEnvironmentPatterns = []string{"TOKEN", "PASSWORD", "SECRET", "SEAL_UNSEAL_KEY"}
+
+var dnsLabelInvalidChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+var exportBaselineStackCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the baseline stack for deployment",
+	Long: `Render the services of a baseline stack instance as a docker-compose file or as Kubernetes manifests.
+
+The stack is configured using the same flags and stack definition file as 'prvd baseline stack run'; the
+organization and workgroup context is resolved, but no containers or tunnels are started.`,
+	RunE: exportProxy,
+}
+
+// composeFile is the subset of the compose specification used to export the stack
+type composeFile struct {
+	Services map[string]*composeService `yaml:"services"`
+	Networks map[string]*composeNetwork `yaml:"networks"`
+	Volumes  map[string]*composeVolume  `yaml:"volumes,omitempty"`
+	Configs  map[string]*composeConfig  `yaml:"configs,omitempty"`
+}
+
+type composeService struct {
+	ContainerName string                        `yaml:"container_name"`
+	Image         string                        `yaml:"image"`
+	Hostname      string                        `yaml:"hostname"`
+	Entrypoint    []string                      `yaml:"entrypoint,omitempty"`
+	Command       []string                      `yaml:"command,omitempty"`
+	Environment   map[string]string             `yaml:"environment"`
+	Ports         []string                      `yaml:"ports,omitempty"`
+	Healthcheck   *composeHealthcheck           `yaml:"healthcheck,omitempty"`
+	DependsOn     map[string]*composeDependency `yaml:"depends_on,omitempty"`
+	Configs       []*composeConfigMount         `yaml:"configs,omitempty"`
+	Volumes       []string                      `yaml:"volumes,omitempty"`
+	Labels        map[string]string             `yaml:"labels"`
+	Networks      []string                      `yaml:"networks"`
+	Restart       string                        `yaml:"restart"`
+}
+
+type composeHealthcheck struct {
+	Test        []string `yaml:"test"`
+	Interval    string   `yaml:"interval"`
+	Timeout     string   `yaml:"timeout"`
+	Retries     int      `yaml:"retries"`
+	StartPeriod string   `yaml:"start_period"`
+}
+
+type composeDependency struct {
+	Condition string `yaml:"condition"`
+}
+
+type composeConfigMount struct {
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+}
+
+type composeNetwork struct {
+	Name   string            `yaml:"name"`
+	Driver string            `yaml:"driver"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+type composeVolume struct {
+	Name   string            `yaml:"name"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+type composeConfig struct {
+	Content string `yaml:"content"`
+}
+
+// k8sObject is the subset of the Kubernetes object model used to export the stack
+type k8sObject struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   k8sMetadata       `yaml:"metadata"`
+	Type       string            `yaml:"type,omitempty"`
+	Data       map[string]string `yaml:"data,omitempty"`
+	StringData map[string]string `yaml:"stringData,omitempty"`
+	Spec       interface{}       `yaml:"spec,omitempty"`
+}
+
+type k8sMetadata struct {
+	Name   string            `yaml:"name,omitempty"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+type k8sDeploymentSpec struct {
+	Replicas int                    `yaml:"replicas"`
+	Strategy *k8sDeploymentStrategy `yaml:"strategy,omitempty"`
+	Selector k8sSelector            `yaml:"selector"`
+	Template k8sPodTemplateSpec     `yaml:"template"`
+}
+
+type k8sDeploymentStrategy struct {
+	Type string `yaml:"type"`
+}
+
+type k8sSelector struct {
+	MatchLabels map[string]string `yaml:"matchLabels"`
+}
+
+type k8sPodTemplateSpec struct {
+	Metadata k8sMetadata `yaml:"metadata"`
+	Spec     k8sPodSpec  `yaml:"spec"`
+}
+
+type k8sPodSpec struct {
+	Hostname   string          `yaml:"hostname"`
+	Containers []*k8sContainer `yaml:"containers"`
+	Volumes    []*k8sVolume    `yaml:"volumes,omitempty"`
+}
+
+type k8sContainer struct {
+	Name           string            `yaml:"name"`
+	Image          string            `yaml:"image"`
+	Command        []string          `yaml:"command,omitempty"`
+	Args           []string          `yaml:"args,omitempty"`
+	EnvFrom        []*k8sEnvFrom     `yaml:"envFrom"`
+	Ports          []*k8sPort        `yaml:"ports,omitempty"`
+	ReadinessProbe *k8sProbe         `yaml:"readinessProbe,omitempty"`
+	LivenessProbe  *k8sProbe         `yaml:"livenessProbe,omitempty"`
+	VolumeMounts   []*k8sVolumeMount `yaml:"volumeMounts,omitempty"`
+}
+
+type k8sEnvFrom struct {
+	ConfigMapRef *k8sLocalObjectReference `yaml:"configMapRef,omitempty"`
+	SecretRef    *k8sLocalObjectReference `yaml:"secretRef,omitempty"`
+}
+
+type k8sLocalObjectReference struct {
+	Name string `yaml:"name"`
+}
+
+type k8sPort struct {
+	Name          string `yaml:"name,omitempty"`
+	ContainerPort int    `yaml:"containerPort,omitempty"`
+	Port          int    `yaml:"port,omitempty"`
+	TargetPort    int    `yaml:"targetPort,omitempty"`
+	Protocol      string `yaml:"protocol"`
+}
+
+type k8sProbe struct {
+	Exec                k8sExecAction `yaml:"exec"`
+	InitialDelaySeconds int           `yaml:"initialDelaySeconds"`
+	PeriodSeconds       int           `yaml:"periodSeconds"`
+	TimeoutSeconds      int           `yaml:"timeoutSeconds"`
+	FailureThreshold    int           `yaml:"failureThreshold"`
+}
+
+type k8sExecAction struct {
+	Command []string `yaml:"command"`
+}
+
+type k8sVolumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	SubPath   string `yaml:"subPath,omitempty"`
+	ReadOnly  bool   `yaml:"readOnly,omitempty"`
+}
+
+type k8sVolume struct {
+	Name                  string                   `yaml:"name"`
+	ConfigMap             *k8sLocalObjectReference `yaml:"configMap,omitempty"`
+	PersistentVolumeClaim *k8sVolumeClaimReference `yaml:"persistentVolumeClaim,omitempty"`
+}
+
+type k8sVolumeClaimReference struct {
+	ClaimName string `yaml:"claimName"`
+}
+
+type k8sVolumeClaimSpec struct {
+	AccessModes []string                `yaml:"accessModes"`
+	Resources   k8sResourceRequirements `yaml:"resources"`
+}
+
+type k8sResourceRequirements struct {
+	Requests map[string]string `yaml:"requests"`
+}
+
+type k8sServiceSpec struct {
+	Selector map[string]string `yaml:"selector"`
+	Ports    []*k8sPort        `yaml:"ports"`
+}
+
+func exportProxy(cmd *cobra.Command, args []string) error {
+	if !containsString(exportFormats, exportFormat) {
+		return common.NewValidationError("invalid --format: %s; must be one of %s", exportFormat, strings.Join(exportFormats, ", "))
+	}
+	if runStackDefinitionPath != "" {
+		if err := applyStackDefinition(cmd.Flags(), runStackDefinitionPath); err != nil {
+			return err
+		}
+	}
+	return generalPrompt(cmd, args, promptStepExport)
+}
+
+func exportProxyRun(cmd *cobra.Command, args []string) error {
+	if err := validateNATSFlags(); err != nil {
+		return err
+	}
+	if err := validateMockSORFlags(); err != nil {
+		return err
+	}
+	if err := requireSystemOfRecord(); err != nil {
+		return err
+	}
+	if err := authorizeContext(); err != nil {
+		return err
+	}
+
+	if baselineOrganizationAddress == "" || baselineOrganizationAddress == "0x" {
+		if key, err := common.RequireOrganizationKeypair("secp256k1"); err == nil && key.Address != nil {
+			common.ResolvedBaselineOrgAddress = *key.Address
+		}
+	}
+	applyFlags()
+	if err := requireNATSCredentials(); err != nil {
+		return err
+	}
+
+	services, err := stackServices()
+	if err != nil {
+		return err
+	}
+
+	var rendered []byte
+	switch exportFormat {
+	case exportFormatCompose:
+		rendered, err = renderCompose(services)
+	case exportFormatK8s:
+		rendered, err = renderK8s(services)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render baseline stack as %s; %w", exportFormat, err)
+	}
+
+	if exportOutputPath == "" {
+		fmt.Print(string(rendered))
+		return nil
+	}
+
+	// the rendered stack contains credentials
+	if err := ioutil.WriteFile(exportOutputPath, rendered, 0600); err != nil {
+		return fmt.Errorf("failed to write %s; %w", exportOutputPath, err)
+	}
+
+	log.Printf("exported %s local baseline instance: %s", name, exportOutputPath)
+	return nil
+}
+
+// renderCompose renders the given services as a docker-compose file; generated files are
+// inlined as configs, and each service waits for its dependencies to become healthy
+func renderCompose(services []*serviceSpec) ([]byte, error) {
+	env := containerEnvironment(containerEnvironmentFactory())
+
+	compose := &composeFile{
+		Services: map[string]*composeService{},
+		Networks: map[string]*composeNetwork{
+			name: {
+				Name:   name,
+				Driver: "bridge",
+				Labels: stackLabels(""),
+			},
+		},
+		Volumes: map[string]*composeVolume{},
+		Configs: map[string]*composeConfig{},
+	}
+
+	for _, spec := range services {
+		svc := &composeService{
+			ContainerName: containerName(spec.service),
+			Image:         spec.image,
+			Hostname:      spec.hostname,
+			Entrypoint:    spec.entrypoint,
+			Command:       spec.cmd,
+			Environment:   env,
+			Labels:        stackLabels(spec.service),
+			Networks:      []string{name},
+			Restart:       "unless-stopped",
+		}
+
+		for _, mapping := range spec.ports {
+			svc.Ports = append(svc.Ports, fmt.Sprintf("%d:%d", mapping.hostPort, mapping.containerPort))
+		}
+
+		if spec.healthcheck != nil {
+			svc.Healthcheck = &composeHealthcheck{
+				Test:        spec.healthcheck,
+				Interval:    healthcheckInterval.String(),
+				Timeout:     healthcheckTimeout.String(),
+				Retries:     healthcheckRetries,
+				StartPeriod: healthcheckStartPeriod.String(),
+			}
+		}
+
+		if len(spec.dependsOn) > 0 {
+			svc.DependsOn = map[string]*composeDependency{}
+			for _, dep := range spec.dependsOn {
+				svc.DependsOn[dep] = &composeDependency{Condition: "service_healthy"}
+			}
+		}
+
+		for _, target := range sortedFileTargets(spec) {
+			config := exportFileName(target)
+			compose.Configs[config] = &composeConfig{Content: spec.files[target]}
+			svc.Configs = append(svc.Configs, &composeConfigMount{
+				Source: config,
+				Target: target,
+			})
+		}
+
+		for _, volume := range spec.volumes {
+			compose.Volumes[volume.volume] = &composeVolume{
+				Name:   volumeName(volume.volume),
+				Labels: stackLabels(spec.service),
+			}
+			svc.Volumes = append(svc.Volumes, fmt.Sprintf("%s:%s", volume.volume, volume.target))
+		}
+
+		compose.Services[spec.service] = svc
+	}
+
+	return yaml.Marshal(compose)
+}
+
+// renderK8s renders the given services as Kubernetes manifests; the environment is shared
+// by all services using a ConfigMap and a Secret, each service is run as a Deployment, named
+// volumes are rendered as PersistentVolumeClaims and each service with ports is exposed within
+// the cluster at its hostname using a Service.
+// Kubernetes has no notion of startup order, so dependencies are left to readiness probes.
+func renderK8s(services []*serviceSpec) ([]byte, error) {
+	env := containerEnvironment(containerEnvironmentFactory())
+	configEnv := map[string]string{}
+	secretEnv := map[string]string{}
+	for key, val := range env {
+		if isSecretEnvironment(key) {
+			secretEnv[key] = val
+		} else {
+			configEnv[key] = val
+		}
+	}
+
+	envName := dnsLabel(fmt.Sprintf("%s-env", name))
+	objects := []*k8sObject{
+		{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Metadata:   k8sMetadata{Name: envName, Labels: stackLabels("")},
+			Data:       configEnv,
+		},
+		{
+			APIVersion: "v1",
+			Kind:       "Secret",
+			Metadata:   k8sMetadata{Name: envName, Labels: stackLabels("")},
+			Type:       "Opaque",
+			StringData: secretEnv,
+		},
+	}
+
+	for _, spec := range services {
+		labels := stackLabels(spec.service)
+		selector := map[string]string{
+			stackLabel:        name,
+			stackServiceLabel: spec.service,
+		}
+		resourceName := dnsLabel(containerName(spec.service))
+
+		container := &k8sContainer{
+			Name:    dnsLabel(spec.service),
+			Image:   spec.image,
+			Command: spec.entrypoint,
+			Args:    spec.cmd,
+			EnvFrom: []*k8sEnvFrom{
+				{ConfigMapRef: &k8sLocalObjectReference{Name: envName}},
+				{SecretRef: &k8sLocalObjectReference{Name: envName}},
+			},
+		}
+
+		for _, mapping := range spec.ports {
+			container.Ports = append(container.Ports, &k8sPort{
+				ContainerPort: mapping.containerPort,
+				Protocol:      "TCP",
+			})
+		}
+
+		if len(spec.healthcheck) > 1 && spec.healthcheck[0] == "CMD" {
+			probe := &k8sProbe{
+				Exec:                k8sExecAction{Command: spec.healthcheck[1:]},
+				InitialDelaySeconds: int(healthcheckStartPeriod.Seconds()),
+				PeriodSeconds:       int(healthcheckInterval.Seconds()),
+				TimeoutSeconds:      int(healthcheckTimeout.Seconds()),
+				FailureThreshold:    healthcheckRetries,
+			}
+			container.ReadinessProbe = probe
+			container.LivenessProbe = probe
+		}
+
+		pod := k8sPodSpec{
+			Hostname:   dnsLabel(spec.hostname),
+			Containers: []*k8sContainer{container},
+		}
+
+		if len(spec.files) > 0 {
+			filesName := dnsLabel(fmt.Sprintf("%s-files", resourceName))
+			files := map[string]string{}
+			for _, target := range sortedFileTargets(spec) {
+				key := exportFileName(target)
+				files[key] = spec.files[target]
+				container.VolumeMounts = append(container.VolumeMounts, &k8sVolumeMount{
+					Name:      "files",
+					MountPath: target,
+					SubPath:   key,
+					ReadOnly:  true,
+				})
+			}
+			pod.Volumes = append(pod.Volumes, &k8sVolume{
+				Name:      "files",
+				ConfigMap: &k8sLocalObjectReference{Name: filesName},
+			})
+			objects = append(objects, &k8sObject{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+				Metadata:   k8sMetadata{Name: filesName, Labels: labels},
+				Data:       files,
+			})
+		}
+
+		var strategy *k8sDeploymentStrategy
+		for _, volume := range spec.volumes {
+			claimName := dnsLabel(volumeName(volume.volume))
+			container.VolumeMounts = append(container.VolumeMounts, &k8sVolumeMount{
+				Name:      volume.volume,
+				MountPath: volume.target,
+			})
+			pod.Volumes = append(pod.Volumes, &k8sVolume{
+				Name:                  volume.volume,
+				PersistentVolumeClaim: &k8sVolumeClaimReference{ClaimName: claimName},
+			})
+			objects = append(objects, &k8sObject{
+				APIVersion: "v1",
+				Kind:       "PersistentVolumeClaim",
+				Metadata:   k8sMetadata{Name: claimName, Labels: labels},
+				Spec: &k8sVolumeClaimSpec{
+					AccessModes: []string{"ReadWriteOnce"},
+					Resources: k8sResourceRequirements{
+						Requests: map[string]string{"storage": defaultVolumeClaimSize},
+					},
+				},
+			})
+			// a volume claim cannot be shared by the old and new pods of a rolling update
+			strategy = &k8sDeploymentStrategy{Type: "Recreate"}
+		}
+
+		objects = append(objects, &k8sObject{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Metadata:   k8sMetadata{Name: resourceName, Labels: labels},
+			Spec: &k8sDeploymentSpec{
+				Replicas: 1,
+				Strategy: strategy,
+				Selector: k8sSelector{MatchLabels: selector},
+				Template: k8sPodTemplateSpec{
+					Metadata: k8sMetadata{Labels: labels},
+					Spec:     pod,
+				},
+			},
+		})
+
+		if len(spec.ports) > 0 {
+			ports := make([]*k8sPort, 0)
+			for _, mapping := range spec.ports {
+				ports = append(ports, &k8sPort{
+					Name:       fmt.Sprintf("tcp-%d", mapping.containerPort),
+					Port:       mapping.containerPort,
+					TargetPort: mapping.containerPort,
+					Protocol:   "TCP",
+				})
+			}
+			// the service name is the hostname referenced by the environment of the stack
+			objects = append(objects, &k8sObject{
+				APIVersion: "v1",
+				Kind:       "Service",
+				Metadata:   k8sMetadata{Name: dnsLabel(spec.hostname), Labels: labels},
+				Spec: &k8sServiceSpec{
+					Selector: selector,
+					Ports:    ports,
+				},
+			})
+		}
+	}
+
+	docs := make([]string, 0)
+	for _, obj := range objects {
+		raw, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, string(raw))
+	}
+	return []byte(fmt.Sprintf("---\n%s", strings.Join(docs, "---\n"))), nil
+}
+
+// isSecretEnvironment returns true if the given container environment variable holds a credential
+func isSecretEnvironment(key string) bool {
+	for _, pattern := range secretEnvironmentPatterns {
+		if strings.Contains(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// dnsLabel sanitizes the given value for use as a Kubernetes resource name (RFC 1123 label)
+func dnsLabel(val string) string {
+	label := dnsLabelInvalidChars.ReplaceAllString(strings.ToLower(val), "-")
+	if len(label) > 63 {
+		label = label[0:63]
+	}
+	return strings.Trim(label, "-")
+}
+
+// exportFileName returns the name under which a generated file is exported; i.e., nats-server.conf
+func exportFileName(target string) string {
+	return filepath.Base(target)
+}
+
+func sortedFileTargets(spec *serviceSpec) []string {
+	targets := make([]string, 0)
+	for target := range spec.files {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+func init() {
+	exportBaselineStackCmd.Flags().StringVarP(&runStackDefinitionPath, "file", "f", "", "path of a stack definition file; flags override the values in the file")
+	exportBaselineStackCmd.Flags().StringVar(&exportFormat, "format", exportFormatCompose, fmt.Sprintf("format of the exported stack; one of %s", strings.Join(exportFormats, ", ")))
+	exportBaselineStackCmd.Flags().StringVar(&exportOutputPath, "out", "", "path of the file to which the exported stack is written; defaults to stdout")
+	addStackRunFlags(exportBaselineStackCmd.Flags())
+}
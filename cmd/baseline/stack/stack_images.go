@@ -0,0 +1,241 @@
+package stack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/client"
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// imageLockVersion is the current version of the image lockfile schema
+const imageLockVersion = 1
+
+const defaultImageLockPath = "baseline-stack.lock"
+
+// imageLockPath is the image lockfile read by 'stack run' and managed by the images commands
+var imageLockPath string
+
+// imageLock is the image lockfile honored by 'stack run'; nil when there is no lockfile
+var imageLock *stackImageLock
+
+var imagesBaselineStackCmd = &cobra.Command{
+	Use:   "images",
+	Short: "Manage baseline stack container images",
+	Long: `Lock, save and load the container images of the baseline stack.
+
+'prvd baseline stack images lock' writes a lockfile of the digest of each image; 'stack run'
+honors the lockfile, running exactly the locked images and pulling only those which are not
+already present. Use 'images save' and 'images load' to move the images to an environment
+without registry access.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
+
+		return imagesPrompt(cmd, args, "")
+	},
+}
+
+// stackImageLock pins each container image of the stack to a digest
+type stackImageLock struct {
+	Version int                    `yaml:"version"`
+	Images  []*stackImageLockEntry `yaml:"images"`
+}
+
+// stackImageLockEntry is a locked container image
+type stackImageLockEntry struct {
+	Image  string `yaml:"image"`  // image reference as run by the stack; i.e., provide/baseline
+	Digest string `yaml:"digest"` // repository digest; i.e., provide/baseline@sha256:...
+	ID     string `yaml:"id"`     // image id; unlike the digest, it survives 'images save' and 'images load'
+}
+
+// lookup returns the locked entry of the given image reference, or nil
+func (l *stackImageLock) lookup(image string) *stackImageLockEntry {
+	if l == nil {
+		return nil
+	}
+	for _, entry := range l.Images {
+		if entry.Image == image {
+			return entry
+		}
+	}
+	return nil
+}
+
+// requireImageLock reads the image lockfile into imageLock; the lockfile at the default path
+// is optional, but a lockfile which is set explicitly must exist
+func requireImageLock(cmd *cobra.Command) error {
+	imageLock = nil
+
+	if _, err := os.Stat(imageLockPath); os.IsNotExist(err) && !cmd.Flags().Changed("lockfile") {
+		return nil
+	}
+
+	lock, err := readImageLock(imageLockPath)
+	if err != nil {
+		return err
+	}
+	imageLock = lock
+	return nil
+}
+
+func readImageLock(path string) (*stackImageLock, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, common.NewNotFoundError("image lockfile not found: %s", path)
+		}
+		return nil, fmt.Errorf("failed to read image lockfile %s; %w", path, err)
+	}
+
+	lock := &stackImageLock{}
+	if err := yaml.UnmarshalStrict(raw, lock); err != nil {
+		return nil, common.NewValidationError("failed to parse image lockfile %s; %s", path, err.Error())
+	}
+
+	if errs := lock.validate(); len(errs) > 0 {
+		return nil, common.NewValidationError("invalid image lockfile %s:\n  - %s", path, strings.Join(errs, "\n  - "))
+	}
+
+	return lock, nil
+}
+
+// validate returns a description of each problem with the lockfile
+func (l *stackImageLock) validate() []string {
+	errs := make([]string, 0)
+
+	if l.Version == 0 {
+		errs = append(errs, "version: required")
+	} else if l.Version != imageLockVersion {
+		errs = append(errs, fmt.Sprintf("version: unsupported version: %d; this version of prvd supports version %d", l.Version, imageLockVersion))
+	}
+
+	for i, entry := range l.Images {
+		if entry.Image == "" {
+			errs = append(errs, fmt.Sprintf("images[%d].image: required", i))
+		}
+		if !strings.HasPrefix(entry.Digest, fmt.Sprintf("%s@sha256:", imageRepository(entry.Image))) {
+			errs = append(errs, fmt.Sprintf("images[%d].digest: invalid digest of %s: %s", i, entry.Image, entry.Digest))
+		}
+		if !strings.HasPrefix(entry.ID, "sha256:") {
+			errs = append(errs, fmt.Sprintf("images[%d].id: invalid image id: %s", i, entry.ID))
+		}
+	}
+
+	return errs
+}
+
+// stackImages returns every container image the stack may run, whether or not the
+// corresponding service is enabled by the current flags
+func stackImages() ([]string, error) {
+	images := []string{
+		natsContainerImage,
+		natsStreamingContainerImage,
+		redisContainerImage,
+		postgresContainerImage,
+		mockSORImage(),
+	}
+
+	for _, image := range []string{identContainerImage, nchainContainerImage, privacyContainerImage, vaultContainerImage} {
+		ref, err := resolveImage(image)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, ref)
+	}
+
+	return append(images, baselineContainerImage), nil
+}
+
+// imageRepository returns the repository of the given image reference; i.e., provide/baseline
+func imageRepository(image string) string {
+	if i := strings.Index(image, "@"); i != -1 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// ensureImage makes the given image available locally; a locked image is pulled by digest
+// only when it is not already present and is then tagged as the given reference, so the
+// stack runs exactly the locked image; an unlocked image is always pulled, but an image
+// which is already present is used when the registry cannot be reached
+func ensureImage(docker *client.Client, image string) error {
+	ctx := context.Background()
+
+	locked := imageLock.lookup(image)
+	if locked == nil {
+		err := pullImage(docker, image)
+		if err != nil {
+			if _, _, inspectErr := docker.ImageInspectWithRaw(ctx, image); inspectErr == nil {
+				log.Printf("WARNING: failed to pull local baseline container image: %s; using the image already present; %s", image, err.Error())
+				return nil
+			}
+		}
+		return err
+	}
+
+	_, _, err := docker.ImageInspectWithRaw(ctx, locked.ID)
+	if err == nil {
+		log.Printf("using locked local baseline container image: %s", locked.Digest)
+	} else if client.IsErrNotFound(err) {
+		if err := pullImage(docker, locked.Digest); err != nil {
+			return err
+		}
+		inspect, _, err := docker.ImageInspectWithRaw(ctx, locked.Digest)
+		if err != nil {
+			return err
+		}
+		if inspect.ID != locked.ID {
+			return fmt.Errorf("pulled image %s does not match locked image id %s", locked.Digest, locked.ID)
+		}
+	} else {
+		return err
+	}
+
+	return docker.ImageTag(ctx, locked.ID, image)
+}
+
+// readImageStream consumes a progress stream written by the docker daemon, returning the
+// first error reported in the stream; the status of each message is passed to fn, if given
+func readImageStream(reader io.Reader, fn func(status string)) error {
+	decoder := json.NewDecoder(reader)
+	for {
+		var msg struct {
+			Stream string `json:"stream"`
+			Status string `json:"status"`
+			Error  string `json:"error"`
+		}
+		if err := decoder.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if msg.Error != "" {
+			return fmt.Errorf("%s", msg.Error)
+		}
+		if fn != nil {
+			if status := strings.TrimSpace(msg.Stream + msg.Status); status != "" {
+				fn(status)
+			}
+		}
+	}
+}
+
+func init() {
+	imagesBaselineStackCmd.AddCommand(imagesLockBaselineStackCmd)
+	imagesBaselineStackCmd.AddCommand(imagesSaveBaselineStackCmd)
+	imagesBaselineStackCmd.AddCommand(imagesLoadBaselineStackCmd)
+}
@@ -0,0 +1,81 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/docker/docker/client"
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var imagesLoadInputPath string
+
+var imagesLoadBaselineStackCmd = &cobra.Command{
+	Use:   "load",
+	Short: "Load the baseline stack container images from a bundle",
+	Long: `Load the container images in a bundle written by 'prvd baseline stack images save'.
+
+When an image lockfile is present, each locked image is verified to be present once the bundle is loaded.`,
+	RunE: imagesLoadProxy,
+}
+
+func imagesLoadProxy(cmd *cobra.Command, args []string) error {
+	return imagesPrompt(cmd, args, promptStepImagesLoad)
+}
+
+func imagesLoadRun(cmd *cobra.Command, args []string) error {
+	bundle, err := os.Open(imagesLoadInputPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return common.NewNotFoundError("image bundle not found: %s", imagesLoadInputPath)
+		}
+		return fmt.Errorf("failed to read %s; %w", imagesLoadInputPath, err)
+	}
+	defer bundle.Close()
+
+	if err := requireImageLock(cmd); err != nil {
+		return err
+	}
+
+	docker, err := client.NewEnvClient()
+	if err != nil {
+		return fmt.Errorf("failed to initialize docker; %w", err)
+	}
+
+	resp, err := docker.ImageLoad(context.Background(), bundle, true)
+	if err != nil {
+		return fmt.Errorf("failed to load image bundle: %s; %w", imagesLoadInputPath, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.JSON {
+		err = readImageStream(resp.Body, func(status string) {
+			log.Printf("%s", status)
+		})
+	} else {
+		_, err = ioutil.ReadAll(resp.Body)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load image bundle: %s; %w", imagesLoadInputPath, err)
+	}
+
+	if imageLock != nil {
+		for _, entry := range imageLock.Images {
+			if _, _, err := docker.ImageInspectWithRaw(context.Background(), entry.ID); err != nil {
+				log.Printf("WARNING: locked local baseline container image is not present: %s; it will be pulled by 'stack run'", entry.Digest)
+			}
+		}
+	}
+
+	log.Printf("loaded local baseline container images: %s", imagesLoadInputPath)
+	return nil
+}
+
+func init() {
+	imagesLoadBaselineStackCmd.Flags().StringVar(&imagesLoadInputPath, "in", defaultImageBundlePath, "path of the image bundle to load")
+	imagesLoadBaselineStackCmd.Flags().StringVar(&imageLockPath, "lockfile", defaultImageLockPath, "path of the image lockfile used to verify the loaded images")
+}
@@ -0,0 +1,89 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+const imageLockHeader = "# baseline stack image lockfile; written by 'prvd baseline stack images lock'\n"
+
+var imagesLockBaselineStackCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Lock the baseline stack container images",
+	Long: `Pull each container image the baseline stack may run and write the digest of each to a lockfile.
+
+'prvd baseline stack run' honors the lockfile, so every run uses the same images until the lockfile is rewritten.`,
+	RunE: imagesLockProxy,
+}
+
+func imagesLockProxy(cmd *cobra.Command, args []string) error {
+	return imagesPrompt(cmd, args, promptStepImagesLock)
+}
+
+func imagesLockRun(cmd *cobra.Command, args []string) error {
+	images, err := stackImages()
+	if err != nil {
+		return err
+	}
+
+	docker, err := client.NewEnvClient()
+	if err != nil {
+		return fmt.Errorf("failed to initialize docker; %w", err)
+	}
+
+	lock := &stackImageLock{
+		Version: imageLockVersion,
+		Images:  make([]*stackImageLockEntry, 0),
+	}
+
+	for _, image := range images {
+		if err := pullImage(docker, image); err != nil {
+			return fmt.Errorf("failed to pull local baseline container image: %s; %w", image, err)
+		}
+
+		inspect, _, err := docker.ImageInspectWithRaw(context.Background(), image)
+		if err != nil {
+			return fmt.Errorf("failed to inspect local baseline container image: %s; %w", image, err)
+		}
+
+		digest := ""
+		for _, repoDigest := range inspect.RepoDigests {
+			if strings.HasPrefix(repoDigest, fmt.Sprintf("%s@", imageRepository(image))) {
+				digest = repoDigest
+				break
+			}
+		}
+		if digest == "" {
+			return fmt.Errorf("failed to resolve digest of local baseline container image: %s", image)
+		}
+
+		lock.Images = append(lock.Images, &stackImageLockEntry{
+			Image:  image,
+			Digest: digest,
+			ID:     inspect.ID,
+		})
+		log.Printf("locked %s: %s", image, digest)
+	}
+
+	raw, err := yaml.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("failed to marshal image lockfile; %w", err)
+	}
+	if err := ioutil.WriteFile(imageLockPath, append([]byte(imageLockHeader), raw...), 0644); err != nil {
+		return fmt.Errorf("failed to write %s; %w", imageLockPath, err)
+	}
+
+	log.Printf("wrote baseline stack image lockfile: %s", imageLockPath)
+	return nil
+}
+
+func init() {
+	imagesLockBaselineStackCmd.Flags().StringVar(&imageLockPath, "lockfile", defaultImageLockPath, "path of the image lockfile to write")
+}
@@ -0,0 +1,84 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/docker/docker/client"
+	"github.com/spf13/cobra"
+)
+
+const defaultImageBundlePath = "baseline-stack-images.tar"
+
+var imagesSaveOutputPath string
+
+var imagesSaveBaselineStackCmd = &cobra.Command{
+	Use:   "save",
+	Short: "Save the baseline stack container images to a bundle",
+	Long: `Write each container image the baseline stack may run to a tarball which can be loaded using
+'prvd baseline stack images load' on a host without registry access.
+
+When an image lockfile is present, the locked images are saved.`,
+	RunE: imagesSaveProxy,
+}
+
+func imagesSaveProxy(cmd *cobra.Command, args []string) error {
+	return imagesPrompt(cmd, args, promptStepImagesSave)
+}
+
+func imagesSaveRun(cmd *cobra.Command, args []string) error {
+	if err := requireImageLock(cmd); err != nil {
+		return err
+	}
+
+	images, err := stackImages()
+	if err != nil {
+		return err
+	}
+	if imageLock != nil {
+		images = make([]string, 0)
+		for _, entry := range imageLock.Images {
+			images = append(images, entry.Image)
+		}
+	}
+
+	docker, err := client.NewEnvClient()
+	if err != nil {
+		return fmt.Errorf("failed to initialize docker; %w", err)
+	}
+
+	// locked images are tagged as their references, so the bundle loads with the tags the stack runs
+	for _, image := range images {
+		if err := ensureImage(docker, image); err != nil {
+			return fmt.Errorf("failed to pull local baseline container image: %s; %w", image, err)
+		}
+	}
+
+	reader, err := docker.ImageSave(context.Background(), images)
+	if err != nil {
+		return fmt.Errorf("failed to save local baseline container images; %w", err)
+	}
+	defer reader.Close()
+
+	out, err := os.OpenFile(imagesSaveOutputPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s; %w", imagesSaveOutputPath, err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, reader); err != nil {
+		os.Remove(imagesSaveOutputPath)
+		return fmt.Errorf("failed to write %s; %w", imagesSaveOutputPath, err)
+	}
+
+	log.Printf("saved %d local baseline container images: %s", len(images), imagesSaveOutputPath)
+	return nil
+}
+
+func init() {
+	imagesSaveBaselineStackCmd.Flags().StringVar(&imagesSaveOutputPath, "out", defaultImageBundlePath, "path of the image bundle to write")
+	imagesSaveBaselineStackCmd.Flags().StringVar(&imageLockPath, "lockfile", defaultImageLockPath, "path of the image lockfile; when present, the locked container images are saved")
+}
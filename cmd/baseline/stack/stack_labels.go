@@ -0,0 +1,69 @@
+package stack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/provideservices/provide-cli/cmd/common"
+)
+
+// labels applied to each docker resource created for a stack
+const stackLabel = "services.provide.baseline.stack"
+const stackServiceLabel = "services.provide.baseline.service"
+const stackCLIVersionLabel = "services.provide.cli.version"
+
+// legacyStackServices are the services of containers which may have been created
+// without labels by an earlier version of prvd
+var legacyStackServices = stackServiceNames
+
+// containerName returns the name of the container running the given service of the named stack
+func containerName(service string) string {
+	return fmt.Sprintf("%s-%s", strings.ReplaceAll(name, " ", ""), service)
+}
+
+// stackLabels returns the labels of a docker resource belonging to the named stack;
+// the service label is omitted for resources shared by all services, i.e., the network
+func stackLabels(service string) map[string]string {
+	labels := map[string]string{
+		stackLabel:           name,
+		stackCLIVersionLabel: common.Version,
+	}
+	if service != "" {
+		labels[stackServiceLabel] = service
+	}
+	return labels
+}
+
+// stackFilter selects the docker resources belonging to the named stack
+func stackFilter() filters.Args {
+	return filters.NewArgs(filters.KeyValuePair{
+		Key:   "label",
+		Value: fmt.Sprintf("%s=%s", stackLabel, name),
+	})
+}
+
+// stackService returns the service run by the given container of the named stack
+func stackService(container types.Container) string {
+	if service, ok := container.Labels[stackServiceLabel]; ok {
+		return service
+	}
+	for _, n := range container.Names {
+		return strings.TrimPrefix(strings.TrimPrefix(n, "/"), fmt.Sprintf("%s-", strings.ReplaceAll(name, " ", "")))
+	}
+	return ""
+}
+
+// isLegacyContainer returns true if the given unlabeled container is named for a service
+// of the named stack; the docker name filter matches substrings, so names are compared
+func isLegacyContainer(container types.Container) bool {
+	for _, service := range legacyStackServices {
+		for _, n := range container.Names {
+			if strings.TrimPrefix(n, "/") == containerName(service) {
+				return true
+			}
+		}
+	}
+	return false
+}
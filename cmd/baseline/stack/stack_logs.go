@@ -1,75 +1,343 @@
 package stack
 
 import (
+	"bytes"
 	"context"
-	"log"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/provideservices/provide-cli/cmd/common"
+	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/spf13/cobra"
 )
 
+// logColors are the ANSI colors cycled through for the service prefixes, as by docker-compose
+var logColors = []string{"36", "33", "32", "35", "34", "31", "96", "93", "92", "95", "94", "91"}
+
+var logsFollow bool
+var logsServices []string
+var logsSince string
+var logsTail string
+var logsTimestamps bool
+var logsGrep string
+var logsJSON bool
+
 var logsBaselineStackCmd = &cobra.Command{
 	Use:   "logs",
 	Short: "Print baseline stack logs",
-	Long:  `Print the logs from each container in a local baseline stack instance`,
-	Run:   logsProxy,
+	Long: `Print the logs from each container in a local baseline stack instance.
+
+The logs of each service are prefixed with the name of the service. Use --json to print each line
+as a JSON object; the structured log lines written by the Provide services are parsed into fields.`,
+	RunE: logsProxy,
 }
 
-func logsProxy(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepLogs)
+// stackLogEntry is a log line of a stack service, as printed using --json
+type stackLogEntry struct {
+	Service   string                 `json:"service"`
+	Stream    string                 `json:"stream"`
+	Timestamp string                 `json:"timestamp,omitempty"`
+	Level     string                 `json:"level,omitempty"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
 }
 
-func logsProxyRun(cmd *cobra.Command, args []string) {
+// logPrinter writes the multiplexed log lines of the stack services
+type logPrinter struct {
+	mutex  sync.Mutex
+	out    io.Writer
+	grep   *regexp.Regexp
+	colors map[string]string // prefix color keyed by service; nil when not writing to a terminal
+	width  int               // width of the longest service name, so the lines align
+}
+
+func logsProxy(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepLogs)
+}
+
+func logsProxyRun(cmd *cobra.Command, args []string) error {
+	printer, err := logPrinterFactory()
+	if err != nil {
+		return err
+	}
+
 	docker, err := client.NewEnvClient()
 	if err != nil {
-		log.Printf("failed to initialize docker; %s", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("failed to initialize docker; %w", err)
+	}
+
+	containers, err := listContainers(docker)
+	if err != nil {
+		return err
+	}
+
+	selected := make([]types.Container, 0)
+	for _, container := range containers {
+		if len(logsServices) == 0 || containsString(logsServices, stackService(container)) {
+			selected = append(selected, container)
+		}
+	}
+	if len(selected) == 0 {
+		return common.NewNotFoundError("no containers found for %s local baseline instance", name)
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		return stackService(selected[i]) < stackService(selected[j])
+	})
+	for i, container := range selected {
+		service := stackService(container)
+		if len(service) > printer.width {
+			printer.width = len(service)
+		}
+		if printer.colors != nil {
+			printer.colors[service] = logColors[i%len(logColors)]
+		}
 	}
 
 	wg := sync.WaitGroup{}
-	logContainers(docker, &wg)
+	errs := make(chan error, len(selected))
+	for _, container := range selected {
+		wg.Add(1)
+		go func(container types.Container) {
+			defer wg.Done()
+			if err := logContainer(docker, container, printer); err != nil {
+				errs <- fmt.Errorf("failed to read logs of %s; %w", stackService(container), err)
+			}
+		}(container)
+	}
 	wg.Wait()
+	close(errs)
+
+	return <-errs
 }
 
-func logContainers(docker *client.Client, wg *sync.WaitGroup) error {
-	for _, container := range listContainers(docker) {
-		if wg != nil {
-			wg.Add(1)
+// logPrinterFactory validates the logs flags and returns the printer they configure
+func logPrinterFactory() (*logPrinter, error) {
+	for _, service := range logsServices {
+		if !containsString(stackServiceNames, service) {
+			return nil, common.NewValidationError("invalid --service: %s; must be one of %s", service, strings.Join(stackServiceNames, ", "))
 		}
+	}
 
-		containerID := make([]byte, len(container.ID))
-		copy(containerID, container.ID)
-
-		go func() {
-			logContainer(docker, string(containerID))
-			if wg != nil {
-				wg.Done()
+	if logsSince != "" {
+		if _, err := time.ParseDuration(logsSince); err != nil {
+			if _, err := time.Parse(time.RFC3339, logsSince); err != nil {
+				return nil, common.NewValidationError("invalid --since: %s; must be a duration, i.e., 10m, or an RFC 3339 timestamp", logsSince)
 			}
-		}()
+		}
+	}
+
+	if logsTail != "all" {
+		if n, err := strconv.Atoi(logsTail); err != nil || n < 0 {
+			return nil, common.NewValidationError("invalid --tail: %s; must be all or a number of lines", logsTail)
+		}
 	}
 
-	return nil
+	printer := &logPrinter{
+		out: os.Stdout,
+	}
+
+	if logsGrep != "" {
+		grep, err := regexp.Compile(logsGrep)
+		if err != nil {
+			return nil, common.NewValidationError("invalid --grep: %s; %s", logsGrep, err.Error())
+		}
+		printer.grep = grep
+	}
+
+	if !isJSONLogs() && terminal.IsTerminal(int(os.Stdout.Fd())) {
+		printer.colors = map[string]string{}
+	}
+
+	return printer, nil
 }
 
-func logContainer(docker *client.Client, containerID string) error {
-	out, err := docker.ContainerLogs(context.Background(), containerID, types.ContainerLogsOptions{
+// isJSONLogs returns true when the log lines are printed as JSON objects
+func isJSONLogs() bool {
+	return logsJSON || strings.ToLower(common.Output) == common.OutputFormatJSON
+}
+
+// logContainer writes the logs of the given container to the printer; when following,
+// it returns once the container stops
+func logContainer(docker *client.Client, container types.Container, printer *logPrinter) error {
+	out, err := docker.ContainerLogs(context.Background(), container.ID, types.ContainerLogsOptions{
 		ShowStderr: true,
 		ShowStdout: true,
+		Follow:     logsFollow,
+		Since:      logsSince,
+		Tail:       logsTail,
+		Timestamps: logsTimestamps || isJSONLogs(),
 	})
 	if err != nil {
 		return err
 	}
+	defer out.Close()
 
-	stdcopy.StdCopy(os.Stdout, os.Stderr, out)
+	service := stackService(container)
+	stdout := &logLineWriter{fn: func(line string) { printer.print(service, "stdout", line) }}
+	stderr := &logLineWriter{fn: func(line string) { printer.print(service, "stderr", line) }}
 
-	return nil
+	_, err = stdcopy.StdCopy(stdout, stderr, out)
+	stdout.flush()
+	stderr.flush()
+	return err
+}
+
+// print writes a single log line of the given service, unless it is filtered by --grep
+func (p *logPrinter) print(service, stream, line string) {
+	line = strings.TrimRight(line, "\r")
+
+	timestamp := ""
+	if logsTimestamps || isJSONLogs() {
+		if i := strings.Index(line, " "); i != -1 {
+			timestamp, line = line[:i], line[i+1:]
+		}
+	}
+
+	if p.grep != nil && !p.grep.MatchString(line) {
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if isJSONLogs() {
+		entry := parseLogLine(line)
+		entry.Service = service
+		entry.Stream = stream
+		entry.Timestamp = timestamp
+		raw, _ := json.Marshal(entry)
+		fmt.Fprintf(p.out, "%s\n", raw)
+		return
+	}
+
+	prefix := fmt.Sprintf("%-*s |", p.width, service)
+	if color, ok := p.colors[service]; ok {
+		prefix = fmt.Sprintf("\033[%sm%s\033[0m", color, prefix)
+	}
+	if timestamp != "" {
+		fmt.Fprintf(p.out, "%s %s %s\n", prefix, timestamp, line)
+	} else {
+		fmt.Fprintf(p.out, "%s %s\n", prefix, line)
+	}
+}
+
+// parseLogLine parses a log line written by a Provide service using either the JSON or the
+// text logrus formatter; lines which are not structured are returned as the message
+func parseLogLine(line string) *stackLogEntry {
+	entry := &stackLogEntry{
+		Message: line,
+	}
+
+	fields := map[string]interface{}{}
+	if strings.HasPrefix(line, "{") {
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			return entry
+		}
+	} else {
+		fields = parseLogfmt(line)
+		if _, ok := fields["msg"]; !ok {
+			return entry
+		}
+	}
+
+	if msg, ok := fields["msg"].(string); ok {
+		entry.Message = msg
+		delete(fields, "msg")
+	}
+	if level, ok := fields["level"].(string); ok {
+		entry.Level = level
+		delete(fields, "level")
+	}
+	if len(fields) > 0 {
+		entry.Fields = fields
+	}
+	return entry
+}
+
+// parseLogfmt parses the key=value pairs of a line written by the logrus text formatter;
+// values are optionally quoted, i.e., time="2021-06-17T20:13:20Z" level=info msg="started"
+func parseLogfmt(line string) map[string]interface{} {
+	fields := map[string]interface{}{}
+	for line = strings.TrimSpace(line); line != ""; line = strings.TrimSpace(line) {
+		eq := strings.Index(line, "=")
+		if eq <= 0 || strings.Contains(line[:eq], " ") {
+			return map[string]interface{}{}
+		}
+		key := line[:eq]
+		line = line[eq+1:]
+
+		if strings.HasPrefix(line, `"`) {
+			end := 1
+			for end < len(line) && (line[end] != '"' || line[end-1] == '\\') {
+				end++
+			}
+			if end == len(line) {
+				return map[string]interface{}{}
+			}
+			val, err := strconv.Unquote(line[:end+1])
+			if err != nil {
+				return map[string]interface{}{}
+			}
+			fields[key] = val
+			line = line[end+1:]
+		} else {
+			end := strings.Index(line, " ")
+			if end == -1 {
+				end = len(line)
+			}
+			fields[key] = line[:end]
+			line = line[end:]
+		}
+	}
+	return fields
+}
+
+// logLineWriter passes each complete line written to it to fn
+type logLineWriter struct {
+	buf bytes.Buffer
+	fn  func(line string)
+}
+
+func (w *logLineWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i == -1 {
+			return len(p), nil
+		}
+		line := string(w.buf.Next(i + 1))
+		w.fn(strings.TrimSuffix(line, "\n"))
+	}
+}
+
+// flush passes the final line to fn when it is not terminated by a newline
+func (w *logLineWriter) flush() {
+	if w.buf.Len() > 0 {
+		w.fn(w.buf.String())
+		w.buf.Reset()
+	}
 }
 
 func init() {
 	logsBaselineStackCmd.Flags().StringVar(&name, "name", "baseline-local", "name of the baseline stack instance")
+	logsBaselineStackCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "follow the logs until interrupted")
+	logsBaselineStackCmd.Flags().StringSliceVar(&logsServices, "service", []string{}, "comma-separated services whose logs are printed; i.e., api,consumer (default all services)")
+	logsBaselineStackCmd.Flags().StringVar(&logsSince, "since", "", "print logs since the given duration, i.e., 10m, or RFC 3339 timestamp")
+	logsBaselineStackCmd.Flags().StringVar(&logsTail, "tail", "all", "number of lines to print from the end of the logs of each service")
+	logsBaselineStackCmd.Flags().BoolVar(&logsTimestamps, "timestamps", false, "when true, each line is prefixed with its timestamp")
+	logsBaselineStackCmd.Flags().StringVar(&logsGrep, "grep", "", "regular expression; only matching lines are printed")
+	logsBaselineStackCmd.Flags().BoolVar(&logsJSON, "json", false, "when true, each line is printed as a JSON object with the fields of structured log lines parsed")
 }
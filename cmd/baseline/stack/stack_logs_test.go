@@ -0,0 +1,143 @@
+package stack
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLogfmt(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want map[string]interface{}
+	}{
+		{
+			name: "unquoted values",
+			line: "level=info msg=started port=8080",
+			want: map[string]interface{}{"level": "info", "msg": "started", "port": "8080"},
+		},
+		{
+			name: "quoted values",
+			line: `time="2021-06-17T20:13:20Z" level=info msg="listening on :8080"`,
+			want: map[string]interface{}{"time": "2021-06-17T20:13:20Z", "level": "info", "msg": "listening on :8080"},
+		},
+		{
+			name: "escaped quotes",
+			line: `level=warning msg="failed to resolve \"api\"; retrying"`,
+			want: map[string]interface{}{"level": "warning", "msg": `failed to resolve "api"; retrying`},
+		},
+		{
+			name: "empty quoted value",
+			line: `level=debug msg=""`,
+			want: map[string]interface{}{"level": "debug", "msg": ""},
+		},
+		{
+			name: "empty unquoted value",
+			line: "level= msg=started",
+			want: map[string]interface{}{"level": "", "msg": "started"},
+		},
+		{
+			name: "surrounding and repeated whitespace",
+			line: "  level=info   msg=started  ",
+			want: map[string]interface{}{"level": "info", "msg": "started"},
+		},
+		{
+			name: "value containing equals",
+			line: "msg=started query=a=b",
+			want: map[string]interface{}{"msg": "started", "query": "a=b"},
+		},
+		{
+			name: "empty",
+			line: "",
+			want: map[string]interface{}{},
+		},
+		{
+			name: "unstructured",
+			line: "listening on :8080",
+			want: map[string]interface{}{},
+		},
+		{
+			name: "missing key",
+			line: "=info msg=started",
+			want: map[string]interface{}{},
+		},
+		{
+			name: "trailing text",
+			line: "level=info msg=started and more",
+			want: map[string]interface{}{},
+		},
+		{
+			name: "unterminated quote",
+			line: `level=info msg="started`,
+			want: map[string]interface{}{},
+		},
+		{
+			name: "invalid escape",
+			line: `level=info msg="\q"`,
+			want: map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseLogfmt(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLogfmt(%q) = %v; want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want *stackLogEntry
+	}{
+		{
+			name: "text formatter",
+			line: `time="2021-06-17T20:13:20Z" level=info msg="listening on :8080"`,
+			want: &stackLogEntry{
+				Level:   "info",
+				Message: "listening on :8080",
+				Fields:  map[string]interface{}{"time": "2021-06-17T20:13:20Z"},
+			},
+		},
+		{
+			name: "text formatter without fields",
+			line: "level=info msg=started",
+			want: &stackLogEntry{Level: "info", Message: "started"},
+		},
+		{
+			name: "text formatter without msg",
+			line: "level=info port=8080",
+			want: &stackLogEntry{Message: "level=info port=8080"},
+		},
+		{
+			name: "json formatter",
+			line: `{"level":"error","msg":"failed to connect","retries":3}`,
+			want: &stackLogEntry{
+				Level:   "error",
+				Message: "failed to connect",
+				Fields:  map[string]interface{}{"retries": float64(3)},
+			},
+		},
+		{
+			name: "invalid json",
+			line: `{"level":"error"`,
+			want: &stackLogEntry{Message: `{"level":"error"`},
+		},
+		{
+			name: "unstructured",
+			line: "listening on :8080",
+			want: &stackLogEntry{Message: "listening on :8080"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseLogLine(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLogLine(%q) = %+v; want %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
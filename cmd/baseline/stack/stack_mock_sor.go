@@ -0,0 +1,264 @@
+package stack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+// mockSORContainerImage packages prvd, which serves the mock system of record using the hidden
+// 'prvd baseline stack mock-sor serve' command; it is built from the Dockerfile of prvd, and
+// tagged with the version of prvd by 'make docker'
+const mockSORContainerImage = "provide/prvd"
+const mockSORContainerPort = 8080
+
+// paths of the fixtures of the mock system of record and its recorded writes within the container
+const mockSORFixturesPath = "/opt/mock-sor/fixtures"
+const mockSORDataPath = "/data"
+const mockSORWritesPath = "/data/writes.jsonl"
+
+// credentials with which the baseline services authorize against the mock system of record
+const mockSORUsername = "prvd"
+const mockSORPassword = "prvd"
+
+// mockSORRequestTimeout bounds a request to the mock system of record; pushing the fixtures
+// waits on the local baseline API
+const mockSORRequestTimeout = time.Minute * 2
+
+// mockSORIdentifiers are the systems of record which can be mocked
+var mockSORIdentifiers = []string{"salesforce", "sap", "servicenow"}
+
+var mockSOR string
+var mockSORPort int
+var mockSORFixturesDir string
+
+var mockSORBaselineStackCmd = &cobra.Command{
+	Use:   "mock-sor",
+	Short: "Interact with the mock system of record of a baseline stack",
+	Long: `Push business objects from, and inspect the writes recorded by, the mock system of record of a
+local baseline stack instance.
+
+'prvd baseline stack run --mock-sor sap|servicenow|salesforce' runs a stand-in for the system of record
+which serves the subset of its API called by the baseline consumer, and wires it into the stack. The mock
+is served by the provide/prvd image tagged with the version of prvd; for an unreleased version, build the
+image from the source of prvd using 'make docker'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
+
+		return mockSORPrompt(cmd, args, "")
+	},
+}
+
+// validateMockSORFlags returns an error if the mock system of record flags are invalid
+func validateMockSORFlags() error {
+	if mockSOR == "" {
+		return nil
+	}
+	if !containsString(mockSORIdentifiers, mockSOR) {
+		return common.NewValidationError("invalid --mock-sor: %s; must be one of %s", mockSOR, strings.Join(mockSORIdentifiers, ", "))
+	}
+	if sorID != "" && sorID != mockSOR {
+		return common.NewValidationError("--sor %s conflicts with --mock-sor %s", sorID, mockSOR)
+	}
+	if mockSORFixturesDir != "" {
+		info, err := os.Stat(mockSORFixturesDir)
+		if os.IsNotExist(err) {
+			return common.NewNotFoundError("mock system of record fixture directory not found: %s", mockSORFixturesDir)
+		} else if err != nil {
+			return fmt.Errorf("failed to read %s; %w", mockSORFixturesDir, err)
+		} else if !info.IsDir() {
+			return common.NewValidationError("invalid --mock-sor-fixtures: %s; must be a directory", mockSORFixturesDir)
+		}
+	}
+	return nil
+}
+
+// mockSORHostname returns the hostname of the mock system of record container
+func mockSORHostname() string {
+	return containerName(serviceMockSOR)
+}
+
+// mockSORPath returns the base path at which the mock serves the API of the system of record
+func mockSORPath() string {
+	if sor, ok := lookupSystemOfRecord(mockSOR).(*sorAPIConnector); ok {
+		return sor.path
+	}
+	return ""
+}
+
+// mockSORURL returns the url of the API of the mock system of record within the stack network
+func mockSORURL() string {
+	return strings.TrimSuffix(fmt.Sprintf("http://%s:%d/%s", mockSORHostname(), mockSORContainerPort, strings.Trim(mockSORPath(), "/")), "/")
+}
+
+// mockSOREnvironment returns the environment which points the baseline services at the
+// mock system of record in place of any configured system of record API
+func mockSOREnvironment() []string {
+	sor, ok := lookupSystemOfRecord(mockSOR).(*sorAPIConnector)
+	if !ok {
+		return nil
+	}
+	return sor.environment(fmt.Sprintf("%s:%d", mockSORHostname(), mockSORContainerPort), "http", sor.path, mockSORUsername, mockSORPassword)
+}
+
+// readMockSORFixtures returns the JSON business object fixtures of the mock system of
+// record, keyed by file name
+func readMockSORFixtures() (map[string]string, error) {
+	fixtures := map[string]string{}
+	if mockSORFixturesDir == "" {
+		return fixtures, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(mockSORFixturesDir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fixtures in %s; %w", mockSORFixturesDir, err)
+	}
+
+	for _, path := range paths {
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s; %w", path, err)
+		}
+		var obj map[string]interface{}
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			return nil, common.NewValidationError("invalid mock system of record fixture %s; must be a JSON object; %s", path, err.Error())
+		}
+		fixtures[filepath.Base(path)] = string(raw)
+	}
+
+	return fixtures, nil
+}
+
+// mockSORImage returns the reference of the image which serves the mock system of record; its
+// version is that of the running prvd, so the hidden serve command is that of the same release
+func mockSORImage() string {
+	return fmt.Sprintf("%s:%s", mockSORContainerImage, common.Version)
+}
+
+// mockSORService returns the mock system of record service; its fixtures are mounted into
+// the container, and its recorded writes outlive the container
+func mockSORService() (*serviceSpec, error) {
+	fixtures, err := readMockSORFixtures()
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{}
+	for fixture, content := range fixtures {
+		files[path.Join(mockSORFixturesPath, fixture)] = content
+	}
+
+	return &serviceSpec{
+		service:     serviceMockSOR,
+		description: fmt.Sprintf("local mock %s system of record", mockSOR),
+		hostname:    mockSORHostname(),
+		image:       mockSORImage(),
+		entrypoint:  []string{"prvd"},
+		cmd: []string{
+			"baseline", "stack", "mock-sor", "serve",
+			"--sor", mockSOR,
+			"--path", strings.Trim(mockSORPath(), "/"),
+			"--port", strconv.Itoa(mockSORContainerPort),
+			"--baseline-api", fmt.Sprintf("http://%s:%d", apiHostname, apiContainerPort),
+			"--fixtures", mockSORFixturesPath,
+			"--writes", mockSORWritesPath,
+		},
+		healthcheck: []string{"CMD", "wget", "-q", "-O", "/dev/null", fmt.Sprintf("http://localhost:%d/_mock/status", mockSORContainerPort)},
+		files:       files,
+		ports: []portMapping{{
+			hostPort:      mockSORPort,
+			containerPort: mockSORContainerPort,
+			flag:          "mock-sor-port",
+		}},
+		volumes: []volumeMapping{{
+			volume: "mock-sor-data",
+			target: mockSORDataPath,
+		}},
+	}, nil
+}
+
+// mockSORRequest calls the control API of the mock system of record of the named stack on
+// the host port it is exposed on, unmarshaling the response into result, if given
+func mockSORRequest(method, uri string, params, result interface{}) error {
+	docker, err := client.NewEnvClient()
+	if err != nil {
+		return fmt.Errorf("failed to initialize docker; %w", err)
+	}
+
+	containers, err := listContainers(docker)
+	if err != nil {
+		return err
+	}
+
+	hostPort := 0
+	for _, container := range containers {
+		if stackService(container) != serviceMockSOR {
+			continue
+		}
+		for _, p := range container.Ports {
+			if int(p.PrivatePort) == mockSORContainerPort && p.PublicPort != 0 {
+				hostPort = int(p.PublicPort)
+			}
+		}
+		if hostPort == 0 {
+			return fmt.Errorf("mock system of record of %s local baseline instance is not running", name)
+		}
+	}
+	if hostPort == 0 {
+		return common.NewNotFoundError("no mock system of record found for %s local baseline instance; run the stack using --mock-sor", name)
+	}
+
+	var body bytes.Buffer
+	if params != nil {
+		if err := json.NewEncoder(&body).Encode(params); err != nil {
+			return fmt.Errorf("failed to marshal mock system of record request; %w", err)
+		}
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("http://localhost:%d%s", hostPort, uri), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := (&http.Client{Timeout: mockSORRequestTimeout}).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach mock system of record of %s local baseline instance; %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read mock system of record response; %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("mock system of record responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+
+	if result != nil && len(raw) > 0 {
+		if err := json.Unmarshal(raw, result); err != nil {
+			return fmt.Errorf("failed to parse mock system of record response; %w", err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	mockSORBaselineStackCmd.AddCommand(mockSORPushBaselineStackCmd)
+	mockSORBaselineStackCmd.AddCommand(mockSORWritesBaselineStackCmd)
+	mockSORBaselineStackCmd.AddCommand(mockSORServeBaselineStackCmd)
+}
@@ -0,0 +1,83 @@
+package stack
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var mockSORPushFixtures []string
+
+var mockSORPushBaselineStackCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Push business objects from the mock system of record",
+	Long: `Push the business object fixtures of the mock system of record to the local baseline API, as the
+system of record would.
+
+Each JSON file in the directory passed to 'prvd baseline stack run' using --mock-sor-fixtures is
+POSTed as-is to /api/v1/business_objects on behalf of the organization; use --fixture to push only
+the named fixtures.`,
+	RunE: mockSORPushProxy,
+}
+
+// mockSORPushResult is the outcome of pushing a single business object fixture
+type mockSORPushResult struct {
+	Fixture  string      `json:"fixture"`
+	Status   int         `json:"status"` // status of the local baseline API response; zero when it was not reached
+	Response interface{} `json:"response,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+func mockSORPushProxy(cmd *cobra.Command, args []string) error {
+	return mockSORPrompt(cmd, args, promptStepMockSORPush)
+}
+
+func mockSORPushRun(cmd *cobra.Command, args []string) error {
+	if err := common.AuthorizeOrganizationContext(false); err != nil {
+		return err
+	}
+
+	results := make([]*mockSORPushResult, 0)
+	err := mockSORRequest("POST", "/_mock/push", map[string]interface{}{
+		"token":    common.OrganizationAccessToken,
+		"fixtures": mockSORPushFixtures,
+	}, &results)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return common.NewNotFoundError("no business object fixtures to push from the mock system of record of %s local baseline instance", name)
+	}
+
+	failed := 0
+	table := common.NewTable("FIXTURE", "STATUS", "ERROR")
+	for _, result := range results {
+		status := "-"
+		if result.Status != 0 {
+			status = strconv.Itoa(result.Status)
+		}
+		if result.Status < 200 || result.Status >= 300 {
+			failed++
+			if result.Error == "" {
+				result.Error = fmt.Sprintf("%v", result.Response)
+			}
+		}
+		table.AddRow(result.Fixture, status, result.Error)
+	}
+	if err := common.Render(results, table); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d business object fixtures failed to push", failed, len(results))
+	}
+	return nil
+}
+
+func init() {
+	mockSORPushBaselineStackCmd.Flags().StringVar(&name, "name", "baseline-local", "name of the baseline stack instance")
+	mockSORPushBaselineStackCmd.Flags().StringVar(&common.OrganizationID, "organization", "", "organization identifier")
+	mockSORPushBaselineStackCmd.Flags().StringSliceVar(&mockSORPushFixtures, "fixture", []string{}, "comma-separated fixtures to push, with or without the .json extension (default all fixtures)")
+}
@@ -0,0 +1,50 @@
+package stack
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+var mockSORServeSOR string
+var mockSORServePath string
+var mockSORServePort int
+var mockSORServeBaselineAPI string
+var mockSORServeFixturesDir string
+var mockSORServeWritesPath string
+
+var mockSORServeBaselineStackCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the mock system of record",
+	Long: `Serve the subset of the API of the mocked system of record which is called by the baseline consumer.
+
+This is the entrypoint of the mock system of record container run by 'prvd baseline stack run --mock-sor';
+it is not intended to be run directly.`,
+	Hidden: true,
+	RunE:   mockSORServeRun,
+}
+
+func mockSORServeRun(cmd *cobra.Command, args []string) error {
+	server, err := newMockSORServer(mockSORServeSOR, mockSORServePath, mockSORServeBaselineAPI, mockSORServeFixturesDir, mockSORServeWritesPath)
+	if err != nil {
+		return err
+	}
+
+	basePath := server.basePath
+	if basePath == "" {
+		basePath = "/"
+	}
+	log.Printf("mock %s system of record listening on port %d; base path: %s", mockSORServeSOR, mockSORServePort, basePath)
+	return http.ListenAndServe(fmt.Sprintf(":%d", mockSORServePort), server)
+}
+
+func init() {
+	mockSORServeBaselineStackCmd.Flags().StringVar(&mockSORServeSOR, "sor", "", "mocked system of record; one of salesforce, sap or servicenow")
+	mockSORServeBaselineStackCmd.Flags().StringVar(&mockSORServePath, "path", "", "base path at which the API of the system of record is served")
+	mockSORServeBaselineStackCmd.Flags().IntVar(&mockSORServePort, "port", mockSORContainerPort, "port on which to serve the mock system of record")
+	mockSORServeBaselineStackCmd.Flags().StringVar(&mockSORServeBaselineAPI, "baseline-api", "", "url of the local baseline API to which fixtures are pushed")
+	mockSORServeBaselineStackCmd.Flags().StringVar(&mockSORServeFixturesDir, "fixtures", mockSORFixturesPath, "directory of JSON business object fixtures")
+	mockSORServeBaselineStackCmd.Flags().StringVar(&mockSORServeWritesPath, "writes", mockSORWritesPath, "JSON lines file to which the writes are recorded")
+}
@@ -0,0 +1,474 @@
+package stack
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	uuid "github.com/kthomas/go.uuid"
+)
+
+// mockSORPushTimeout bounds the push of a single business object fixture to the local baseline API
+const mockSORPushTimeout = time.Second * 30
+
+// mockSORRoute is an operation of the API of a mocked system of record; each segment of the
+// pattern of the form {param} matches any single segment of the request path
+type mockSORRoute struct {
+	method  string
+	pattern string // relative to the base path of the API; i.e., business_objects/{id}
+	handle  func(params map[string]string, body interface{}) (int, interface{})
+}
+
+// mockSORRoutes are the operations of the API of each mocked system of record which are called
+// by the baseline consumer, keyed by system of record
+var mockSORRoutes = map[string][]*mockSORRoute{
+	// internal SAP API service, i.e., the UBC extension
+	"sap": {
+		{method: http.MethodGet, pattern: "health", handle: mockSORStatus},
+		{method: http.MethodPost, pattern: "business_objects", handle: mockSORCreated},
+		{method: http.MethodPut, pattern: "business_objects/{id}", handle: mockSORNoContent},
+		{method: http.MethodPut, pattern: "business_objects/{id}/status", handle: mockSORNoContent},
+		{method: http.MethodPut, pattern: "proxy", handle: mockSORNoContent},
+		{method: http.MethodPost, pattern: "tenants", handle: mockSORCreated},
+		{method: http.MethodDelete, pattern: "tenants/{id}", handle: mockSORNoContent},
+	},
+
+	// ServiceNow table API
+	"servicenow": {
+		{method: http.MethodGet, pattern: "{table}", handle: mockServiceNowRecords},
+		{method: http.MethodGet, pattern: "{table}/{sys_id}", handle: mockServiceNowRecordNotFound},
+		{method: http.MethodPost, pattern: "{table}", handle: mockServiceNowRecord},
+		{method: http.MethodPut, pattern: "{table}/{sys_id}", handle: mockServiceNowRecord},
+		{method: http.MethodPatch, pattern: "{table}/{sys_id}", handle: mockServiceNowRecord},
+		{method: http.MethodDelete, pattern: "{table}/{sys_id}", handle: mockSORNoContent},
+	},
+
+	// Salesforce REST API
+	"salesforce": {
+		{method: http.MethodGet, pattern: "services/data", handle: mockSalesforceVersions},
+		{method: http.MethodGet, pattern: "services/data/{version}/query", handle: mockSalesforceQuery},
+		{method: http.MethodPost, pattern: "services/data/{version}/sobjects/{sobject}", handle: mockSalesforceCreated},
+		{method: http.MethodPatch, pattern: "services/data/{version}/sobjects/{sobject}/{id}", handle: mockSORNoContent},
+		{method: http.MethodDelete, pattern: "services/data/{version}/sobjects/{sobject}/{id}", handle: mockSORNoContent},
+	},
+}
+
+func mockSORStatus(params map[string]string, body interface{}) (int, interface{}) {
+	return http.StatusOK, map[string]interface{}{"status": "ok"}
+}
+
+func mockSORCreated(params map[string]string, body interface{}) (int, interface{}) {
+	return http.StatusCreated, map[string]interface{}{"id": mockSORObjectID()}
+}
+
+func mockSORNoContent(params map[string]string, body interface{}) (int, interface{}) {
+	return http.StatusNoContent, nil
+}
+
+func mockServiceNowRecords(params map[string]string, body interface{}) (int, interface{}) {
+	return http.StatusOK, map[string]interface{}{"result": []interface{}{}}
+}
+
+// mockServiceNowRecordNotFound responds as ServiceNow does to a read of an unknown record; the
+// mock does not retain the records written to it
+func mockServiceNowRecordNotFound(params map[string]string, body interface{}) (int, interface{}) {
+	return http.StatusNotFound, map[string]interface{}{
+		"error":  map[string]interface{}{"message": "No Record found", "detail": "Record doesn't exist or ACL restricts the record retrieval"},
+		"status": "failure",
+	}
+}
+
+// mockServiceNowRecord echoes the written record, as ServiceNow does, assigning its sys_id on create
+func mockServiceNowRecord(params map[string]string, body interface{}) (int, interface{}) {
+	record := map[string]interface{}{}
+	if fields, ok := body.(map[string]interface{}); ok {
+		for key, val := range fields {
+			record[key] = val
+		}
+	}
+
+	status := http.StatusOK
+	if id, ok := params["sys_id"]; ok {
+		record["sys_id"] = id
+	} else {
+		status = http.StatusCreated
+		record["sys_id"] = mockSORObjectID()
+	}
+	return status, map[string]interface{}{"result": record}
+}
+
+func mockSalesforceVersions(params map[string]string, body interface{}) (int, interface{}) {
+	return http.StatusOK, []interface{}{
+		map[string]interface{}{"label": "Summer '21", "url": "/services/data/v52.0", "version": "52.0"},
+	}
+}
+
+func mockSalesforceQuery(params map[string]string, body interface{}) (int, interface{}) {
+	return http.StatusOK, map[string]interface{}{"done": true, "records": []interface{}{}, "totalSize": 0}
+}
+
+func mockSalesforceCreated(params map[string]string, body interface{}) (int, interface{}) {
+	return http.StatusCreated, map[string]interface{}{"id": mockSORObjectID(), "success": true, "errors": []interface{}{}}
+}
+
+// mockSORObjectID returns a new identifier of an object created in the mocked system of record
+func mockSORObjectID() string {
+	id, _ := uuid.NewV4()
+	return id.String()
+}
+
+// match returns the parameters of the route if it matches the given path, relative to the base path
+func (r *mockSORRoute) match(method, path string) (map[string]string, bool) {
+	if r.method != method {
+		return nil, false
+	}
+
+	pattern := strings.Split(r.pattern, "/")
+	segments := strings.Split(path, "/")
+	if len(pattern) != len(segments) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for i, segment := range pattern {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if segments[i] == "" {
+				return nil, false
+			}
+			params[strings.Trim(segment, "{}")] = segments[i]
+		} else if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// mockSORServer serves the mocked system of record within the stack; it records each write
+// to a JSON lines file, and pushes its business object fixtures to the local baseline API
+// when asked to by 'prvd baseline stack mock-sor push'
+type mockSORServer struct {
+	sor         string
+	basePath    string // i.e., /ubc; empty when the API is served from the root
+	baselineAPI string
+	fixturesDir string
+	writesPath  string
+	routes      []*mockSORRoute
+
+	client *http.Client
+	mutex  sync.Mutex // serializes access to the recorded writes
+}
+
+// newMockSORServer returns the mock server of the given system of record, serving its API at
+// the given base path
+func newMockSORServer(sor, basePath, baselineAPI, fixturesDir, writesPath string) (*mockSORServer, error) {
+	routes, ok := mockSORRoutes[sor]
+	if !ok {
+		return nil, fmt.Errorf("unsupported mock system of record: %s", sor)
+	}
+
+	if basePath = strings.Trim(basePath, "/"); basePath != "" {
+		basePath = "/" + basePath
+	}
+
+	return &mockSORServer{
+		sor:         sor,
+		basePath:    basePath,
+		baselineAPI: strings.TrimSuffix(baselineAPI, "/"),
+		fixturesDir: fixturesDir,
+		writesPath:  writesPath,
+		routes:      routes,
+		client:      &http.Client{Timeout: mockSORPushTimeout},
+	}, nil
+}
+
+func (s *mockSORServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/_mock/status":
+		if r.Method != http.MethodGet {
+			s.reply(w, http.StatusMethodNotAllowed, map[string]interface{}{"error": "method not allowed"})
+			return
+		}
+		s.reply(w, http.StatusOK, map[string]interface{}{"status": "ok", "sor": s.sor})
+		return
+	case "/_mock/writes":
+		s.serveWrites(w, r)
+		return
+	case "/_mock/push":
+		s.servePush(w, r)
+		return
+	}
+
+	body, err := mockSORRequestBody(r)
+	if err != nil {
+		s.reply(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	route, params := s.route(r.Method, r.URL.Path)
+	if route == nil {
+		log.Printf("unsupported request: %s %s", r.Method, r.URL.Path)
+		s.reply(w, http.StatusNotFound, map[string]interface{}{"error": "not found"})
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		if err := s.record(r, body); err != nil {
+			log.Printf("failed to record write; %s", err.Error())
+			s.reply(w, http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+			return
+		}
+	}
+
+	status, resp := route.handle(params, body)
+	s.reply(w, status, resp)
+}
+
+// route returns the route of the mocked API which handles the given request, and its parameters
+func (s *mockSORServer) route(method, path string) (*mockSORRoute, map[string]string) {
+	if s.basePath != "" {
+		if path != s.basePath && !strings.HasPrefix(path, s.basePath+"/") {
+			return nil, nil
+		}
+		path = strings.TrimPrefix(path, s.basePath)
+	}
+	path = strings.Trim(path, "/")
+
+	for _, route := range s.routes {
+		if params, ok := route.match(method, path); ok {
+			return route, params
+		}
+	}
+	return nil, nil
+}
+
+func (s *mockSORServer) reply(w http.ResponseWriter, status int, body interface{}) {
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+	raw, _ := json.Marshal(body)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(raw)
+}
+
+// mockSORRequestBody returns the JSON body of the given request; a body which is not JSON is
+// returned as a string
+func mockSORRequestBody(r *http.Request) (interface{}, error) {
+	raw, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body; %w", err)
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return string(raw), nil
+	}
+	return body, nil
+}
+
+// basicAuthUsername returns the username with which the given request authorizes, if any
+func basicAuthUsername(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(strings.ToLower(auth), "basic ") {
+		return ""
+	}
+	raw, err := base64.StdEncoding.DecodeString(auth[len("basic "):])
+	if err != nil {
+		return ""
+	}
+	return strings.SplitN(string(raw), ":", 2)[0]
+}
+
+// record appends the given write to the recorded writes
+func (s *mockSORServer) record(r *http.Request, body interface{}) error {
+	raw, err := json.Marshal(&mockSORWrite{
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		SOR:       s.sor,
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Query:     r.URL.RawQuery,
+		Username:  basicAuthUsername(r),
+		Body:      body,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.writesPath), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.writesPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(raw, '\n')); err != nil {
+		return err
+	}
+	log.Printf("recorded write: %s", raw)
+	return nil
+}
+
+// writes returns the recorded writes, oldest first
+func (s *mockSORServer) writes() ([]*mockSORWrite, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	writes := make([]*mockSORWrite, 0)
+	f, err := os.Open(s.writesPath)
+	if os.IsNotExist(err) {
+		return writes, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		write := &mockSORWrite{}
+		if err := json.Unmarshal(scanner.Bytes(), write); err != nil {
+			return nil, fmt.Errorf("invalid recorded write; %w", err)
+		}
+		writes = append(writes, write)
+	}
+	return writes, scanner.Err()
+}
+
+// clearWrites discards the recorded writes
+func (s *mockSORServer) clearWrites() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := os.Remove(s.writesPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *mockSORServer) serveWrites(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writes, err := s.writes()
+		if err != nil {
+			s.reply(w, http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+			return
+		}
+		s.reply(w, http.StatusOK, writes)
+	case http.MethodDelete:
+		if err := s.clearWrites(); err != nil {
+			s.reply(w, http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+			return
+		}
+		s.reply(w, http.StatusNoContent, nil)
+	default:
+		s.reply(w, http.StatusMethodNotAllowed, map[string]interface{}{"error": "method not allowed"})
+	}
+}
+
+func (s *mockSORServer) servePush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.reply(w, http.StatusMethodNotAllowed, map[string]interface{}{"error": "method not allowed"})
+		return
+	}
+
+	var params struct {
+		Token    string   `json:"token"`
+		Fixtures []string `json:"fixtures"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.Token == "" {
+		s.reply(w, http.StatusBadRequest, map[string]interface{}{"error": "token required"})
+		return
+	}
+
+	results, err := s.push(params.Token, params.Fixtures)
+	if err != nil {
+		s.reply(w, http.StatusInternalServerError, map[string]interface{}{"error": err.Error()})
+		return
+	}
+	s.reply(w, http.StatusOK, results)
+}
+
+// push POSTs each business object fixture, or only the named fixtures, to the local baseline
+// API on behalf of the organization authorized by the given token
+func (s *mockSORServer) push(token string, names []string) ([]*mockSORPushResult, error) {
+	paths, err := filepath.Glob(filepath.Join(s.fixturesDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	results := make([]*mockSORPushResult, 0)
+	for _, path := range paths {
+		fixture := filepath.Base(path)
+		if len(names) > 0 && !containsString(names, fixture) && !containsString(names, strings.TrimSuffix(fixture, ".json")) {
+			continue
+		}
+
+		result := &mockSORPushResult{Fixture: fixture}
+		if err := s.pushFixture(token, path, result); err != nil {
+			result.Error = err.Error()
+		}
+
+		raw, _ := json.Marshal(result)
+		log.Printf("pushed fixture: %s", raw)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *mockSORServer) pushFixture(token, path string, result *mockSORPushResult) error {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/v1/business_objects", s.baselineAPI), bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("bearer %s", token))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	result.Status = resp.StatusCode
+	raw, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if len(raw) > 0 {
+		var response interface{}
+		if err := json.Unmarshal(raw, &response); err != nil {
+			response = string(raw)
+		}
+		result.Response = response
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package stack
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testMockSORServer returns a test server of the mock of the given system of record; its
+// fixtures are written to a temporary directory and pushed to the given baseline API
+func testMockSORServer(t *testing.T, sor, basePath, baselineAPI string, fixtures map[string]string) (*httptest.Server, *mockSORServer) {
+	dir := t.TempDir()
+	for fixture, content := range fixtures {
+		if err := ioutil.WriteFile(filepath.Join(dir, fixture), []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write fixture %s; %s", fixture, err.Error())
+		}
+	}
+
+	server, err := newMockSORServer(sor, basePath, baselineAPI, dir, filepath.Join(dir, "data", "writes.jsonl"))
+	if err != nil {
+		t.Fatalf("newMockSORServer() error = %v", err)
+	}
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+	return srv, server
+}
+
+// mockSORCall calls the given test server, returning the status and the unmarshaled response
+func mockSORCall(t *testing.T, srv *httptest.Server, method, uri, body string) (int, interface{}) {
+	req, err := http.NewRequest(method, srv.URL+uri, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to build request; %s", err.Error())
+	}
+	req.SetBasicAuth(mockSORUsername, mockSORPassword)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed; %s", method, uri, err.Error())
+	}
+	defer resp.Body.Close()
+
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response; %s", err.Error())
+	}
+	var result interface{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &result); err != nil {
+			t.Fatalf("%s %s responded with invalid JSON: %s", method, uri, raw)
+		}
+	}
+	return resp.StatusCode, result
+}
+
+// field returns the value at the given dot-separated path of the given JSON value
+func field(value interface{}, path string) interface{} {
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = obj[key]
+	}
+	return value
+}
+
+func TestMockSORRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		sor      string
+		basePath string
+		method   string
+		uri      string
+		body     string
+
+		status    int
+		fields    map[string]interface{} // expected values of the response, keyed by path
+		generated []string               // paths of the response holding generated identifiers
+		recorded  bool
+	}{
+		{name: "sap health", sor: "sap", basePath: "ubc", method: "GET", uri: "/ubc/health", status: 200, fields: map[string]interface{}{"status": "ok"}},
+		{name: "sap create business object", sor: "sap", basePath: "ubc", method: "POST", uri: "/ubc/business_objects", body: `{"type":"purchase_order"}`, status: 201, generated: []string{"id"}, recorded: true},
+		{name: "sap update business object", sor: "sap", basePath: "ubc", method: "PUT", uri: "/ubc/business_objects/po-1", body: `{"status":"approved"}`, status: 204, recorded: true},
+		{name: "sap update business object status", sor: "sap", basePath: "ubc", method: "PUT", uri: "/ubc/business_objects/po-1/status", body: `{"status":"success"}`, status: 204, recorded: true},
+		{name: "sap configure proxy", sor: "sap", basePath: "ubc", method: "PUT", uri: "/ubc/proxy", body: `{"proxy_endpoint":"http://api:8080"}`, status: 204, recorded: true},
+		{name: "sap create tenant", sor: "sap", basePath: "ubc", method: "POST", uri: "/ubc/tenants", body: `{"organization_id":"org"}`, status: 201, generated: []string{"id"}, recorded: true},
+		{name: "sap delete tenant", sor: "sap", basePath: "ubc", method: "DELETE", uri: "/ubc/tenants/tenant-1", status: 204, recorded: true},
+		{name: "sap outside base path", sor: "sap", basePath: "ubc", method: "POST", uri: "/business_objects", body: `{}`, status: 404},
+		{name: "sap base path prefix", sor: "sap", basePath: "ubc", method: "GET", uri: "/ubcx/health", status: 404},
+		{name: "sap unsupported method", sor: "sap", basePath: "ubc", method: "DELETE", uri: "/ubc/business_objects/po-1", status: 404},
+		{name: "sap unsupported path", sor: "sap", basePath: "ubc", method: "POST", uri: "/ubc/invoices", body: `{}`, status: 404},
+
+		{name: "servicenow list records", sor: "servicenow", basePath: "api/now/table", method: "GET", uri: "/api/now/table/incident?sysparm_limit=1", status: 200, fields: map[string]interface{}{"result": []interface{}{}}},
+		{name: "servicenow get record", sor: "servicenow", basePath: "api/now/table", method: "GET", uri: "/api/now/table/incident/abc", status: 404, fields: map[string]interface{}{"status": "failure", "error.message": "No Record found"}},
+		{name: "servicenow create record", sor: "servicenow", basePath: "api/now/table", method: "POST", uri: "/api/now/table/incident", body: `{"short_description":"baselined"}`, status: 201, fields: map[string]interface{}{"result.short_description": "baselined"}, generated: []string{"result.sys_id"}, recorded: true},
+		{name: "servicenow replace record", sor: "servicenow", basePath: "api/now/table", method: "PUT", uri: "/api/now/table/incident/abc", body: `{"state":"2"}`, status: 200, fields: map[string]interface{}{"result.sys_id": "abc", "result.state": "2"}, recorded: true},
+		{name: "servicenow update record", sor: "servicenow", basePath: "api/now/table", method: "PATCH", uri: "/api/now/table/incident/abc", body: `{"state":"3"}`, status: 200, fields: map[string]interface{}{"result.sys_id": "abc", "result.state": "3"}, recorded: true},
+		{name: "servicenow delete record", sor: "servicenow", basePath: "api/now/table", method: "DELETE", uri: "/api/now/table/incident/abc", status: 204, recorded: true},
+		{name: "servicenow nested path", sor: "servicenow", basePath: "api/now/table", method: "POST", uri: "/api/now/table/incident/abc/attachments", body: `{}`, status: 404},
+
+		{name: "salesforce versions", sor: "salesforce", method: "GET", uri: "/services/data", status: 200},
+		{name: "salesforce query", sor: "salesforce", method: "GET", uri: "/services/data/v52.0/query?q=SELECT+Id+FROM+Order", status: 200, fields: map[string]interface{}{"done": true, "totalSize": float64(0)}},
+		{name: "salesforce create sobject", sor: "salesforce", method: "POST", uri: "/services/data/v52.0/sobjects/Order", body: `{"Status":"Draft"}`, status: 201, fields: map[string]interface{}{"success": true}, generated: []string{"id"}, recorded: true},
+		{name: "salesforce update sobject", sor: "salesforce", method: "PATCH", uri: "/services/data/v52.0/sobjects/Order/8015", body: `{"Status":"Activated"}`, status: 204, recorded: true},
+		{name: "salesforce delete sobject", sor: "salesforce", method: "DELETE", uri: "/services/data/v52.0/sobjects/Order/8015", status: 204, recorded: true},
+		{name: "salesforce replace sobject", sor: "salesforce", method: "PUT", uri: "/services/data/v52.0/sobjects/Order/8015", body: `{}`, status: 404},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, server := testMockSORServer(t, tt.sor, tt.basePath, "", nil)
+
+			status, resp := mockSORCall(t, srv, tt.method, tt.uri, tt.body)
+			if status != tt.status {
+				t.Fatalf("%s %s responded with status %d; want %d: %v", tt.method, tt.uri, status, tt.status, resp)
+			}
+			for path, want := range tt.fields {
+				got, _ := json.Marshal(field(resp, path))
+				expected, _ := json.Marshal(want)
+				if string(got) != string(expected) {
+					t.Errorf("response %s = %s; want %s", path, got, expected)
+				}
+			}
+			for _, path := range tt.generated {
+				if id, _ := field(resp, path).(string); id == "" {
+					t.Errorf("response %s is not a generated identifier: %v", path, resp)
+				}
+			}
+
+			writes, err := server.writes()
+			if err != nil {
+				t.Fatalf("writes() error = %v", err)
+			}
+			if !tt.recorded {
+				if len(writes) != 0 {
+					t.Errorf("recorded %d writes; want none", len(writes))
+				}
+				return
+			}
+			if len(writes) != 1 {
+				t.Fatalf("recorded %d writes; want 1", len(writes))
+			}
+
+			write := writes[0]
+			path := tt.uri
+			query := ""
+			if i := strings.Index(path, "?"); i != -1 {
+				path, query = path[:i], path[i+1:]
+			}
+			if write.SOR != tt.sor || write.Method != tt.method || write.Path != path || write.Query != query || write.Username != mockSORUsername {
+				t.Errorf("recorded write %+v; want %s %s by %s", write, tt.method, tt.uri, mockSORUsername)
+			}
+			if tt.body != "" {
+				raw, _ := json.Marshal(write.Body)
+				if string(raw) != tt.body {
+					t.Errorf("recorded write body %s; want %s", raw, tt.body)
+				}
+			}
+		})
+	}
+}
+
+func TestMockSORWrites(t *testing.T) {
+	srv, _ := testMockSORServer(t, "sap", "ubc", "", nil)
+
+	status, resp := mockSORCall(t, srv, "GET", "/_mock/writes", "")
+	if status != 200 || len(resp.([]interface{})) != 0 {
+		t.Fatalf("GET /_mock/writes = %d %v; want 200 and no writes", status, resp)
+	}
+
+	mockSORCall(t, srv, "POST", "/ubc/business_objects", `{"id":"po-1"}`)
+	mockSORCall(t, srv, "PUT", "/ubc/business_objects/po-1/status", "not json")
+	mockSORCall(t, srv, "GET", "/ubc/health", "")
+
+	status, resp = mockSORCall(t, srv, "GET", "/_mock/writes", "")
+	if status != 200 {
+		t.Fatalf("GET /_mock/writes = %d %v; want 200", status, resp)
+	}
+	writes := resp.([]interface{})
+	if len(writes) != 2 {
+		t.Fatalf("GET /_mock/writes returned %d writes; want 2: %v", len(writes), writes)
+	}
+	if field(writes[0], "method") != "POST" || field(writes[0], "body.id") != "po-1" {
+		t.Errorf("first write = %v; want the POST of po-1", writes[0])
+	}
+	if field(writes[1], "path") != "/ubc/business_objects/po-1/status" || field(writes[1], "body") != "not json" {
+		t.Errorf("second write = %v; want the PUT of the raw status", writes[1])
+	}
+	if timestamp, _ := field(writes[0], "timestamp").(string); timestamp == "" {
+		t.Errorf("first write = %v; want a timestamp", writes[0])
+	}
+
+	if status, _ := mockSORCall(t, srv, "DELETE", "/_mock/writes", ""); status != 204 {
+		t.Fatalf("DELETE /_mock/writes = %d; want 204", status)
+	}
+	if status, resp := mockSORCall(t, srv, "GET", "/_mock/writes", ""); status != 200 || len(resp.([]interface{})) != 0 {
+		t.Errorf("GET /_mock/writes = %d %v after clearing; want 200 and no writes", status, resp)
+	}
+	if status, _ := mockSORCall(t, srv, "DELETE", "/_mock/writes", ""); status != 204 {
+		t.Errorf("DELETE /_mock/writes = %d without writes; want 204", status)
+	}
+	if status, _ := mockSORCall(t, srv, "POST", "/_mock/writes", "{}"); status != 405 {
+		t.Errorf("POST /_mock/writes = %d; want 405", status)
+	}
+}
+
+func TestMockSORStatus(t *testing.T) {
+	srv, _ := testMockSORServer(t, "servicenow", "api/now/table", "", nil)
+
+	status, resp := mockSORCall(t, srv, "GET", "/_mock/status", "")
+	if status != 200 || field(resp, "status") != "ok" || field(resp, "sor") != "servicenow" {
+		t.Errorf("GET /_mock/status = %d %v; want 200 ok", status, resp)
+	}
+}
+
+func TestMockSORPush(t *testing.T) {
+	pushed := make([]string, 0)
+	baseline := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v1/business_objects" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "bearer org-token" {
+			t.Errorf("fixture pushed using %q; want the given token", auth)
+		}
+		raw, _ := ioutil.ReadAll(r.Body)
+		pushed = append(pushed, string(raw))
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(string(raw), "invalid") {
+			w.WriteHeader(422)
+			w.Write([]byte(`{"errors":[{"message":"invalid business object"}]}`))
+			return
+		}
+		w.WriteHeader(202)
+		w.Write([]byte(`{"baseline_id":"b1"}`))
+	}))
+	defer baseline.Close()
+
+	fixtures := map[string]string{
+		"a-order.json":   `{"type":"order"}`,
+		"b-invalid.json": `{"type":"invalid"}`,
+		"c-invoice.json": `{"type":"invoice"}`,
+		"notes.txt":      `not a fixture`,
+	}
+
+	tests := []struct {
+		name     string
+		params   string
+		status   int
+		fixtures []string // pushed fixtures, in order
+		statuses []float64
+	}{
+		{name: "all fixtures", params: `{"token":"org-token"}`, status: 200, fixtures: []string{"a-order.json", "b-invalid.json", "c-invoice.json"}, statuses: []float64{202, 422, 202}},
+		{name: "named fixtures", params: `{"token":"org-token","fixtures":["c-invoice","a-order.json"]}`, status: 200, fixtures: []string{"a-order.json", "c-invoice.json"}, statuses: []float64{202, 202}},
+		{name: "unknown fixture", params: `{"token":"org-token","fixtures":["missing"]}`, status: 200, fixtures: []string{}},
+		{name: "missing token", params: `{"fixtures":["a-order"]}`, status: 400},
+		{name: "invalid params", params: `not json`, status: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pushed = pushed[:0]
+			srv, _ := testMockSORServer(t, "sap", "ubc", baseline.URL+"/", fixtures)
+
+			status, resp := mockSORCall(t, srv, "POST", "/_mock/push", tt.params)
+			if status != tt.status {
+				t.Fatalf("POST /_mock/push = %d %v; want %d", status, resp, tt.status)
+			}
+			if tt.status != 200 {
+				if len(pushed) != 0 {
+					t.Errorf("pushed %d fixtures; want none", len(pushed))
+				}
+				return
+			}
+
+			results := resp.([]interface{})
+			if len(results) != len(tt.fixtures) || len(pushed) != len(tt.fixtures) {
+				t.Fatalf("pushed %d fixtures with %d results; want %d", len(pushed), len(results), len(tt.fixtures))
+			}
+			for i, result := range results {
+				if field(result, "fixture") != tt.fixtures[i] || field(result, "status") != tt.statuses[i] {
+					t.Errorf("result %d = %v; want %s with status %v", i, result, tt.fixtures[i], tt.statuses[i])
+				}
+				if pushed[i] != fixtures[tt.fixtures[i]] {
+					t.Errorf("pushed %s; want the content of %s", pushed[i], tt.fixtures[i])
+				}
+			}
+			if len(results) > 1 && field(results[1], "response.errors") == nil && tt.statuses[1] == 422 {
+				t.Errorf("result of rejected fixture = %v; want the response of the baseline API", results[1])
+			}
+		})
+	}
+
+	t.Run("unreachable baseline API", func(t *testing.T) {
+		srv, _ := testMockSORServer(t, "sap", "ubc", "http://127.0.0.1:1", map[string]string{"order.json": `{}`})
+		status, resp := mockSORCall(t, srv, "POST", "/_mock/push", `{"token":"org-token"}`)
+		if status != 200 {
+			t.Fatalf("POST /_mock/push = %d %v; want 200", status, resp)
+		}
+		results := resp.([]interface{})
+		if len(results) != 1 || field(results[0], "status") != float64(0) || field(results[0], "error") == nil {
+			t.Errorf("results = %v; want an error for order.json", results)
+		}
+	})
+}
+
+func TestNewMockSORServer(t *testing.T) {
+	if _, err := newMockSORServer("dynamics365", "", "", "", ""); err == nil {
+		t.Errorf("newMockSORServer() of an unsupported system of record succeeded")
+	}
+	for _, sor := range mockSORIdentifiers {
+		if _, ok := mockSORRoutes[sor]; !ok {
+			t.Errorf("no routes for mock system of record %s", sor)
+		}
+	}
+}
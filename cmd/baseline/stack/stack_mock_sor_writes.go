@@ -0,0 +1,70 @@
+package stack
+
+import (
+	"encoding/json"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var mockSORWritesClear bool
+
+var mockSORWritesBaselineStackCmd = &cobra.Command{
+	Use:   "writes",
+	Short: "List the writes recorded by the mock system of record",
+	Long: `List each inbound write, i.e., each POST, PUT, PATCH or DELETE request, received by the mock
+system of record from the baseline services, oldest first.
+
+The writes are recorded in a volume of the stack, so they survive restarts of the stack; use --clear
+to discard them once printed.`,
+	RunE: mockSORWritesProxy,
+}
+
+// mockSORWrite is an inbound write recorded by the mock system of record
+type mockSORWrite struct {
+	Timestamp string      `json:"timestamp"`
+	SOR       string      `json:"sor"`
+	Method    string      `json:"method"`
+	Path      string      `json:"path"`
+	Query     string      `json:"query,omitempty"`
+	Username  string      `json:"username,omitempty"`
+	Body      interface{} `json:"body,omitempty"`
+}
+
+func mockSORWritesProxy(cmd *cobra.Command, args []string) error {
+	return mockSORPrompt(cmd, args, promptStepMockSORWrites)
+}
+
+func mockSORWritesRun(cmd *cobra.Command, args []string) error {
+	writes := make([]*mockSORWrite, 0)
+	if err := mockSORRequest("GET", "/_mock/writes", nil, &writes); err != nil {
+		return err
+	}
+
+	table := common.NewTable("TIMESTAMP", "METHOD", "PATH", "BODY")
+	for _, write := range writes {
+		path := write.Path
+		if write.Query != "" {
+			path = path + "?" + write.Query
+		}
+		body := ""
+		if write.Body != nil {
+			raw, _ := json.Marshal(write.Body)
+			body = string(raw)
+		}
+		table.AddRow(write.Timestamp, write.Method, path, body)
+	}
+	if err := common.Render(writes, table); err != nil {
+		return err
+	}
+
+	if mockSORWritesClear {
+		return mockSORRequest("DELETE", "/_mock/writes", nil, nil)
+	}
+	return nil
+}
+
+func init() {
+	mockSORWritesBaselineStackCmd.Flags().StringVar(&name, "name", "baseline-local", "name of the baseline stack instance")
+	mockSORWritesBaselineStackCmd.Flags().BoolVar(&mockSORWritesClear, "clear", false, "when true, the recorded writes are discarded once printed")
+}
@@ -0,0 +1,304 @@
+package stack
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+)
+
+// paths of the generated NATS configuration and TLS material within the containers
+const natsConfigPath = "/etc/nats/nats-server.conf"
+const natsStreamingConfigPath = "/etc/nats/nats-streaming-server.conf"
+const natsTLSCertificatePath = "/etc/nats/tls/server.pem"
+const natsTLSKeyPath = "/etc/nats/tls/server-key.pem"
+const natsCACertificatePath = "/etc/nats/tls/ca.pem"
+
+const natsJetStreamDataPath = "/data/jetstream"
+const natsMaxPayload = "100Mb"
+
+// natsAuthTokenLength is the number of random bytes in a generated NATS auth token
+const natsAuthTokenLength = 32
+
+const natsTLSCertificateValidity = time.Hour * 24 * 365
+
+var natsTLS bool
+var natsTLSCertificateFile string
+var natsTLSKeyFile string
+var natsTLSCAFile string
+
+// PEM-encoded TLS material of the NATS services; resolved by requireNATSCredentials
+var natsTLSCertificate string
+var natsTLSKey string
+var natsCACertificate string
+
+// natsCA signs the generated NATS server certificates; it is generated once per process,
+// so the parties of a simulated workgroup trust the messaging endpoints of one another
+var natsCA *tls.Certificate
+
+// validateNATSFlags returns an error if the NATS TLS flags are inconsistent
+func validateNATSFlags() error {
+	if !natsTLS {
+		return nil
+	}
+	if (natsTLSCertificateFile == "") != (natsTLSKeyFile == "") {
+		return common.NewValidationError("--nats-tls-cert and --nats-tls-key must be set together")
+	}
+	if natsTLSCAFile != "" && natsTLSCertificateFile == "" {
+		return common.NewValidationError("--nats-tls-ca requires --nats-tls-cert and --nats-tls-key")
+	}
+	return nil
+}
+
+// requireNATSCredentials generates an auth token for the NATS services unless one was given,
+// and resolves their TLS material; a server certificate is generated for the hostnames of the
+// stack unless one was given, so it must be called once the hostnames are resolved by applyFlags
+func requireNATSCredentials() error {
+	if natsAuthToken == "" {
+		token := make([]byte, natsAuthTokenLength)
+		if _, err := rand.Read(token); err != nil {
+			return fmt.Errorf("failed to generate NATS auth token; %w", err)
+		}
+		natsAuthToken = hex.EncodeToString(token)
+	}
+
+	natsTLSCertificate = ""
+	natsTLSKey = ""
+	natsCACertificate = ""
+
+	if !natsTLS {
+		return nil
+	}
+	if natsTLSCertificateFile != "" {
+		return loadNATSTLSCertificate()
+	}
+	return generateNATSTLSCertificate()
+}
+
+// loadNATSTLSCertificate reads the given NATS server certificate and key; the certificate
+// is trusted as its own CA unless a CA certificate is given
+func loadNATSTLSCertificate() error {
+	files := map[string]*string{
+		natsTLSCertificateFile: &natsTLSCertificate,
+		natsTLSKeyFile:         &natsTLSKey,
+	}
+	if natsTLSCAFile != "" {
+		files[natsTLSCAFile] = &natsCACertificate
+	}
+
+	for path, dst := range files {
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return common.NewNotFoundError("NATS TLS file not found: %s", path)
+			}
+			return fmt.Errorf("failed to read %s; %w", path, err)
+		}
+		*dst = string(raw)
+	}
+
+	if _, err := tls.X509KeyPair([]byte(natsTLSCertificate), []byte(natsTLSKey)); err != nil {
+		return common.NewValidationError("invalid NATS TLS certificate %s and key %s; %s", natsTLSCertificateFile, natsTLSKeyFile, err.Error())
+	}
+
+	if natsCACertificate == "" {
+		natsCACertificate = natsTLSCertificate
+	} else if block, _ := pem.Decode([]byte(natsCACertificate)); block == nil || block.Type != "CERTIFICATE" {
+		return common.NewValidationError("invalid NATS TLS CA certificate %s", natsTLSCAFile)
+	}
+
+	return nil
+}
+
+// generateNATSTLSCertificate issues a server certificate for the NATS hostnames of the stack
+func generateNATSTLSCertificate() error {
+	if natsCA == nil {
+		ca, err := generateCertificate(&x509.Certificate{
+			Subject:               pkix.Name{CommonName: "prvd local baseline NATS CA"},
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to generate NATS CA certificate; %w", err)
+		}
+		natsCA = ca
+	}
+
+	server, err := generateCertificate(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: natsHostname},
+		DNSNames:    []string{natsHostname, natsStreamingHostname, containerName(serviceNATS), containerName(serviceNATSStreaming), "localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		// the NATS streaming server connects to its embedded NATS server as a TLS client
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}, natsCA)
+	if err != nil {
+		return fmt.Errorf("failed to generate NATS server certificate; %w", err)
+	}
+
+	key, err := x509.MarshalECPrivateKey(server.PrivateKey.(*ecdsa.PrivateKey))
+	if err != nil {
+		return fmt.Errorf("failed to marshal NATS server key; %w", err)
+	}
+
+	natsTLSCertificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate[0]}))
+	natsTLSKey = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key}))
+	natsCACertificate = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: natsCA.Certificate[0]}))
+	return nil
+}
+
+// generateCertificate issues a certificate from the given template, signed by parent, or
+// self-signed when parent is nil
+func generateCertificate(template *x509.Certificate, parent *tls.Certificate) (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Minute)
+	template.NotAfter = time.Now().Add(natsTLSCertificateValidity)
+
+	issuer := template
+	var signer interface{} = key
+	if parent != nil {
+		issuer = parent.Leaf
+		signer = parent.PrivateKey
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf, err := x509.ParseCertificate(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{raw},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// natsTLSFiles returns the TLS material mounted into the NATS services, keyed by container path
+func natsTLSFiles() map[string]string {
+	if !natsTLS {
+		return map[string]string{}
+	}
+	return map[string]string{
+		natsTLSCertificatePath: natsTLSCertificate,
+		natsTLSKeyPath:         natsTLSKey,
+		natsCACertificatePath:  natsCACertificate,
+	}
+}
+
+// natsTLSBlock renders a NATS tls configuration block using the mounted TLS material
+func natsTLSBlock(indent string) string {
+	return strings.Join([]string{
+		"tls {",
+		fmt.Sprintf("  cert_file: %s", strconv.Quote(natsTLSCertificatePath)),
+		fmt.Sprintf("  key_file: %s", strconv.Quote(natsTLSKeyPath)),
+		fmt.Sprintf("  ca_file: %s", strconv.Quote(natsCACertificatePath)),
+		"  timeout: 5",
+		"}",
+	}, "\n"+indent) + "\n"
+}
+
+// natsAuthorizationBlock renders a NATS authorization block which accepts the auth token of the
+// stack; when jwt is set, bearer JWTs vended by 'prvd baseline workgroups participants invite'
+// are also accepted once verified using the JWT signer public key of the organization, and the
+// NATS permissions they carry are enforced
+func natsAuthorizationBlock(jwt bool) string {
+	var conf strings.Builder
+	conf.WriteString("authorization {\n")
+	fmt.Fprintf(&conf, "  token: %s\n", strconv.Quote(natsAuthToken))
+	if jwt && jwtSignerPublicKey != "" {
+		conf.WriteString("  # bearer JWTs vended by 'prvd baseline workgroups participants invite' are verified using this key\n")
+		fmt.Fprintf(&conf, "  jwt_signer_public_key: %s\n", strconv.Quote(jwtSignerPublicKey))
+	}
+	conf.WriteString("}\n")
+	return conf.String()
+}
+
+// natsConfig renders the configuration of the NATS service of the stack; connections authorize
+// using the auth token of the stack, or using a bearer JWT issued by the organization
+func natsConfig() string {
+	var conf strings.Builder
+	fmt.Fprintf(&conf, "# NATS configuration of the %s local baseline instance; generated by prvd\n\n", name)
+	fmt.Fprintf(&conf, "server_name: %s\n", strconv.Quote(natsServerName))
+	fmt.Fprintf(&conf, "port: %d\n", natsContainerPort)
+	fmt.Fprintf(&conf, "max_payload: %s\n\n", natsMaxPayload)
+
+	conf.WriteString(natsAuthorizationBlock(true))
+	conf.WriteString("\n")
+
+	if natsTLS {
+		conf.WriteString(natsTLSBlock(""))
+		conf.WriteString("\n")
+	}
+
+	conf.WriteString("websocket {\n")
+	fmt.Fprintf(&conf, "  port: %d\n", natsWebsocketContainerPort)
+	if natsTLS {
+		conf.WriteString("  " + natsTLSBlock("  "))
+	} else {
+		conf.WriteString("  no_tls: true\n")
+	}
+	conf.WriteString("}\n\n")
+
+	conf.WriteString("jetstream {\n")
+	fmt.Fprintf(&conf, "  store_dir: %s\n", strconv.Quote(natsJetStreamDataPath))
+	conf.WriteString("}\n")
+
+	return conf.String()
+}
+
+// natsStreamingConfig renders the configuration of the NATS streaming service of the stack;
+// the cluster id and store are passed as arguments
+func natsStreamingConfig() string {
+	var conf strings.Builder
+	fmt.Fprintf(&conf, "# NATS streaming configuration of the %s local baseline instance; generated by prvd\n\n", name)
+	fmt.Fprintf(&conf, "max_payload: %s\n\n", natsMaxPayload)
+
+	// the streaming server connects to its embedded NATS server using the same token
+	conf.WriteString(natsAuthorizationBlock(false))
+
+	if natsTLS {
+		conf.WriteString("\n")
+		conf.WriteString(natsTLSBlock(""))
+
+		// the streaming server connects to its embedded NATS server as a client
+		conf.WriteString("\nstreaming {\n")
+		conf.WriteString("  tls {\n")
+		fmt.Fprintf(&conf, "    client_cert: %s\n", strconv.Quote(natsTLSCertificatePath))
+		fmt.Fprintf(&conf, "    client_key: %s\n", strconv.Quote(natsTLSKeyPath))
+		fmt.Fprintf(&conf, "    client_ca: %s\n", strconv.Quote(natsCACertificatePath))
+		conf.WriteString("  }\n")
+		conf.WriteString("}\n")
+	}
+
+	return conf.String()
+}
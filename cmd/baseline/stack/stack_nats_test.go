@@ -0,0 +1,67 @@
+package stack
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNATSAuthorization(t *testing.T) {
+	natsAuthToken, jwtSignerPublicKey = "testtoken", "-----BEGIN PUBLIC KEY-----\nMIIB\n-----END PUBLIC KEY-----\n"
+	defer func() {
+		natsAuthToken, jwtSignerPublicKey = "", ""
+	}()
+
+	tests := []struct {
+		name    string
+		config  string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "nats",
+			config: natsConfig(),
+			want: []string{
+				`  token: "testtoken"`,
+				`  jwt_signer_public_key: "-----BEGIN PUBLIC KEY-----\nMIIB\n-----END PUBLIC KEY-----\n"`,
+			},
+		},
+		{
+			name:    "nats streaming",
+			config:  natsStreamingConfig(),
+			want:    []string{`  token: "testtoken"`},
+			notWant: []string{"jwt_signer_public_key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, want := range tt.want {
+				if !strings.Contains(tt.config, "authorization {\n") || !strings.Contains(tt.config, want+"\n") {
+					t.Errorf("config does not authorize using %s:\n%s", want, tt.config)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(tt.config, notWant) {
+					t.Errorf("config contains %s:\n%s", notWant, tt.config)
+				}
+			}
+		})
+	}
+
+	for _, arg := range natsStreamingService().cmd {
+		if strings.Contains(arg, natsAuthToken) {
+			t.Errorf("nats streaming arguments contain the auth token: %q", natsStreamingService().cmd)
+		}
+	}
+}
+
+func TestNATSConfigWithoutSigner(t *testing.T) {
+	natsAuthToken = "testtoken"
+	defer func() {
+		natsAuthToken = ""
+	}()
+
+	if config := natsConfig(); strings.Contains(config, "jwt_signer_public_key") {
+		t.Errorf("config contains an empty JWT signer public key:\n%s", config)
+	}
+}
@@ -0,0 +1,126 @@
+package stack
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/pflag"
+)
+
+// maxPortAllocationAttempts bounds the search for a free host port when --auto-ports is set
+const maxPortAllocationAttempts = 32
+
+var autoPorts bool
+
+// portConflict is a planned host port of the stack which cannot be bound
+type portConflict struct {
+	service string
+	mapping *portMapping
+	reason  string
+}
+
+func (c *portConflict) String() string {
+	return fmt.Sprintf("%s: host port %d (--%s) %s", c.service, c.mapping.hostPort, c.mapping.flag, c.reason)
+}
+
+// preflightPorts probes each planned host port of the given services before any container is
+// created; conflicting ports are reported by service unless autoAllocate is set, in which case
+// a free port is allocated for each conflict by setting the corresponding run flag
+func preflightPorts(flags *pflag.FlagSet, services []*serviceSpec, autoAllocate bool) error {
+	conflicts, planned := portConflicts(services)
+
+	if len(conflicts) > 0 && !autoAllocate {
+		reported := make([]string, 0)
+		for _, conflict := range conflicts {
+			reported = append(reported, conflict.String())
+		}
+		return common.NewValidationError("host port conflicts for %s local baseline instance; %s; free the ports, set the listed flags or pass --auto-ports", name, strings.Join(reported, "; "))
+	}
+
+	for _, conflict := range conflicts {
+		allocated, err := allocatePort(planned)
+		if err != nil {
+			return fmt.Errorf("failed to allocate host port for %s; %w", conflict.service, err)
+		}
+		if err := flags.Set(conflict.mapping.flag, strconv.Itoa(allocated)); err != nil {
+			return fmt.Errorf("failed to allocate host port for %s; %w", conflict.service, err)
+		}
+		log.Printf("allocated host port %d for %s; host port %d %s", allocated, conflict.service, conflict.mapping.hostPort, conflict.reason)
+		conflict.mapping.hostPort = allocated
+		planned[allocated] = conflict.service
+	}
+
+	if autoAllocate {
+		for _, spec := range services {
+			for _, mapping := range spec.ports {
+				log.Printf("%s: 0.0.0.0:%d->%d/tcp (--%s)", spec.service, mapping.hostPort, mapping.containerPort, mapping.flag)
+			}
+		}
+	}
+
+	return nil
+}
+
+// portConflicts returns each planned host port of the given services which is in use or
+// planned for more than one service, and the planned host ports keyed by port
+func portConflicts(services []*serviceSpec) ([]*portConflict, map[int]string) {
+	conflicts := make([]*portConflict, 0)
+	planned := map[int]string{}
+
+	for _, spec := range services {
+		for i := range spec.ports {
+			mapping := &spec.ports[i]
+			if service, ok := planned[mapping.hostPort]; ok {
+				conflicts = append(conflicts, &portConflict{
+					service: spec.service,
+					mapping: mapping,
+					reason:  fmt.Sprintf("is also planned for %s", service),
+				})
+				continue
+			}
+			planned[mapping.hostPort] = spec.service
+
+			if !isPortAvailable(mapping.hostPort) {
+				conflicts = append(conflicts, &portConflict{
+					service: spec.service,
+					mapping: mapping,
+					reason:  "is in use",
+				})
+			}
+		}
+	}
+
+	return conflicts, planned
+}
+
+// isPortAvailable returns true if the given host port can be bound on all interfaces
+func isPortAvailable(port int) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
+
+// allocatePort returns a free host port, chosen by the operating system, which is not
+// already planned for another service
+func allocatePort(planned map[int]string) (int, error) {
+	for i := 0; i < maxPortAllocationAttempts; i++ {
+		listener, err := net.Listen("tcp", ":0")
+		if err != nil {
+			return 0, err
+		}
+		port := listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+
+		if _, ok := planned[port]; !ok {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no free port found after %d attempts", maxPortAllocationAttempts)
+}
@@ -0,0 +1,208 @@
+package stack
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/pflag"
+)
+
+// busyPort returns a host port which is bound until the test completes
+func busyPort(t *testing.T) int {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("failed to bind port; %s", err.Error())
+	}
+	t.Cleanup(func() {
+		listener.Close()
+	})
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// freePort returns a host port which was free when probed
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("failed to bind port; %s", err.Error())
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// testService returns a service spec which maps each of the given host ports
+func testService(service string, hostPorts ...int) *serviceSpec {
+	spec := &serviceSpec{service: service}
+	for i, port := range hostPorts {
+		spec.ports = append(spec.ports, portMapping{
+			hostPort:      port,
+			containerPort: port,
+			flag:          service + "-port-" + strconv.Itoa(i),
+		})
+	}
+	return spec
+}
+
+// testFlags returns a flag set defining the port flags of the given services
+func testFlags(services []*serviceSpec) *pflag.FlagSet {
+	flags := pflag.NewFlagSet("run", pflag.ContinueOnError)
+	for _, spec := range services {
+		for _, mapping := range spec.ports {
+			flags.Int(mapping.flag, mapping.hostPort, "")
+		}
+	}
+	return flags
+}
+
+func TestPortConflicts(t *testing.T) {
+	free := freePort(t)
+	busy := busyPort(t)
+
+	tests := []struct {
+		name     string
+		services []*serviceSpec
+		want     []string // conflicts, formatted
+		planned  int
+	}{
+		{
+			name:     "no conflicts",
+			services: []*serviceSpec{testService("api", free)},
+			want:     []string{},
+			planned:  1,
+		},
+		{
+			name:     "in use",
+			services: []*serviceSpec{testService("api", busy)},
+			want:     []string{"api: host port " + strconv.Itoa(busy) + " (--api-port-0) is in use"},
+			planned:  1,
+		},
+		{
+			name:     "planned for more than one service",
+			services: []*serviceSpec{testService("api", free), testService("nats", free)},
+			want:     []string{"nats: host port " + strconv.Itoa(free) + " (--nats-port-0) is also planned for api"},
+			planned:  1,
+		},
+		{
+			name:     "in use and planned for more than one service",
+			services: []*serviceSpec{testService("api", busy), testService("nats", free, busy)},
+			want: []string{
+				"api: host port " + strconv.Itoa(busy) + " (--api-port-0) is in use",
+				"nats: host port " + strconv.Itoa(busy) + " (--nats-port-1) is also planned for api",
+			},
+			planned: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts, planned := portConflicts(tt.services)
+			got := make([]string, 0)
+			for _, conflict := range conflicts {
+				got = append(got, conflict.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("portConflicts() = %q; want %q", got, tt.want)
+			}
+			if len(planned) != tt.planned {
+				t.Errorf("portConflicts() planned %d ports; want %d", len(planned), tt.planned)
+			}
+		})
+	}
+}
+
+func TestPreflightPorts(t *testing.T) {
+	tests := []struct {
+		name         string
+		services     func(t *testing.T) []*serviceSpec
+		autoAllocate bool
+		wantErr      string
+		allocated    []string // flags expected to be set to an allocated port
+	}{
+		{
+			name: "no conflicts",
+			services: func(t *testing.T) []*serviceSpec {
+				return []*serviceSpec{testService("api", freePort(t)), testService("nats", freePort(t))}
+			},
+		},
+		{
+			name: "conflicts reported",
+			services: func(t *testing.T) []*serviceSpec {
+				return []*serviceSpec{testService("api", busyPort(t)), testService("nats", freePort(t))}
+			},
+			wantErr: "(--api-port-0) is in use; free the ports, set the listed flags or pass --auto-ports",
+		},
+		{
+			name: "conflicts allocated",
+			services: func(t *testing.T) []*serviceSpec {
+				port := freePort(t)
+				return []*serviceSpec{testService("api", busyPort(t), port), testService("nats", port)}
+			},
+			autoAllocate: true,
+			allocated:    []string{"api-port-0", "nats-port-0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			services := tt.services(t)
+			flags := testFlags(services)
+			original := map[string]int{}
+			for _, spec := range services {
+				for _, mapping := range spec.ports {
+					original[mapping.flag] = mapping.hostPort
+				}
+			}
+
+			err := preflightPorts(flags, services, tt.autoAllocate)
+			if tt.wantErr != "" {
+				var validationErr *common.ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("preflightPorts() error = %v; want validation error", err)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("preflightPorts() error = %q; want %q", err.Error(), tt.wantErr)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("preflightPorts() error = %v", err)
+			}
+
+			ports := map[int]string{}
+			for _, spec := range services {
+				for _, mapping := range spec.ports {
+					if flag, ok := ports[mapping.hostPort]; ok {
+						t.Errorf("host port %d planned for --%s and --%s", mapping.hostPort, flag, mapping.flag)
+					}
+					ports[mapping.hostPort] = mapping.flag
+
+					value, _ := flags.GetInt(mapping.flag)
+					if value != mapping.hostPort {
+						t.Errorf("--%s = %d; want %d", mapping.flag, value, mapping.hostPort)
+					}
+
+					if containsString(tt.allocated, mapping.flag) == (mapping.hostPort == original[mapping.flag]) {
+						t.Errorf("--%s = %d; allocated: %v", mapping.flag, mapping.hostPort, containsString(tt.allocated, mapping.flag))
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAllocatePort(t *testing.T) {
+	planned := map[int]string{busyPort(t): "api", freePort(t): "nats"}
+
+	port, err := allocatePort(planned)
+	if err != nil {
+		t.Fatalf("allocatePort() error = %v", err)
+	}
+	if service, ok := planned[port]; ok {
+		t.Errorf("allocatePort() = %d; already planned for %s", port, service)
+	}
+	if !isPortAvailable(port) {
+		t.Errorf("allocatePort() = %d; port is not available", port)
+	}
+}
@@ -13,10 +13,29 @@ import (
 const promptStepRun = "Run"
 const promptStepStop = "Stop"
 const promptStepLogs = "Logs"
+const promptStepStatus = "Status"
+const promptStepExport = "Export"
+const promptStepBackup = "Backup"
+const promptStepRestore = "Restore"
+const promptStepSimulate = "Simulate"
+const promptStepDoctor = "Doctor"
+const promptStepConfigInit = "Init"
+const promptStepConfigValidate = "Validate"
+const promptStepImagesLock = "Lock"
+const promptStepImagesSave = "Save"
+const promptStepImagesLoad = "Load"
+const promptStepMockSORPush = "Push"
+const promptStepMockSORWrites = "Writes"
+const promptStepSORList = "List"
 
-var emptyPromptArgs = []string{promptStepRun, promptStepStop, promptStepLogs}
+var emptyPromptArgs = []string{promptStepRun, promptStepStop, promptStepLogs, promptStepStatus, promptStepExport, promptStepBackup, promptStepRestore, promptStepSimulate, promptStepDoctor}
 var emptyPromptLabel = "What would you like to do"
 
+var configPromptArgs = []string{promptStepConfigInit, promptStepConfigValidate}
+var imagesPromptArgs = []string{promptStepImagesLock, promptStepImagesSave, promptStepImagesLoad}
+var mockSORPromptArgs = []string{promptStepMockSORPush, promptStepMockSORWrites}
+var sorCmdPromptArgs = []string{promptStepSORList}
+
 var boolPromptArgs = []string{"No", "Yes"}
 var tunnelPromptLabel = "Would you like to set up a tunnel"
 var tunnelAPIPromptLabel = "Would you like to set up a API tunnel"
@@ -27,162 +46,430 @@ var localIdentPromptLabel = "Would you like to set up ident locally"
 var localNchainPromptLabel = "Would you like to set up nachain locally"
 var localPrivacyPromptLabel = "Would you like to set up privacy locally"
 
-var SoRPromptArgs = []string{"SAP", "ServiceNow", "Sales Force"}
-var SoRPromptLabel = "Select a Sor"
-
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, currentStep string) {
+func generalPrompt(cmd *cobra.Command, args []string, currentStep string) error {
+	var err error
+
 	switch step := currentStep; step {
 	case promptStepRun:
-		common.RequireOrganization()
+		if err := common.RequireOrganization(); err != nil {
+			return err
+		}
+		if baselineWorkgroupID == "" {
+			if err := common.RequireWorkgroup(); err != nil {
+				return err
+			}
+		}
 		if Optional {
 			if name == "" {
-				name = common.FreeInput("Name", "", common.NoValidation)
+				if name, err = common.FreeInput("Name", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if common.APIEndpoint == "" {
-				common.APIEndpoint = common.FreeInput("API endpoint", "", common.NoValidation)
+				if common.APIEndpoint, err = common.FreeInput("API endpoint", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if common.MessagingEndpoint == "" {
-				common.MessagingEndpoint = common.FreeInput("Messaging endpoint", "", common.NoValidation)
+				if common.MessagingEndpoint, err = common.FreeInput("Messaging endpoint", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if !common.Tunnel {
-				common.Tunnel = common.SelectInput(boolPromptArgs, tunnelPromptLabel) == "Yes"
+				result, err := common.SelectInput(boolPromptArgs, tunnelPromptLabel)
+				if err != nil {
+					return err
+				}
+				common.Tunnel = result == "Yes"
 			}
 			if !common.ExposeAPITunnel {
-				common.ExposeAPITunnel = common.SelectInput(boolPromptArgs, tunnelAPIPromptLabel) == "Yes"
+				result, err := common.SelectInput(boolPromptArgs, tunnelAPIPromptLabel)
+				if err != nil {
+					return err
+				}
+				common.ExposeAPITunnel = result == "Yes"
 			}
 			if !common.ExposeMessagingTunnel {
-				common.ExposeMessagingTunnel = common.SelectInput(boolPromptArgs, tunnelMessagingPromptLabel) == "Yes"
+				result, err := common.SelectInput(boolPromptArgs, tunnelMessagingPromptLabel)
+				if err != nil {
+					return err
+				}
+				common.ExposeMessagingTunnel = result == "Yes"
 			}
 			// TODO ... call app flags
-			if sorID == "" {
-				sorID = common.SelectInput(SoRPromptArgs, SoRPromptLabel)
-			}
-			if sorURL == "" {
-				sorURL = common.FreeInput("SoR URL", "", common.NoValidation)
-			}
 			if apiHostname == "" {
-				apiHostname = common.FreeInput("API Hostname", "", common.NoValidation)
+				if apiHostname, err = common.FreeInput("API Hostname", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if port == 8080 {
-				port, _ = strconv.Atoi(common.FreeInput("Port", "8080", common.NumberValidation))
+				result, err := common.FreeInput("Port", "8080", common.NumberValidation)
+				if err != nil {
+					return err
+				}
+				port, _ = strconv.Atoi(result)
 			}
 			if consumerHostname == name+"-consumer" {
-				consumerHostname = common.FreeInput("Consumer Hostname", name+"-consumer", common.NoValidation)
+				if consumerHostname, err = common.FreeInput("Consumer Hostname", name+"-consumer", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if natsHostname == name+"-nats" {
-				natsHostname = common.FreeInput("Nats Hostname", name+"-nats", common.NoValidation)
+				if natsHostname, err = common.FreeInput("Nats Hostname", name+"-nats", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if natsPort == 4222 {
-				natsPort, _ = strconv.Atoi(common.FreeInput("Nats Port", "4222", common.NumberValidation))
+				result, err := common.FreeInput("Nats Port", "4222", common.NumberValidation)
+				if err != nil {
+					return err
+				}
+				natsPort, _ = strconv.Atoi(result)
 			}
 			if natsWebsocketPort == 4221 {
-				natsWebsocketPort, _ = strconv.Atoi(common.FreeInput("Nats Websocket Port", "4221", common.NumberValidation))
+				result, err := common.FreeInput("Nats Websocket Port", "4221", common.NumberValidation)
+				if err != nil {
+					return err
+				}
+				natsWebsocketPort, _ = strconv.Atoi(result)
 			}
-			if natsAuthToken == "testtoken" {
-				natsAuthToken = common.FreeInput("Nats Auth Token", "testtoken", common.NoValidation)
+			if natsAuthToken == "" {
+				if natsAuthToken, err = common.FreeInput("Nats Auth Token (random if empty)", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if natsStreamingHostname == name+"-nats-streaming" {
-				natsStreamingHostname = common.FreeInput("Nats Streaming Token", name+"-nats-streaming", common.NoValidation)
+				if natsStreamingHostname, err = common.FreeInput("Nats Streaming Token", name+"-nats-streaming", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if natsStreamingPort == 4220 {
-				natsStreamingPort, _ = strconv.Atoi(common.FreeInput("Nats Streaming Port", "4221", common.NumberValidation))
+				result, err := common.FreeInput("Nats Streaming Port", "4221", common.NumberValidation)
+				if err != nil {
+					return err
+				}
+				natsStreamingPort, _ = strconv.Atoi(result)
 			}
 			if redisHostname == name+"-reddis" {
-				redisHostname = common.FreeInput("Reddis Host Name", name+"-reddis", common.NoValidation)
+				if redisHostname, err = common.FreeInput("Reddis Host Name", name+"-reddis", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if redisPort == 6379 {
-				redisPort, _ = strconv.Atoi(common.FreeInput("Reddis Port", "6379", common.NumberValidation))
+				result, err := common.FreeInput("Reddis Port", "6379", common.NumberValidation)
+				if err != nil {
+					return err
+				}
+				redisPort, _ = strconv.Atoi(result)
 			}
 			if redisHosts == redisHostname+":"+strconv.Itoa(redisContainerPort) {
-				redisPort, _ = strconv.Atoi(common.FreeInput("Reddis Port", redisHostname+":"+strconv.Itoa(redisContainerPort), common.NoValidation))
+				result, err := common.FreeInput("Reddis Port", redisHostname+":"+strconv.Itoa(redisContainerPort), common.NoValidation)
+				if err != nil {
+					return err
+				}
+				redisPort, _ = strconv.Atoi(result)
 			}
 			if !autoRemove {
-				autoRemove = common.SelectInput(boolPromptArgs, autoRemovePromptLabel) == "Yes"
+				result, err := common.SelectInput(boolPromptArgs, autoRemovePromptLabel)
+				if err != nil {
+					return err
+				}
+				autoRemove = result == "Yes"
 			}
 			if logLevel == "DEBUG" {
-				logLevel = common.FreeInput("Reddis Host Name", "DEBUG", common.NoValidation)
+				if logLevel, err = common.FreeInput("Reddis Host Name", "DEBUG", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if jwtSignerPublicKey == "" {
-				jwtSignerPublicKey = common.FreeInput("JWT Signer Public Key", "", common.NoValidation)
+				if jwtSignerPublicKey, err = common.FreeInput("JWT Signer Public Key", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if identAPIHost == "ident.provide.services" {
-				nchainAPIHost = common.FreeInput("Ident API Host", "ident.provide.services", common.NoValidation)
+				if nchainAPIHost, err = common.FreeInput("Ident API Host", "ident.provide.services", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if identAPIScheme == "https" {
-				nchainAPIScheme = common.FreeInput("Ident API Scheme", "https", common.NoValidation)
+				if nchainAPIScheme, err = common.FreeInput("Ident API Scheme", "https", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if nchainAPIHost == "nchain.provide.services" {
-				nchainAPIHost = common.FreeInput("Nchain API Host", "nchain.provide.services", common.NoValidation)
+				if nchainAPIHost, err = common.FreeInput("Nchain API Host", "nchain.provide.services", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if nchainAPIScheme == "https" {
-				nchainAPIScheme = common.FreeInput("Nchain API Scheme", "https", common.NoValidation)
+				if nchainAPIScheme, err = common.FreeInput("Nchain API Scheme", "https", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if privacyAPIHost == "privacy.provide.services" {
-				privacyAPIHost = common.FreeInput("Privacy API Host", "privacy.provide.services", common.NoValidation)
+				if privacyAPIHost, err = common.FreeInput("Privacy API Host", "privacy.provide.services", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if privacyAPIScheme == "https" {
-				privacyAPIScheme = common.FreeInput("Privacy API Scheme", "https", common.NoValidation)
+				if privacyAPIScheme, err = common.FreeInput("Privacy API Scheme", "https", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if vaultAPIHost == "vault.provide.services" {
-				vaultAPIHost = common.FreeInput("Vault API Host", "vault.provide.services", common.NoValidation)
+				if vaultAPIHost, err = common.FreeInput("Vault API Host", "vault.provide.services", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if vaultAPIScheme == "https" {
-				vaultAPIScheme = common.FreeInput("Vault API Scheme", "https", common.NoValidation)
+				if vaultAPIScheme, err = common.FreeInput("Vault API Scheme", "https", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if vaultRefreshToken == os.Getenv("VAULT_REFRESH_TOKEN") {
-				vaultRefreshToken = common.FreeInput("Vault API Refresh Token", os.Getenv("VAULT_REFRESH_TOKEN"), common.NoValidation)
+				if vaultRefreshToken, err = common.FreeInput("Vault API Refresh Token", os.Getenv("VAULT_REFRESH_TOKEN"), common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if vaultSealUnsealKey == os.Getenv("VAULT_SEAL_UNSEAL_KEY") {
-				vaultSealUnsealKey = common.FreeInput("Vault Un/Seal Token", os.Getenv("VAULT_SEAL_UNSEAL_KEY"), common.NoValidation)
+				if vaultSealUnsealKey, err = common.FreeInput("Vault Un/Seal Token", os.Getenv("VAULT_SEAL_UNSEAL_KEY"), common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if !withLocalVault {
-				withLocalVault = strings.ToLower(common.SelectInput(boolPromptArgs, localVaultPromptLabel)) == "yes"
+				result, err := common.SelectInput(boolPromptArgs, localVaultPromptLabel)
+				if err != nil {
+					return err
+				}
+				withLocalVault = strings.ToLower(result) == "yes"
 			}
 			if !withLocalIdent {
-				withLocalIdent = strings.ToLower(common.SelectInput(boolPromptArgs, localIdentPromptLabel)) == "yes"
+				result, err := common.SelectInput(boolPromptArgs, localIdentPromptLabel)
+				if err != nil {
+					return err
+				}
+				withLocalIdent = strings.ToLower(result) == "yes"
 			}
 			if !withLocalNChain {
-				withLocalNChain = strings.ToLower(common.SelectInput(boolPromptArgs, localNchainPromptLabel)) == "yes"
+				result, err := common.SelectInput(boolPromptArgs, localNchainPromptLabel)
+				if err != nil {
+					return err
+				}
+				withLocalNChain = strings.ToLower(result) == "yes"
 			}
 			if !withLocalPrivacy {
-				withLocalPrivacy = strings.ToLower(common.SelectInput(boolPromptArgs, localPrivacyPromptLabel)) == "yes"
+				result, err := common.SelectInput(boolPromptArgs, localPrivacyPromptLabel)
+				if err != nil {
+					return err
+				}
+				withLocalPrivacy = strings.ToLower(result) == "yes"
 			}
 			if organizationRefreshToken == os.Getenv("PROVIDE_ORGANIZATION_REFRESH_TOKEN") {
-				organizationRefreshToken = common.FreeInput("Organization Refresh Token", os.Getenv("PROVIDE_ORGANIZATION_REFRESH_TOKEN"), common.NoValidation)
+				if organizationRefreshToken, err = common.FreeInput("Organization Refresh Token", os.Getenv("PROVIDE_ORGANIZATION_REFRESH_TOKEN"), common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if baselineOrganizationAddress == "0x" {
-				baselineOrganizationAddress = common.FreeInput("Baseline Organization Address", "0x", common.NoValidation)
+				if baselineOrganizationAddress, err = common.FreeInput("Baseline Organization Address", "0x", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if baselineRegistryContractAddress == "0x" {
-				baselineOrganizationAddress = common.FreeInput("Baseline Registry Contract Address", "0x", common.HexValidation)
+				if baselineOrganizationAddress, err = common.FreeInput("Baseline Registry Contract Address", "0x", common.HexValidation); err != nil {
+					return err
+				}
 			}
 			if baselineWorkgroupID == "" {
-				baselineOrganizationAddress = common.FreeInput("Baseline Workgroup ID", "", common.HexValidation)
+				if baselineOrganizationAddress, err = common.FreeInput("Baseline Workgroup ID", "", common.HexValidation); err != nil {
+					return err
+				}
 			}
 			if nchainBaselineNetworkID == "0x" {
-				baselineOrganizationAddress = common.FreeInput("Nchain Baseline Network ID", "0x", common.HexValidation)
+				if baselineOrganizationAddress, err = common.FreeInput("Nchain Baseline Network ID", "0x", common.HexValidation); err != nil {
+					return err
+				}
 			}
 		}
-		runProxyRun(cmd, args)
+		if err := sorPrompt(); err != nil {
+			return err
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return runProxyRun(cmd, args)
 	case promptStepStop:
 		if Optional {
 			fmt.Println("Optional Flags:")
 			if name == "" {
-				name = common.FreeInput("Name", "", common.NoValidation)
+				if name, err = common.FreeInput("Name", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 		}
-		stopProxyRun(cmd, args)
+		return stopProxyRun(cmd, args)
 	case promptStepLogs:
 		if Optional {
 			fmt.Println("Optional Flags:")
 			if name == "" {
-				name = common.FreeInput("Name", "", common.NoValidation)
+				if name, err = common.FreeInput("Name", "", common.NoValidation); err != nil {
+					return err
+				}
+			}
+		}
+		return logsProxyRun(cmd, args)
+	case promptStepStatus:
+		if Optional {
+			fmt.Println("Optional Flags:")
+			if name == "" {
+				if name, err = common.FreeInput("Name", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 		}
-		logsProxyRun(cmd, args)
+		return statusProxyRun(cmd, args)
+	case promptStepExport:
+		if err := common.RequireOrganization(); err != nil {
+			return err
+		}
+		if baselineWorkgroupID == "" {
+			if err := common.RequireWorkgroup(); err != nil {
+				return err
+			}
+		}
+		if err := sorPrompt(); err != nil {
+			return err
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return exportProxyRun(cmd, args)
+	case promptStepBackup:
+		if Optional {
+			fmt.Println("Optional Flags:")
+			if name == "" {
+				if name, err = common.FreeInput("Name", "", common.NoValidation); err != nil {
+					return err
+				}
+			}
+		}
+		return backupProxyRun(cmd, args)
+	case promptStepRestore:
+		if Optional {
+			fmt.Println("Optional Flags:")
+			if name == "" {
+				if name, err = common.FreeInput("Name", "", common.NoValidation); err != nil {
+					return err
+				}
+			}
+		}
+		return restoreProxyRun(cmd, args)
+	case promptStepSimulate:
+		if common.NetworkID == "" {
+			if err := common.RequirePublicNetwork(); err != nil {
+				return err
+			}
+		}
+		if err := sorPrompt(); err != nil {
+			return err
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return simulateProxyRun(cmd, args)
+	case promptStepDoctor:
+		if err := common.RequireOrganization(); err != nil {
+			return err
+		}
+		if baselineWorkgroupID == "" {
+			if err := common.RequireWorkgroup(); err != nil {
+				return err
+			}
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return doctorProxyRun(cmd, args)
+	case "":
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
+	}
+	return nil
+}
+
+// configPrompt handles the stack definition file commands
+func configPrompt(cmd *cobra.Command, args []string, currentStep string) error {
+	switch step := currentStep; step {
+	case promptStepConfigInit:
+		return configInitRun(cmd, args)
+	case promptStepConfigValidate:
+		return configValidateRun(cmd, args)
+	case "":
+		result, err := common.SelectInput(configPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return configPrompt(cmd, args, result)
+	}
+	return nil
+}
+
+// imagesPrompt handles the image commands
+func imagesPrompt(cmd *cobra.Command, args []string, currentStep string) error {
+	switch step := currentStep; step {
+	case promptStepImagesLock:
+		return imagesLockRun(cmd, args)
+	case promptStepImagesSave:
+		return imagesSaveRun(cmd, args)
+	case promptStepImagesLoad:
+		return imagesLoadRun(cmd, args)
+	case "":
+		result, err := common.SelectInput(imagesPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return imagesPrompt(cmd, args, result)
+	}
+	return nil
+}
+
+// mockSORPrompt handles the mock system of record commands
+func mockSORPrompt(cmd *cobra.Command, args []string, currentStep string) error {
+	switch step := currentStep; step {
+	case promptStepMockSORPush:
+		if err := common.RequireOrganization(); err != nil {
+			return err
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return mockSORPushRun(cmd, args)
+	case promptStepMockSORWrites:
+		return mockSORWritesRun(cmd, args)
+	case "":
+		result, err := common.SelectInput(mockSORPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return mockSORPrompt(cmd, args, result)
+	}
+	return nil
+}
+
+// sorCmdPrompt handles the system of record commands
+func sorCmdPrompt(cmd *cobra.Command, args []string, currentStep string) error {
+	switch step := currentStep; step {
+	case promptStepSORList:
+		return sorListRun(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(sorCmdPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return sorCmdPrompt(cmd, args, result)
 	}
+	return nil
 }
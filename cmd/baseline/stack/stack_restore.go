@@ -0,0 +1,245 @@
+package stack
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/provideservices/provide-cli/cmd/common"
+
+	"github.com/spf13/cobra"
+)
+
+var restoreInputPath string
+
+var restoreBaselineStackCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore the state of the baseline stack from a backup",
+	Long: `Restore a running local baseline stack instance from an archive written by 'prvd baseline stack backup'.
+
+The services of the stack which use the restored state are stopped for the duration of the restore.`,
+	RunE: restoreProxy,
+}
+
+// stackBackup is a stack backup archive read into memory
+type stackBackup struct {
+	manifest      *stackBackupManifest
+	databases     map[string][]byte // pg_dump output keyed by database
+	redis         []*archiveEntry
+	natsStreaming []*archiveEntry
+}
+
+type archiveEntry struct {
+	header  *tar.Header
+	content []byte
+}
+
+func restoreProxy(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepRestore)
+}
+
+func restoreProxyRun(cmd *cobra.Command, args []string) error {
+	backup, err := readStackBackup(restoreInputPath)
+	if err != nil {
+		return err
+	}
+
+	docker, err := client.NewEnvClient()
+	if err != nil {
+		return fmt.Errorf("failed to initialize docker; %w", err)
+	}
+
+	containers, err := runningServices(docker)
+	if err != nil {
+		return err
+	}
+	for _, service := range backup.manifest.Services {
+		if _, ok := containers[service]; !ok {
+			return common.NewNotFoundError("%s is not running in %s local baseline instance; start the stack before restoring it", service, name)
+		}
+	}
+
+	// stop the services which hold connections to the restored state; each stopped service
+	// is restarted however the restore ends, including when another service fails to stop
+	stopped := make([]string, 0)
+	restartStopped := func() {
+		sort.Strings(stopped)
+		for _, service := range stopped {
+			log.Printf("starting %s", service)
+			if err := docker.ContainerStart(context.Background(), containers[service], types.ContainerStartOptions{}); err != nil {
+				log.Printf("WARNING: failed to start %s; %s", service, err.Error())
+			}
+		}
+		stopped = nil
+	}
+	defer restartStopped()
+
+	for service, id := range containers {
+		if service == servicePostgres || service == serviceRedis || service == serviceNATS || service == serviceNATSStreaming {
+			continue
+		}
+		log.Printf("stopping %s", service)
+		if err := docker.ContainerStop(context.Background(), id, nil); err != nil {
+			return fmt.Errorf("failed to stop %s; %w", service, err)
+		}
+		stopped = append(stopped, service)
+	}
+
+	restoreErr := restoreServices(docker, containers, backup)
+	restartStopped()
+
+	if restoreErr != nil {
+		return restoreErr
+	}
+
+	log.Printf("restored %s local baseline instance from %s backup of %s", name, backup.manifest.CreatedAt.Format("2006-01-02 15:04:05 MST"), backup.manifest.Name)
+	return nil
+}
+
+// restoreServices restores each database, the redis dataset and the NATS streaming file
+// store in the given backup
+func restoreServices(docker *client.Client, containers map[string]string, backup *stackBackup) error {
+	for _, db := range backup.manifest.Databases {
+		log.Printf("restoring %s database", db)
+		_, err := execInContainer(docker, containers[servicePostgres], backup.databases[db],
+			"psql", "-U", postgresUser, "-d", "postgres", "-v", "ON_ERROR_STOP=1", "-q",
+		)
+		if err != nil {
+			return fmt.Errorf("failed to restore %s database; %w", db, err)
+		}
+	}
+
+	if len(backup.redis) > 0 {
+		log.Printf("restoring redis dataset")
+		if err := replaceContainerData(docker, containers[serviceRedis], redisDataPath, backup.redis); err != nil {
+			return fmt.Errorf("failed to restore redis dataset; %w", err)
+		}
+	}
+
+	if len(backup.natsStreaming) > 0 {
+		log.Printf("restoring NATS streaming file store")
+		if err := replaceContainerData(docker, containers[serviceNATSStreaming], natsStreamingDataPath, backup.natsStreaming); err != nil {
+			return fmt.Errorf("failed to restore NATS streaming file store; %w", err)
+		}
+	}
+
+	return nil
+}
+
+// replaceContainerData copies the given entries into dstPath while the container is stopped,
+// so the service cannot overwrite them on shutdown, and then restarts the container
+func replaceContainerData(docker *client.Client, containerID, dstPath string, entries []*archiveEntry) error {
+	var buf bytes.Buffer
+	archive := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		if err := archive.WriteHeader(entry.header); err != nil {
+			return err
+		}
+		if _, err := archive.Write(entry.content); err != nil {
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := docker.ContainerStop(ctx, containerID, nil); err != nil {
+		return err
+	}
+	copyErr := docker.CopyToContainer(ctx, containerID, dstPath, &buf, types.CopyToContainerOptions{})
+	if err := docker.ContainerStart(ctx, containerID, types.ContainerStartOptions{}); err != nil {
+		return err
+	}
+	return copyErr
+}
+
+// readStackBackup reads and validates the stack backup archive at the given path
+func readStackBackup(backupPath string) (*stackBackup, error) {
+	f, err := os.Open(backupPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, common.NewNotFoundError("backup archive not found: %s", backupPath)
+		}
+		return nil, fmt.Errorf("failed to read %s; %w", backupPath, err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, common.NewValidationError("invalid backup archive: %s; %s", backupPath, err.Error())
+	}
+
+	backup := &stackBackup{
+		databases: map[string][]byte{},
+	}
+
+	archive := tar.NewReader(gz)
+	for {
+		hdr, err := archive.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, common.NewValidationError("invalid backup archive: %s; %s", backupPath, err.Error())
+		}
+
+		content, err := ioutil.ReadAll(archive)
+		if err != nil {
+			return nil, common.NewValidationError("invalid backup archive: %s; %s", backupPath, err.Error())
+		}
+
+		dir, rel := splitArchivePath(hdr.Name)
+		switch {
+		case hdr.Name == backupManifestEntry:
+			backup.manifest = &stackBackupManifest{}
+			if err := json.Unmarshal(content, backup.manifest); err != nil {
+				return nil, common.NewValidationError("invalid backup manifest in %s; %s", backupPath, err.Error())
+			}
+		case dir == backupPostgresDir && strings.HasSuffix(rel, ".sql"):
+			backup.databases[strings.TrimSuffix(rel, ".sql")] = content
+		case dir == backupRedisDir:
+			hdr.Name = rel
+			backup.redis = append(backup.redis, &archiveEntry{header: hdr, content: content})
+		case dir == backupNATSStreamingDir:
+			hdr.Name = rel
+			backup.natsStreaming = append(backup.natsStreaming, &archiveEntry{header: hdr, content: content})
+		}
+	}
+
+	if backup.manifest == nil {
+		return nil, common.NewValidationError("invalid backup archive: %s; %s not found", backupPath, backupManifestEntry)
+	}
+	for _, db := range backup.manifest.Databases {
+		if _, ok := backup.databases[db]; !ok {
+			return nil, common.NewValidationError("invalid backup archive: %s; dump of %s database not found", backupPath, db)
+		}
+	}
+
+	return backup, nil
+}
+
+// splitArchivePath splits an archive entry name into its top-level directory and the remainder
+func splitArchivePath(name string) (string, string) {
+	parts := strings.SplitN(path.Clean(name), "/", 2)
+	if len(parts) < 2 {
+		return "", parts[0]
+	}
+	return parts[0], parts[1]
+}
+
+func init() {
+	restoreBaselineStackCmd.Flags().StringVar(&name, "name", "baseline-local", "name of the baseline stack instance")
+	restoreBaselineStackCmd.Flags().StringVar(&restoreInputPath, "in", defaultBackupPath, "path of the backup archive to restore")
+}
@@ -3,16 +3,14 @@ package stack
 import (
 	"context"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"log"
-	"net/url"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -29,7 +27,7 @@ import (
 	"github.com/provideservices/provide-go/api/vault"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+	"github.com/spf13/pflag"
 )
 
 const baselineContainerImage = "provide/baseline"
@@ -70,6 +68,7 @@ const redisContainerPort = 6379
 type portMapping struct {
 	hostPort      int
 	containerPort int
+	flag          string // run flag which configures the host port; i.e., nats-port
 }
 
 var dockerNetworkID string
@@ -138,200 +137,159 @@ var vaultAPIScheme string
 var vaultRefreshToken string
 var vaultSealUnsealKey string
 
-var sapAPIHost string
-var sapAPIScheme string
-var sapAPIUsername string
-var sapAPIPassword string
-var sapAPIPath string
-
-var serviceNowAPIHost string
-var serviceNowAPIScheme string
-var serviceNowAPIUsername string
-var serviceNowAPIPassword string
-var serviceNowAPIPath string
-
-var salesforceAPIHost string
-var salesforceAPIScheme string
-var salesforceAPIPath string
-
 var withLocalVault bool
 var withLocalIdent bool
 var withLocalNChain bool
 var withLocalPrivacy bool
 
+var runStackDefinitionPath string
+
 var runBaselineStackCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Run the baseline stack",
 	Long:  `Start a local baseline stack instance and connect to internal systems of record`,
-	Run:   runProxy,
+	RunE:  runProxy,
 }
 
-func runProxy(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepRun)
+func runProxy(cmd *cobra.Command, args []string) error {
+	if runStackDefinitionPath != "" {
+		if err := applyStackDefinition(cmd.Flags(), runStackDefinitionPath); err != nil {
+			return err
+		}
+	}
+	if err := requireImageLock(cmd); err != nil {
+		return err
+	}
+	return generalPrompt(cmd, args, promptStepRun)
 }
 
-func runProxyRun(cmd *cobra.Command, args []string) {
+func runProxyRun(cmd *cobra.Command, args []string) error {
+	if startupTimeout <= 0 {
+		return common.NewValidationError("invalid --startup-timeout: %s; must be greater than zero", startupTimeout)
+	}
+	if err := validateNATSFlags(); err != nil {
+		return err
+	}
+	if err := validateMockSORFlags(); err != nil {
+		return err
+	}
+	if err := requireSystemOfRecord(); err != nil {
+		return err
+	}
+
 	docker, err := client.NewEnvClient()
 	if err != nil {
-		log.Printf("failed to initialize docker; %s", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("failed to initialize docker; %w", err)
 	}
 
-	go purgeContainers(docker)
+	return runStack(cmd, docker, func(fn func() error) error {
+		if err := tunnelAPIPrompt(); err != nil {
+			return err
+		}
+		if err := tunnelMessagingPrompt(); err != nil {
+			return err
+		}
+		return common.RequireOrganizationEndpoints(fn, port, natsPort)
+	})
+}
 
-	authorizeContext()
-	sorPrompt()
-	tunnelAPIPrompt()
-	tunnelMessagingPrompt()
+// runStack starts the named stack; requireEndpoints is responsible for resolving and
+// registering the endpoints of the organization before calling fn to start the services
+func runStack(cmd *cobra.Command, docker *client.Client, requireEndpoints func(fn func() error) error) (err error) {
+	if err := purgeContainers(docker); err != nil {
+		return err
+	}
+	if err := resetStackFilesDir(); err != nil {
+		return err
+	}
 
-	wg := &sync.WaitGroup{}
+	if err := authorizeContext(); err != nil {
+		return err
+	}
 
-	images := make([]string, 0)
-	images = append(
-		images,
-		baselineContainerImage,
-		natsContainerImage,
-		natsStreamingContainerImage,
-		redisContainerImage,
-	)
+	wg := &sync.WaitGroup{}
 
-	if withLocalIdent {
-		identVersion := "latest"
-		if common.IsReleaseContext() {
-			version, err := common.Manifest.GetImageVersion(identContainerImage)
-			if err != nil {
-				log.Printf("failed to resolve version for pinned container image: %s; %s", identContainerImage, err.Error())
-				os.Exit(1)
-			}
-			identVersion = *version
-		}
-		identImage := fmt.Sprintf("%s:%s", identContainerImage, identVersion)
-		images = append(images, identImage)
-	} else if common.IsReleaseContext() {
-		// TODO: enforce target API major version level using status endpoint at configured identBaseURL/status
+	services, err := stackServices()
+	if err != nil {
+		return err
 	}
 
-	if withLocalNChain {
-		nchainVersion := "latest"
-		if common.IsReleaseContext() {
-			version, err := common.Manifest.GetImageVersion(nchainContainerImage)
-			if err != nil {
-				log.Printf("failed to resolve version for pinned container image: %s; %s", nchainContainerImage, err.Error())
-				os.Exit(1)
-			}
-			nchainVersion = *version
-		}
-		nchainImage := fmt.Sprintf("%s:%s", nchainContainerImage, nchainVersion)
-		images = append(images, nchainImage)
-	} else if common.IsReleaseContext() {
-		// TODO: enforce target API major version level using status endpoint at configured nchainBaseURL/status
+	if err := preflightPorts(cmd.Flags(), services, autoPorts); err != nil {
+		return err
 	}
 
-	if withLocalPrivacy {
-		privacyVersion := "latest"
-		if common.IsReleaseContext() {
-			version, err := common.Manifest.GetImageVersion(privacyContainerImage)
-			if err != nil {
-				log.Printf("failed to resolve version for pinned container image: %s; %s", privacyContainerImage, err.Error())
-				os.Exit(1)
-			}
-			privacyVersion = *version
+	images := make([]string, 0)
+	for _, svc := range services {
+		if !containsString(images, svc.image) {
+			images = append(images, svc.image)
 		}
-		privacyImage := fmt.Sprintf("%s:%s", privacyContainerImage, privacyVersion)
-		images = append(images, privacyImage)
-	} else if common.IsReleaseContext() {
-		// TODO: enforce target API major version level using status endpoint at configured privacyBaseURL/status
 	}
 
-	if withLocalVault {
-		vaultVersion := "latest"
-		if common.IsReleaseContext() {
-			version, err := common.Manifest.GetImageVersion(vaultContainerImage)
-			if err != nil {
-				log.Printf("failed to resolve version for pinned container image: %s; %s", vaultContainerImage, err.Error())
-				os.Exit(1)
-			}
-			vaultVersion = *version
+	pullErrs := make(chan error, len(images))
+	// the image pulls are awaited, and the network removed, on every path which does
+	// not result in a started stack
+	networkConfigured := false
+	defer func() {
+		wg.Wait()
+		if err != nil && networkConfigured {
+			purgeNetwork(docker)
 		}
-		vaultImage := fmt.Sprintf("%s:%s", vaultContainerImage, vaultVersion)
-		images = append(images, vaultImage)
-	} else if common.IsReleaseContext() {
-		// TODO: enforce target API major version level using status endpoint at configured vaultBaseURL/status
-	}
-
-	if withLocalIdent || withLocalNChain || withLocalPrivacy || withLocalVault {
-		images = append(images, postgresContainerImage)
-	}
+	}()
 
 	for _, image := range images {
 		img := image
 		wg.Add(1)
 		go func() {
-			err := pullImage(docker, img)
-			if err != nil {
-				log.Printf("failed to pull local baseline container image: %s; %s", img, err.Error())
-				os.Exit(1)
+			defer wg.Done()
+			err := ensureImage(docker, img)
+			if err != nil && img == mockSORImage() {
+				pullErrs <- fmt.Errorf("failed to pull local baseline container image: %s; %w; build it from the source of this version of prvd using 'make docker VERSION=%s'", img, err, common.Version)
+			} else if err != nil {
+				pullErrs <- fmt.Errorf("failed to pull local baseline container image: %s; %w", img, err)
 			}
-			wg.Done()
 		}()
 	}
 
-	configureNetwork(docker)
-	common.RequireOrganizationEndpoints(
-		func() {
-			applyFlags()
-
-			wg.Wait()
-
-			// run local deps
-			go runNATS(docker)
-			go runNATSStreaming(docker)
-			go runRedis(docker)
-
-			if withLocalIdent || withLocalNChain || withLocalPrivacy || withLocalVault {
-				go runPostgres(docker)
-			}
-
-			// run optional local containers
-			if withLocalIdent {
-				go runIdentAPI(docker)
-				go runIdentConsumer(docker)
-			}
+	if err := configureNetwork(docker); err != nil {
+		return err
+	}
+	networkConfigured = true
 
-			if withLocalNChain {
-				go runNChainAPI(docker)
-				go runNChainConsumer(docker)
-				go runStatsdaemon(docker)
-				go runReachabilitydaemon(docker)
-			}
+	return requireEndpoints(func() error {
+		applyFlags()
+		if err := requireNATSCredentials(); err != nil {
+			return err
+		}
 
-			if withLocalPrivacy {
-				go runPrivacyAPI(docker)
-				go runPrivacyConsumer(docker)
-			}
+		wg.Wait()
+		close(pullErrs)
+		if err := <-pullErrs; err != nil {
+			return err
+		}
 
-			if withLocalVault {
-				go runVaultAPI(docker)
-			}
+		// hostnames are resolved by applyFlags
+		services, err := stackServices()
+		if err != nil {
+			return err
+		}
 
-			// run proxy
-			go runProxyAPI(docker)
-			go runProxyConsumer(docker)
+		if err := startServices(docker, services); err != nil {
+			return err
+		}
 
-			log.Printf("%s local baseline instance started", name)
-		},
-		port,
-		natsPort,
-	)
+		log.Printf("%s local baseline instance started", name)
+		return nil
+	})
 }
 
-func configureNetwork(docker *client.Client) {
+func configureNetwork(docker *client.Client) error {
 	network, err := docker.NetworkCreate(
 		context.Background(),
 		name,
 		types.NetworkCreate{
 			// CheckDuplicate bool
 			Driver: "bridge",
+			Labels: stackLabels(""),
 			// Scope          string
 			// EnableIPv6     bool
 			IPAM: &network.IPAM{},
@@ -341,66 +299,83 @@ func configureNetwork(docker *client.Client) {
 			// ConfigOnly     bool
 			// ConfigFrom     *network.ConfigReference
 			// Options        map[string]string
-			// Labels         map[string]string
 		},
 	)
 
 	if err != nil {
-		log.Printf("failed to setup docker network; %s", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("failed to setup docker network; %w", err)
 	}
 
 	dockerNetworkID = network.ID
 	log.Printf("configured network for local baseline instance: %s", name)
+	return nil
 }
 
-func authorizeContext() {
+func authorizeContext() error {
 	// log.Printf("authorizing workgroup context")
-	authorizeWorkgroupContext()
+	if err := authorizeWorkgroupContext(); err != nil {
+		return err
+	}
 
 	// log.Printf("authorizing organization context")
-	common.AuthorizeOrganizationContext(false)
+	if err := common.AuthorizeOrganizationContext(false); err != nil {
+		return err
+	}
 
 	if organizationRefreshToken == "" {
 		refreshTokenKey := common.BuildConfigKeyWithOrg(common.APIRefreshTokenConfigKeyPartial, common.OrganizationID)
-		if viper.IsSet(refreshTokenKey) {
+		cachedRefreshToken, err := common.CachedCredential(refreshTokenKey)
+		if err != nil {
+			return err
+		}
+		if cachedRefreshToken != "" {
 			// log.Printf("using cached API refresh token for organization: %s\n", common.OrganizationID)
-			organizationRefreshToken = viper.GetString(refreshTokenKey)
+			organizationRefreshToken = cachedRefreshToken
 			if vaultRefreshToken == "" {
 				vaultRefreshToken = organizationRefreshToken
 			}
 		} else {
-			organizationAuthPrompt()
+			if err := organizationAuthPrompt(); err != nil {
+				return err
+			}
 			if common.OrganizationRefreshToken != "" {
 				organizationRefreshToken = common.OrganizationRefreshToken
 				if vaultRefreshToken == "" {
 					vaultRefreshToken = organizationRefreshToken
 				}
+			} else if !common.IsInteractive() {
+				return &common.MissingInputError{Flags: []string{"--organization-refresh-token"}}
 			} else {
-				log.Printf("failed to resolve refresh token for organization: %s\n", common.OrganizationID)
-				os.Exit(1)
+				return common.NewAuthError("failed to resolve refresh token for organization: %s", common.OrganizationID)
 			}
 		}
 	}
+	return nil
 }
 
-func authorizeWorkgroupContext() {
+func authorizeWorkgroupContext() error {
 	if baselineWorkgroupID == "" {
 		err := common.RequireWorkgroup()
 		if err != nil {
-			log.Printf("failed to require workgroup; %s", err.Error())
-			os.Exit(1)
+			return fmt.Errorf("failed to require workgroup; %w", err)
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
 		}
 		baselineWorkgroupID = common.ApplicationID
 	}
 
-	token, err := ident.CreateToken(common.RequireUserAccessToken(), map[string]interface{}{
+	userAccessToken, err := common.RequireUserAccessToken()
+	if err != nil {
+		return err
+	}
+
+	token, err := ident.CreateToken(userAccessToken, map[string]interface{}{
 		"scope":          "offline_access",
 		"application_id": baselineWorkgroupID,
 	})
 	if err != nil {
-		log.Printf("failed to authorize API access token on behalf of workgroup %s; %s", baselineWorkgroupID, err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "failed to authorize API access token on behalf of workgroup %s", baselineWorkgroupID)
 	}
 
 	if token.AccessToken != nil {
@@ -409,19 +384,16 @@ func authorizeWorkgroupContext() {
 
 	workgroup, err := ident.GetApplicationDetails(workgroupAccessToken, baselineWorkgroupID, map[string]interface{}{})
 	if err != nil {
-		log.Printf("failed to resolve workgroup: %s; %s", baselineWorkgroupID, err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "failed to resolve workgroup: %s", baselineWorkgroupID)
 	}
 
 	contracts, err := nchain.ListContracts(workgroupAccessToken, map[string]interface{}{
 		"type": "organization-registry",
 	})
 	if err != nil {
-		log.Printf("failed to resolve global organization registry contract; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "failed to resolve global organization registry contract")
 	} else if len(contracts) == 0 {
-		log.Printf("failed to resolve global organization registry contract")
-		os.Exit(1)
+		return common.NewNotFoundError("failed to resolve global organization registry contract")
 	}
 
 	if nchainBaselineNetworkID == "" {
@@ -430,8 +402,10 @@ func authorizeWorkgroupContext() {
 		} else {
 			err := common.RequirePublicNetwork()
 			if err != nil {
-				log.Printf("failed to require network id; %s", err.Error())
-				os.Exit(1)
+				return fmt.Errorf("failed to require network id; %w", err)
+			}
+			if err := common.MissingInput(); err != nil {
+				return err
 			}
 			nchainBaselineNetworkID = common.NetworkID
 		}
@@ -439,10 +413,10 @@ func authorizeWorkgroupContext() {
 
 	orgRegistryContract := contracts[0]
 	if orgRegistryContract.Address == nil || *orgRegistryContract.Address == "0x" {
-		log.Printf("failed to resolve global organization registry contract; %s", err.Error())
-		os.Exit(1)
+		return common.NewNotFoundError("failed to resolve global organization registry contract")
 	}
 	baselineRegistryContractAddress = *contracts[0].Address
+	return nil
 }
 
 func applyFlags() {
@@ -467,7 +441,7 @@ func applyFlags() {
 	}
 
 	// HACK
-	if strings.HasSuffix(identHostname, "-ident-consumer") {
+	if strings.HasSuffix(identConsumerHostname, "-ident-consumer") {
 		identConsumerHostname = fmt.Sprintf("%s-ident-consumer", name)
 	}
 
@@ -482,7 +456,7 @@ func applyFlags() {
 	}
 
 	// HACK
-	if strings.HasSuffix(nchainConsumerHostname, "-reachabilitydaemon") {
+	if strings.HasSuffix(nchainReachabilitydaemonHostname, "-reachabilitydaemon") {
 		nchainReachabilitydaemonHostname = fmt.Sprintf("%s-reachabilitydaemon", name)
 	}
 
@@ -522,6 +496,27 @@ func applyFlags() {
 		redisHosts = fmt.Sprintf("%s:%d", redisHostname, redisContainerPort)
 	}
 
+	// HACK -- the hostnames of the local provide services are not configurable using flags
+	for hostname, service := range map[*string]string{
+		&identHostname:                    serviceIdentAPI,
+		&identConsumerHostname:            serviceIdentConsumer,
+		&nchainHostname:                   serviceNChainAPI,
+		&nchainConsumerHostname:           serviceNChainConsumer,
+		&nchainStatsdaemonHostname:        serviceStatsdaemon,
+		&nchainReachabilitydaemonHostname: serviceReachabilitydaemon,
+		&privacyHostname:                  servicePrivacyAPI,
+		&privacyConsumerHostname:          servicePrivacyConsumer,
+		&vaultHostname:                    serviceVaultAPI,
+	} {
+		if *hostname == "" {
+			*hostname = containerName(service)
+		}
+	}
+
+	if mockSOR != "" {
+		sorURL = mockSORURL()
+	}
+
 	// HACK
 	if natsServerName == "" {
 		natsServerName = defaultNatsServerName
@@ -533,11 +528,11 @@ func applyFlags() {
 			"spec": "RSA-4096",
 		})
 		if err != nil {
-			log.Printf("WARNING: failed to resolve RSA-4096 key for organization; %s", err.Error())
+			log.Printf("WARNING: failed to resolve RSA-4096 key for organization; bearer JWTs will not authorize NATS connections; %s", err.Error())
 			return
 		}
 		if len(keys) == 0 {
-			log.Printf("WARNING: failed to resolve RSA-4096 key for organization")
+			log.Printf("WARNING: failed to resolve RSA-4096 key for organization; bearer JWTs will not authorize NATS connections")
 			return
 		}
 
@@ -580,410 +575,15 @@ func containerEnvironmentFactory() []string {
 		env = append(env, envvar)
 	}
 
-	if sapAPIHost != "" && sapAPIUsername != "" && sapAPIPassword != "" {
-		for _, envvar := range []string{
-			fmt.Sprintf("SAP_API_HOST=%s", sapAPIHost),
-			fmt.Sprintf("SAP_API_SCHEME=%s", sapAPIScheme),
-			fmt.Sprintf("SAP_API_PATH=%s", sapAPIPath),
-			fmt.Sprintf("SAP_API_USERNAE=%s", sapAPIUsername),
-			fmt.Sprintf("SAP_API_PASSWORD=%s", sapAPIPassword),
-		} {
-			env = append(env, envvar)
-		}
-	} else if sorID == "sap" && sorURL != "" {
-		_url, err := url.Parse(sorURL)
-		if err != nil {
-			log.Printf("WARNING: system of record url invalid; %s", err.Error())
-		}
-		for _, envvar := range []string{
-			fmt.Sprintf("SAP_API_HOST=%s", _url.Host),
-			fmt.Sprintf("SAP_API_SCHEME=%s", _url.Scheme),
-		} {
-			env = append(env, envvar)
-		}
-
-		if _url.Path != "" {
-			env = append(env, fmt.Sprintf("SAP_API_PATH=%s", strings.TrimLeft(_url.Path, "/")))
-		}
-	}
-
-	if serviceNowAPIHost != "" && serviceNowAPIUsername != "" && serviceNowAPIPassword != "" {
-		for _, envvar := range []string{
-			fmt.Sprintf("SERVICENOW_API_HOST=%s", serviceNowAPIHost),
-			fmt.Sprintf("SERVICENOW_API_SCHEME=%s", serviceNowAPIScheme),
-			fmt.Sprintf("SERVICENOW_API_PATH=%s", serviceNowAPIPath),
-			fmt.Sprintf("SERVICENOW_API_USERNAE=%s", serviceNowAPIUsername),
-			fmt.Sprintf("SERVICENOW_API_PASSWORD=%s", serviceNowAPIPassword),
-		} {
-			env = append(env, envvar)
-		}
-	} else if sorID == "servicenow" || sorID == "snow" && sorURL != "" {
-		_url, err := url.Parse(sorURL)
-		if err != nil {
-			log.Printf("WARNING: system of record url invalid; %s", err.Error())
-		}
-		for _, envvar := range []string{
-			fmt.Sprintf("SERVICENOW_API_HOST=%s", _url.Host),
-			fmt.Sprintf("SERVICENOW_API_SCHEME=%s", _url.Scheme),
-		} {
-			env = append(env, envvar)
-		}
-
-		if _url.Path != "" {
-			env = append(env, fmt.Sprintf("SERVICENOW_API_PATH=%s", strings.TrimLeft(_url.Path, "/")))
-		}
-	}
-
-	return env
-}
-
-func runProxyAPI(docker *client.Client) {
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-api", strings.ReplaceAll(name, " ", "")),
-		apiHostname,
-		baselineContainerImage,
-		&[]string{"./ops/run_api.sh"},
-		nil,
-		&[]string{"CMD", "curl", "-f", fmt.Sprintf("http://%s:%d/status", apiHostname, apiContainerPort)},
-		map[string]string{},
-		[]portMapping{{
-			hostPort:      port,
-			containerPort: apiContainerPort,
-		}}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local baseline API container; %s", err.Error())
-		os.Exit(1)
-	}
-}
-
-func runProxyConsumer(docker *client.Client) {
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-consumer", strings.ReplaceAll(name, " ", "")),
-		consumerHostname,
-		baselineContainerImage,
-		&[]string{"./ops/run_consumer.sh"},
-		nil,
-		&[]string{"CMD", "curl", "-f", fmt.Sprintf("http://%s:%d/status", apiHostname, port)},
-		map[string]string{},
-		[]portMapping{}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local baseline consumer container; %s", err.Error())
-		os.Exit(1)
-	}
-}
-
-func runIdentAPI(docker *client.Client) {
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-ident-api", strings.ReplaceAll(name, " ", "")),
-		identHostname,
-		identContainerImage,
-		&[]string{"./ops/run_api.sh"},
-		nil,
-		&[]string{"CMD", "curl", "-f", fmt.Sprintf("http://%s:%d/status", identHostname, apiContainerPort)},
-		map[string]string{},
-		[]portMapping{{
-			hostPort:      identPort,
-			containerPort: apiContainerPort,
-		}}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local ident API container; %s", err.Error())
-		os.Exit(1)
-	}
-}
-
-func runIdentConsumer(docker *client.Client) {
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-ident-consumer", strings.ReplaceAll(name, " ", "")),
-		identConsumerHostname,
-		identContainerImage,
-		&[]string{"./ops/run_consumer.sh"},
-		nil,
-		&[]string{"CMD", "curl", "-f", fmt.Sprintf("http://%s:%d/status", identHostname, apiContainerPort)},
-		map[string]string{},
-		[]portMapping{}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local ident consumer container; %s", err.Error())
-		os.Exit(1)
-	}
-}
-
-func runNChainAPI(docker *client.Client) {
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-nchain-api", strings.ReplaceAll(name, " ", "")),
-		nchainHostname,
-		nchainContainerImage,
-		&[]string{"./ops/run_api.sh"},
-		nil,
-		&[]string{"CMD", "curl", "-f", fmt.Sprintf("http://%s:%d/status", nchainHostname, apiContainerPort)},
-		map[string]string{},
-		[]portMapping{{
-			hostPort:      nchainPort,
-			containerPort: apiContainerPort,
-		}}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local nchain API container; %s", err.Error())
-		os.Exit(1)
-	}
-}
-
-func runNChainConsumer(docker *client.Client) {
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-nchain-consumer", strings.ReplaceAll(name, " ", "")),
-		nchainConsumerHostname,
-		nchainContainerImage,
-		&[]string{"./ops/run_consumer.sh"},
-		nil,
-		&[]string{"CMD", "curl", "-f", fmt.Sprintf("http://%s:%d/status", nchainHostname, apiContainerPort)},
-		map[string]string{},
-		[]portMapping{}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local nchain consumer container; %s", err.Error())
-		os.Exit(1)
-	}
-}
-
-func runStatsdaemon(docker *client.Client) {
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-statsdaemon", strings.ReplaceAll(name, " ", "")),
-		nchainStatsdaemonHostname,
-		nchainContainerImage,
-		&[]string{"./ops/run_statsdaemon.sh"},
-		nil,
-		&[]string{"CMD", "curl", "-f", fmt.Sprintf("http://%s:%d/status", nchainHostname, apiContainerPort)},
-		map[string]string{},
-		[]portMapping{}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local statsdaemon container; %s", err.Error())
-		os.Exit(1)
-	}
-}
-
-func runReachabilitydaemon(docker *client.Client) {
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-reachabilitydaemon", strings.ReplaceAll(name, " ", "")),
-		nchainReachabilitydaemonHostname,
-		nchainContainerImage,
-		&[]string{"./ops/run_reachabilitydaemon.sh"},
-		nil,
-		&[]string{"CMD", "curl", "-f", fmt.Sprintf("http://%s:%d/status", nchainHostname, apiContainerPort)},
-		map[string]string{},
-		[]portMapping{}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local reachabilitydaemon container; %s", err.Error())
-		os.Exit(1)
-	}
-}
-
-func runPrivacyAPI(docker *client.Client) {
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-privacy-api", strings.ReplaceAll(name, " ", "")),
-		privacyHostname,
-		privacyContainerImage,
-		&[]string{"./ops/run_api.sh"},
-		nil,
-		&[]string{"CMD", "curl", "-f", fmt.Sprintf("http://%s:%d/status", privacyHostname, apiContainerPort)},
-		map[string]string{},
-		[]portMapping{{
-			hostPort:      privacyPort,
-			containerPort: apiContainerPort,
-		}}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local privacy API container; %s", err.Error())
-		os.Exit(1)
-	}
-}
-
-func runPrivacyConsumer(docker *client.Client) {
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-privacy-consumer", strings.ReplaceAll(name, " ", "")),
-		privacyConsumerHostname,
-		privacyContainerImage,
-		&[]string{"./ops/run_consumer.sh"},
-		nil,
-		&[]string{"CMD", "curl", "-f", fmt.Sprintf("http://%s:%d/status", privacyHostname, apiContainerPort)},
-		map[string]string{},
-		[]portMapping{}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local privacy consumer container; %s", err.Error())
-		os.Exit(1)
-	}
-}
-
-func runVaultAPI(docker *client.Client) {
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-vault-api", strings.ReplaceAll(name, " ", "")),
-		vaultHostname,
-		vaultContainerImage,
-		&[]string{"./ops/run_api.sh"},
-		nil,
-		&[]string{"CMD", "curl", "-f", fmt.Sprintf("http://%s:%d/status", vaultHostname, apiContainerPort)},
-		map[string]string{},
-		[]portMapping{{
-			hostPort:      vaultPort,
-			containerPort: apiContainerPort,
-		}}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local vault API container; %s", err.Error())
-		os.Exit(1)
-	}
-}
-
-func writeNATSConfig() {
-	cfg := []byte("max_payload: 100Mb\n")
-	// FIXME-- use properly-resolved path to tmp
-	err := ioutil.WriteFile("/tmp/nats-server.conf", cfg, 0644)
-	if err != nil {
-		log.Printf("failed to write local nats-server.conf; %s", err.Error())
-		os.Exit(1)
-	}
-}
-
-func runNATS(docker *client.Client) {
-	writeNATSConfig()
-
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-nats", strings.ReplaceAll(name, " ", "")),
-		natsHostname,
-		natsContainerImage,
-		nil,
-		&[]string{
-			"--auth", natsAuthToken,
-			"--config", "/etc/nats-server.conf",
-			"--name", natsServerName,
-			"--port", fmt.Sprintf("%d", natsContainerPort),
-			"-DVV",
-		},
-		&[]string{"CMD", "/usr/local/bin/await_tcp.sh", fmt.Sprintf("localhost:%d", natsContainerPort)},
-		map[string]string{
-			"/tmp/nats-server.conf": "/etc/nats-server.conf",
-		},
-		[]portMapping{
-			{
-				hostPort:      natsPort,
-				containerPort: natsContainerPort,
-			},
-			{
-				hostPort:      natsWebsocketPort,
-				containerPort: natsWebsocketContainerPort,
-			},
-		}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local baseline NATS container; %s", err.Error())
-		os.Exit(1)
+	if natsTLS {
+		env = append(env, fmt.Sprintf("NATS_ROOT_CA_CERTIFICATES=%s", natsCACertificatePath))
 	}
-}
 
-func runNATSStreaming(docker *client.Client) {
-	writeNATSConfig()
-
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-nats-streaming", strings.ReplaceAll(name, " ", "")),
-		natsStreamingHostname,
-		natsStreamingContainerImage,
-		nil,
-		&[]string{
-			"--cluster_id", defaultNATSStreamingClusterID,
-			"--auth", natsAuthToken,
-			"--config", "/etc/nats-server.conf",
-			"-SDV",
-		},
-		&[]string{"CMD", "/usr/local/bin/await_tcp.sh", fmt.Sprintf("localhost:%d", natsStreamingContainerPort)},
-		map[string]string{
-			"/tmp/nats-server.conf": "/etc/nats-server.conf",
-		},
-		[]portMapping{
-			{
-				hostPort:      natsStreamingPort,
-				containerPort: natsStreamingContainerPort,
-			},
-		}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local baseline NATS streaming container; %s", err.Error())
-		os.Exit(1)
+	if mockSOR != "" {
+		return append(env, mockSOREnvironment()...)
 	}
-}
 
-func runPostgres(docker *client.Client) {
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-postgres", strings.ReplaceAll(name, " ", "")),
-		postgresHostname,
-		postgresContainerImage,
-		nil,
-		nil,
-		&[]string{"CMD", "pg_isready", "-U", "prvd", "-d", "prvd"},
-		map[string]string{},
-		[]portMapping{{
-			hostPort:      postgresPort,
-			containerPort: postgresContainerPort,
-		}}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local postgres container; %s", err.Error())
-		os.Exit(1)
-	}
-}
-
-func runRedis(docker *client.Client) {
-	_, err := runContainer(
-		docker,
-		fmt.Sprintf("%s-redis", strings.ReplaceAll(name, " ", "")),
-		redisHostname,
-		redisContainerImage,
-		nil,
-		nil,
-		&[]string{"CMD", "redis-cli", "ping"},
-		map[string]string{},
-		[]portMapping{{
-			hostPort:      redisPort,
-			containerPort: redisContainerPort,
-		}}...,
-	)
-
-	if err != nil {
-		log.Printf("failed to create local baseline redis container; %s", err.Error())
-		os.Exit(1)
-	}
+	return append(env, sorEnvironment()...)
 }
 
 func pullImage(docker *client.Client, image string) error {
@@ -994,27 +594,14 @@ func pullImage(docker *client.Client, image string) error {
 	}
 	defer reader.Close()
 
-	_, err = ioutil.ReadAll(reader)
-	if err != nil {
-		log.Printf("WARNING: %s", err.Error())
-	}
-
-	// log.Printf("%s", string(buf))
-	io.Copy(os.Stdout, reader)
-
-	return nil
+	// errors which occur once the pull is underway are only reported in the progress stream
+	return readImageStream(reader, nil)
 }
 
-func runContainer(
-	docker *client.Client,
-	name, hostname, image string,
-	entrypoint, cmd, healthcheck *[]string,
-	mounts map[string]string,
-	ports ...portMapping,
-) (*container.ContainerCreateCreatedBody, error) {
-	log.Printf("running local baseline container image: %s", image)
+func runContainer(docker *client.Client, spec *serviceSpec) (*container.ContainerCreateCreatedBody, error) {
+	log.Printf("running local baseline container image: %s", spec.image)
 	portBinding := nat.PortMap{}
-	for _, mapping := range ports {
+	for _, mapping := range spec.ports {
 		port, _ := nat.NewPort("tcp", strconv.Itoa(mapping.containerPort))
 		portBinding[port] = []nat.PortBinding{{
 			HostIP:   "0.0.0.0",
@@ -1023,35 +610,57 @@ func runContainer(
 	}
 
 	containerConfig := &container.Config{
-		Env:      containerEnvironmentFactory(),
-		Hostname: hostname,
-		Image:    image,
-	}
-
-	if cmd != nil {
-		containerConfig.Cmd = *cmd
+		Env:        containerEnvironmentFactory(),
+		Hostname:   spec.hostname,
+		Image:      spec.image,
+		Labels:     stackLabels(spec.service),
+		Entrypoint: spec.entrypoint,
+		Cmd:        spec.cmd,
 	}
 
-	if entrypoint != nil {
-		containerConfig.Entrypoint = *entrypoint
-	}
-
-	if healthcheck != nil {
+	if spec.healthcheck != nil {
 		containerConfig.Healthcheck = &container.HealthConfig{
-			Interval:    time.Minute * 1,
-			Retries:     2,
-			StartPeriod: time.Second * 10,
-			Test:        *healthcheck,
-			Timeout:     time.Second * 1,
+			Interval:    healthcheckInterval,
+			Retries:     healthcheckRetries,
+			StartPeriod: healthcheckStartPeriod,
+			Test:        spec.healthcheck,
+			Timeout:     healthcheckTimeout,
 		}
 	}
 
 	mountedVolumes := make([]mount.Mount, 0)
-	for source := range mounts { // mounts are mapped source => target...
+	for target, content := range spec.files {
+		// generated files are written to the private directory of the stack, so stacks run side by side do not collide;
+		// those which hold credentials are readable only by the current user
+		filesDir, err := stackFilesDir()
+		if err != nil {
+			return nil, err
+		}
+		dir := filepath.Join(filesDir, spec.service)
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create %s; %w", dir, err)
+		}
+		source := filepath.Join(dir, filepath.Base(target))
+		mode := os.FileMode(0644)
+		if containsString(secretFiles, target) {
+			mode = 0600
+		}
+		if err := ioutil.WriteFile(source, []byte(content), mode); err != nil {
+			return nil, fmt.Errorf("failed to write %s; %w", source, err)
+		}
+		mountedVolumes = append(mountedVolumes, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   source,
+			Target:   target,
+			ReadOnly: true,
+		})
+	}
+
+	for _, volume := range spec.volumes {
 		mountedVolumes = append(mountedVolumes, mount.Mount{
-			Type:   mount.TypeBind,
-			Source: source,
-			Target: mounts[source],
+			Type:   mount.TypeVolume,
+			Source: volumeName(volume.volume),
+			Target: volume.target,
 		})
 	}
 
@@ -1068,7 +677,7 @@ func runContainer(
 			},
 		},
 		&network.NetworkingConfig{},
-		strings.ReplaceAll(name, " ", ""),
+		containerName(spec.service),
 	)
 
 	if err != nil {
@@ -1094,60 +703,102 @@ func runContainer(
 	return &container, nil
 }
 
-func listContainers(docker *client.Client) []types.Container {
+// stackFilesDir returns the private directory to which the generated files of the named stack,
+// i.e., its NATS configuration and TLS material, are written for mounting into its containers;
+// it is within the cache directory of the current user, rather than the shared temp directory
+func stackFilesDir() (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve cache directory; %w", err)
+	}
+	return filepath.Join(cache, "prvd", "stacks", strings.ReplaceAll(name, " ", "")), nil
+}
+
+// resetStackFilesDir removes the generated files of a previous run of the named stack and
+// recreates its private directory, readable only by the current user
+func resetStackFilesDir() error {
+	dir, err := stackFilesDir()
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove %s; %w", dir, err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s; %w", dir, err)
+	}
+	return os.Chmod(dir, 0700)
+}
+
+// listContainers returns every container belonging to the named stack; containers are
+// selected by label, and by name when created by a version of prvd which predates labels
+func listContainers(docker *client.Client) ([]types.Container, error) {
 	containers, err := docker.ContainerList(context.Background(), types.ContainerListOptions{
-		All: true,
-		Filters: filters.NewArgs([]filters.KeyValuePair{
-			{
-				Key:   "name",
-				Value: fmt.Sprintf("%s-api", strings.ReplaceAll(name, " ", "")),
-			},
-			{
-				Key:   "name",
-				Value: fmt.Sprintf("%s-consumer", strings.ReplaceAll(name, " ", "")),
-			},
-			{
-				Key:   "name",
-				Value: fmt.Sprintf("%s-nats", strings.ReplaceAll(name, " ", "")),
-			},
-			{
-				Key:   "name",
-				Value: fmt.Sprintf("%s-nats-streaming", strings.ReplaceAll(name, " ", "")),
-			},
-			{
-				Key:   "name",
-				Value: fmt.Sprintf("%s-redis", strings.ReplaceAll(name, " ", "")),
-			},
-		}...),
+		All:     true,
+		Filters: stackFilter(),
 	})
 	if err != nil {
-		log.Printf("failed to list containers; %s", err.Error())
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to list containers; %w", err)
 	}
 
-	return containers
+	legacyFilters := make([]filters.KeyValuePair, 0)
+	for _, service := range legacyStackServices {
+		legacyFilters = append(legacyFilters, filters.KeyValuePair{
+			Key:   "name",
+			Value: containerName(service),
+		})
+	}
+
+	legacyContainers, err := docker.ContainerList(context.Background(), types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(legacyFilters...),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers; %w", err)
+	}
+
+	listed := map[string]bool{}
+	for _, container := range containers {
+		listed[container.ID] = true
+	}
+	for _, container := range legacyContainers {
+		if !listed[container.ID] && container.Labels[stackLabel] == "" && isLegacyContainer(container) {
+			containers = append(containers, container)
+		}
+	}
+
+	return containers, nil
 }
 
-func organizationAuthPrompt() {
+func organizationAuthPrompt() error {
+	if !common.IsInteractive() {
+		return nil
+	}
+
+	target := common.OrganizationID
+	if common.Organization != nil && common.Organization.Name != nil {
+		target = *common.Organization.Name
+	}
+
 	prompt := promptui.Prompt{
 		IsConfirm: true,
-		Label:     fmt.Sprintf("Authorize access/refresh token for %s?", *common.Organization.Name),
+		Label:     fmt.Sprintf("Authorize access/refresh token for %s?", target),
 	}
 
 	result, err := prompt.Run()
 	if err != nil {
-		os.Exit(1)
-		return
+		return err
 	}
 
 	if strings.ToLower(result) == "y" {
-		common.AuthorizeOrganizationContext(true)
+		return common.AuthorizeOrganizationContext(true)
 	}
+	return nil
 }
 
-func tunnelAPIPrompt() {
-	if common.ExposeAPITunnel {
-		return
+func tunnelAPIPrompt() error {
+	if common.ExposeAPITunnel || !common.IsInteractive() {
+		return nil
 	}
 
 	prompt := promptui.Prompt{
@@ -1156,19 +807,19 @@ func tunnelAPIPrompt() {
 	}
 
 	result, err := prompt.Run()
-	if err != nil {
-		os.Exit(1)
-		return
+	if err != nil && err != promptui.ErrAbort {
+		return fmt.Errorf("prompt failed: Expose tunnel for the local API?; %w", err)
 	}
 
 	if strings.ToLower(result) == "y" {
 		common.ExposeAPITunnel = true
 	}
+	return nil
 }
 
-func tunnelMessagingPrompt() {
-	if common.ExposeMessagingTunnel {
-		return
+func tunnelMessagingPrompt() error {
+	if common.ExposeMessagingTunnel || !common.IsInteractive() {
+		return nil
 	}
 
 	prompt := promptui.Prompt{
@@ -1177,136 +828,146 @@ func tunnelMessagingPrompt() {
 	}
 
 	result, err := prompt.Run()
-	if err != nil {
-		os.Exit(1)
-		return
+	if err != nil && err != promptui.ErrAbort {
+		return fmt.Errorf("prompt failed: Expose tunnel for the local messaging endpoint?; %w", err)
 	}
 
 	if strings.ToLower(result) == "y" {
 		common.ExposeMessagingTunnel = true
 	}
+	return nil
 }
 
-func sorPrompt() {
-	if sorID != "" {
-		return
+func sorPrompt() error {
+	if sorID == "" && mockSOR != "" {
+		// the mock system of record stands in for the primary system of record
+		sorID = mockSOR
 	}
-
-	items := map[string]string{
-		"Dynamics365":           "dynamics365",
-		"Ephemeral (In-Memory)": "ephemeral",
-		"Excel":                 "excel",
-		"Salesforce":            "salesforce",
-		"SAP":                   "sap",
-		"ServiceNow":            "servicenow",
+	if sorID != "" {
+		return nil
 	}
 
+	items := map[string]string{}
 	opts := make([]string, 0)
-	for k := range items {
-		opts = append(opts, k)
+	for _, sor := range systemsOfRecord() {
+		items[sor.Name()] = sor.ID()
+		opts = append(opts, sor.Name())
 	}
 	sort.Strings(opts)
 
+	if !common.IsInteractive() {
+		_, err := common.RequireSelectInput("--sor", opts, "")
+		return err
+	}
+
 	prmpt := promptui.Select{
 		Label: "What is your primary system of record?",
 		Items: opts,
 	}
 
-	_, result, _ := prmpt.Run()
+	_, result, err := prmpt.Run()
+	if err != nil {
+		return fmt.Errorf("prompt failed: %s; %w", prmpt.Label, err)
+	}
 	sorID = items[result]
 
-	switch sorID {
-	case "ephemeral":
-		// no-op
-	default:
-		sorURLPrompt()
+	if sor := lookupSystemOfRecord(sorID); sor != nil {
+		return sor.Prompt()
 	}
+	return nil
 }
 
-func sorURLPrompt() {
+func sorURLPrompt() error {
 	if sorURL != "" {
-		return
+		return nil
 	}
 
-	prompt := promptui.Prompt{
-		Label: "What is the API endpoint for your primary system of record?",
-	}
-
-	result, err := prompt.Run()
-	if err != nil {
-		os.Exit(1)
-		return
-	}
-
-	sorURL = result
+	var err error
+	sorURL, err = common.RequireFreeInput("--sor-url", "What is the API endpoint for your primary system of record?", "", common.NoValidation)
+	return err
 }
 
 func init() {
-	runBaselineStackCmd.Flags().StringVar(&name, "name", "baseline-local", "name of the baseline stack instance")
+	runBaselineStackCmd.Flags().StringVarP(&runStackDefinitionPath, "file", "f", "", "path of a stack definition file; flags override the values in the file")
+	runBaselineStackCmd.Flags().StringVar(&imageLockPath, "lockfile", defaultImageLockPath, "path of the image lockfile; when present, the locked container images are run")
+	addStackRunFlags(runBaselineStackCmd.Flags())
+	runBaselineStackCmd.Flags().BoolVar(&autoPorts, "auto-ports", false, "when true, a free host port is allocated for each service whose host port is in use")
+	runBaselineStackCmd.Flags().DurationVar(&startupTimeout, "startup-timeout", defaultStartupTimeout, "maximum time to wait for all services to report healthy before the stack is rolled back")
+	runBaselineStackCmd.Flags().BoolVarP(&Optional, "optionalStack", "", false, "List all the optional flags")
+}
+
+// addStackRunFlags registers the flags which configure the services of the stack; these
+// are shared by the commands which run or render the stack
+func addStackRunFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&name, "name", "baseline-local", "name of the baseline stack instance")
 
-	runBaselineStackCmd.Flags().StringVar(&common.OrganizationID, "organization", os.Getenv("PROVIDE_ORGANIZATION_ID"), "organization identifier")
+	flags.StringVar(&common.OrganizationID, "organization", os.Getenv("PROVIDE_ORGANIZATION_ID"), "organization identifier")
 	// runBaselineStackCmd.MarkFlagRequired("organization")
 
-	runBaselineStackCmd.Flags().StringVar(&common.APIEndpoint, "api-endpoint", "", "local baseline API endpoint for use by one or more authorized systems of record")
-	runBaselineStackCmd.Flags().StringVar(&common.MessagingEndpoint, "messaging-endpoint", "", "public messaging endpoint used for sending and receiving protocol messages")
-	runBaselineStackCmd.Flags().BoolVar(&common.Tunnel, "tunnel", false, "when true, a tunnel is established to expose the API and messaging endpoints to the WAN")
-	runBaselineStackCmd.Flags().BoolVar(&common.ExposeAPITunnel, "api-tunnel", false, "when true, a tunnel is established to expose the API endpoint to the WAN")
-	runBaselineStackCmd.Flags().BoolVar(&common.ExposeMessagingTunnel, "messaging-tunnel", false, "when true, a tunnel is established to expose the messaging endpoint to the WAN")
+	flags.StringVar(&common.APIEndpoint, "api-endpoint", "", "local baseline API endpoint for use by one or more authorized systems of record")
+	flags.StringVar(&common.MessagingEndpoint, "messaging-endpoint", "", "public messaging endpoint used for sending and receiving protocol messages")
+	flags.BoolVar(&common.Tunnel, "tunnel", false, "when true, a tunnel is established to expose the API and messaging endpoints to the WAN using the tunnel-provider of the prvd configuration")
+	flags.BoolVar(&common.ExposeAPITunnel, "api-tunnel", false, "when true, a tunnel is established to expose the API endpoint to the WAN")
+	flags.BoolVar(&common.ExposeMessagingTunnel, "messaging-tunnel", false, "when true, a tunnel is established to expose the messaging endpoint to the WAN")
 
-	runBaselineStackCmd.Flags().StringVar(&sorID, "sor", "", "primary internal system of record identifier being baselined")
-	runBaselineStackCmd.Flags().StringVar(&sorURL, "sor-url", "https://", "url of the primary internal system of record being baselined")
+	flags.StringVar(&sorID, "sor", "", "primary internal system of record identifier being baselined")
+	flags.StringVar(&sorURL, "sor-url", defaultSORURL, "url of the primary internal system of record being baselined")
 
-	runBaselineStackCmd.Flags().StringVar(&apiHostname, "hostname", fmt.Sprintf("%s-api", name), "hostname for the local baseline API container")
-	runBaselineStackCmd.Flags().IntVar(&port, "port", 8080, "host port on which to expose the local baseline API service")
+	flags.StringVar(&apiHostname, "hostname", fmt.Sprintf("%s-api", name), "hostname for the local baseline API container")
+	flags.IntVar(&port, "port", 8080, "host port on which to expose the local baseline API service")
 
-	runBaselineStackCmd.Flags().StringVar(&consumerHostname, "consumer-hostname", fmt.Sprintf("%s-consumer", name), "hostname for the local baseline consumer container")
-	runBaselineStackCmd.Flags().StringVar(&natsHostname, "nats-hostname", fmt.Sprintf("%s-nats", name), "hostname for the local baseline NATS container")
-	runBaselineStackCmd.Flags().IntVar(&natsPort, "nats-port", 4222, "host port on which to expose the local NATS service")
-	runBaselineStackCmd.Flags().IntVar(&natsWebsocketPort, "nats-ws-port", 4221, "host port on which to expose the local NATS websocket service")
-	runBaselineStackCmd.Flags().StringVar(&natsAuthToken, "nats-auth-token", "testtoken", "authorization token for the local baseline NATS service; will be passed as the -auth argument to NATS")
+	flags.StringVar(&consumerHostname, "consumer-hostname", fmt.Sprintf("%s-consumer", name), "hostname for the local baseline consumer container")
+	flags.StringVar(&natsHostname, "nats-hostname", fmt.Sprintf("%s-nats", name), "hostname for the local baseline NATS container")
+	flags.IntVar(&natsPort, "nats-port", 4222, "host port on which to expose the local NATS service")
+	flags.IntVar(&natsWebsocketPort, "nats-ws-port", 4221, "host port on which to expose the local NATS websocket service")
+	flags.StringVar(&natsAuthToken, "nats-auth-token", "", "authorization token for the local baseline NATS services; a random token is generated by default")
+	flags.BoolVar(&natsTLS, "nats-tls", true, "when true, the local baseline NATS services require TLS")
+	flags.StringVar(&natsTLSCertificateFile, "nats-tls-cert", "", "path of the PEM-encoded NATS server certificate; a certificate is generated for the stack by default")
+	flags.StringVar(&natsTLSKeyFile, "nats-tls-key", "", "path of the PEM-encoded private key of the NATS server certificate")
+	flags.StringVar(&natsTLSCAFile, "nats-tls-ca", "", "path of the PEM-encoded CA certificate which issued the NATS server certificate; defaults to the certificate itself")
 
-	runBaselineStackCmd.Flags().StringVar(&natsStreamingHostname, "nats-streaming-hostname", fmt.Sprintf("%s-nats-streaming", name), "hostname for the local baseline NATS streaming container")
-	runBaselineStackCmd.Flags().IntVar(&natsStreamingPort, "nats-streaming-port", 4220, "host port on which to expose the local NATS streaming service")
+	flags.StringVar(&natsStreamingHostname, "nats-streaming-hostname", fmt.Sprintf("%s-nats-streaming", name), "hostname for the local baseline NATS streaming container")
+	flags.IntVar(&natsStreamingPort, "nats-streaming-port", 4220, "host port on which to expose the local NATS streaming service")
 
-	runBaselineStackCmd.Flags().StringVar(&postgresHostname, "postgres-hostname", fmt.Sprintf("%s-postgres", name), "hostname for the local postgres container")
-	runBaselineStackCmd.Flags().IntVar(&postgresPort, "postgres-port", 5432, "host port on which to expose the local postgres service")
+	flags.StringVar(&postgresHostname, "postgres-hostname", fmt.Sprintf("%s-postgres", name), "hostname for the local postgres container")
+	flags.IntVar(&postgresPort, "postgres-port", 5432, "host port on which to expose the local postgres service")
 
-	runBaselineStackCmd.Flags().StringVar(&redisHostname, "redis-hostname", fmt.Sprintf("%s-redis", name), "hostname for the local baseline redis container")
-	runBaselineStackCmd.Flags().IntVar(&redisPort, "redis-port", 6379, "host port on which to expose the local redis service")
-	runBaselineStackCmd.Flags().StringVar(&redisHosts, "redis-hosts", fmt.Sprintf("%s:%d", redisHostname, redisContainerPort), "list of clustered redis hosts in the local baseline stack")
+	flags.StringVar(&redisHostname, "redis-hostname", fmt.Sprintf("%s-redis", name), "hostname for the local baseline redis container")
+	flags.IntVar(&redisPort, "redis-port", 6379, "host port on which to expose the local redis service")
+	flags.StringVar(&redisHosts, "redis-hosts", fmt.Sprintf("%s:%d", redisHostname, redisContainerPort), "list of clustered redis hosts in the local baseline stack")
 
-	runBaselineStackCmd.Flags().BoolVar(&autoRemove, "autoremove", false, "when true, containers are automatically pruned upon exit")
-	runBaselineStackCmd.Flags().StringVar(&logLevel, "log-level", "DEBUG", "log level to set within the running local baseline stack")
+	flags.BoolVar(&autoRemove, "autoremove", false, "when true, containers are automatically pruned upon exit")
+	flags.StringVar(&logLevel, "log-level", "DEBUG", "log level to set within the running local baseline stack")
 
-	runBaselineStackCmd.Flags().StringVar(&jwtSignerPublicKey, "jwt-signer-public-key", "", "PEM-encoded public key of the authorized JWT signer for verifying inbound connection attempts")
+	flags.StringVar(&jwtSignerPublicKey, "jwt-signer-public-key", "", "PEM-encoded public key of the authorized JWT signer for verifying inbound connection attempts")
 
-	runBaselineStackCmd.Flags().StringVar(&identAPIHost, "ident-host", "ident.provide.services", "hostname of the ident service")
-	runBaselineStackCmd.Flags().StringVar(&identAPIScheme, "ident-scheme", "https", "protocol scheme of the ident service")
+	flags.StringVar(&identAPIHost, "ident-host", "ident.provide.services", "hostname of the ident service")
+	flags.StringVar(&identAPIScheme, "ident-scheme", "https", "protocol scheme of the ident service")
 
-	runBaselineStackCmd.Flags().StringVar(&nchainAPIHost, "nchain-host", "nchain.provide.services", "hostname of the nchain service")
-	runBaselineStackCmd.Flags().StringVar(&nchainAPIScheme, "nchain-scheme", "https", "protocol scheme of the nchain service")
+	flags.StringVar(&nchainAPIHost, "nchain-host", "nchain.provide.services", "hostname of the nchain service")
+	flags.StringVar(&nchainAPIScheme, "nchain-scheme", "https", "protocol scheme of the nchain service")
 
-	runBaselineStackCmd.Flags().StringVar(&privacyAPIHost, "privacy-host", "privacy.provide.services", "hostname of the privacy service")
-	runBaselineStackCmd.Flags().StringVar(&privacyAPIScheme, "privacy-scheme", "https", "protocol scheme of the privacy service")
+	flags.StringVar(&privacyAPIHost, "privacy-host", "privacy.provide.services", "hostname of the privacy service")
+	flags.StringVar(&privacyAPIScheme, "privacy-scheme", "https", "protocol scheme of the privacy service")
 
-	runBaselineStackCmd.Flags().StringVar(&vaultAPIHost, "vault-host", "vault.provide.services", "hostname of the vault service")
-	runBaselineStackCmd.Flags().StringVar(&vaultAPIScheme, "vault-scheme", "https", "protocol scheme of the vault service")
-	runBaselineStackCmd.Flags().StringVar(&vaultRefreshToken, "vault-refresh-token", os.Getenv("VAULT_REFRESH_TOKEN"), "refresh token to vend access tokens for use with vault")
-	runBaselineStackCmd.Flags().StringVar(&vaultSealUnsealKey, "vault-seal-unseal-key", os.Getenv("VAULT_SEAL_UNSEAL_KEY"), "seal/unseal key for the vault service")
+	flags.StringVar(&vaultAPIHost, "vault-host", "vault.provide.services", "hostname of the vault service")
+	flags.StringVar(&vaultAPIScheme, "vault-scheme", "https", "protocol scheme of the vault service")
+	flags.StringVar(&vaultRefreshToken, "vault-refresh-token", os.Getenv("VAULT_REFRESH_TOKEN"), "refresh token to vend access tokens for use with vault")
+	flags.StringVar(&vaultSealUnsealKey, "vault-seal-unseal-key", os.Getenv("VAULT_SEAL_UNSEAL_KEY"), "seal/unseal key for the vault service")
 
-	runBaselineStackCmd.Flags().BoolVar(&withLocalIdent, "with-local-ident", false, "when true, ident service is run locally")
-	runBaselineStackCmd.Flags().IntVar(&identPort, "ident-local-port", 8081, "port for the local ident service")
+	flags.BoolVar(&withLocalIdent, "with-local-ident", false, "when true, ident service is run locally")
+	flags.IntVar(&identPort, "ident-local-port", 8081, "port for the local ident service")
 
-	runBaselineStackCmd.Flags().BoolVar(&withLocalNChain, "with-local-nchain", false, "when true, nchain service is run locally")
-	runBaselineStackCmd.Flags().IntVar(&nchainPort, "nchain-local-port", 8082, "port for the local nchain service")
+	flags.BoolVar(&withLocalNChain, "with-local-nchain", false, "when true, nchain service is run locally")
+	flags.IntVar(&nchainPort, "nchain-local-port", 8082, "port for the local nchain service")
 
-	runBaselineStackCmd.Flags().BoolVar(&withLocalPrivacy, "with-local-privacy", false, "when true, privacy service is run locally")
-	runBaselineStackCmd.Flags().IntVar(&privacyPort, "privacy-local-port", 8083, "port for the local privacy service")
+	flags.BoolVar(&withLocalPrivacy, "with-local-privacy", false, "when true, privacy service is run locally")
+	flags.IntVar(&privacyPort, "privacy-local-port", 8083, "port for the local privacy service")
 
-	runBaselineStackCmd.Flags().BoolVar(&withLocalVault, "with-local-vault", false, "when true, vault service is run locally")
-	runBaselineStackCmd.Flags().IntVar(&vaultPort, "vault-local-port", 8084, "port for the local vault service")
+	flags.BoolVar(&withLocalVault, "with-local-vault", false, "when true, vault service is run locally")
+	flags.IntVar(&vaultPort, "vault-local-port", 8084, "port for the local vault service")
 
-	runBaselineStackCmd.Flags().StringVar(&organizationRefreshToken, "organization-refresh-token", os.Getenv("PROVIDE_ORGANIZATION_REFRESH_TOKEN"), "refresh token to vend access tokens for use with the local organization")
+	flags.StringVar(&organizationRefreshToken, "organization-refresh-token", os.Getenv("PROVIDE_ORGANIZATION_REFRESH_TOKEN"), "refresh token to vend access tokens for use with the local organization")
 
 	defaultBaselineOrganizationAddress := "0x"
 	if os.Getenv("BASELINE_ORGANIZATION_ADDRESS") != "" {
@@ -1323,30 +984,15 @@ func init() {
 	// 	defaultNChainBaselineNetworkID = os.Getenv("NCHAIN_BASELINE_NETWORK_ID")
 	// }
 
-	runBaselineStackCmd.Flags().StringVar(&baselineOrganizationAddress, "organization-address", defaultBaselineOrganizationAddress, "public baseline regsitry address of the organization")
-	runBaselineStackCmd.Flags().StringVar(&baselineRegistryContractAddress, "registry-contract-address", defaultBaselineRegistryContractAddress, "public baseline regsitry contract address")
-	runBaselineStackCmd.Flags().StringVar(&baselineWorkgroupID, "workgroup", "", "baseline workgroup identifier")
+	flags.StringVar(&baselineOrganizationAddress, "organization-address", defaultBaselineOrganizationAddress, "public baseline regsitry address of the organization")
+	flags.StringVar(&baselineRegistryContractAddress, "registry-contract-address", defaultBaselineRegistryContractAddress, "public baseline regsitry contract address")
+	flags.StringVar(&baselineWorkgroupID, "workgroup", "", "baseline workgroup identifier")
 
-	runBaselineStackCmd.Flags().StringVar(&nchainBaselineNetworkID, "nchain-network-id", "", "nchain network id of the baseline mainnet")
+	flags.StringVar(&nchainBaselineNetworkID, "nchain-network-id", "", "nchain network id of the baseline mainnet")
 
-	initSORFlags()
-}
+	addSORFlags(flags)
 
-func initSORFlags() {
-	runBaselineStackCmd.Flags().StringVar(&salesforceAPIHost, "salesforce-api-host", "", "hostname of the Salesforce API service")
-	runBaselineStackCmd.Flags().StringVar(&salesforceAPIScheme, "salesforce-api-scheme", "https", "protocol scheme of the Salesforce API service")
-	runBaselineStackCmd.Flags().StringVar(&salesforceAPIPath, "salesforce-api-path", "", "base path of the Salesforce API service")
-
-	runBaselineStackCmd.Flags().StringVar(&sapAPIHost, "sap-api-host", "", "hostname of the internal SAP API service")
-	runBaselineStackCmd.Flags().StringVar(&sapAPIScheme, "sap-api-scheme", "https", "protocol scheme of the internal SAP API service")
-	runBaselineStackCmd.Flags().StringVar(&sapAPIPath, "sap-api-path", "ubc", "base path of the SAP API service")
-	runBaselineStackCmd.Flags().StringVar(&sapAPIUsername, "sap-api-username", "", "username to use for basic authorization against the SAP API service")
-	runBaselineStackCmd.Flags().StringVar(&sapAPIPassword, "sap-api-password", "", "password to use for basic authorization against the SAP API service")
-
-	runBaselineStackCmd.Flags().StringVar(&serviceNowAPIHost, "servicenow-api-host", "", "hostname of the ServiceNow service")
-	runBaselineStackCmd.Flags().StringVar(&serviceNowAPIScheme, "servicenow-api-scheme", "https", "protocol scheme of the ServiceNow service")
-	runBaselineStackCmd.Flags().StringVar(&serviceNowAPIPath, "servicenow-api-path", "api/now/table", "base path of the ServiceNow API")
-	runBaselineStackCmd.Flags().StringVar(&serviceNowAPIUsername, "servicenow-api-username", "", "username to use for basic authorization against the ServiceNow API")
-	runBaselineStackCmd.Flags().StringVar(&serviceNowAPIPassword, "servicenow-api-password", "", "password to use for basic authorization against the ServiceNow API")
-	runBaselineStackCmd.Flags().BoolVarP(&Optional, "optionalStack", "", false, "List all the optional flags")
+	flags.StringVar(&mockSOR, "mock-sor", "", "when set, a mock system of record is run as part of the stack in place of the system of record API; one of salesforce, sap or servicenow; it is served by the provide/prvd image tagged with the version of prvd, which is pulled unless present, and must be built using 'make docker' for an unreleased version")
+	flags.IntVar(&mockSORPort, "mock-sor-port", 8085, "host port on which to expose the mock system of record")
+	flags.StringVar(&mockSORFixturesDir, "mock-sor-fixtures", "", "directory of JSON business object fixtures which the mock system of record pushes using 'prvd baseline stack mock-sor push'")
 }
@@ -0,0 +1,357 @@
+package stack
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+)
+
+// services of the stack; each is run in its own container named <name>-<service>
+const (
+	serviceAPI                = "api"
+	serviceConsumer           = "consumer"
+	serviceIdentAPI           = "ident-api"
+	serviceIdentConsumer      = "ident-consumer"
+	serviceNChainAPI          = "nchain-api"
+	serviceNChainConsumer     = "nchain-consumer"
+	serviceStatsdaemon        = "statsdaemon"
+	serviceReachabilitydaemon = "reachabilitydaemon"
+	servicePrivacyAPI         = "privacy-api"
+	servicePrivacyConsumer    = "privacy-consumer"
+	serviceVaultAPI           = "vault-api"
+	serviceNATS               = "nats"
+	serviceNATSStreaming      = "nats-streaming"
+	servicePostgres           = "postgres"
+	serviceRedis              = "redis"
+	serviceMockSOR            = "mock-sor"
+)
+
+// stackServiceNames are the names of all services the stack may run
+var stackServiceNames = []string{
+	serviceAPI,
+	serviceConsumer,
+	serviceIdentAPI,
+	serviceIdentConsumer,
+	serviceNChainAPI,
+	serviceNChainConsumer,
+	serviceStatsdaemon,
+	serviceReachabilitydaemon,
+	servicePrivacyAPI,
+	servicePrivacyConsumer,
+	serviceVaultAPI,
+	serviceNATS,
+	serviceNATSStreaming,
+	servicePostgres,
+	serviceRedis,
+	serviceMockSOR,
+}
+
+// container healthcheck settings common to all services; the interval bounds how quickly
+// each service is reported healthy, and therefore how quickly dependent services start
+const healthcheckInterval = time.Second * 5
+const healthcheckRetries = 2
+const healthcheckStartPeriod = time.Second * 10
+const healthcheckTimeout = time.Second * 1
+
+// paths at which the stateful services persist their data
+const natsStreamingDataPath = "/data/nats-streaming"
+const postgresDataPath = "/var/lib/postgresql/data"
+const redisDataPath = "/data"
+
+// postgresUser is the superuser of the local postgres service
+const postgresUser = "prvd"
+
+// serviceSpec describes a single container of the stack; the same model is used to
+// run the stack and to export it for deployment without prvd
+type serviceSpec struct {
+	service     string // i.e., api
+	description string // i.e., local baseline API
+	hostname    string
+	image       string // image reference, including the tag
+	entrypoint  []string
+	cmd         []string
+	healthcheck []string
+	files       map[string]string // generated files, mounted read-only; keyed by container path
+	ports       []portMapping
+	volumes     []volumeMapping
+	dependsOn   []string // services which must be healthy before the service is started
+}
+
+// volumeMapping mounts a named volume of the stack; the data in the volume outlives the container
+type volumeMapping struct {
+	volume string // i.e., postgres-data; the docker volume is named <name>-<volume>
+	target string
+}
+
+// stackServices returns the services of the stack as configured by the current flags,
+// dependencies first
+func stackServices() ([]*serviceSpec, error) {
+	withPostgres := withLocalIdent || withLocalNChain || withLocalPrivacy || withLocalVault
+	deps := []string{serviceNATS, serviceNATSStreaming, serviceRedis}
+	if withPostgres {
+		deps = append(deps, servicePostgres)
+	}
+
+	services := []*serviceSpec{
+		natsService(),
+		natsStreamingService(),
+		redisService(),
+	}
+	if withPostgres {
+		services = append(services, postgresService())
+	}
+
+	apiDeps := append([]string{}, deps...)
+
+	if withLocalIdent {
+		image, err := resolveImage(identContainerImage)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services,
+			providerAPIService(serviceIdentAPI, "local ident API", identHostname, image, identPort, "ident-local-port", deps),
+			providerDaemonService(serviceIdentConsumer, "local ident consumer", identConsumerHostname, image, "./ops/run_consumer.sh", "consumer", serviceIdentAPI),
+		)
+		apiDeps = append(apiDeps, serviceIdentAPI)
+	}
+
+	if withLocalNChain {
+		image, err := resolveImage(nchainContainerImage)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services,
+			providerAPIService(serviceNChainAPI, "local nchain API", nchainHostname, image, nchainPort, "nchain-local-port", deps),
+			providerDaemonService(serviceNChainConsumer, "local nchain consumer", nchainConsumerHostname, image, "./ops/run_consumer.sh", "consumer", serviceNChainAPI),
+			providerDaemonService(serviceStatsdaemon, "local statsdaemon", nchainStatsdaemonHostname, image, "./ops/run_statsdaemon.sh", "statsdaemon", serviceNChainAPI),
+			providerDaemonService(serviceReachabilitydaemon, "local reachabilitydaemon", nchainReachabilitydaemonHostname, image, "./ops/run_reachabilitydaemon.sh", "reachabilitydaemon", serviceNChainAPI),
+		)
+		apiDeps = append(apiDeps, serviceNChainAPI)
+	}
+
+	if withLocalPrivacy {
+		image, err := resolveImage(privacyContainerImage)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services,
+			providerAPIService(servicePrivacyAPI, "local privacy API", privacyHostname, image, privacyPort, "privacy-local-port", deps),
+			providerDaemonService(servicePrivacyConsumer, "local privacy consumer", privacyConsumerHostname, image, "./ops/run_consumer.sh", "consumer", servicePrivacyAPI),
+		)
+		apiDeps = append(apiDeps, servicePrivacyAPI)
+	}
+
+	if withLocalVault {
+		image, err := resolveImage(vaultContainerImage)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, providerAPIService(serviceVaultAPI, "local vault API", vaultHostname, image, vaultPort, "vault-local-port", []string{servicePostgres}))
+		apiDeps = append(apiDeps, serviceVaultAPI)
+	}
+
+	if mockSOR != "" {
+		mock, err := mockSORService()
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, mock)
+		apiDeps = append(apiDeps, serviceMockSOR)
+	}
+
+	services = append(services, &serviceSpec{
+		service:     serviceAPI,
+		description: "local baseline API",
+		hostname:    apiHostname,
+		image:       baselineContainerImage,
+		entrypoint:  []string{"./ops/run_api.sh"},
+		healthcheck: []string{"CMD", "curl", "-f", fmt.Sprintf("http://%s:%d/status", apiHostname, apiContainerPort)},
+		ports: []portMapping{{
+			hostPort:      port,
+			containerPort: apiContainerPort,
+			flag:          "port",
+		}},
+		dependsOn: apiDeps,
+	}, &serviceSpec{
+		service:     serviceConsumer,
+		description: "local baseline consumer",
+		hostname:    consumerHostname,
+		image:       baselineContainerImage,
+		entrypoint:  []string{"./ops/run_consumer.sh"},
+		healthcheck: processHealthcheck("consumer"),
+		dependsOn:   []string{serviceAPI},
+	})
+
+	// the NATS clients of the stack verify the NATS server certificate using the CA
+	if natsTLS {
+		for _, spec := range services {
+			if !containsString([]string{servicePostgres, serviceRedis, serviceNATS, serviceNATSStreaming, serviceMockSOR}, spec.service) {
+				spec.files = withFile(spec.files, natsCACertificatePath, natsCACertificate)
+			}
+		}
+	}
+
+	return services, nil
+}
+
+// secretFiles are the container paths of the generated files which hold credentials, i.e., the NATS
+// auth token or TLS key; the other generated files are readable by services which run as any user
+var secretFiles = []string{natsConfigPath, natsStreamingConfigPath, natsTLSKeyPath}
+
+// withFile returns the given generated files with the given file added
+func withFile(files map[string]string, target, content string) map[string]string {
+	if files == nil {
+		files = map[string]string{}
+	}
+	files[target] = content
+	return files
+}
+
+// resolveImage returns the reference of the given Provide image; the version is pinned
+// by the release manifest when run from the root of a Provide release
+func resolveImage(image string) (string, error) {
+	version := "latest"
+	if common.IsReleaseContext() {
+		v, err := common.Manifest.GetImageVersion(image)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve version for pinned container image: %s; %w", image, err)
+		}
+		version = *v
+	}
+	return fmt.Sprintf("%s:%s", image, version), nil
+}
+
+// providerAPIService returns the API service of a locally-run Provide service
+func providerAPIService(service, description, hostname, image string, hostPort int, flag string, dependsOn []string) *serviceSpec {
+	return &serviceSpec{
+		service:     service,
+		description: description,
+		hostname:    hostname,
+		image:       image,
+		entrypoint:  []string{"./ops/run_api.sh"},
+		healthcheck: []string{"CMD", "curl", "-f", fmt.Sprintf("http://%s:%d/status", hostname, apiContainerPort)},
+		ports: []portMapping{{
+			hostPort:      hostPort,
+			containerPort: apiContainerPort,
+			flag:          flag,
+		}},
+		dependsOn: dependsOn,
+	}
+}
+
+// providerDaemonService returns a consumer or daemon of a locally-run Provide service, which
+// runs the given binary of the image of the service
+func providerDaemonService(service, description, hostname, image, entrypoint, binary, api string) *serviceSpec {
+	return &serviceSpec{
+		service:     service,
+		description: description,
+		hostname:    hostname,
+		image:       image,
+		entrypoint:  []string{entrypoint},
+		healthcheck: processHealthcheck(binary),
+		dependsOn:   []string{api},
+	}
+}
+
+// processHealthcheck returns the healthcheck of a consumer or daemon, which serves no endpoint;
+// it is healthy while the given binary of its image, i.e., ./.bin/consumer, is running
+func processHealthcheck(binary string) []string {
+	return []string{"CMD", "pgrep", "-f", fmt.Sprintf("/.bin/%s", binary)}
+}
+
+func natsService() *serviceSpec {
+	return &serviceSpec{
+		service:     serviceNATS,
+		description: "local baseline NATS",
+		hostname:    natsHostname,
+		image:       natsContainerImage,
+		cmd: []string{
+			"--config", natsConfigPath,
+			"-DVV",
+		},
+		healthcheck: []string{"CMD", "/usr/local/bin/await_tcp.sh", fmt.Sprintf("localhost:%d", natsContainerPort)},
+		files:       withFile(natsTLSFiles(), natsConfigPath, natsConfig()),
+		ports: []portMapping{
+			{
+				hostPort:      natsPort,
+				containerPort: natsContainerPort,
+				flag:          "nats-port",
+			},
+			{
+				hostPort:      natsWebsocketPort,
+				containerPort: natsWebsocketContainerPort,
+				flag:          "nats-ws-port",
+			},
+		},
+		volumes: []volumeMapping{{
+			volume: "nats-jetstream-data",
+			target: natsJetStreamDataPath,
+		}},
+	}
+}
+
+func natsStreamingService() *serviceSpec {
+	return &serviceSpec{
+		service:     serviceNATSStreaming,
+		description: "local baseline NATS streaming",
+		hostname:    natsStreamingHostname,
+		image:       natsStreamingContainerImage,
+		cmd: []string{
+			"--cluster_id", defaultNATSStreamingClusterID,
+			"--config", natsStreamingConfigPath,
+			"--store", "file",
+			"--dir", natsStreamingDataPath,
+			"-SDV",
+		},
+		healthcheck: []string{"CMD", "/usr/local/bin/await_tcp.sh", fmt.Sprintf("localhost:%d", natsStreamingContainerPort)},
+		files:       withFile(natsTLSFiles(), natsStreamingConfigPath, natsStreamingConfig()),
+		ports: []portMapping{{
+			hostPort:      natsStreamingPort,
+			containerPort: natsStreamingContainerPort,
+			flag:          "nats-streaming-port",
+		}},
+		volumes: []volumeMapping{{
+			volume: "nats-streaming-data",
+			target: natsStreamingDataPath,
+		}},
+	}
+}
+
+func postgresService() *serviceSpec {
+	return &serviceSpec{
+		service:     servicePostgres,
+		description: "local postgres",
+		hostname:    postgresHostname,
+		image:       postgresContainerImage,
+		healthcheck: []string{"CMD", "pg_isready", "-U", postgresUser, "-d", postgresUser},
+		ports: []portMapping{{
+			hostPort:      postgresPort,
+			containerPort: postgresContainerPort,
+			flag:          "postgres-port",
+		}},
+		volumes: []volumeMapping{{
+			volume: "postgres-data",
+			target: postgresDataPath,
+		}},
+	}
+}
+
+func redisService() *serviceSpec {
+	return &serviceSpec{
+		service:     serviceRedis,
+		description: "local baseline redis",
+		hostname:    redisHostname,
+		image:       redisContainerImage,
+		healthcheck: []string{"CMD", "redis-cli", "ping"},
+		ports: []portMapping{{
+			hostPort:      redisPort,
+			containerPort: redisContainerPort,
+			flag:          "redis-port",
+		}},
+		volumes: []volumeMapping{{
+			volume: "redis-data",
+			target: redisDataPath,
+		}},
+	}
+}
@@ -0,0 +1,61 @@
+package stack
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+)
+
+func TestDaemonHealthchecks(t *testing.T) {
+	withLocalIdent, withLocalNChain, withLocalPrivacy = true, true, true
+	defer func() {
+		withLocalIdent, withLocalNChain, withLocalPrivacy = false, false, false
+	}()
+
+	services, err := stackServices()
+	if err != nil {
+		t.Fatalf("stackServices() error = %v", err)
+	}
+
+	want := map[string]string{
+		serviceConsumer:           "consumer",
+		serviceIdentConsumer:      "consumer",
+		serviceNChainConsumer:     "consumer",
+		serviceStatsdaemon:        "statsdaemon",
+		serviceReachabilitydaemon: "reachabilitydaemon",
+		servicePrivacyConsumer:    "consumer",
+	}
+	for _, spec := range services {
+		binary, ok := want[spec.service]
+		if !ok {
+			continue
+		}
+		delete(want, spec.service)
+
+		if !reflect.DeepEqual(spec.healthcheck, processHealthcheck(binary)) {
+			t.Errorf("%s healthcheck = %q; want %q", spec.service, spec.healthcheck, processHealthcheck(binary))
+		}
+		for _, other := range services {
+			if other.hostname != "" && other.hostname != spec.hostname && strings.Contains(strings.Join(spec.healthcheck, " "), other.hostname) {
+				t.Errorf("%s healthcheck probes %s", spec.service, other.service)
+			}
+		}
+	}
+	for service := range want {
+		t.Errorf("%s not run", service)
+	}
+}
+
+func TestMockSORImage(t *testing.T) {
+	version := common.Version
+	common.Version = "1.2.3"
+	defer func() {
+		common.Version = version
+	}()
+
+	if image := mockSORImage(); image != "provide/prvd:1.2.3" {
+		t.Errorf("mockSORImage() = %s; want the image of the version of prvd", image)
+	}
+}
@@ -0,0 +1,299 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/provideservices/provide-cli/cmd/baseline/participants"
+	"github.com/provideservices/provide-cli/cmd/baseline/workgroups"
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/provideservices/provide-go/api/ident"
+
+	"github.com/spf13/cobra"
+)
+
+const defaultSimulationName = "baseline-sim"
+const defaultSimulationParties = 2
+const minSimulationParties = 2
+
+// simulationPortOffset is added to each host port of the stack for each subsequent party
+const simulationPortOffset = 10
+
+var simulationParties int
+
+var simulateBaselineStackCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Simulate a baseline workgroup of several parties",
+	Long: `Run a local baseline stack for each of several simulated parties.
+
+An organization is created for each party, and each party runs its own stack with its own vault, ports,
+network and NATS configuration. The first party initializes a workgroup which each subsequent party is
+invited to join. The API and NATS containers of each stack are attached to a shared network so the parties
+can exchange protocol messages. When a party fails to be simulated, the stacks of the parties, the shared
+network and the workgroup are removed.`,
+	RunE: simulateProxy,
+}
+
+// simulatedParty is a party of a simulated baseline workgroup
+type simulatedParty struct {
+	Name              string `json:"name"`
+	OrganizationID    string `json:"organization_id"`
+	Address           string `json:"address"`
+	APIEndpoint       string `json:"api_endpoint"`
+	MessagingEndpoint string `json:"messaging_endpoint"`
+}
+
+// simulation is a simulated baseline workgroup
+type simulation struct {
+	WorkgroupID string            `json:"workgroup_id"`
+	Parties     []*simulatedParty `json:"parties"`
+}
+
+func simulateProxy(cmd *cobra.Command, args []string) error {
+	if simulationParties < minSimulationParties {
+		return common.NewValidationError("invalid --parties: %d; at least %d parties are required", simulationParties, minSimulationParties)
+	}
+	if !cmd.Flags().Changed("name") {
+		name = defaultSimulationName
+	}
+	if err := requireImageLock(cmd); err != nil {
+		return err
+	}
+	return generalPrompt(cmd, args, promptStepSimulate)
+}
+
+func simulateProxyRun(cmd *cobra.Command, args []string) (err error) {
+	if startupTimeout <= 0 {
+		return common.NewValidationError("invalid --startup-timeout: %s; must be greater than zero", startupTimeout)
+	}
+	if err := validateNATSFlags(); err != nil {
+		return err
+	}
+	if err := validateMockSORFlags(); err != nil {
+		return err
+	}
+	if err := requireSystemOfRecord(); err != nil {
+		return err
+	}
+
+	docker, err := client.NewEnvClient()
+	if err != nil {
+		return fmt.Errorf("failed to initialize docker; %w", err)
+	}
+
+	userToken, err := common.RequireUserAccessToken()
+	if err != nil {
+		return err
+	}
+
+	prefix := name
+	partyNames := make([]string, 0)
+	for i := 1; i <= simulationParties; i++ {
+		partyNames = append(partyNames, fmt.Sprintf("%s-%d", prefix, i))
+	}
+
+	// the containers of a previous simulation are removed before the shared network
+	for _, partyName := range partyNames {
+		name = partyName
+		if err := purgeContainers(docker); err != nil {
+			return err
+		}
+		purgeNetwork(docker)
+	}
+
+	name = prefix
+	purgeNetwork(docker)
+	sharedNetwork, err := docker.NetworkCreate(context.Background(), prefix, types.NetworkCreate{
+		Driver: "bridge",
+		Labels: stackLabels(""),
+		IPAM:   &network.IPAM{},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to setup docker network; %w", err)
+	}
+	log.Printf("configured shared network for simulated baseline workgroup: %s", prefix)
+
+	// the resources created for the parties which were simulated are removed when a party fails
+	created := &simulationResources{network: prefix}
+	defer func() {
+		if err != nil {
+			err = created.rollback(docker, userToken, err)
+		}
+	}()
+
+	basePorts := map[*int]int{}
+	for _, p := range []*int{&port, &natsPort, &natsWebsocketPort, &natsStreamingPort, &postgresPort, &redisPort, &identPort, &nchainPort, &privacyPort, &vaultPort, &mockSORPort} {
+		basePorts[p] = *p
+	}
+	autoPorts = true
+
+	result := &simulation{
+		Parties: make([]*simulatedParty, 0),
+	}
+
+	var invitorOrganizationID string
+	for i, partyName := range partyNames {
+		log.Printf("simulating party %d of %d: %s", i+1, len(partyNames), partyName)
+
+		organization, err := ident.CreateOrganization(userToken, map[string]interface{}{
+			"name": partyName,
+		})
+		if err != nil {
+			return common.NewAPIError(err, "failed to create organization for %s", partyName)
+		}
+		created.organizationIDs = append(created.organizationIDs, organization.ID.String())
+
+		var inviteJWT string
+		var claims *workgroups.InviteClaims
+
+		if i == 0 {
+			selectSimulatedOrganization(organization.ID.String())
+			common.ApplicationID = ""
+			workgroup, err := workgroups.InitWorkgroup(prefix)
+			created.workgroupID = common.ApplicationID // the workgroup is created before it is fully initialized
+			if err != nil {
+				return err
+			}
+			invitorOrganizationID = organization.ID.String()
+			result.WorkgroupID = workgroup.ID.String()
+		} else {
+			selectSimulatedOrganization(invitorOrganizationID)
+			inviteJWT, err = participants.InviteParticipant(partyName, fmt.Sprintf("%s@baseline.local", partyName))
+			if err != nil {
+				return err
+			}
+
+			selectSimulatedOrganization(organization.ID.String())
+			claims, err = workgroups.JoinWorkgroup(inviteJWT)
+			if err != nil {
+				return err
+			}
+		}
+
+		name = partyName
+		baselineWorkgroupID = result.WorkgroupID
+		common.ApplicationID = result.WorkgroupID
+		organizationRefreshToken = common.OrganizationRefreshToken
+		vaultRefreshToken = common.OrganizationRefreshToken
+		jwtSignerPublicKey = ""
+		if !cmd.Flags().Changed("nats-auth-token") {
+			// each party generates its own token; its server certificate is issued by the CA shared by the parties
+			natsAuthToken = ""
+		}
+		baselineOrganizationAddress = "0x"
+		common.ResolvedBaselineOrgAddress = ""
+		for p, base := range basePorts {
+			*p = base + i*simulationPortOffset
+		}
+
+		created.stacks = append(created.stacks, partyName)
+		err = runStack(cmd, docker, func(fn func() error) error {
+			// the parties reach one another by container name on the shared network
+			common.APIEndpoint = fmt.Sprintf("http://%s:%d", containerName(serviceAPI), apiContainerPort)
+			common.MessagingEndpoint = fmt.Sprintf("nats://%s:%d", containerName(serviceNATS), natsContainerPort)
+			if err := common.UpdateOrganizationEndpoints(); err != nil {
+				return err
+			}
+			return fn()
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, service := range []string{serviceAPI, serviceNATS} {
+			err := docker.NetworkConnect(context.Background(), sharedNetwork.ID, containerName(service), &network.EndpointSettings{})
+			if err != nil {
+				return fmt.Errorf("failed to attach %s to shared network; %w", containerName(service), err)
+			}
+		}
+
+		if claims != nil {
+			if err := workgroups.ConfigureLocalBaselineStack(fmt.Sprintf("localhost:%d", port), inviteJWT, claims); err != nil {
+				return err
+			}
+		}
+
+		result.Parties = append(result.Parties, &simulatedParty{
+			Name:              partyName,
+			OrganizationID:    organization.ID.String(),
+			Address:           common.ResolvedBaselineOrgAddress,
+			APIEndpoint:       fmt.Sprintf("http://localhost:%d", port),
+			MessagingEndpoint: fmt.Sprintf("nats://localhost:%d", natsPort),
+		})
+	}
+
+	table := common.NewTable("NAME", "ORGANIZATION", "ADDRESS", "API", "MESSAGING")
+	for _, party := range result.Parties {
+		table.AddRow(party.Name, party.OrganizationID, party.Address, party.APIEndpoint, party.MessagingEndpoint)
+	}
+	if err := common.Render(result, table); err != nil {
+		return fmt.Errorf("failed to render simulation; %w", err)
+	}
+
+	if !common.IsStructuredOutput() {
+		fmt.Printf("\nsimulated baseline workgroup: %s\n", result.WorkgroupID)
+		for _, party := range result.Parties {
+			fmt.Printf("stop %s: prvd baseline stack stop --name %s\n", party.Name, party.Name)
+		}
+		fmt.Printf("remove the shared network: prvd baseline stack stop --name %s\n", prefix)
+	}
+	return nil
+}
+
+// simulationResources are the resources created by a simulation, in the order of their creation
+type simulationResources struct {
+	network         string // name of the shared network
+	workgroupID     string
+	organizationIDs []string
+	stacks          []string // names of the stacks of the parties
+}
+
+// rollback removes the stacks of the parties, the shared network and the workgroup, in the
+// reverse order of their creation, after the given error occurred; the returned error describes
+// the outcome of the rollback
+func (r *simulationResources) rollback(docker *client.Client, token string, err error) error {
+	log.Printf("rolling back simulated baseline workgroup: %s", r.network)
+	for i := len(r.stacks) - 1; i >= 0; i-- {
+		name = r.stacks[i]
+		if removeErr := removeStack(docker, false); removeErr != nil {
+			log.Printf("WARNING: failed to remove %s local baseline instance; %s", name, removeErr.Error())
+		}
+	}
+	name = r.network
+	purgeNetwork(docker)
+
+	if r.workgroupID != "" {
+		if deleteErr := ident.DeleteApplication(token, r.workgroupID); deleteErr != nil {
+			err = fmt.Errorf("%w; failed to delete the simulated baseline workgroup %s; %s", err, r.workgroupID, deleteErr.Error())
+		}
+	}
+	if len(r.organizationIDs) > 0 {
+		// provide-go does not support deleting an organization
+		err = fmt.Errorf("%w; the organizations created for the simulated parties remain: %s", err, strings.Join(r.organizationIDs, ", "))
+	}
+	return err
+}
+
+// selectSimulatedOrganization makes the given organization the context of subsequent calls
+func selectSimulatedOrganization(organizationID string) {
+	common.OrganizationID = organizationID
+	common.OrganizationAccessToken = ""
+	common.OrganizationRefreshToken = ""
+	common.VaultID = ""
+}
+
+func init() {
+	addStackRunFlags(simulateBaselineStackCmd.Flags())
+	simulateBaselineStackCmd.Flags().Lookup("name").DefValue = defaultSimulationName
+	simulateBaselineStackCmd.Flags().Lookup("name").Usage = "name prefix of the simulated baseline stack instances; i.e., each party runs <name>-<n>"
+	simulateBaselineStackCmd.Flags().IntVar(&simulationParties, "parties", defaultSimulationParties, "number of parties to simulate")
+	simulateBaselineStackCmd.Flags().StringVar(&common.NetworkID, "network", "", "nchain network id of the baseline mainnet to use for the simulated workgroup")
+	simulateBaselineStackCmd.Flags().StringVar(&imageLockPath, "lockfile", defaultImageLockPath, "path of the image lockfile; when present, the locked container images are run by each party")
+	simulateBaselineStackCmd.Flags().DurationVar(&startupTimeout, "startup-timeout", defaultStartupTimeout, "maximum time to wait for the services of each party to report healthy before its stack is rolled back")
+	simulateBaselineStackCmd.Flags().BoolVarP(&Optional, "optional", "", false, "List all the optional flags")
+}
@@ -0,0 +1,308 @@
+package stack
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// defaultSORURL is the --sor-url placeholder; it configures no system of record API
+const defaultSORURL = "https://"
+
+// SystemOfRecord connects the baseline services of the stack to an internal system of record;
+// each connector is declared in its own file and registered using registerSystemOfRecord
+type SystemOfRecord interface {
+	// ID returns the identifier of the system of record, as passed using --sor; i.e., sap
+	ID() string
+
+	// Name returns the name of the system of record, as presented when prompting; i.e., SAP
+	Name() string
+
+	// Aliases returns the identifiers which are accepted in place of the identifier
+	Aliases() []string
+
+	// AddFlags registers the flags which configure the connector
+	AddFlags(flags *pflag.FlagSet)
+
+	// Prompt interactively resolves the configuration of the connector which was not set by
+	// flags, once it is selected as the primary system of record
+	Prompt() error
+
+	// ParseURL configures the connector from the --sor-url of the primary system of record,
+	// for any configuration which was not set by flags
+	ParseURL(sorURL *url.URL) error
+
+	// ValidateCredentials returns an error if the credentials of the connector are incomplete
+	ValidateCredentials() error
+
+	// Environment returns the environment variables which configure the baseline services to
+	// connect to the system of record
+	Environment() []string
+}
+
+// sorRegistry is every registered system of record connector, keyed by identifier
+var sorRegistry = map[string]SystemOfRecord{}
+
+// registerSystemOfRecord adds the given connector to the registry; it is called in the
+// declaration of a package-level var, so the registry is complete before any init func
+// registers the flags of the connectors
+func registerSystemOfRecord(sor SystemOfRecord) bool {
+	if _, ok := sorRegistry[sor.ID()]; ok {
+		panic(fmt.Sprintf("system of record registered twice: %s", sor.ID()))
+	}
+	sorRegistry[sor.ID()] = sor
+	return true
+}
+
+// systemsOfRecord returns the registered connectors, ordered by identifier
+func systemsOfRecord() []SystemOfRecord {
+	sors := make([]SystemOfRecord, 0)
+	for _, sor := range sorRegistry {
+		sors = append(sors, sor)
+	}
+	sort.Slice(sors, func(i, j int) bool {
+		return sors[i].ID() < sors[j].ID()
+	})
+	return sors
+}
+
+// sorIdentifiers returns the identifiers of the registered connectors, sorted
+func sorIdentifiers() []string {
+	ids := make([]string, 0)
+	for _, sor := range systemsOfRecord() {
+		ids = append(ids, sor.ID())
+	}
+	return ids
+}
+
+// lookupSystemOfRecord returns the connector with the given identifier or alias, or nil
+func lookupSystemOfRecord(id string) SystemOfRecord {
+	if sor, ok := sorRegistry[id]; ok {
+		return sor
+	}
+	for _, sor := range sorRegistry {
+		if containsString(sor.Aliases(), id) {
+			return sor
+		}
+	}
+	return nil
+}
+
+// requireSystemOfRecord validates the primary system of record, configuring its connector
+// from --sor-url, and validates the credentials of every connector
+func requireSystemOfRecord() error {
+	if sorID != "" {
+		sor := lookupSystemOfRecord(sorID)
+		if sor == nil {
+			return common.NewValidationError("unsupported --sor: %s; must be one of %s", sorID, strings.Join(sorIdentifiers(), ", "))
+		}
+
+		if sorURL != "" && sorURL != defaultSORURL {
+			_url, err := url.Parse(sorURL)
+			if err != nil {
+				return common.NewValidationError("invalid --sor-url: %s; %s", sorURL, err.Error())
+			}
+			if err := sor.ParseURL(_url); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, sor := range systemsOfRecord() {
+		if err := sor.ValidateCredentials(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sorEnvironment returns the environment variables of every configured system of record
+func sorEnvironment() []string {
+	env := make([]string, 0)
+	for _, sor := range systemsOfRecord() {
+		env = append(env, sor.Environment()...)
+	}
+	return env
+}
+
+// addSORFlags registers the flags of every system of record connector
+func addSORFlags(flags *pflag.FlagSet) {
+	for _, sor := range systemsOfRecord() {
+		sor.AddFlags(flags)
+	}
+}
+
+var sorBaselineStackCmd = &cobra.Command{
+	Use:   "sor",
+	Short: "Interact with the systems of record supported by the baseline stack",
+	Long: `List the systems of record to which a local baseline stack instance can connect.
+
+The primary system of record is selected using 'prvd baseline stack run --sor'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
+
+		return sorCmdPrompt(cmd, args, "")
+	},
+}
+
+// sorConnector is a system of record which is configured using --sor-url alone
+type sorConnector struct {
+	id      string
+	name    string
+	aliases []string
+	prompt  bool // when true, the url of the system of record is prompted for
+}
+
+func (c *sorConnector) ID() string {
+	return c.id
+}
+
+func (c *sorConnector) Name() string {
+	return c.name
+}
+
+func (c *sorConnector) Aliases() []string {
+	return c.aliases
+}
+
+func (c *sorConnector) AddFlags(flags *pflag.FlagSet) {}
+
+func (c *sorConnector) Prompt() error {
+	if c.prompt {
+		return sorURLPrompt()
+	}
+	return nil
+}
+
+func (c *sorConnector) ParseURL(sorURL *url.URL) error {
+	return nil
+}
+
+func (c *sorConnector) ValidateCredentials() error {
+	return nil
+}
+
+func (c *sorConnector) Environment() []string {
+	return nil
+}
+
+// sorAPIConnector is a system of record whose API is configured using the
+// --<id>-api-host, --<id>-api-scheme and --<id>-api-path flags, and when it uses basic
+// authorization, the --<id>-api-username and --<id>-api-password flags; the environment
+// variables are named after the flags, i.e., SAP_API_HOST
+type sorAPIConnector struct {
+	id          string
+	name        string
+	aliases     []string
+	description string // i.e., internal SAP API service
+	defaultPath string
+	basicAuth   bool
+
+	host     string
+	scheme   string
+	path     string
+	username string
+	password string
+}
+
+func (c *sorAPIConnector) ID() string {
+	return c.id
+}
+
+func (c *sorAPIConnector) Name() string {
+	return c.name
+}
+
+func (c *sorAPIConnector) Aliases() []string {
+	return c.aliases
+}
+
+func (c *sorAPIConnector) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&c.host, c.flag("host"), "", fmt.Sprintf("hostname of the %s", c.description))
+	flags.StringVar(&c.scheme, c.flag("scheme"), "https", fmt.Sprintf("protocol scheme of the %s", c.description))
+	flags.StringVar(&c.path, c.flag("path"), c.defaultPath, fmt.Sprintf("base path of the %s", c.description))
+	if c.basicAuth {
+		flags.StringVar(&c.username, c.flag("username"), "", fmt.Sprintf("username to use for basic authorization against the %s", c.description))
+		flags.StringVar(&c.password, c.flag("password"), "", fmt.Sprintf("password to use for basic authorization against the %s", c.description))
+	}
+}
+
+// flag returns the name of the given flag of the connector; i.e., sap-api-host
+func (c *sorAPIConnector) flag(field string) string {
+	return fmt.Sprintf("%s-api-%s", c.id, field)
+}
+
+func (c *sorAPIConnector) Prompt() error {
+	if c.host == "" {
+		return sorURLPrompt()
+	}
+	return nil
+}
+
+func (c *sorAPIConnector) ParseURL(sorURL *url.URL) error {
+	if c.host != "" || sorURL.Host == "" {
+		return nil
+	}
+	if sorURL.Scheme != "http" && sorURL.Scheme != "https" {
+		return common.NewValidationError("invalid --sor-url: %s; scheme must be http or https", sorURL.String())
+	}
+
+	c.host = sorURL.Host
+	c.scheme = sorURL.Scheme
+	if path := strings.Trim(sorURL.Path, "/"); path != "" {
+		c.path = path
+	}
+	if sorURL.User != nil && c.basicAuth && c.username == "" {
+		c.username = sorURL.User.Username()
+		c.password, _ = sorURL.User.Password()
+	}
+	return nil
+}
+
+func (c *sorAPIConnector) ValidateCredentials() error {
+	if !c.basicAuth {
+		return nil
+	}
+	if (c.username == "") != (c.password == "") {
+		return common.NewValidationError("--%s and --%s must be set together", c.flag("username"), c.flag("password"))
+	}
+	return nil
+}
+
+func (c *sorAPIConnector) Environment() []string {
+	if c.host == "" {
+		return nil
+	}
+	return c.environment(c.host, c.scheme, c.path, c.username, c.password)
+}
+
+// environment returns the environment variables which configure the baseline services to
+// connect to the API of the system of record at the given host
+func (c *sorAPIConnector) environment(host, scheme, path, username, password string) []string {
+	prefix := strings.ToUpper(strings.ReplaceAll(c.id, "-", "_"))
+	env := []string{
+		fmt.Sprintf("%s_API_HOST=%s", prefix, host),
+		fmt.Sprintf("%s_API_SCHEME=%s", prefix, scheme),
+	}
+	if path = strings.Trim(path, "/"); path != "" {
+		env = append(env, fmt.Sprintf("%s_API_PATH=%s", prefix, path))
+	}
+	if c.basicAuth && username != "" {
+		env = append(env,
+			fmt.Sprintf("%s_API_USERNAME=%s", prefix, username),
+			fmt.Sprintf("%s_API_PASSWORD=%s", prefix, password),
+		)
+	}
+	return env
+}
+
+func init() {
+	sorBaselineStackCmd.AddCommand(sorListBaselineStackCmd)
+}
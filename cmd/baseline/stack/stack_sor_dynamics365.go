@@ -0,0 +1,7 @@
+package stack
+
+var _ = registerSystemOfRecord(&sorConnector{
+	id:     "dynamics365",
+	name:   "Dynamics365",
+	prompt: true,
+})
@@ -0,0 +1,7 @@
+package stack
+
+// the ephemeral system of record is held in memory by the baseline services
+var _ = registerSystemOfRecord(&sorConnector{
+	id:   "ephemeral",
+	name: "Ephemeral (In-Memory)",
+})
@@ -0,0 +1,7 @@
+package stack
+
+var _ = registerSystemOfRecord(&sorConnector{
+	id:     "excel",
+	name:   "Excel",
+	prompt: true,
+})
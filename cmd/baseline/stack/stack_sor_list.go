@@ -0,0 +1,60 @@
+package stack
+
+import (
+	"strings"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var sorListBaselineStackCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the supported systems of record",
+	Long:  `List the systems of record which can be passed to 'prvd baseline stack run' using --sor, and the flags which configure each`,
+	RunE:  sorListProxy,
+}
+
+// sorListing describes a supported system of record
+type sorListing struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+	Mock    bool     `json:"mock"` // true when the system of record can be mocked using --mock-sor
+	Flags   []string `json:"flags"`
+}
+
+func sorListProxy(cmd *cobra.Command, args []string) error {
+	return sorCmdPrompt(cmd, args, promptStepSORList)
+}
+
+func sorListRun(cmd *cobra.Command, args []string) error {
+	listings := make([]*sorListing, 0)
+	table := common.NewTable("ID", "NAME", "MOCK", "FLAGS")
+	for _, sor := range systemsOfRecord() {
+		listing := &sorListing{
+			ID:      sor.ID(),
+			Name:    sor.Name(),
+			Aliases: sor.Aliases(),
+			Mock:    containsString(mockSORIdentifiers, sor.ID()),
+			Flags:   make([]string, 0),
+		}
+
+		// the flags are registered on a throwaway flag set to list them; the values
+		// of the connector are not otherwise used by this command
+		flags := pflag.NewFlagSet(sor.ID(), pflag.ContinueOnError)
+		sor.AddFlags(flags)
+		flags.VisitAll(func(flag *pflag.Flag) {
+			listing.Flags = append(listing.Flags, "--"+flag.Name)
+		})
+		listings = append(listings, listing)
+
+		mock := "no"
+		if listing.Mock {
+			mock = "yes"
+		}
+		table.AddRow(listing.ID, listing.Name, mock, strings.Join(listing.Flags, ", "))
+	}
+
+	return common.Render(listings, table)
+}
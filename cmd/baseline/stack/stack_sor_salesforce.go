@@ -0,0 +1,10 @@
+package stack
+
+// salesforceSOR connects the stack to the Salesforce API
+var salesforceSOR = &sorAPIConnector{
+	id:          "salesforce",
+	name:        "Salesforce",
+	description: "Salesforce API service",
+}
+
+var _ = registerSystemOfRecord(salesforceSOR)
@@ -0,0 +1,12 @@
+package stack
+
+// sapSOR connects the stack to the internal SAP API service, i.e., the UBC extension
+var sapSOR = &sorAPIConnector{
+	id:          "sap",
+	name:        "SAP",
+	description: "internal SAP API service",
+	defaultPath: "ubc",
+	basicAuth:   true,
+}
+
+var _ = registerSystemOfRecord(sapSOR)
@@ -0,0 +1,13 @@
+package stack
+
+// serviceNowSOR connects the stack to the ServiceNow table API
+var serviceNowSOR = &sorAPIConnector{
+	id:          "servicenow",
+	name:        "ServiceNow",
+	aliases:     []string{"snow"},
+	description: "ServiceNow API",
+	defaultPath: "api/now/table",
+	basicAuth:   true,
+}
+
+var _ = registerSystemOfRecord(serviceNowSOR)
@@ -0,0 +1,185 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+const defaultStartupTimeout = time.Minute * 5
+const startupPollInterval = time.Second * 1
+
+var startupTimeout time.Duration
+
+// startServices starts the given services in dependency order; each service is started once
+// all of its dependencies report healthy, and services which do not depend on one another
+// are started concurrently. When any service fails to become healthy before the startup
+// timeout elapses, the containers and network of the stack are removed.
+func startServices(docker *client.Client, services []*serviceSpec) error {
+	ctx, cancel := context.WithTimeout(context.Background(), startupTimeout)
+	defer cancel()
+
+	stages, err := startupStages(services)
+	if err != nil {
+		return err
+	}
+
+	if err := createVolumes(docker, services); err != nil {
+		rollbackServices(docker)
+		return err
+	}
+
+	startedAt := time.Now()
+	for _, stage := range stages {
+		wg := &sync.WaitGroup{}
+		errs := make(chan error, len(stage))
+		for _, svc := range stage {
+			spec := svc
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := startService(ctx, docker, spec); err != nil {
+					errs <- err
+					cancel() // abandon the remaining services of the stage
+				}
+			}()
+		}
+
+		wg.Wait()
+		close(errs)
+		if err := <-errs; err != nil {
+			rollbackServices(docker)
+			return err
+		}
+	}
+
+	log.Printf("started %d services in %s", len(services), time.Since(startedAt).Round(time.Second))
+	return nil
+}
+
+// startService runs the container for the given service and waits for it to report healthy
+func startService(ctx context.Context, docker *client.Client, spec *serviceSpec) error {
+	log.Printf("starting %s", spec.description)
+	startedAt := time.Now()
+
+	container, err := runContainer(docker, spec)
+	if err != nil {
+		return fmt.Errorf("failed to create %s container; %w", spec.description, err)
+	}
+
+	if err := awaitHealthy(ctx, docker, container.ID, spec); err != nil {
+		return err
+	}
+
+	log.Printf("%s is healthy (%s)", spec.description, time.Since(startedAt).Round(time.Second))
+	return nil
+}
+
+// awaitHealthy blocks until the container reports healthy, or is running when no healthcheck
+// is defined for the service; an error is returned if the container exits, is reported
+// unhealthy or the context is done
+func awaitHealthy(ctx context.Context, docker *client.Client, containerID string, spec *serviceSpec) error {
+	ticker := time.NewTicker(startupPollInterval)
+	defer ticker.Stop()
+
+	for {
+		info, err := docker.ContainerInspect(ctx, containerID)
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("failed to inspect %s container; %w", spec.description, err)
+		}
+
+		if err == nil && info.State != nil {
+			if !info.State.Running && (info.State.Status == "exited" || info.State.Status == "dead") {
+				return fmt.Errorf("%s exited with status %d before becoming healthy", spec.description, info.State.ExitCode)
+			}
+
+			if info.State.Health != nil {
+				switch info.State.Health.Status {
+				case "healthy":
+					return nil
+				case "unhealthy":
+					return fmt.Errorf("%s is unhealthy", spec.description)
+				}
+			} else if spec.healthcheck == nil && info.State.Running {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out after %s waiting for %s to become healthy", startupTimeout, spec.description)
+		case <-ticker.C:
+		}
+	}
+}
+
+// rollbackServices removes the containers and network of the stack after a failed startup
+func rollbackServices(docker *client.Client) {
+	log.Printf("rolling back %s local baseline instance", name)
+	if err := purgeContainers(docker); err != nil {
+		log.Printf("WARNING: failed to remove containers; %s", err.Error())
+	}
+	purgeNetwork(docker)
+}
+
+// startupStages groups the given services into stages, each of which depends only on the
+// services of the preceding stages
+func startupStages(services []*serviceSpec) ([][]*serviceSpec, error) {
+	pending := map[string]*serviceSpec{}
+	for _, spec := range services {
+		pending[spec.service] = spec
+	}
+
+	for _, spec := range services {
+		for _, dep := range spec.dependsOn {
+			if _, ok := pending[dep]; !ok {
+				return nil, fmt.Errorf("%s depends on %s, which is not part of the stack", spec.service, dep)
+			}
+		}
+	}
+
+	started := map[string]bool{}
+	stages := make([][]*serviceSpec, 0)
+	for len(pending) > 0 {
+		stage := make([]*serviceSpec, 0)
+		for _, spec := range services {
+			if _, ok := pending[spec.service]; !ok {
+				continue
+			}
+
+			ready := true
+			for _, dep := range spec.dependsOn {
+				if !started[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				stage = append(stage, spec)
+			}
+		}
+
+		if len(stage) == 0 {
+			cyclic := make([]string, 0)
+			for service := range pending {
+				cyclic = append(cyclic, service)
+			}
+			sort.Strings(cyclic)
+			return nil, fmt.Errorf("circular dependency between services: %s", strings.Join(cyclic, ", "))
+		}
+
+		for _, spec := range stage {
+			started[spec.service] = true
+			delete(pending, spec.service)
+		}
+		stages = append(stages, stage)
+	}
+
+	return stages, nil
+}
@@ -0,0 +1,262 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"github.com/provideservices/provide-cli/cmd/common"
+
+	"github.com/spf13/cobra"
+)
+
+const statusHealthNone = "-"
+const defaultStatusWatchInterval = time.Second * 5
+
+// tunnelHostSuffixes are the public hostnames assigned to endpoints tunneled using ngrok
+var tunnelHostSuffixes = []string{".ngrok.io", ".ngrok.app", ".ngrok-free.app"}
+
+var watchStatus bool
+var watchStatusInterval time.Duration
+
+var statusBaselineStackCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show baseline stack status",
+	Long:  `Show the health, ports and uptime of each container in a local baseline stack instance, and the endpoints it advertises`,
+	RunE:  statusProxy,
+}
+
+// stackStatus is the observed state of a local baseline stack instance
+type stackStatus struct {
+	Name                string           `json:"name"`
+	OrganizationAddress string           `json:"organization_address,omitempty"`
+	APIEndpoint         string           `json:"api_endpoint,omitempty"`
+	MessagingEndpoint   string           `json:"messaging_endpoint,omitempty"`
+	Tunnels             []string         `json:"tunnels"`
+	Services            []*serviceStatus `json:"services"`
+}
+
+// serviceStatus is the observed state of a single container in a local baseline stack instance
+type serviceStatus struct {
+	Service      string     `json:"service"`
+	Container    string     `json:"container"`
+	Image        string     `json:"image"`
+	Tag          string     `json:"tag"`
+	State        string     `json:"state"`
+	Health       string     `json:"health"`
+	RestartCount int        `json:"restart_count"`
+	Ports        []string   `json:"ports"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	Uptime       string     `json:"uptime,omitempty"`
+}
+
+func statusProxy(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepStatus)
+}
+
+func statusProxyRun(cmd *cobra.Command, args []string) error {
+	docker, err := client.NewEnvClient()
+	if err != nil {
+		return fmt.Errorf("failed to initialize docker; %w", err)
+	}
+
+	if !watchStatus {
+		return renderStackStatus(docker)
+	}
+
+	if watchStatusInterval <= 0 {
+		return common.NewValidationError("invalid --interval: %s; must be greater than zero", watchStatusInterval)
+	}
+
+	timer := time.NewTicker(watchStatusInterval)
+	defer timer.Stop()
+
+	for {
+		if !common.IsStructuredOutput() {
+			fmt.Print("\033[H\033[2J") // clear the terminal between snapshots
+		}
+		if err := renderStackStatus(docker); err != nil {
+			return err
+		}
+		<-timer.C
+	}
+}
+
+func renderStackStatus(docker *client.Client) error {
+	status, err := resolveStackStatus(docker)
+	if err != nil {
+		return err
+	}
+
+	if common.IsStructuredOutput() {
+		if err := common.Render(status, nil); err != nil {
+			return fmt.Errorf("failed to render baseline stack status; %w", err)
+		}
+		return nil
+	}
+
+	if len(status.Services) == 0 {
+		fmt.Printf("%s local baseline instance is not running\n", status.Name)
+		return nil
+	}
+
+	table := common.NewTable("SERVICE", "IMAGE", "TAG", "STATE", "HEALTH", "RESTARTS", "PORTS", "UPTIME")
+	for _, svc := range status.Services {
+		table.AddRow(
+			svc.Service,
+			svc.Image,
+			svc.Tag,
+			svc.State,
+			svc.Health,
+			strconv.Itoa(svc.RestartCount),
+			strings.Join(svc.Ports, ", "),
+			svc.Uptime,
+		)
+	}
+	if err := common.Render(status, table); err != nil {
+		return fmt.Errorf("failed to render baseline stack status; %w", err)
+	}
+
+	fmt.Println()
+	endpoints := common.NewTable("ENDPOINT", "VALUE")
+	endpoints.AddRow("api", status.APIEndpoint)
+	endpoints.AddRow("messaging", status.MessagingEndpoint)
+	for _, tunnel := range status.Tunnels {
+		endpoints.AddRow("tunnel", tunnel)
+	}
+	endpoints.AddRow("organization address", status.OrganizationAddress)
+	return common.Render(status, endpoints)
+}
+
+// resolveStackStatus inspects each container of the named stack; the advertised endpoints
+// and organization address are read back from the environment of the stack containers
+func resolveStackStatus(docker *client.Client) (*stackStatus, error) {
+	containers, err := listContainers(docker)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &stackStatus{
+		Name:     name,
+		Tunnels:  make([]string, 0),
+		Services: make([]*serviceStatus, 0),
+	}
+
+	for _, container := range containers {
+		info, err := docker.ContainerInspect(context.Background(), container.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect container: %s; %w", container.ID, err)
+		}
+
+		image, tag := parseImageReference(container.Image)
+
+		svc := &serviceStatus{
+			Service:      stackService(container),
+			Container:    strings.TrimPrefix(info.Name, "/"),
+			Image:        image,
+			Tag:          tag,
+			State:        info.State.Status,
+			Health:       statusHealthNone,
+			RestartCount: info.RestartCount,
+			Ports:        formatPorts(container.Ports),
+		}
+
+		if info.State.Health != nil {
+			svc.Health = info.State.Health.Status
+		}
+
+		if info.State.Running {
+			if startedAt, err := time.Parse(time.RFC3339Nano, info.State.StartedAt); err == nil {
+				svc.StartedAt = &startedAt
+				svc.Uptime = time.Since(startedAt).Round(time.Second).String()
+			}
+		}
+
+		if info.Config != nil {
+			env := containerEnvironment(info.Config.Env)
+			if status.APIEndpoint == "" {
+				status.APIEndpoint = env["BASELINE_ORGANIZATION_PROXY_ENDPOINT"]
+			}
+			if status.MessagingEndpoint == "" {
+				status.MessagingEndpoint = env["BASELINE_ORGANIZATION_MESSAGING_ENDPOINT"]
+			}
+			if status.OrganizationAddress == "" {
+				status.OrganizationAddress = env["BASELINE_ORGANIZATION_ADDRESS"]
+			}
+		}
+
+		status.Services = append(status.Services, svc)
+	}
+
+	sort.Slice(status.Services, func(i, j int) bool {
+		return status.Services[i].Service < status.Services[j].Service
+	})
+
+	for _, endpoint := range []string{status.APIEndpoint, status.MessagingEndpoint} {
+		if isTunnelEndpoint(endpoint) {
+			status.Tunnels = append(status.Tunnels, endpoint)
+		}
+	}
+
+	return status, nil
+}
+
+// containerEnvironment maps the given KEY=VALUE container environment
+func containerEnvironment(env []string) map[string]string {
+	vars := map[string]string{}
+	for _, envvar := range env {
+		parts := strings.SplitN(envvar, "=", 2)
+		if len(parts) == 2 {
+			vars[parts[0]] = parts[1]
+		}
+	}
+	return vars
+}
+
+// parseImageReference splits the given image reference into its repository and tag
+func parseImageReference(ref string) (string, string) {
+	ref = strings.SplitN(ref, "@", 2)[0]
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[0:i], ref[i+1:]
+	}
+	return ref, "latest"
+}
+
+// formatPorts renders the host port bindings of a container; i.e., 0.0.0.0:8080->8080/tcp
+func formatPorts(ports []types.Port) []string {
+	formatted := make([]string, 0)
+	for _, p := range ports {
+		if p.PublicPort == 0 {
+			continue
+		}
+		formatted = append(formatted, fmt.Sprintf("%s:%d->%d/%s", p.IP, p.PublicPort, p.PrivatePort, p.Type))
+	}
+	sort.Strings(formatted)
+	return formatted
+}
+
+// isTunnelEndpoint returns true if the given endpoint was exposed using a tunnel
+func isTunnelEndpoint(endpoint string) bool {
+	_url, err := url.Parse(endpoint)
+	if err != nil || _url.Hostname() == "" {
+		return false
+	}
+	for _, suffix := range tunnelHostSuffixes {
+		if strings.HasSuffix(_url.Hostname(), suffix) {
+			return true
+		}
+	}
+	return containsString(common.TunnelEndpointHosts(), _url.Hostname())
+}
+
+func init() {
+	statusBaselineStackCmd.Flags().StringVar(&name, "name", "baseline-local", "name of the baseline stack instance")
+	statusBaselineStackCmd.Flags().BoolVar(&watchStatus, "watch", false, "continuously refresh the status of the baseline stack instance")
+	statusBaselineStackCmd.Flags().DurationVar(&watchStatusInterval, "interval", defaultStatusWatchInterval, "refresh interval when --watch is set")
+}
@@ -2,6 +2,7 @@ package stack
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 
@@ -11,33 +12,61 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var keepData bool
+
 var stopBaselineStackCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stop the baseline stack",
-	Long:  `Stop a local baseline stack instance`,
-	Run:   stopProxy,
+	Long:  `Stop a local baseline stack instance; the named volumes holding the state of the stack are removed unless --keep-data is set`,
+	RunE:  stopProxy,
 }
 
-func stopProxy(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepStop)
+func stopProxy(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepStop)
 }
 
-func stopProxyRun(cmd *cobra.Command, args []string) {
+func stopProxyRun(cmd *cobra.Command, args []string) error {
 	docker, err := client.NewEnvClient()
 	if err != nil {
-		log.Printf("failed to initialize docker; %s", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("failed to initialize docker; %w", err)
 	}
 
-	purgeContainers(docker)
-	purgeNetwork(docker)
+	if err := removeStack(docker, keepData); err != nil {
+		return err
+	}
 
 	log.Printf("%s local baseline instance stopped", name)
+	return nil
 }
 
-func purgeContainers(docker *client.Client) {
+// removeStack removes the containers, network and generated files of the named stack, and its
+// named volumes unless keepData is set
+func removeStack(docker *client.Client, keepData bool) error {
+	if err := purgeContainers(docker); err != nil {
+		return err
+	}
+	purgeNetwork(docker)
+
+	if dir, err := stackFilesDir(); err != nil {
+		log.Printf("WARNING: %s", err.Error())
+	} else if err := os.RemoveAll(dir); err != nil {
+		log.Printf("WARNING: failed to remove %s; %s", dir, err.Error())
+	}
+
+	if !keepData {
+		return purgeVolumes(docker)
+	}
+	return nil
+}
+
+func purgeContainers(docker *client.Client) error {
 	// log.Printf("purging containers for local baseline instance: %s", name)
-	for _, container := range listContainers(docker) {
+	containers, err := listContainers(docker)
+	if err != nil {
+		return err
+	}
+
+	for _, container := range containers {
 		err := docker.ContainerRemove(context.Background(), container.ID, types.ContainerRemoveOptions{
 			RemoveVolumes: true,
 			Force:         true,
@@ -47,12 +76,16 @@ func purgeContainers(docker *client.Client) {
 			log.Printf("WARNING: failed to remove container: %s; %s", container.Names[0], err.Error())
 		}
 	}
+
+	return nil
 }
 
+// purgeNetwork removes the network of the named stack; the network is selected by label,
+// and by name when created by a version of prvd which predates labels
 func purgeNetwork(docker *client.Client) {
 	networks, _ := docker.NetworkList(context.Background(), types.NetworkListOptions{})
 	for _, ntwrk := range networks {
-		if ntwrk.Name == name {
+		if ntwrk.Labels[stackLabel] == name || (ntwrk.Labels[stackLabel] == "" && ntwrk.Name == name) {
 			docker.NetworkRemove(context.Background(), ntwrk.ID)
 		}
 	}
@@ -60,4 +93,5 @@ func purgeNetwork(docker *client.Client) {
 
 func init() {
 	stopBaselineStackCmd.Flags().StringVar(&name, "name", "baseline-local", "name of the baseline stack instance")
+	stopBaselineStackCmd.Flags().BoolVar(&keepData, "keep-data", false, "when true, the volumes holding the state of the stack are retained for the next run")
 }
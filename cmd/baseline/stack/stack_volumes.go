@@ -0,0 +1,50 @@
+package stack
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	volumetypes "github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+)
+
+// volumeName returns the name of the docker volume of the named stack; i.e., baseline-local-postgres-data
+func volumeName(volume string) string {
+	return fmt.Sprintf("%s-%s", strings.ReplaceAll(name, " ", ""), volume)
+}
+
+// createVolumes creates the named volumes of the given services; existing volumes, and
+// therefore the data of a previous run of the stack, are reused
+func createVolumes(docker *client.Client, services []*serviceSpec) error {
+	for _, spec := range services {
+		for _, volume := range spec.volumes {
+			_, err := docker.VolumeCreate(context.Background(), volumetypes.VolumeCreateBody{
+				Driver: "local",
+				Labels: stackLabels(spec.service),
+				Name:   volumeName(volume.volume),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create volume: %s; %w", volumeName(volume.volume), err)
+			}
+		}
+	}
+	return nil
+}
+
+// purgeVolumes removes the named volumes of the stack, and with them all local stack state
+func purgeVolumes(docker *client.Client) error {
+	volumes, err := docker.VolumeList(context.Background(), stackFilter())
+	if err != nil {
+		return fmt.Errorf("failed to list volumes; %w", err)
+	}
+
+	for _, volume := range volumes.Volumes {
+		if err := docker.VolumeRemove(context.Background(), volume.Name, true); err != nil {
+			log.Printf("WARNING: failed to remove volume: %s; %s", volume.Name, err.Error())
+		}
+	}
+
+	return nil
+}
@@ -1,24 +1,351 @@
 package messages
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"log"
-	"os"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/provideservices/provide-go/api/baseline"
 	"github.com/spf13/cobra"
 )
 
+const defaultListLimit = 50
+const defaultWatchInterval = time.Second * 5
+
+var workflowID string
+var counterparty string
+var since string
+var until string
+var cursor string
+var limit int
+var watch bool
+var watchInterval time.Duration
+
 var listBaselineMessagesCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List baseline messages",
-	Long:  `List baseline messages in the context of a workflow`,
-	Run:   listMessages,
+	Long: `List the messages baselined in the context of a workgroup, or one of its workflows, oldest first.
+
+Each message is listed with its status (pending, verified or rejected), the commitment, or proof,
+by which it was baselined, and when it was created and last updated. Messages are listed a page at
+a time; when more messages are available, the cursor of the next page is printed, and is passed
+using --cursor to list it. Use --watch to continue listing new messages as they are baselined.
+
+The --workflow, --type, --baseline-id and --id filters are applied by the baseline API. The API does
+not paginate messages or filter them by time or counterparty, so --cursor, --limit, --since, --until
+and --counterparty are applied on the client: each page, and each poll when watching, retrieves every
+message matching the other filters.`,
+	RunE: listMessages,
 }
 
-func listMessages(cmd *cobra.Command, args []string) {
-	log.Printf("not implemented")
-	os.Exit(1)
+// baselineMessage is a message baselined in the context of a workgroup, as returned by the
+// baseline API
+type baselineMessage struct {
+	ID          string                  `json:"id"` // identifier of the record in the internal system of record
+	BaselineID  string                  `json:"baseline_id"`
+	WorkgroupID string                  `json:"workgroup_id,omitempty"`
+	WorkflowID  string                  `json:"workflow_id,omitempty"`
+	Type        string                  `json:"type"`
+	Status      string                  `json:"status,omitempty"`
+	Sender      string                  `json:"sender,omitempty"`
+	Recipients  []*baseline.Participant `json:"recipients,omitempty"`
+	Commitment  string                  `json:"commitment,omitempty"`
+	Proof       string                  `json:"proof,omitempty"`
+	CreatedAt   *time.Time              `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time              `json:"updated_at,omitempty"`
 }
 
-func init() {
+// baselineMessagesPage is a page of baselined messages and the cursor of the next page, if any
+type baselineMessagesPage struct {
+	Messages   []*baselineMessage `json:"messages"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// baselineMessagesFilter selects the baselined messages to list
+type baselineMessagesFilter struct {
+	since        *time.Time
+	until        *time.Time
+	counterparty string // address or organization identifier, lowercased
+}
+
+func listMessages(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepList)
+}
+
+func listMessagesRun(cmd *cobra.Command, args []string) error {
+	if limit <= 0 {
+		return common.NewValidationError("invalid --limit: %d; must be greater than zero", limit)
+	}
+	if watch && watchInterval <= 0 {
+		return common.NewValidationError("invalid --interval: %s; must be greater than zero", watchInterval)
+	}
+
+	filter := &baselineMessagesFilter{
+		counterparty: strings.ToLower(counterparty),
+	}
+	var err error
+	if filter.since, err = parseMessagesTime("--since", since); err != nil {
+		return err
+	}
+	if filter.until, err = parseMessagesTime("--until", until); err != nil {
+		return err
+	}
+	after, err := parseMessagesCursor(cursor)
+	if err != nil {
+		return err
+	}
 
+	if err := common.AuthorizeOrganizationContext(false); err != nil {
+		return err
+	}
+
+	params := map[string]interface{}{
+		"workgroup_id": common.ApplicationID,
+	}
+	if workflowID != "" {
+		params["workflow_id"] = workflowID
+	}
+	if messageType != "" {
+		params["type"] = messageType
+	}
+	if baselineID != "" {
+		params["baseline_id"] = baselineID
+	}
+	if id != "" {
+		params["id"] = id
+	}
+
+	rendered := false
+	for {
+		messages, err := listBaselineMessages(common.OrganizationAccessToken, params)
+		if err != nil {
+			return err
+		}
+		page := paginateMessages(messages, filter, after, limit)
+
+		if !watch {
+			if err := renderMessages(page, true); err != nil {
+				return err
+			}
+			if page.NextCursor != "" && !common.IsStructuredOutput() {
+				log.Printf("more messages are available; use --cursor %s to list the next page", page.NextCursor)
+			}
+			return nil
+		}
+
+		if len(page.Messages) > 0 {
+			if err := renderMessages(page, !rendered); err != nil {
+				return err
+			}
+			rendered = true
+			after = page.Messages[len(page.Messages)-1]
+		}
+		if page.NextCursor == "" {
+			time.Sleep(watchInterval)
+		}
+	}
+}
+
+// listBaselineMessages retrieves the messages baselined by the organization matching the
+// given params; the baseline API does not paginate messages by cursor, so the cursor and
+// the remaining filters are applied to the messages it returns
+func listBaselineMessages(token string, params map[string]interface{}) ([]*baselineMessage, error) {
+	status, resp, err := baseline.InitBaselineService(token).Get("objects", params)
+	if err != nil {
+		return nil, common.NewAPIError(err, "failed to retrieve baseline messages")
+	}
+	if status != 200 {
+		return nil, common.NewAPIError(fmt.Errorf("status: %v", status), "failed to retrieve baseline messages")
+	}
+
+	messages := make([]*baselineMessage, 0)
+	raw, _ := json.Marshal(resp)
+	if err := json.Unmarshal(raw, &messages); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline messages; %w", err)
+	}
+	return messages, nil
+}
+
+// paginateMessages returns the page of at most limit messages matching the filter which
+// follow the message at the cursor, oldest first
+func paginateMessages(messages []*baselineMessage, filter *baselineMessagesFilter, after *baselineMessage, limit int) *baselineMessagesPage {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].before(messages[j])
+	})
+
+	page := &baselineMessagesPage{
+		Messages: make([]*baselineMessage, 0),
+	}
+	for _, msg := range messages {
+		if (after != nil && !after.before(msg)) || !filter.matches(msg) {
+			continue
+		}
+		if len(page.Messages) == limit {
+			page.NextCursor = page.Messages[limit-1].cursor()
+			break
+		}
+		page.Messages = append(page.Messages, msg)
+	}
+	return page
+}
+
+// renderMessages renders the given page of messages; the header is omitted from each page
+// rendered after the first when watching for new messages
+func renderMessages(page *baselineMessagesPage, header bool) error {
+	table := common.NewTable()
+	if header {
+		table = common.NewTable("BASELINE ID", "ID", "TYPE", "STATUS", "COUNTERPARTIES", "HASH", "CREATED", "UPDATED")
+	}
+	for _, msg := range page.Messages {
+		hash := msg.Commitment
+		if hash == "" {
+			hash = msg.Proof
+		}
+		table.AddRow(msg.BaselineID, msg.ID, msg.Type, messageCell(msg.Status), messageCell(strings.Join(msg.counterparties(), ",")), messageCell(hash), messageTimestamp(msg.CreatedAt), messageTimestamp(msg.UpdatedAt))
+	}
+	if err := common.Render(page, table); err != nil {
+		return fmt.Errorf("failed to render baseline messages; %w", err)
+	}
+	return nil
+}
+
+// matches returns true if the given message matches the filter
+func (f *baselineMessagesFilter) matches(msg *baselineMessage) bool {
+	if f.since != nil && (msg.CreatedAt == nil || msg.CreatedAt.Before(*f.since)) {
+		return false
+	}
+	if f.until != nil && (msg.CreatedAt == nil || !msg.CreatedAt.Before(*f.until)) {
+		return false
+	}
+	if f.counterparty != "" {
+		for _, party := range msg.counterparties() {
+			if strings.ToLower(party) == f.counterparty {
+				return true
+			}
+		}
+		for _, recipient := range msg.Recipients {
+			if orgID, _ := recipient.Metadata["organization_id"].(string); strings.ToLower(orgID) == f.counterparty {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// counterparties returns the addresses of the sender and recipients of the message
+func (m *baselineMessage) counterparties() []string {
+	parties := make([]string, 0)
+	if m.Sender != "" {
+		parties = append(parties, m.Sender)
+	}
+	for _, recipient := range m.Recipients {
+		if recipient.Address != nil && *recipient.Address != "" {
+			parties = append(parties, *recipient.Address)
+		}
+	}
+	return parties
+}
+
+// before returns true if the message was baselined before the given message
+func (m *baselineMessage) before(msg *baselineMessage) bool {
+	var t, _t time.Time
+	if m.CreatedAt != nil {
+		t = *m.CreatedAt
+	}
+	if msg.CreatedAt != nil {
+		_t = *msg.CreatedAt
+	}
+	if !t.Equal(_t) {
+		return t.Before(_t)
+	}
+	return m.BaselineID < msg.BaselineID
+}
+
+// cursor returns the opaque cursor of the page which follows the message
+func (m *baselineMessage) cursor() string {
+	createdAt := ""
+	if m.CreatedAt != nil {
+		createdAt = m.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s|%s", createdAt, m.BaselineID)))
+}
+
+// parseMessagesCursor returns the message at the given cursor, holding only the fields by which
+// messages are ordered, or nil when no cursor is given
+func parseMessagesCursor(val string) (*baselineMessage, error) {
+	if val == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(val)
+	parts := strings.SplitN(string(raw), "|", 2)
+	if err != nil || len(parts) != 2 {
+		return nil, common.NewValidationError("invalid --cursor: %s", val)
+	}
+
+	msg := &baselineMessage{
+		BaselineID: parts[1],
+	}
+	if parts[0] != "" {
+		createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+		if err != nil {
+			return nil, common.NewValidationError("invalid --cursor: %s", val)
+		}
+		msg.CreatedAt = &createdAt
+	}
+	return msg, nil
+}
+
+// parseMessagesTime parses the value of the given time range flag, which is either a duration
+// before now or an RFC 3339 timestamp
+func parseMessagesTime(flag, val string) (*time.Time, error) {
+	if val == "" {
+		return nil, nil
+	}
+	if d, err := time.ParseDuration(val); err == nil {
+		t := time.Now().Add(-d)
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return nil, common.NewValidationError("invalid %s: %s; must be a duration, i.e., 10m, or an RFC 3339 timestamp", flag, val)
+	}
+	return &t, nil
+}
+
+// messageCell returns the given value for tabular output
+func messageCell(val string) string {
+	if val == "" {
+		return "-"
+	}
+	return val
+}
+
+func messageTimestamp(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func init() {
+	listBaselineMessagesCmd.Flags().StringVar(&common.ApplicationID, "workgroup", "", "workgroup identifier")
+	listBaselineMessagesCmd.Flags().StringVar(&common.OrganizationID, "organization", "", "organization identifier")
+	listBaselineMessagesCmd.Flags().StringVar(&workflowID, "workflow", "", "list only the messages of the given workflow")
+	listBaselineMessagesCmd.Flags().StringVar(&messageType, "type", "", "list only the messages of the given type")
+	listBaselineMessagesCmd.Flags().StringVar(&counterparty, "counterparty", "", "list only the messages sent by or to the given address or organization identifier")
+	listBaselineMessagesCmd.Flags().StringVar(&baselineID, "baseline-id", "", "list only the messages having the given baseline identifier")
+	listBaselineMessagesCmd.Flags().StringVar(&id, "id", "", "list only the messages of the given record in the internal system of record")
+	listBaselineMessagesCmd.Flags().StringVar(&since, "since", "", "list only the messages baselined since the given duration, i.e., 24h, or RFC 3339 timestamp")
+	listBaselineMessagesCmd.Flags().StringVar(&until, "until", "", "list only the messages baselined before the given duration, i.e., 1h, or RFC 3339 timestamp")
+	listBaselineMessagesCmd.Flags().StringVar(&cursor, "cursor", "", "cursor of the page to list, as printed with the previous page")
+	listBaselineMessagesCmd.Flags().IntVar(&limit, "limit", defaultListLimit, "maximum number of messages to list per page")
+	listBaselineMessagesCmd.Flags().BoolVar(&watch, "watch", false, "continuously list new messages as they are baselined")
+	listBaselineMessagesCmd.Flags().DurationVar(&watchInterval, "interval", defaultWatchInterval, "polling interval when --watch is set")
 }
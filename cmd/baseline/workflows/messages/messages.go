@@ -9,10 +9,12 @@ var MessagesCmd = &cobra.Command{
 	Use:   "messages",
 	Short: "Interact with a baseline workflows",
 	Long:  `Create, manage and interact with workflows via the baseline protocol.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		common.CmdExistsOrExit(cmd, args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
 
-		generalPrompt(cmd, args, "")
+		return generalPrompt(cmd, args, "")
 	},
 }
 
@@ -5,44 +5,85 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const promptStepList = "List"
 const promptStepSend = "Send"
 
 var items = map[string]string{"General Consistency": "general_consistency"}
 var custodyPromptLabel = "Message Type"
 
-var emptyPromptArgs = []string{promptStepSend}
+var emptyPromptArgs = []string{promptStepList, promptStepSend}
 var emptyPromptLabel = "What would you like to do"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, currentStep string) {
+func generalPrompt(cmd *cobra.Command, args []string, currentStep string) error {
+	var err error
+
 	switch step := currentStep; step {
-	case promptStepSend:
+	case promptStepList:
 		if common.ApplicationID == "" {
-			common.RequireWorkgroup()
+			if err := common.RequireWorkgroup(); err != nil {
+				return err
+			}
 		}
 		if common.OrganizationID == "" {
-			common.RequireOrganization()
+			if err := common.RequireOrganization(); err != nil {
+				return err
+			}
 		}
-		if messageType == "" {
-			opts := make([]string, 0)
-			for k := range items {
-				opts = append(opts, k)
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return listMessagesRun(cmd, args)
+	case promptStepSend:
+		if common.ApplicationID == "" {
+			if err := common.RequireWorkgroup(); err != nil {
+				return err
 			}
-			value := common.SelectInput(opts, custodyPromptLabel)
-			messageType = items[value]
 		}
-		if id == "" {
-			id = common.FreeInput("ID", "", common.MandatoryValidation)
+		if common.OrganizationID == "" {
+			if err := common.RequireOrganization(); err != nil {
+				return err
+			}
 		}
-		if baselineID == "" {
-			baselineID = common.FreeInput("Baseline ID", "", common.NoValidation)
+		if batchPath == "" {
+			// the records of a batch declare their own identifiers and types
+			if messageType == "" {
+				opts := make([]string, 0)
+				for k := range items {
+					opts = append(opts, k)
+				}
+				value, err := common.RequireSelectInput("--type", opts, custodyPromptLabel)
+				if err != nil {
+					return err
+				}
+				messageType = items[value]
+			}
+			if id == "" {
+				if id, err = common.RequireFreeInput("--id", "ID", "", common.MandatoryValidation); err != nil {
+					return err
+				}
+			}
+			if baselineID == "" {
+				if baselineID, err = common.FreeInput("Baseline ID", "", common.NoValidation); err != nil {
+					return err
+				}
+			}
+			if data == "" && dataFile == "" && !dataStdin {
+				if data, err = common.RequireFreeInput("--data", "Data", "", common.JSONValidation); err != nil {
+					return err
+				}
+			}
 		}
-		if data == "" {
-			data = common.FreeInput("Data", "", common.JSONValidation)
+		if err := common.MissingInput(); err != nil {
+			return err
 		}
-		sendMessageRun(cmd, args)
+		return sendMessageRun(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
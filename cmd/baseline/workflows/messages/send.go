@@ -1,10 +1,15 @@
 package messages
 
 import (
+	"bufio"
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	"github.com/provideservices/provide-go/api/baseline"
@@ -12,9 +17,25 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const defaultSendConcurrency = 4
+
+// maxBatchRecordSize bounds the size of a single record of a batch file
+const maxBatchRecordSize = 1024 * 1024 * 8
+
+// outcomes of sending a record
+const sendStatusBaselined = "baselined"
+const sendStatusInvalid = "invalid"
+const sendStatusFailed = "failed"
+
 var baselineAPIEndpoint string
 var baselineID string
 var data string
+var dataFile string
+var dataStdin bool
+var batchPath string
+var concurrency int
+var resumePath string
+var mappingPath string
 var id string
 var messageType string
 var recipients string
@@ -23,67 +44,488 @@ var Optional bool
 var sendBaselineMessageCmd = &cobra.Command{
 	Use:   "send",
 	Short: "Send baseline message",
-	Long:  `Send baseline message in the context of a workflow`,
-	Run:   sendMessage,
+	Long: `Send baseline message in the context of a workflow.
+
+The payload of a single record is passed inline using --data, read from a file using --file or read
+from stdin using --stdin. Records are sent in bulk using --batch, which reads a file having a JSON
+record per line, i.e., {"id": "po-1", "type": "purchase_order", "payload": {...}}; --type is used for
+records which do not declare a type. Up to --concurrency records are sent at once, and the result of
+each record is listed. Records which fail are written to a resume file, which is passed using --batch
+to retry them; the resume file is removed once every record of the batch is baselined.
+
+When --workflow is given, each payload is validated against the JSON schema of the step of the
+workflow having the type of the record before it is sent. Use --mapping to append the baseline id
+assigned to each record to a file, for reconciliation with the internal system of record.`,
+	RunE: sendMessage,
 }
 
-func sendMessage(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepSend)
+// messageRecord is a record of the internal system of record to be baselined
+type messageRecord struct {
+	ID         string                 `json:"id"`
+	BaselineID string                 `json:"baseline_id,omitempty"`
+	Type       string                 `json:"type,omitempty"`
+	Payload    map[string]interface{} `json:"payload"`
+
+	line int    // line of the record in the batch file; zero otherwise
+	raw  string // record as read from the batch file
+	err  string // problem with the record as read from the batch file, if any
 }
 
-func sendMessageRun(cmd *cobra.Command, args []string) {
-	common.AuthorizeApplicationContext()
-	common.AuthorizeOrganizationContext(false)
+// messageResult is the outcome of sending a single record
+type messageResult struct {
+	Line       int    `json:"line,omitempty"`
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	BaselineID string `json:"baseline_id,omitempty"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+}
 
-	var payload map[string]interface{}
-	err := json.Unmarshal([]byte(data), &payload)
+// messageMapping maps a record of the internal system of record to its baseline id
+type messageMapping struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	BaselineID string `json:"baseline_id"`
+	WorkflowID string `json:"workflow_id,omitempty"`
+}
+
+func sendMessage(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepSend)
+}
+
+func sendMessageRun(cmd *cobra.Command, args []string) error {
+	if concurrency <= 0 {
+		return common.NewValidationError("invalid --concurrency: %d; must be greater than zero", concurrency)
+	}
+
+	records, err := readMessageRecords()
 	if err != nil {
-		log.Printf("WARNING: failed to send baseline message; failed to parse message data as JSON; %s", err.Error())
-		os.Exit(1)
+		return err
 	}
 
-	params := map[string]interface{}{
-		"id":      id,
-		"payload": payload,
-		"type":    messageType,
-	}
-	if baselineID != "" {
-		params["baseline_id"] = baselineID
-	}
-	if recipients != "" {
-		_recipients := make([]*baseline.Participant, 0)
-		for _, id := range strings.Split(recipients, ",") {
-			orgs, err := ident.ListApplicationOrganizations(common.ApplicationAccessToken, common.ApplicationID, map[string]interface{}{
-				"organization_id": id,
-			})
-			if err != nil {
-				log.Printf("WARNING: failed to send message message data as JSON; %s", err.Error())
-				os.Exit(1)
-			}
-			for _, org := range orgs {
-				if addr, addrOk := org.Metadata["address"].(string); addrOk {
-					_recipients = append(_recipients, &baseline.Participant{
-						Address: &addr,
-					})
-				}
+	if err := common.AuthorizeApplicationContext(); err != nil {
+		return err
+	}
+	if err := common.AuthorizeOrganizationContext(false); err != nil {
+		return err
+	}
 
-			}
-		}
-		params["recipients"] = _recipients
+	schemas, err := requireWorkflowSchemas(common.OrganizationAccessToken)
+	if err != nil {
+		return err
 	}
 
-	baselinedRecord, err := baseline.CreateObject(common.OrganizationAccessToken, params)
+	_recipients, err := resolveRecipients()
 	if err != nil {
-		log.Printf("WARNING: failed to baseline %d-byte payload; %s", len(data), err.Error())
-		os.Exit(1)
+		return err
+	}
+
+	if batchPath == "" {
+		return sendMessageRecord(records[0], schemas, _recipients)
 	}
 
-	log.Printf("baselined record: %v", baselinedRecord.(map[string]interface{})["baseline_id"].(string))
+	results := make([]*messageResult, len(records))
+	sem := make(chan struct{}, concurrency)
+	wg := &sync.WaitGroup{}
+	for i, record := range records {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, record *messageRecord) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = sendRecord(record, schemas, _recipients)
+		}(i, record)
+	}
+	wg.Wait()
+
+	return renderBatchResults(records, results)
+}
+
+// sendMessageRecord sends the single record passed using --data, --file or --stdin
+func sendMessageRecord(record *messageRecord, schemas map[string]interface{}, _recipients []*baseline.Participant) error {
+	if problems := validateRecord(record, schemas); len(problems) > 0 {
+		return common.NewValidationError("invalid message payload:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+
+	baselinedRecord, err := baseline.CreateObject(common.OrganizationAccessToken, recordParams(record, _recipients))
+	if err != nil {
+		return common.NewAPIError(err, "failed to baseline %d-byte payload", len(data))
+	}
+
+	_baselineID, err := recordBaselineID(baselinedRecord)
+	if err != nil {
+		return common.NewAPIError(err, "failed to baseline record %s", record.ID)
+	}
+	log.Printf("baselined record: %v", _baselineID)
 	if common.Verbose {
 		raw, _ := json.MarshalIndent(baselinedRecord, "", "  ")
 		log.Printf(string(raw))
 	}
 
+	return writeMessageMappings([]*messageResult{{
+		ID:         record.ID,
+		Type:       record.Type,
+		BaselineID: _baselineID,
+		Status:     sendStatusBaselined,
+	}})
+}
+
+// sendRecord validates and sends a single record of a batch
+func sendRecord(record *messageRecord, schemas map[string]interface{}, _recipients []*baseline.Participant) *messageResult {
+	result := &messageResult{
+		Line: record.line,
+		ID:   record.ID,
+		Type: record.Type,
+	}
+
+	problems := make([]string, 0)
+	if record.err != "" {
+		problems = append(problems, record.err)
+	} else {
+		problems = validateRecord(record, schemas)
+	}
+	if len(problems) > 0 {
+		result.Status = sendStatusInvalid
+		result.Error = strings.Join(problems, "; ")
+		return result
+	}
+
+	baselinedRecord, err := baseline.CreateObject(common.OrganizationAccessToken, recordParams(record, _recipients))
+	if err != nil {
+		result.Status = sendStatusFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	if result.BaselineID, err = recordBaselineID(baselinedRecord); err != nil {
+		result.Status = sendStatusFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = sendStatusBaselined
+	return result
+}
+
+// recordBaselineID returns the baseline id assigned to a record, as returned by the baseline API
+func recordBaselineID(baselinedRecord interface{}) (string, error) {
+	resp, ok := baselinedRecord.(map[string]interface{})
+	if baselinedRecord == nil {
+		return "", fmt.Errorf("empty response; no baseline id returned")
+	} else if !ok {
+		return "", fmt.Errorf("unexpected response: %v", baselinedRecord)
+	}
+	_baselineID, _ := resp["baseline_id"].(string)
+	if _baselineID == "" {
+		return "", fmt.Errorf("no baseline id returned")
+	}
+	return _baselineID, nil
+}
+
+// validateRecord returns a description of each problem with the given record; when a workflow
+// is given, the payload is validated against the schema of the step having the type of the record
+func validateRecord(record *messageRecord, schemas map[string]interface{}) []string {
+	problems := make([]string, 0)
+	if record.ID == "" {
+		problems = append(problems, "id: required")
+	}
+	if record.Type == "" {
+		problems = append(problems, "type: required")
+	}
+	if schemas == nil || record.Type == "" {
+		return problems
+	}
+
+	schema, ok := schemas[record.Type]
+	if !ok {
+		return append(problems, fmt.Sprintf("type: no step of workflow %s sends messages of type %s", workflowID, record.Type))
+	}
+	for _, problem := range common.ValidateJSON(schema, record.Payload) {
+		problems = append(problems, fmt.Sprintf("payload: %s", problem))
+	}
+	return problems
+}
+
+// recordParams returns the params with which the given record is baselined
+func recordParams(record *messageRecord, _recipients []*baseline.Participant) map[string]interface{} {
+	params := map[string]interface{}{
+		"id":      record.ID,
+		"payload": record.Payload,
+		"type":    record.Type,
+	}
+	if record.BaselineID != "" {
+		params["baseline_id"] = record.BaselineID
+	}
+	if workflowID != "" {
+		params["workflow_id"] = workflowID
+	}
+	if len(_recipients) > 0 {
+		params["recipients"] = _recipients
+	}
+	return params
+}
+
+// readMessageRecords reads the records to send from the input given using --data, --file,
+// --stdin or --batch
+func readMessageRecords() ([]*messageRecord, error) {
+	inputs := 0
+	for _, isSet := range []bool{data != "", dataFile != "", dataStdin, batchPath != ""} {
+		if isSet {
+			inputs++
+		}
+	}
+	if inputs != 1 {
+		return nil, common.NewValidationError("exactly one of --data, --file, --stdin or --batch is required")
+	}
+
+	if batchPath != "" {
+		return readBatchRecords(batchPath)
+	}
+
+	switch {
+	case dataFile != "":
+		raw, err := ioutil.ReadFile(dataFile)
+		if os.IsNotExist(err) {
+			return nil, common.NewNotFoundError("message data file not found: %s", dataFile)
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read message data file %s; %w", dataFile, err)
+		}
+		data = string(raw)
+	case dataStdin:
+		raw, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read message data from stdin; %w", err)
+		}
+		data = string(raw)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		return nil, common.NewValidationError("failed to parse message data as a JSON object; %s", err.Error())
+	}
+
+	return []*messageRecord{{
+		ID:         id,
+		BaselineID: baselineID,
+		Type:       messageType,
+		Payload:    payload,
+	}}, nil
+}
+
+// readBatchRecords reads the records of the given batch file, having a JSON record per line;
+// a record which cannot be parsed is returned with the problem, so it is reported with the
+// results of the batch and written to the resume file
+func readBatchRecords(path string) ([]*messageRecord, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, common.NewNotFoundError("batch file not found: %s", path)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read batch file %s; %w", path, err)
+	}
+	defer f.Close()
+
+	records := make([]*messageRecord, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBatchRecordSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+
+		record := &messageRecord{}
+		if err := json.Unmarshal([]byte(raw), record); err != nil {
+			record.err = fmt.Sprintf("failed to parse record; %s", err.Error())
+		} else if record.Payload == nil {
+			record.err = "payload: required"
+		}
+		record.line = line
+		record.raw = raw
+		if record.Type == "" && messageType != "" {
+			record.Type = messageType
+			if record.err == "" {
+				// the type is written to the resume file, so it is retained when the batch is resumed
+				record.raw = withRecordType(raw, messageType)
+			}
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch file %s; %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, common.NewValidationError("no records in batch file: %s", path)
+	}
+
+	return records, nil
+}
+
+// withRecordType returns the given raw record having the given type
+func withRecordType(raw, _type string) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+		return raw
+	}
+	fields["type"], _ = json.Marshal(_type)
+	typed, err := json.Marshal(fields)
+	if err != nil {
+		return raw
+	}
+	return string(typed)
+}
+
+// renderBatchResults renders the result of each record of the batch, and writes the mapping
+// of the records which were baselined and the resume file of the records which were not
+func renderBatchResults(records []*messageRecord, results []*messageResult) error {
+	table := common.NewTable("LINE", "ID", "TYPE", "BASELINE ID", "STATUS", "ERROR")
+	for _, result := range results {
+		table.AddRow(strconv.Itoa(result.Line), result.ID, result.Type, result.BaselineID, result.Status, result.Error)
+	}
+	if err := common.Render(results, table); err != nil {
+		return fmt.Errorf("failed to render baseline message results; %w", err)
+	}
+
+	if err := writeMessageMappings(results); err != nil {
+		return err
+	}
+
+	failed := make([]string, 0)
+	for i, result := range results {
+		if result.Status != sendStatusBaselined {
+			failed = append(failed, records[i].raw)
+		}
+	}
+
+	path := resumePath
+	if path == "" && strings.HasSuffix(batchPath, ".resume") {
+		path = batchPath // resuming a batch; overwrite the resume file with the records which failed again
+	} else if path == "" {
+		path = fmt.Sprintf("%s.resume", batchPath)
+	}
+
+	if len(failed) == 0 {
+		// a stale resume file would baseline its records again if passed using --batch
+		if err := os.Remove(path); err == nil {
+			log.Printf("removed resume file %s; every record was baselined", path)
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove resume file %s; %w", path, err)
+		}
+		return nil
+	}
+
+	if err := ioutil.WriteFile(path, []byte(strings.Join(failed, "\n")+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write resume file %s; %w", path, err)
+	}
+	return fmt.Errorf("%d of %d records failed to baseline; resume using --batch %s", len(failed), len(results), path)
+}
+
+// writeMessageMappings appends the mapping of each baselined record to the --mapping file, if given
+func writeMessageMappings(results []*messageResult) error {
+	if mappingPath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(mappingPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open mapping file %s; %w", mappingPath, err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, result := range results {
+		if result.Status != sendStatusBaselined {
+			continue
+		}
+		err := enc.Encode(&messageMapping{
+			ID:         result.ID,
+			Type:       result.Type,
+			BaselineID: result.BaselineID,
+			WorkflowID: workflowID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write mapping file %s; %w", mappingPath, err)
+		}
+	}
+	return nil
+}
+
+// requireWorkflowSchemas retrieves the schema of each step of the --workflow, keyed by message
+// type, or returns nil when no workflow is given
+func requireWorkflowSchemas(token string) (map[string]interface{}, error) {
+	if workflowID == "" {
+		return nil, nil
+	}
+
+	status, resp, err := baseline.InitBaselineService(token).Get("worksteps", map[string]interface{}{
+		"workflow_id": workflowID,
+	})
+	if err == nil && status != 200 {
+		err = fmt.Errorf("status: %v", status)
+	}
+	if err != nil {
+		return nil, common.NewAPIError(err, "failed to retrieve worksteps of baseline workflow %s", workflowID)
+	}
+
+	var worksteps []*struct {
+		Name     string `json:"name"`
+		Metadata *struct {
+			Type   string      `json:"type"`
+			Schema interface{} `json:"schema"`
+		} `json:"metadata"`
+	}
+	raw, _ := json.Marshal(resp)
+	if err := json.Unmarshal(raw, &worksteps); err != nil {
+		return nil, fmt.Errorf("failed to parse worksteps of baseline workflow %s; %w", workflowID, err)
+	}
+
+	schemas := map[string]interface{}{}
+	for _, workstep := range worksteps {
+		if workstep.Metadata != nil && workstep.Metadata.Type != "" {
+			schemas[workstep.Metadata.Type] = workstep.Metadata.Schema
+		}
+	}
+	if len(schemas) == 0 {
+		return nil, common.NewNotFoundError("no worksteps found for baseline workflow %s", workflowID)
+	}
+	return schemas, nil
+}
+
+// resolveRecipients resolves the addresses of the organizations given using --recipients, each of
+// which must participate in the workgroup
+func resolveRecipients() ([]*baseline.Participant, error) {
+	_recipients := make([]*baseline.Participant, 0)
+	if recipients == "" {
+		return _recipients, nil
+	}
+
+	orgs, err := ident.ListApplicationOrganizations(common.ApplicationAccessToken, common.ApplicationID, map[string]interface{}{})
+	if err != nil {
+		return nil, common.NewAPIError(err, "failed to resolve recipient organizations")
+	}
+
+	for _, orgID := range strings.Split(recipients, ",") {
+		orgID = strings.TrimSpace(orgID)
+		var address string
+		found := false
+		for _, org := range orgs {
+			if org.ID.String() == orgID {
+				address, _ = org.Metadata["address"].(string)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, common.NewNotFoundError("recipient organization %s does not participate in workgroup %s", orgID, common.ApplicationID)
+		}
+		if address == "" {
+			return nil, common.NewValidationError("recipient organization %s has not registered an address in workgroup %s", orgID, common.ApplicationID)
+		}
+		_recipients = append(_recipients, &baseline.Participant{
+			Address: &address,
+		})
+	}
+	return _recipients, nil
 }
 
 func init() {
@@ -93,12 +535,20 @@ func init() {
 
 	sendBaselineMessageCmd.Flags().StringVar(&data, "data", "", "content of the message")
 	// sendBaselineMessageCmd.MarkFlagRequired("data")
+	sendBaselineMessageCmd.Flags().StringVar(&dataFile, "file", "", "path of a file containing the content of the message")
+	sendBaselineMessageCmd.Flags().BoolVar(&dataStdin, "stdin", false, "read the content of the message from stdin")
+	sendBaselineMessageCmd.Flags().StringVar(&batchPath, "batch", "", "path of a file having a JSON record to send per line")
+	sendBaselineMessageCmd.Flags().IntVar(&concurrency, "concurrency", defaultSendConcurrency, "maximum number of records of a batch to send at once")
+	sendBaselineMessageCmd.Flags().StringVar(&resumePath, "resume-file", "", "path to which the records of a batch which fail are written (default the batch path with a .resume suffix, or the batch path when resuming)")
+	sendBaselineMessageCmd.Flags().StringVar(&mappingPath, "mapping", "", "path of a file to which the baseline id of each record sent is appended")
 
 	sendBaselineMessageCmd.Flags().StringVar(&id, "id", "", "identifier of the associated payload in the internal system of record")
 	// sendBaselineMessageCmd.MarkFlagRequired("id")
 
 	sendBaselineMessageCmd.Flags().StringVar(&messageType, "type", "", "type of the payload to be baselined")
-	// sendBaselineMessageCmd.Flags().StringVar(&recipients, "recipients", "", "comma-delimited list of recipient organization ids")
+	sendBaselineMessageCmd.Flags().StringVar(&recipients, "recipients", "", "comma-delimited list of recipient organization ids")
+
+	sendBaselineMessageCmd.Flags().StringVar(&workflowID, "workflow", "", "identifier of the workflow against the schemas of which payloads are validated")
 
 	sendBaselineMessageCmd.Flags().StringVar(&common.OrganizationID, "organization", "", "organization identifier")
 	// sendBaselineMessageCmd.MarkFlagRequired("organization")
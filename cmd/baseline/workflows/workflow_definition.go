@@ -0,0 +1,314 @@
+package workflows
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	uuid "github.com/kthomas/go.uuid"
+	"github.com/provideservices/provide-cli/cmd/common"
+	"gopkg.in/yaml.v2"
+)
+
+const defaultWorkflowDefinitionPath = "workflow.yaml"
+
+// workflowDefinitionVersion is the version of the workflow definition format supported by prvd
+const workflowDefinitionVersion = 1
+
+// defaults of a circuit which is declared by a workflow step
+const defaultCircuitProvider = "gnark"
+const defaultCircuitProvingScheme = "groth16"
+const defaultCircuitCurve = "BN254"
+
+// workflowDefinition is a baseline workflow, as declared in a workflow definition file
+type workflowDefinition struct {
+	Version      int                              `yaml:"version" json:"version"`
+	Name         string                           `yaml:"name" json:"name"`
+	Description  string                           `yaml:"description,omitempty" json:"description,omitempty"`
+	Workgroup    string                           `yaml:"workgroup,omitempty" json:"workgroup,omitempty"`
+	Participants []*workflowParticipantDefinition `yaml:"participants" json:"participants"`
+	Steps        []*workflowStepDefinition        `yaml:"steps" json:"steps"`
+}
+
+// workflowParticipantDefinition is a party to the workflow and the role in which it participates
+type workflowParticipantDefinition struct {
+	Name         string `yaml:"name" json:"name"`
+	Organization string `yaml:"organization,omitempty" json:"organization,omitempty"`
+	Address      string `yaml:"address,omitempty" json:"address,omitempty"`
+	Role         string `yaml:"role" json:"role"`
+}
+
+// workflowStepDefinition is a step of the workflow; each step exchanges a single type of
+// message, sent by a participant in one of the sender roles
+type workflowStepDefinition struct {
+	Name            string                    `yaml:"name" json:"name"`
+	Description     string                    `yaml:"description,omitempty" json:"description,omitempty"`
+	Type            string                    `yaml:"type" json:"type"`
+	Schema          interface{}               `yaml:"schema,omitempty" json:"schema,omitempty"`
+	SchemaFile      string                    `yaml:"schema_file,omitempty" json:"-"` // relative to the definition file
+	schemaRead      bool                      // true once the schema has been read from the schema file
+	Senders         []string                  `yaml:"senders" json:"senders"`
+	RequireFinality bool                      `yaml:"require_finality,omitempty" json:"require_finality,omitempty"`
+	Privacy         workflowPrivacyDefinition `yaml:"privacy" json:"privacy"`
+}
+
+// workflowPrivacyDefinition is the means by which the messages of a step are verified; exactly
+// one of an existing circuit, a circuit to create or a verifier contract is declared
+type workflowPrivacyDefinition struct {
+	CircuitID string                     `yaml:"circuit_id,omitempty" json:"circuit_id,omitempty"`
+	Circuit   *workflowCircuitDefinition `yaml:"circuit,omitempty" json:"circuit,omitempty"`
+	Verifier  string                     `yaml:"verifier,omitempty" json:"verifier,omitempty"`
+}
+
+// workflowCircuitDefinition is a circuit which is created using the privacy service when the
+// workflow is initialized
+type workflowCircuitDefinition struct {
+	Name          string `yaml:"name,omitempty" json:"name,omitempty"`
+	Identifier    string `yaml:"identifier" json:"identifier"`
+	Provider      string `yaml:"provider,omitempty" json:"provider,omitempty"`
+	ProvingScheme string `yaml:"proving_scheme,omitempty" json:"proving_scheme,omitempty"`
+	Curve         string `yaml:"curve,omitempty" json:"curve,omitempty"`
+}
+
+// readWorkflowDefinition reads and validates the workflow definition file at the given path;
+// the schema files of its steps are resolved relative to the definition file
+func readWorkflowDefinition(path string) (*workflowDefinition, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, common.NewNotFoundError("workflow definition file not found: %s", path)
+		}
+		return nil, fmt.Errorf("failed to read workflow definition file %s; %w", path, err)
+	}
+
+	def := &workflowDefinition{}
+	if err := yaml.UnmarshalStrict(raw, def); err != nil {
+		return nil, common.NewValidationError("failed to parse workflow definition file %s; %s", path, err.Error())
+	}
+
+	errs := make([]string, 0)
+	for i, step := range def.Steps {
+		if step.SchemaFile == "" {
+			continue
+		}
+		schemaPath := step.SchemaFile
+		if !filepath.IsAbs(schemaPath) {
+			schemaPath = filepath.Join(filepath.Dir(path), schemaPath)
+		}
+		schema, err := ioutil.ReadFile(schemaPath)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("steps[%d].schema_file: failed to read %s; %s", i, schemaPath, err.Error()))
+			continue
+		}
+		if step.Schema != nil {
+			continue // reported by validate
+		}
+		if err := json.Unmarshal(schema, &step.Schema); err != nil {
+			errs = append(errs, fmt.Sprintf("steps[%d].schema_file: failed to parse %s; %s", i, schemaPath, err.Error()))
+			continue
+		}
+		step.schemaRead = true
+	}
+
+	errs = append(def.validate(), errs...)
+	if len(errs) > 0 {
+		return nil, common.NewValidationError("invalid workflow definition file %s:\n  - %s", path, strings.Join(errs, "\n  - "))
+	}
+
+	if err := def.normalize(); err != nil {
+		return nil, fmt.Errorf("failed to read workflow definition file %s; %w", path, err)
+	}
+	return def, nil
+}
+
+// validate returns a description of each problem with the definition
+func (d *workflowDefinition) validate() []string {
+	errs := make([]string, 0)
+
+	if d.Version == 0 {
+		errs = append(errs, "version: required")
+	} else if d.Version != workflowDefinitionVersion {
+		errs = append(errs, fmt.Sprintf("version: unsupported version: %d; this version of prvd supports version %d", d.Version, workflowDefinitionVersion))
+	}
+
+	if d.Name == "" {
+		errs = append(errs, "name: required")
+	}
+	if _, err := uuid.FromString(d.Workgroup); d.Workgroup != "" && err != nil {
+		errs = append(errs, fmt.Sprintf("workgroup: invalid identifier: %s", d.Workgroup))
+	}
+
+	if len(d.Participants) == 0 {
+		errs = append(errs, "participants: at least one participant is required")
+	}
+	participants := map[string]bool{}
+	roles := map[string]bool{}
+	for i, participant := range d.Participants {
+		key := fmt.Sprintf("participants[%d]", i)
+		if participant.Name == "" {
+			errs = append(errs, fmt.Sprintf("%s.name: required", key))
+		} else if participants[participant.Name] {
+			errs = append(errs, fmt.Sprintf("%s.name: duplicate participant: %s", key, participant.Name))
+		}
+		participants[participant.Name] = true
+
+		if participant.Organization == "" && participant.Address == "" {
+			errs = append(errs, fmt.Sprintf("%s: organization or address is required", key))
+		}
+		if _, err := uuid.FromString(participant.Organization); participant.Organization != "" && err != nil {
+			errs = append(errs, fmt.Sprintf("%s.organization: invalid identifier: %s", key, participant.Organization))
+		}
+		if participant.Address != "" && !strings.HasPrefix(participant.Address, "0x") {
+			errs = append(errs, fmt.Sprintf("%s.address: invalid address: %s", key, participant.Address))
+		}
+
+		if participant.Role == "" {
+			errs = append(errs, fmt.Sprintf("%s.role: required", key))
+		}
+		roles[participant.Role] = true
+	}
+
+	if len(d.Steps) == 0 {
+		errs = append(errs, "steps: at least one step is required")
+	}
+	steps := map[string]bool{}
+	for i, step := range d.Steps {
+		key := fmt.Sprintf("steps[%d]", i)
+		if step.Name == "" {
+			errs = append(errs, fmt.Sprintf("%s.name: required", key))
+		} else if steps[step.Name] {
+			errs = append(errs, fmt.Sprintf("%s.name: duplicate step: %s", key, step.Name))
+		}
+		steps[step.Name] = true
+
+		if step.Type == "" {
+			errs = append(errs, fmt.Sprintf("%s.type: required", key))
+		}
+
+		if step.Schema != nil && step.SchemaFile != "" && !step.schemaRead {
+			errs = append(errs, fmt.Sprintf("%s: schema and schema_file are mutually exclusive", key))
+		} else if step.Schema == nil && step.SchemaFile == "" {
+			errs = append(errs, fmt.Sprintf("%s.schema: required", key))
+		} else if step.Schema != nil {
+			for _, problem := range common.CheckJSONSchema(jsonValue(step.Schema)) {
+				errs = append(errs, fmt.Sprintf("%s.schema: %s", key, problem))
+			}
+		}
+
+		if len(step.Senders) == 0 {
+			errs = append(errs, fmt.Sprintf("%s.senders: at least one role is required", key))
+		}
+		for _, role := range step.Senders {
+			if !roles[role] {
+				errs = append(errs, fmt.Sprintf("%s.senders: undefined role: %s", key, role))
+			}
+		}
+
+		errs = append(errs, step.Privacy.validate(fmt.Sprintf("%s.privacy", key))...)
+	}
+
+	return errs
+}
+
+// validate returns a description of each problem with the privacy of the step at the given key
+func (p *workflowPrivacyDefinition) validate(key string) []string {
+	errs := make([]string, 0)
+
+	declared := 0
+	for _, isSet := range []bool{p.CircuitID != "", p.Circuit != nil, p.Verifier != ""} {
+		if isSet {
+			declared++
+		}
+	}
+	if declared != 1 {
+		errs = append(errs, fmt.Sprintf("%s: exactly one of circuit_id, circuit or verifier is required", key))
+	}
+
+	if _, err := uuid.FromString(p.CircuitID); p.CircuitID != "" && err != nil {
+		errs = append(errs, fmt.Sprintf("%s.circuit_id: invalid identifier: %s", key, p.CircuitID))
+	}
+	if p.Circuit != nil && p.Circuit.Identifier == "" {
+		errs = append(errs, fmt.Sprintf("%s.circuit.identifier: required", key))
+	}
+	if p.Verifier != "" && !strings.HasPrefix(p.Verifier, "0x") {
+		errs = append(errs, fmt.Sprintf("%s.verifier: invalid address: %s", key, p.Verifier))
+	}
+
+	return errs
+}
+
+// normalize converts the schemas of the steps to JSON values and applies the circuit defaults,
+// so the definition compares equal to the one recorded when it was initialized
+func (d *workflowDefinition) normalize() error {
+	for _, step := range d.Steps {
+		raw, err := json.Marshal(jsonValue(step.Schema))
+		if err != nil {
+			return fmt.Errorf("failed to marshal schema of step %s; %w", step.Name, err)
+		}
+		step.Schema = nil
+		if err := json.Unmarshal(raw, &step.Schema); err != nil {
+			return fmt.Errorf("failed to unmarshal schema of step %s; %w", step.Name, err)
+		}
+
+		if circuit := step.Privacy.Circuit; circuit != nil {
+			if circuit.Name == "" {
+				circuit.Name = step.Name
+			}
+			if circuit.Provider == "" {
+				circuit.Provider = defaultCircuitProvider
+			}
+			if circuit.ProvingScheme == "" {
+				circuit.ProvingScheme = defaultCircuitProvingScheme
+			}
+			if circuit.Curve == "" {
+				circuit.Curve = defaultCircuitCurve
+			}
+		}
+	}
+	return nil
+}
+
+// participantsInRoles returns the participants in any of the given roles
+func (d *workflowDefinition) participantsInRoles(roles []string) []*workflowParticipantDefinition {
+	participants := make([]*workflowParticipantDefinition, 0)
+	for _, participant := range d.Participants {
+		for _, role := range roles {
+			if participant.Role == role {
+				participants = append(participants, participant)
+				break
+			}
+		}
+	}
+	return participants
+}
+
+// jsonValue converts the maps decoded from YAML, which are keyed by interface{}, to maps keyed
+// by string, so the value can be marshaled to JSON
+func jsonValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		obj := map[string]interface{}{}
+		for key, item := range v {
+			obj[fmt.Sprintf("%v", key)] = jsonValue(item)
+		}
+		return obj
+	case map[string]interface{}:
+		obj := map[string]interface{}{}
+		for key, item := range v {
+			obj[key] = jsonValue(item)
+		}
+		return obj
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = jsonValue(item)
+		}
+		return items
+	case int:
+		return float64(v)
+	}
+	return val
+}
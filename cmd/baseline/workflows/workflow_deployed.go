@@ -0,0 +1,147 @@
+package workflows
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/provideservices/provide-go/api/baseline"
+)
+
+// deployedWorkflow is a workflow as returned by the baseline API; provide-go omits the name
+// and metadata of workflows and worksteps, so they are retrieved using the baseline service
+type deployedWorkflow struct {
+	ID           string                  `json:"id"`
+	Name         string                  `json:"name"`
+	Description  string                  `json:"description,omitempty"`
+	WorkgroupID  string                  `json:"workgroup_id"`
+	Participants []*baseline.Participant `json:"participants"`
+	Metadata     map[string]interface{}  `json:"metadata,omitempty"`
+	CreatedAt    string                  `json:"created_at,omitempty"`
+	Worksteps    []*deployedWorkstep     `json:"worksteps,omitempty"`
+}
+
+// deployedWorkstep is a workstep as returned by the baseline API
+type deployedWorkstep struct {
+	ID              string                  `json:"id"`
+	Name            string                  `json:"name"`
+	Cardinality     int                     `json:"cardinality"`
+	CircuitID       *string                 `json:"circuit_id"`
+	Participants    []*baseline.Participant `json:"participants"`
+	RequireFinality bool                    `json:"require_finality"`
+	WorkflowID      string                  `json:"workflow_id"`
+	Metadata        *workstepMetadata       `json:"metadata,omitempty"`
+}
+
+// workstepMetadata is the definition of a step which is recorded with its workstep
+type workstepMetadata struct {
+	Description string                     `json:"description,omitempty"`
+	Type        string                     `json:"type"`
+	Schema      interface{}                `json:"schema,omitempty"`
+	Senders     []string                   `json:"senders"`
+	Privacy     *workflowPrivacyDefinition `json:"privacy,omitempty"`
+}
+
+// listDeployedWorkflows retrieves the workflows of the given workgroup matching the given params
+func listDeployedWorkflows(token, workgroupID string, params map[string]interface{}) ([]*deployedWorkflow, error) {
+	query := map[string]interface{}{
+		"workgroup_id": workgroupID,
+	}
+	for key, val := range params {
+		query[key] = val
+	}
+
+	workflows := make([]*deployedWorkflow, 0)
+	if err := baselineGet(token, "workflows", query, &workflows); err != nil {
+		return nil, common.NewAPIError(err, "failed to retrieve baseline workflows")
+	}
+	for _, workflow := range workflows {
+		if err := workflow.fetchWorksteps(token); err != nil {
+			return nil, err
+		}
+	}
+	return workflows, nil
+}
+
+// fetchDeployedWorkflow retrieves the workflow with the given id, including its worksteps
+func fetchDeployedWorkflow(token, workflowID string) (*deployedWorkflow, error) {
+	workflow := &deployedWorkflow{}
+	if err := baselineGet(token, fmt.Sprintf("workflows/%s", workflowID), map[string]interface{}{}, workflow); err != nil {
+		return nil, common.NewAPIError(err, "failed to retrieve baseline workflow %s", workflowID)
+	}
+	if err := workflow.fetchWorksteps(token); err != nil {
+		return nil, err
+	}
+	return workflow, nil
+}
+
+// fetchWorksteps retrieves the worksteps of the workflow, ordered by cardinality
+func (w *deployedWorkflow) fetchWorksteps(token string) error {
+	worksteps := make([]*deployedWorkstep, 0)
+	if err := baselineGet(token, "worksteps", map[string]interface{}{"workflow_id": w.ID}, &worksteps); err != nil {
+		return common.NewAPIError(err, "failed to retrieve worksteps of baseline workflow %s", w.ID)
+	}
+	sort.SliceStable(worksteps, func(i, j int) bool {
+		return worksteps[i].Cardinality < worksteps[j].Cardinality
+	})
+	w.Worksteps = worksteps
+	return nil
+}
+
+// definition returns the workflow definition recorded when the workflow was initialized
+func (w *deployedWorkflow) definition() *workflowDefinition {
+	def := &workflowDefinition{
+		Version:      workflowDefinitionVersion,
+		Name:         w.Name,
+		Description:  w.Description,
+		Workgroup:    w.WorkgroupID,
+		Participants: make([]*workflowParticipantDefinition, 0),
+		Steps:        make([]*workflowStepDefinition, 0),
+	}
+
+	for _, participant := range w.Participants {
+		p := &workflowParticipantDefinition{}
+		if resolved, _ := participant.Metadata["address_resolved"].(bool); !resolved {
+			// an address resolved from the organization of the participant is not part of its definition
+			p.Address = common.StringValue(participant.Address)
+		}
+		p.Name, _ = participant.Metadata["name"].(string)
+		p.Organization, _ = participant.Metadata["organization_id"].(string)
+		p.Role, _ = participant.Metadata["role"].(string)
+		def.Participants = append(def.Participants, p)
+	}
+
+	for _, workstep := range w.Worksteps {
+		step := &workflowStepDefinition{
+			Name:            workstep.Name,
+			RequireFinality: workstep.RequireFinality,
+		}
+		if workstep.Metadata != nil {
+			step.Description = workstep.Metadata.Description
+			step.Type = workstep.Metadata.Type
+			step.Schema = workstep.Metadata.Schema
+			step.Senders = workstep.Metadata.Senders
+			if workstep.Metadata.Privacy != nil {
+				step.Privacy = *workstep.Metadata.Privacy
+			}
+		}
+		def.Steps = append(def.Steps, step)
+	}
+
+	return def
+}
+
+// baselineGet retrieves the given resource of the baseline API, unmarshaling it into result
+func baselineGet(token, uri string, params map[string]interface{}, result interface{}) error {
+	status, resp, err := baseline.InitBaselineService(token).Get(uri, params)
+	if err != nil {
+		return err
+	}
+	if status != 200 {
+		return fmt.Errorf("failed to retrieve %s; status: %v", uri, status)
+	}
+
+	raw, _ := json.Marshal(resp)
+	return json.Unmarshal(raw, result)
+}
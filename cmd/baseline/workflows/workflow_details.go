@@ -0,0 +1,58 @@
+package workflows
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var workflowID string
+
+var detailsBaselineWorkflowCmd = &cobra.Command{
+	Use:   "details",
+	Short: "Retrieve a specific baseline workflow",
+	Long:  `Retrieve the details of a baseline workflow and its worksteps by identifier`,
+	RunE:  fetchWorkflowDetails,
+}
+
+func fetchWorkflowDetails(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepDetails)
+}
+
+func fetchWorkflowDetailsRun(cmd *cobra.Command, args []string) error {
+	if err := common.AuthorizeOrganizationContext(false); err != nil {
+		return err
+	}
+
+	workflow, err := fetchDeployedWorkflow(common.OrganizationAccessToken, workflowID)
+	if err != nil {
+		return err
+	}
+
+	table := common.NewTable("#", "WORKSTEP", "NAME", "TYPE", "SENDERS", "CIRCUIT", "VERIFIER")
+	for i, workstep := range workflow.Worksteps {
+		stepType := ""
+		senders := ""
+		verifier := ""
+		if workstep.Metadata != nil {
+			stepType = workstep.Metadata.Type
+			senders = strings.Join(workstep.Metadata.Senders, ",")
+			if workstep.Metadata.Privacy != nil {
+				verifier = workstep.Metadata.Privacy.Verifier
+			}
+		}
+		table.AddRow(strconv.Itoa(i+1), workstep.ID, workstep.Name, stepType, senders, common.StringValue(workstep.CircuitID), verifier)
+	}
+	if err := common.Render(workflow, table); err != nil {
+		return fmt.Errorf("failed to render baseline workflow details; %w", err)
+	}
+	return nil
+}
+
+func init() {
+	detailsBaselineWorkflowCmd.Flags().StringVar(&workflowID, "workflow", "", "workflow identifier")
+	detailsBaselineWorkflowCmd.Flags().StringVar(&common.OrganizationID, "organization", "", "organization identifier")
+}
@@ -0,0 +1,192 @@
+package workflows
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var diffBaselineWorkflowCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare a workflow definition to the deployed workflow",
+	Long: `Compare a workflow definition file to the baseline workflow which was initialized in the workgroup.
+
+The deployed workflow is the workflow of the workgroup having the name of the definition, unless
+--workflow is given. Each participant and step is matched by name; each difference is listed with
+its local and deployed values, and a participant or step which exists on one side only is listed
+with its definition on that side.`,
+	RunE: diffWorkflow,
+}
+
+// workflowDifference is a difference between a local workflow definition and the deployed workflow
+type workflowDifference struct {
+	Path     string `json:"path"` // i.e., steps[purchase order].senders
+	Local    string `json:"local,omitempty"`
+	Deployed string `json:"deployed,omitempty"`
+}
+
+func diffWorkflow(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepDiff)
+}
+
+func diffWorkflowRun(cmd *cobra.Command, args []string) error {
+	def, err := readWorkflowDefinition(workflowDefinitionPath)
+	if err != nil {
+		return err
+	}
+	if common.ApplicationID == "" {
+		common.ApplicationID = def.Workgroup
+	}
+	def.Workgroup = ""
+
+	if err := authorizeWorkflowContext(); err != nil {
+		return err
+	}
+	token := common.OrganizationAccessToken
+
+	var deployed *deployedWorkflow
+	if workflowID != "" {
+		deployed, err = fetchDeployedWorkflow(token, workflowID)
+		if err != nil {
+			return err
+		}
+	} else {
+		workflows, err := listDeployedWorkflows(token, common.ApplicationID, map[string]interface{}{
+			"name": def.Name,
+		})
+		if err != nil {
+			return err
+		}
+		for _, workflow := range workflows {
+			if workflow.Name == def.Name {
+				deployed = workflow
+				break
+			}
+		}
+		if deployed == nil {
+			return common.NewNotFoundError("baseline workflow %s not found in workgroup %s; use 'prvd baseline workflows init' to initialize it", def.Name, common.ApplicationID)
+		}
+	}
+
+	deployedDef := deployed.definition()
+	deployedDef.Workgroup = ""
+	diffs := diffWorkflowDefinitions(def, deployedDef)
+	if len(diffs) == 0 {
+		log.Printf("baseline workflow %s matches %s", deployed.ID, workflowDefinitionPath)
+	}
+
+	table := common.NewTable("PATH", "LOCAL", "DEPLOYED")
+	for _, diff := range diffs {
+		table.AddRow(diff.Path, diffCell(diff.Local), diffCell(diff.Deployed))
+	}
+	if err := common.Render(diffs, table); err != nil {
+		return fmt.Errorf("failed to render baseline workflow differences; %w", err)
+	}
+	return nil
+}
+
+// diffWorkflowDefinitions returns each difference between the local and deployed definitions
+func diffWorkflowDefinitions(local, deployed *workflowDefinition) []*workflowDifference {
+	diffs := make([]*workflowDifference, 0)
+	compare := func(path string, localVal, deployedVal interface{}) {
+		l := diffValue(localVal)
+		d := diffValue(deployedVal)
+		if l != d {
+			diffs = append(diffs, &workflowDifference{
+				Path:     path,
+				Local:    l,
+				Deployed: d,
+			})
+		}
+	}
+
+	compare("name", local.Name, deployed.Name)
+	compare("description", local.Description, deployed.Description)
+
+	deployedParticipants := map[string]*workflowParticipantDefinition{}
+	for _, participant := range deployed.Participants {
+		deployedParticipants[participant.Name] = participant
+	}
+	for _, participant := range local.Participants {
+		path := fmt.Sprintf("participants[%s]", participant.Name)
+		deployedParticipant, ok := deployedParticipants[participant.Name]
+		if !ok {
+			compare(path, participant, nil)
+			continue
+		}
+		delete(deployedParticipants, participant.Name)
+		compare(path+".organization", participant.Organization, deployedParticipant.Organization)
+		compare(path+".address", participant.Address, deployedParticipant.Address)
+		compare(path+".role", participant.Role, deployedParticipant.Role)
+	}
+	for _, participant := range deployed.Participants {
+		if _, ok := deployedParticipants[participant.Name]; ok {
+			compare(fmt.Sprintf("participants[%s]", participant.Name), nil, participant)
+		}
+	}
+
+	deployedSteps := map[string]int{}
+	for i, step := range deployed.Steps {
+		deployedSteps[step.Name] = i
+	}
+	for i, step := range local.Steps {
+		path := fmt.Sprintf("steps[%s]", step.Name)
+		j, ok := deployedSteps[step.Name]
+		if !ok {
+			compare(path, step, nil)
+			continue
+		}
+		delete(deployedSteps, step.Name)
+		deployedStep := deployed.Steps[j]
+		compare(path+".position", i+1, j+1)
+		compare(path+".description", step.Description, deployedStep.Description)
+		compare(path+".type", step.Type, deployedStep.Type)
+		compare(path+".schema", step.Schema, deployedStep.Schema)
+		compare(path+".senders", step.Senders, deployedStep.Senders)
+		compare(path+".require_finality", step.RequireFinality, deployedStep.RequireFinality)
+		compare(path+".privacy", step.Privacy, deployedStep.Privacy)
+	}
+	for _, step := range deployed.Steps {
+		if _, ok := deployedSteps[step.Name]; ok {
+			compare(fmt.Sprintf("steps[%s]", step.Name), nil, step)
+		}
+	}
+
+	return diffs
+}
+
+// diffValue returns the given value as compared and rendered by diff; values other than strings
+// are compared as compact JSON, which orders the keys of objects
+func diffValue(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	raw, _ := json.Marshal(val)
+	return string(raw)
+}
+
+// diffCell returns the given value for tabular output
+func diffCell(val string) string {
+	if val == "" {
+		return "-"
+	}
+	return val
+}
+
+func init() {
+	diffBaselineWorkflowCmd.Flags().StringVarP(&workflowDefinitionPath, "file", "f", defaultWorkflowDefinitionPath, "path of the workflow definition file")
+	diffBaselineWorkflowCmd.Flags().StringVar(&workflowID, "workflow", "", "identifier of the deployed workflow; defaults to the workflow of the workgroup having the name of the definition")
+	diffBaselineWorkflowCmd.Flags().StringVar(&common.ApplicationID, "workgroup", "", "workgroup identifier; overrides the workgroup in the definition")
+	diffBaselineWorkflowCmd.Flags().StringVar(&common.OrganizationID, "organization", "", "organization identifier")
+}
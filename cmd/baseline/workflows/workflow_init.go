@@ -1,37 +1,321 @@
 package workflows
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"strings"
 
+	uuid "github.com/kthomas/go.uuid"
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/provideservices/provide-go/api/baseline"
+	"github.com/provideservices/provide-go/api/ident"
+	"github.com/provideservices/provide-go/api/privacy"
 	"github.com/spf13/cobra"
 )
 
 var name string
-var workgroupID string
+var workflowDefinitionPath string
 var Optional bool
 
 var initBaselineWorkflowCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize baseline workflow",
-	Long:  `Initialize and configure a new baseline workflow`,
-	Run:   initWorkflow,
+	Long:  `Initialize a baseline workflow and its worksteps from a workflow definition file; the definition is validated and resolved before anything is created, and a partially initialized workflow is deleted`,
+	Example: `  prvd baseline workflows init -f workflow.yaml --workgroup <workgroup id> --organization <organization id>
+
+  # workflow.yaml; a participant declared by organization only is resolved to its registered address
+  version: 1
+  name: Procure to Pay
+  participants:
+    - name: Acme
+      organization: 5d3c6a9e-0c0a-4c33-a4b5-1f3b2a3e4d5c
+      role: buyer
+    - name: Globex
+      address: "0x5E250bB077ec836915155229E83d187715266167"
+      role: seller
+  steps:
+    - name: purchase order
+      type: purchase_order
+      # the schema may use the type, properties, required, additionalProperties, items, enum, pattern,
+      # minimum, maximum, exclusiveMinimum, exclusiveMaximum, minLength, maxLength, minItems and
+      # maxItems keywords; any other validation keyword, e.g., $ref, oneOf or format, is rejected
+      schema_file: schemas/purchase_order.json
+      senders: [buyer]
+      privacy:
+        circuit:
+          identifier: purchase_order`,
+	RunE: initWorkflow,
 }
 
-func initWorkflow(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepInit)
+func initWorkflow(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepInit)
 }
 
-func initWorkflowRun(cmd *cobra.Command, args []string) {
-	log.Printf("not implemented")
-	os.Exit(1)
+func initWorkflowRun(cmd *cobra.Command, args []string) error {
+	def, err := readWorkflowDefinition(workflowDefinitionPath)
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		def.Name = name
+	}
+	if common.ApplicationID == "" {
+		common.ApplicationID = def.Workgroup
+	}
+	def.Workgroup = ""
+
+	if err := authorizeWorkflowContext(); err != nil {
+		return err
+	}
+	token := common.OrganizationAccessToken
+
+	existing, err := listDeployedWorkflows(token, common.ApplicationID, map[string]interface{}{
+		"name": def.Name,
+	})
+	if err != nil {
+		return err
+	}
+	for _, workflow := range existing {
+		if workflow.Name == def.Name {
+			return common.NewValidationError("baseline workflow %s already exists in workgroup %s: %s; use 'prvd baseline workflows diff' to compare it to the definition", def.Name, common.ApplicationID, workflow.ID)
+		}
+	}
+
+	// the definition is resolved against the workgroup and the privacy service before any
+	// circuit, workflow or workstep is created
+	addresses, err := resolveParticipantAddresses(def)
+	if err != nil {
+		return err
+	}
+	if err := resolveWorkflowCircuits(token, def); err != nil {
+		return err
+	}
+
+	circuitIDs, err := requireWorkflowCircuits(token, def)
+	if err != nil {
+		return err
+	}
+
+	workflow, err := baseline.CreateWorkflow(token, map[string]interface{}{
+		"name":         def.Name,
+		"description":  def.Description,
+		"workgroup_id": common.ApplicationID,
+		"participants": workflowParticipants(def.Participants, addresses),
+		"metadata": map[string]interface{}{
+			"version": def.Version,
+		},
+	})
+	if err != nil {
+		return createdCircuitsError(def, circuitIDs, common.NewAPIError(err, "failed to initialize baseline workflow %s", def.Name))
+	}
+	if workflow.ID == nil {
+		return createdCircuitsError(def, circuitIDs, fmt.Errorf("failed to initialize baseline workflow %s; no workflow id returned by the baseline API", def.Name))
+	}
+
+	workstepIDs := make([]string, 0)
+	table := common.NewTable("STEP", "TYPE", "SENDERS", "WORKSTEP", "CIRCUIT", "VERIFIER")
+	for i, step := range def.Steps {
+		params := map[string]interface{}{
+			"name":             step.Name,
+			"cardinality":      i + 1,
+			"workflow_id":      workflow.ID.String(),
+			"participants":     workflowParticipants(def.participantsInRoles(step.Senders), addresses),
+			"require_finality": step.RequireFinality,
+			"metadata": &workstepMetadata{
+				Description: step.Description,
+				Type:        step.Type,
+				Schema:      step.Schema,
+				Senders:     step.Senders,
+				Privacy:     &step.Privacy,
+			},
+		}
+		if circuitIDs[i] != "" {
+			params["circuit_id"] = circuitIDs[i]
+		}
+
+		workstep, err := baseline.CreateWorkstep(token, params)
+		if err == nil && workstep.ID == nil {
+			err = errors.New("no workstep id returned by the baseline API")
+		}
+		if err != nil {
+			err = common.NewAPIError(err, "failed to initialize workstep %s of baseline workflow %s", step.Name, workflow.ID)
+			return createdCircuitsError(def, circuitIDs, rollbackWorkflow(token, workflow.ID.String(), workstepIDs, err))
+		}
+		workstepIDs = append(workstepIDs, workstep.ID.String())
+		table.AddRow(step.Name, step.Type, strings.Join(step.Senders, ","), workstep.ID.String(), circuitIDs[i], step.Privacy.Verifier)
+	}
+
+	log.Printf("initialized baseline workflow: %s", workflow.ID)
+	if err := common.Render(workflow, table); err != nil {
+		return fmt.Errorf("failed to render baseline workflow; %w", err)
+	}
+	return nil
 }
 
-func init() {
-	initBaselineWorkflowCmd.Flags().StringVar(&name, "name", "", "name of the baseline workflow")
-	// initBaselineWorkflowCmd.MarkFlagRequired("name")
+// rollbackWorkflow deletes the given worksteps and workflow, which were created before the given
+// error occurred, so the definition can be initialized again; the returned error describes the
+// outcome of the rollback
+func rollbackWorkflow(token, workflowID string, workstepIDs []string, err error) error {
+	svc := baseline.InitBaselineService(token)
+	uris := make([]string, 0)
+	for i := len(workstepIDs) - 1; i >= 0; i-- {
+		uris = append(uris, fmt.Sprintf("worksteps/%s", workstepIDs[i]))
+	}
+	uris = append(uris, fmt.Sprintf("workflows/%s", workflowID))
+
+	for _, uri := range uris {
+		status, _, deleteErr := svc.Delete(uri)
+		if deleteErr == nil && status != 204 && status != 200 {
+			deleteErr = fmt.Errorf("status: %v", status)
+		}
+		if deleteErr != nil {
+			return fmt.Errorf("%w; failed to delete %s of the partially initialized baseline workflow %s; %s; delete the workflow before initializing it again", err, uri, workflowID, deleteErr.Error())
+		}
+	}
+
+	log.Printf("deleted partially initialized baseline workflow: %s", workflowID)
+	return fmt.Errorf("%w; the partially initialized baseline workflow %s was deleted, so the definition can be initialized again", err, workflowID)
+}
 
-	initBaselineWorkflowCmd.Flags().StringVar(&workgroupID, "workgroup", "", "workgroup identifier")
-	// initBaselineWorkflowCmd.MarkFlagRequired("workgroup")
+// createdCircuitsError annotates the given error with the circuits created for the steps of the
+// definition, which the privacy service does not support deleting, so they can be referenced
+// using circuit_id when the definition is initialized again
+func createdCircuitsError(def *workflowDefinition, circuitIDs []string, err error) error {
+	created := make([]string, 0)
+	for i, step := range def.Steps {
+		if step.Privacy.Circuit != nil && circuitIDs[i] != "" {
+			created = append(created, fmt.Sprintf("%s: %s", step.Name, circuitIDs[i]))
+		}
+	}
+	if len(created) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w; circuits created for the definition remain, and may be referenced using circuit_id: %s", err, strings.Join(created, ", "))
+}
+
+// resolveParticipantAddresses resolves the address of each participant of the given definition
+// which is declared by organization only, using the organizations of the workgroup; the
+// resolved addresses are returned keyed by participant name
+func resolveParticipantAddresses(def *workflowDefinition) (map[string]string, error) {
+	addresses := map[string]string{}
+	unresolved := make([]*workflowParticipantDefinition, 0)
+	for _, participant := range def.Participants {
+		if participant.Address == "" {
+			unresolved = append(unresolved, participant)
+		}
+	}
+	if len(unresolved) == 0 {
+		return addresses, nil
+	}
+
+	if err := common.AuthorizeApplicationContext(); err != nil {
+		return nil, err
+	}
+	orgs, err := ident.ListApplicationOrganizations(common.ApplicationAccessToken, common.ApplicationID, map[string]interface{}{})
+	if err != nil {
+		return nil, common.NewAPIError(err, "failed to resolve the organizations of workgroup %s", common.ApplicationID)
+	}
+	orgAddresses := map[string]string{}
+	for _, org := range orgs {
+		addr, _ := org.Metadata["address"].(string)
+		orgAddresses[org.ID.String()] = addr
+	}
+
+	errs := make([]string, 0)
+	for _, participant := range unresolved {
+		addr, ok := orgAddresses[participant.Organization]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Sprintf("participants[%s].organization: %s is not an organization of workgroup %s", participant.Name, participant.Organization, common.ApplicationID))
+		case addr == "" || addr == "0x":
+			errs = append(errs, fmt.Sprintf("participants[%s].organization: %s has no registered address; declare the address of the participant", participant.Name, participant.Organization))
+		default:
+			addresses[participant.Name] = addr
+		}
+	}
+	if len(errs) > 0 {
+		return nil, common.NewValidationError("failed to resolve the participants of baseline workflow %s:\n  - %s", def.Name, strings.Join(errs, "\n  - "))
+	}
+	return addresses, nil
+}
+
+// resolveWorkflowCircuits verifies each existing circuit referenced by the steps of the given
+// definition using circuit_id
+func resolveWorkflowCircuits(token string, def *workflowDefinition) error {
+	for _, step := range def.Steps {
+		if step.Privacy.CircuitID == "" {
+			continue
+		}
+		if _, err := privacy.GetCircuitDetails(token, step.Privacy.CircuitID); err != nil {
+			return common.NewAPIError(err, "failed to resolve circuit %s of step %s", step.Privacy.CircuitID, step.Name)
+		}
+	}
+	return nil
+}
+
+// requireWorkflowCircuits resolves the circuit of each step of the given definition, creating
+// the circuits it declares using the privacy service; the id of the circuit of each step is
+// returned in step order, or an empty string for a step which is verified by a contract
+func requireWorkflowCircuits(token string, def *workflowDefinition) ([]string, error) {
+	circuitIDs := make([]string, len(def.Steps))
+	for i, step := range def.Steps {
+		switch {
+		case step.Privacy.CircuitID != "":
+			circuitIDs[i] = step.Privacy.CircuitID
+		case step.Privacy.Circuit != nil:
+			circuit, err := privacy.CreateCircuit(token, map[string]interface{}{
+				"name":           step.Privacy.Circuit.Name,
+				"description":    fmt.Sprintf("%s step of the %s baseline workflow", step.Name, def.Name),
+				"identifier":     step.Privacy.Circuit.Identifier,
+				"provider":       step.Privacy.Circuit.Provider,
+				"proving_scheme": step.Privacy.Circuit.ProvingScheme,
+				"curve":          step.Privacy.Circuit.Curve,
+			})
+			if err != nil {
+				return nil, createdCircuitsError(def, circuitIDs, common.NewAPIError(err, "failed to create circuit %s of step %s", step.Privacy.Circuit.Identifier, step.Name))
+			}
+			if circuit.Model == nil || circuit.ID == uuid.Nil {
+				return nil, createdCircuitsError(def, circuitIDs, fmt.Errorf("failed to create circuit %s of step %s; no circuit id returned by the privacy service", step.Privacy.Circuit.Identifier, step.Name))
+			}
+			circuitIDs[i] = circuit.ID.String()
+		}
+	}
+	return circuitIDs, nil
+}
+
+// workflowParticipants returns the baseline participants of the given workflow participants,
+// using the given addresses resolved for participants declared by organization only; the
+// name, role and organization of each participant is recorded as its metadata
+func workflowParticipants(participants []*workflowParticipantDefinition, addresses map[string]string) []*baseline.Participant {
+	_participants := make([]*baseline.Participant, 0)
+	for _, participant := range participants {
+		_participant := &baseline.Participant{
+			Metadata: map[string]interface{}{
+				"name": participant.Name,
+				"role": participant.Role,
+			},
+		}
+		if participant.Address != "" {
+			address := participant.Address
+			_participant.Address = &address
+		} else if address, ok := addresses[participant.Name]; ok {
+			_participant.Address = &address
+			_participant.Metadata["address_resolved"] = true
+		}
+		if participant.Organization != "" {
+			_participant.Metadata["organization_id"] = participant.Organization
+		}
+		_participants = append(_participants, _participant)
+	}
+	return _participants
+}
+
+func init() {
+	initBaselineWorkflowCmd.Flags().StringVarP(&workflowDefinitionPath, "file", "f", defaultWorkflowDefinitionPath, "path of the workflow definition file")
+	initBaselineWorkflowCmd.Flags().StringVar(&name, "name", "", "name of the baseline workflow; overrides the name in the definition")
+	initBaselineWorkflowCmd.Flags().StringVar(&common.ApplicationID, "workgroup", "", "workgroup identifier; overrides the workgroup in the definition")
+	initBaselineWorkflowCmd.Flags().StringVar(&common.OrganizationID, "organization", os.Getenv("PROVIDE_ORGANIZATION_ID"), "organization identifier")
 	initBaselineWorkflowCmd.Flags().BoolVarP(&Optional, "optional", "", false, "List all the Optional flags")
 }
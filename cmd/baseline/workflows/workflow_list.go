@@ -0,0 +1,45 @@
+package workflows
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var listBaselineWorkflowsCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List baseline workflows",
+	Long:  `List the baseline workflows of a workgroup`,
+	RunE:  listWorkflows,
+}
+
+func listWorkflows(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepList)
+}
+
+func listWorkflowsRun(cmd *cobra.Command, args []string) error {
+	if err := authorizeWorkflowContext(); err != nil {
+		return err
+	}
+
+	workflows, err := listDeployedWorkflows(common.OrganizationAccessToken, common.ApplicationID, map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+
+	table := common.NewTable("ID", "NAME", "PARTICIPANTS", "STEPS")
+	for _, workflow := range workflows {
+		table.AddRow(workflow.ID, workflow.Name, strconv.Itoa(len(workflow.Participants)), strconv.Itoa(len(workflow.Worksteps)))
+	}
+	if err := common.Render(workflows, table); err != nil {
+		return fmt.Errorf("failed to render baseline workflows; %w", err)
+	}
+	return nil
+}
+
+func init() {
+	listBaselineWorkflowsCmd.Flags().StringVar(&common.ApplicationID, "workgroup", "", "workgroup identifier")
+	listBaselineWorkflowsCmd.Flags().StringVar(&common.OrganizationID, "organization", "", "organization identifier")
+}
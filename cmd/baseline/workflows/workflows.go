@@ -10,15 +10,35 @@ var WorkflowsCmd = &cobra.Command{
 	Use:   "workflows",
 	Short: "Interact with a baseline workflows",
 	Long:  `Create, manage and interact with workflows via the baseline protocol.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		common.CmdExistsOrExit(cmd, args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
 
-		generalPrompt(cmd, args, "")
+		return generalPrompt(cmd, args, "")
 	},
 }
 
+// authorizeWorkflowContext authorizes the organization on behalf of which the workflows of the
+// workgroup are managed using its baseline API
+func authorizeWorkflowContext() error {
+	if err := common.RequireWorkgroup(); err != nil {
+		return err
+	}
+	if err := common.RequireOrganization(); err != nil {
+		return err
+	}
+	if err := common.MissingInput(); err != nil {
+		return err
+	}
+	return common.AuthorizeOrganizationContext(false)
+}
+
 func init() {
+	WorkflowsCmd.AddCommand(detailsBaselineWorkflowCmd)
+	WorkflowsCmd.AddCommand(diffBaselineWorkflowCmd)
 	WorkflowsCmd.AddCommand(initBaselineWorkflowCmd)
+	WorkflowsCmd.AddCommand(listBaselineWorkflowsCmd)
 	WorkflowsCmd.AddCommand(messages.MessagesCmd)
 	WorkflowsCmd.Flags().BoolVarP(&Optional, "optional", "", false, "List all the Optional flags")
 
@@ -8,27 +8,44 @@ import (
 )
 
 const promptStepInit = "Initialize"
+const promptStepList = "List"
+const promptStepDetails = "Details"
+const promptStepDiff = "Diff"
 const promptStepMessages = "Messages"
 
-var emptyPromptArgs = []string{promptStepInit, promptStepMessages}
+var emptyPromptArgs = []string{promptStepInit, promptStepList, promptStepDetails, promptStepDiff, promptStepMessages}
 var emptyPromptLabel = "What would you like to do"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, step string) {
+func generalPrompt(cmd *cobra.Command, args []string, step string) error {
+	var err error
+
 	switch step {
 	case promptStepInit:
-		if workgroupID == "" {
-			common.RequireWorkgroup()
+		return initWorkflowRun(cmd, args)
+	case promptStepList:
+		return listWorkflowsRun(cmd, args)
+	case promptStepDetails:
+		if workflowID == "" {
+			if workflowID, err = common.RequireFreeInput("--workflow", "Workflow ID", "", common.MandatoryValidation); err != nil {
+				return err
+			}
 		}
-		if name == "" {
-			name = common.FreeInput("Name", "", common.MandatoryValidation)
+		if err := common.MissingInput(); err != nil {
+			return err
 		}
-		initWorkflowRun(cmd, args)
+		return fetchWorkflowDetailsRun(cmd, args)
+	case promptStepDiff:
+		return diffWorkflowRun(cmd, args)
 	case promptStepMessages:
 		messages.Optional = Optional
-		messages.MessagesCmd.Run(cmd, args)
+		return messages.MessagesCmd.RunE(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
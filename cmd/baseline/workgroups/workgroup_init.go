@@ -30,33 +30,58 @@ var initBaselineWorkgroupCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize baseline workgroup",
 	Long:  `Initialize and configure a new baseline workgroup`,
-	Run:   initWorkgroup,
+	RunE:  initWorkgroup,
 }
 
-func authorizeApplicationContext() {
-	common.AuthorizeApplicationContext()
+func authorizeApplicationContext() error {
+	if err := common.AuthorizeApplicationContext(); err != nil {
+		return err
+	}
 	_, err := nchain.CreateWallet(common.ApplicationAccessToken, map[string]interface{}{
 		"purpose": 44,
 	})
 	if err != nil {
-		log.Printf("failed to initialize HD wallet; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "failed to initialize HD wallet")
 	}
+	return nil
 }
-func initWorkgroup(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepInit)
+func initWorkgroup(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepInit)
 }
 
-func initWorkgroupRun(cmd *cobra.Command, args []string) {
+func initWorkgroupRun(cmd *cobra.Command, args []string) error {
 	if name == "" {
-		namePrompt()
+		if err := namePrompt(); err != nil {
+			return err
+		}
 	}
 	if common.NetworkID == "" {
-		common.RequirePublicNetwork()
+		if err := common.RequirePublicNetwork(); err != nil {
+			return err
+		}
+	}
+	if err := common.RequireOrganization(); err != nil {
+		return err
+	}
+	if err := common.MissingInput(); err != nil {
+		return err
 	}
-	common.AuthorizeOrganizationContext(true)
 
-	token := common.RequireUserAccessToken()
+	_, err := InitWorkgroup(name)
+	return err
+}
+
+// InitWorkgroup initializes a baseline workgroup with the given name on behalf of the
+// configured organization, which is registered as its first participant
+func InitWorkgroup(name string) (*ident.Application, error) {
+	if err := common.AuthorizeOrganizationContext(true); err != nil {
+		return nil, err
+	}
+
+	token, err := common.RequireUserAccessToken()
+	if err != nil {
+		return nil, err
+	}
 	application, err := ident.CreateApplication(token, map[string]interface{}{
 		"config": map[string]interface{}{
 			"baselined": true,
@@ -66,22 +91,30 @@ func initWorkgroupRun(cmd *cobra.Command, args []string) {
 		"type":       defaultWorkgroupType,
 	})
 	if err != nil {
-		log.Printf("failed to initialize baseline workgroup; %s", err.Error())
-		os.Exit(1)
+		return nil, common.NewAPIError(err, "failed to initialize baseline workgroup")
 	}
 
 	common.ApplicationID = application.ID.String()
-	authorizeApplicationContext()
+	if err := authorizeApplicationContext(); err != nil {
+		return nil, err
+	}
 
-	common.InitWorkgroupContract()
+	if _, err := common.InitWorkgroupContract(); err != nil {
+		return nil, err
+	}
 
-	common.RequireOrganizationVault()
+	if err := common.RequireOrganizationVault(); err != nil {
+		return nil, err
+	}
 	requireOrganizationKeys()
 
-	common.RegisterWorkgroupOrganization(application.ID.String())
+	if err := common.RegisterWorkgroupOrganization(application.ID.String()); err != nil {
+		return nil, err
+	}
 	//common.RequireOrganizationEndpoints(nil)
 
 	log.Printf("initialized baseline workgroup: %s", application.ID)
+	return application, nil
 }
 
 func requireOrganizationKeys() {
@@ -109,21 +142,17 @@ func requireOrganizationKeys() {
 	}
 }
 
-func namePrompt() {
-	prompt := promptui.Prompt{
-		Label: "Workgroup Name",
-	}
+func namePrompt() error {
+	var err error
+	name, err = common.RequireFreeInput("--name", "Workgroup Name", "", common.NoValidation)
+	return err
+}
 
-	result, err := prompt.Run()
-	if err != nil {
-		os.Exit(1)
-		return
+func organizationAuthPrompt(target string) error {
+	if !common.IsInteractive() {
+		return nil
 	}
 
-	name = result
-}
-
-func organizationAuthPrompt(target string) {
 	prompt := promptui.Prompt{
 		IsConfirm: true,
 		Label:     fmt.Sprintf("Authorize access/refresh token for %s?", target),
@@ -131,13 +160,13 @@ func organizationAuthPrompt(target string) {
 
 	result, err := prompt.Run()
 	if err != nil {
-		os.Exit(1)
-		return
+		return err
 	}
 
 	if strings.ToLower(result) == "y" {
-		common.AuthorizeOrganizationContext(true)
+		return common.AuthorizeOrganizationContext(true)
 	}
+	return nil
 }
 
 func init() {
@@ -1,11 +1,11 @@
 package workgroups
 
 import (
+	"fmt"
 	"log"
 	"os"
 
 	"github.com/dgrijalva/jwt-go"
-	"github.com/manifoldco/promptui"
 	"github.com/provideservices/provide-cli/cmd/api_tokens"
 	"github.com/provideservices/provide-cli/cmd/common"
 	"github.com/provideservices/provide-go/api/baseline"
@@ -31,85 +31,129 @@ var joinBaselineWorkgroupCmd = &cobra.Command{
 	Use:   "join",
 	Short: "Join a baseline workgroup",
 	Long:  `Join a baseline workgroup by accepting the invite.`,
-	Run:   joinWorkgroup,
+	RunE:  joinWorkgroup,
 }
 
-func joinWorkgroup(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepJoin)
+func joinWorkgroup(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepJoin)
 }
 
-func joinWorkgroupRun(cmd *cobra.Command, args []string) {
+func joinWorkgroupRun(cmd *cobra.Command, args []string) error {
 	if inviteJWT == "" {
-		jwtPrompt()
+		if err := jwtPrompt(); err != nil {
+			return err
+		}
+	}
+	if err := common.RequireOrganization(); err != nil {
+		return err
 	}
+	if err := common.MissingInput(); err != nil {
+		return err
+	}
+
+	claims, err := JoinWorkgroup(inviteJWT)
+	if err != nil {
+		return err
+	}
+
+	return ConfigureBaselineStack(inviteJWT, claims)
+}
 
-	api_tokens.RequirePublicJWTVerifiers() // FIXME...
-	claims := parseJWT(inviteJWT)
+// JoinWorkgroup accepts the given invitation on behalf of the configured organization, which
+// is registered as a participant of the workgroup
+func JoinWorkgroup(jwt string) (*InviteClaims, error) {
+	if err := api_tokens.RequirePublicJWTVerifiers(); err != nil { // FIXME...
+		return nil, err
+	}
+	claims, err := parseJWT(jwt)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Baseline == nil || claims.Baseline.WorkgroupID == nil {
+		return nil, common.NewValidationError("invitation does not contain a baseline workgroup")
+	}
 	log.Printf("resolved baseline claims containing invitation for workgroup: %s", *claims.Baseline.WorkgroupID)
 
 	common.ApplicationID = *claims.Baseline.WorkgroupID
-	common.AuthorizeOrganizationContext(true)
-	authorizeApplicationContext()
+	if err := common.AuthorizeOrganizationContext(true); err != nil {
+		return nil, err
+	}
+	if err := authorizeApplicationContext(); err != nil {
+		return nil, err
+	}
 
 	// initWorkgroupContract()
 
-	common.RequireOrganizationVault()
+	if err := common.RequireOrganizationVault(); err != nil {
+		return nil, err
+	}
 	requireOrganizationKeys()
-	common.RegisterWorkgroupOrganization(common.ApplicationID)
+	if err := common.RegisterWorkgroupOrganization(common.ApplicationID); err != nil {
+		return nil, err
+	}
 	// common.RequireOrganizationEndpoints(nil)
 
-	configureBaselineStack(inviteJWT, claims)
+	return claims, nil
 }
 
-func parseJWT(token string) *InviteClaims {
+func parseJWT(token string) (*InviteClaims, error) {
 	claims := &InviteClaims{}
 
 	var jwtParser jwt.Parser
 	jwtToken, _, err := jwtParser.ParseUnverified(token, claims)
 	if err != nil {
-		log.Printf("failed to parse JWT; %s", err.Error())
-		os.Exit(1)
+		return nil, common.NewValidationError("failed to parse JWT; %s", err.Error())
 	}
 
 	// FIXME-- use the unverified key material to lookup the signer's public key for verification below...
 	if false {
 		jwtToken, err = api_tokens.ParseJWT(token)
 		if err != nil {
-			log.Printf("failed to parse JWT; %s", err.Error())
-			os.Exit(1)
+			return nil, common.NewValidationError("failed to parse JWT; %s", err.Error())
 		}
 
 		log.Printf("%v", jwtToken)
 	}
 
-	return claims
+	return claims, nil
 }
 
-// configureBaselineStack initializes a workgroup in the context of the running baseline stack
-func configureBaselineStack(jwt string, claims *InviteClaims) {
-	token := common.RequireAPIToken()
-	_, err := baseline.CreateWorkgroup(token, map[string]interface{}{
-		"token": jwt,
-	})
-	if err != nil {
-		// log.Printf("failed to configure baseline stack to support joined workgroup; %s", err.Error())
-		os.Exit(1)
-	}
-	log.Printf("configured baseline workgroup on local stack: %s", *claims.Baseline.WorkgroupID)
+// ConfigureBaselineStack initializes a workgroup in the context of the running baseline stack
+func ConfigureBaselineStack(jwt string, claims *InviteClaims) error {
+	return ConfigureLocalBaselineStack("", jwt, claims)
 }
 
-func jwtPrompt() {
-	prompt := promptui.Prompt{
-		Label: "Verifiable Credential (Invite JWT)",
+// ConfigureLocalBaselineStack initializes a workgroup in the context of the baseline stack whose
+// API is served at the given host, i.e., localhost:8080; the configured baseline API is used when
+// host is empty
+func ConfigureLocalBaselineStack(host, jwt string, claims *InviteClaims) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
 	}
 
-	result, err := prompt.Run()
+	svc := baseline.InitBaselineService(token)
+	if host != "" {
+		svc.Host = host
+		svc.Scheme = "http"
+	}
+	status, _, err := svc.Post("workgroups", map[string]interface{}{
+		"token": jwt,
+	})
+	if err == nil && status != 200 {
+		err = fmt.Errorf("failed to create workgroup; status: %v", status)
+	}
 	if err != nil {
-		os.Exit(1)
-		return
+		return common.NewAPIError(err, "failed to configure baseline stack to support joined workgroup")
 	}
+	log.Printf("configured baseline workgroup on local stack: %s", *claims.Baseline.WorkgroupID)
+	return nil
+}
 
-	inviteJWT = result
+func jwtPrompt() error {
+	var err error
+	inviteJWT, err = common.RequireFreeInput("--jwt", "Verifiable Credential (Invite JWT)", "", common.NoValidation)
+	return err
 }
 
 func init() {
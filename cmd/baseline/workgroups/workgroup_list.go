@@ -2,8 +2,6 @@ package workgroups
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	ident "github.com/provideservices/provide-go/api/ident"
@@ -14,27 +12,33 @@ var listBaselineWorkgroupsCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List baseline workgroups",
 	Long:  `List all available baseline workgroups`,
-	Run:   listWorkgroups,
+	RunE:  listWorkgroups,
 }
 
-func listWorkgroups(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepList)
+func listWorkgroups(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepList)
 }
 
-func listWorkgroupsRun(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func listWorkgroupsRun(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	applications, err := ident.ListApplications(token, map[string]interface{}{
 		"type": "baseline",
 	})
 	if err != nil {
-		log.Printf("failed to retrieve baseline workgroups; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "failed to retrieve baseline workgroups")
 	}
+	table := common.NewTable("ID", "NAME")
 	for i := range applications {
 		workgroup := applications[i]
-		result := fmt.Sprintf("%s\t%s\n", workgroup.ID.String(), *workgroup.Name)
-		fmt.Print(result)
+		table.AddRow(workgroup.ID.String(), common.StringValue(workgroup.Name))
+	}
+	if err := common.Render(applications, table); err != nil {
+		return fmt.Errorf("failed to render baseline workgroups; %w", err)
 	}
+	return nil
 }
 
 func init() {
@@ -10,10 +10,12 @@ var WorkgroupsCmd = &cobra.Command{
 	Use:   "workgroups",
 	Short: "Interact with a baseline workgroups",
 	Long:  `Create, manage and interact with workgroups via the baseline protocol.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		common.CmdExistsOrExit(cmd, args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
 
-		generalPrompt(cmd, args, "")
+		return generalPrompt(cmd, args, "")
 	},
 }
 
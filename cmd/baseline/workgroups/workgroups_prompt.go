@@ -16,40 +16,58 @@ var emptyPromptArgs = []string{promptStepInit, promptStepList, promptStepJoin}
 var emptyPromptLabel = "What would you like to do"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, step string) {
+func generalPrompt(cmd *cobra.Command, args []string, step string) error {
+	var err error
+
 	switch step {
 	case promptStepInit:
 		if Optional {
 			fmt.Println("Optional Flags:")
 			if common.NetworkID == "" {
-				common.RequirePublicNetwork()
+				if err := common.RequirePublicNetwork(); err != nil {
+					return err
+				}
 			}
 			if common.OrganizationID == "" {
-				common.RequireOrganization()
+				if err := common.RequireOrganization(); err != nil {
+					return err
+				}
 			}
 			if common.MessagingEndpoint == "" {
-				common.MessagingEndpoint = common.FreeInput("Messaging Endpoint", "", common.NoValidation)
+				if common.MessagingEndpoint, err = common.FreeInput("Messaging Endpoint", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if name == "" {
-				name = common.FreeInput("Name", "", common.NoValidation)
+				if name, err = common.FreeInput("Name", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 		}
-		initWorkgroupRun(cmd, args)
+		return initWorkgroupRun(cmd, args)
 	case promptStepList:
-		listWorkgroupsRun(cmd, args)
+		return listWorkgroupsRun(cmd, args)
 	case promptStepJoin:
 		if Optional {
 			fmt.Println("Optional Flags:")
 			if common.OrganizationID == "" {
-				common.RequireOrganization()
+				if err := common.RequireOrganization(); err != nil {
+					return err
+				}
 			}
 			if inviteJWT == "" {
-				inviteJWT = common.FreeInput("JWT Invite", "", common.NoValidation)
+				if inviteJWT, err = common.FreeInput("JWT Invite", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 		}
-		joinWorkgroupRun(cmd, args)
+		return joinWorkgroupRun(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
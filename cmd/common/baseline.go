@@ -1,23 +1,18 @@
 package common
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
-	"os/exec"
 	"os/signal"
 	"strings"
-	"sync/atomic"
 	"syscall"
 	"time"
 
-	"github.com/kthomas/gonnel"
-	"github.com/ory/viper"
 	"github.com/provideservices/provide-go/api/ident"
 	"github.com/provideservices/provide-go/api/nchain"
 	"github.com/provideservices/provide-go/api/vault"
@@ -55,19 +50,22 @@ import (
 
 const defaultBaselineRegistryContractName = "Shuttle"
 
+// CapabilitiesManifestURLEnvVar is the environment variable which overrides the location
+// of the capabilities manifest; i.e., to point prvd at a local or mock API
+const CapabilitiesManifestURLEnvVar = "PROVIDE_CAPABILITIES_MANIFEST_URL"
+
 const requireContractSleepInterval = time.Second * 1
 const requireContractTickerInterval = time.Second * 5
 const requireContractTimeout = time.Minute * 10
 
-const requireOrganizationAPIEndpointTimeout = time.Second * 5
-const requireOrganizationMessagingEndpointTimeout = time.Second * 5
+// requireOrganizationEndpointsTimeout bounds how long the tunnel takes to expose the endpoints
+const requireOrganizationEndpointsTimeout = time.Second * 30
 
 var Tunnel bool
 var APIEndpoint string
 var ExposeAPITunnel bool
 var ExposeMessagingTunnel bool
 var MessagingEndpoint string
-var tunnelClient *gonnel.Client
 
 var ApplicationAccessToken string
 var OrganizationAccessToken string
@@ -77,33 +75,53 @@ var VaultID string
 
 var ResolvedBaselineOrgAddress string // HACK
 
-func AuthorizeApplicationContext() {
-	RequireWorkgroup()
+func AuthorizeApplicationContext() error {
+	if err := RequireWorkgroup(); err != nil {
+		return err
+	}
+	if err := MissingInput(); err != nil {
+		return err
+	}
 
-	token, err := ident.CreateToken(RequireUserAccessToken(), map[string]interface{}{
+	userToken, err := RequireUserAccessToken()
+	if err != nil {
+		return err
+	}
+
+	token, err := ident.CreateToken(userToken, map[string]interface{}{
 		"scope":          "offline_access",
 		"application_id": ApplicationID,
 	})
 	if err != nil {
-		log.Printf("failed to authorize API access token on behalf of application %s; %s", ApplicationID, err.Error())
-		os.Exit(1)
+		return NewAPIError(err, "failed to authorize API access token on behalf of application %s", ApplicationID)
 	}
 
 	if token.AccessToken != nil {
 		ApplicationAccessToken = *token.AccessToken
 	}
+
+	return nil
 }
 
-func AuthorizeOrganizationContext(persist bool) {
-	RequireOrganization()
+func AuthorizeOrganizationContext(persist bool) error {
+	if err := RequireOrganization(); err != nil {
+		return err
+	}
+	if err := MissingInput(); err != nil {
+		return err
+	}
 
-	token, err := ident.CreateToken(RequireUserAccessToken(), map[string]interface{}{
+	userToken, err := RequireUserAccessToken()
+	if err != nil {
+		return err
+	}
+
+	token, err := ident.CreateToken(userToken, map[string]interface{}{
 		"scope":           "offline_access",
 		"organization_id": OrganizationID,
 	})
 	if err != nil {
-		log.Printf("failed to authorize API access token on behalf of organization %s; %s", OrganizationID, err.Error())
-		os.Exit(1)
+		return NewAPIError(err, "failed to authorize API access token on behalf of organization %s", OrganizationID)
 	}
 
 	if token.AccessToken != nil {
@@ -120,35 +138,35 @@ func AuthorizeOrganizationContext(persist bool) {
 
 			if token.AccessToken != nil {
 				// fmt.Printf("Access token authorized for organization: %s\t%s\n", OrganizationID, *token.AccessToken)
-				if !viper.IsSet(orgAPIAccessTokenKey) {
-					viper.Set(orgAPIAccessTokenKey, *token.AccessToken)
-					viper.WriteConfig()
+				err := CacheCredentialUnlessCached(orgAPIAccessTokenKey, *token.AccessToken)
+				if err != nil {
+					return err
 				}
 				if token.RefreshToken != nil {
 					// fmt.Printf("Refresh token authorized for organization: %s\t%s\n", OrganizationID, *token.RefreshToken)
-					if !viper.IsSet(orgAPIRefreshTokenKey) {
-						viper.Set(orgAPIRefreshTokenKey, *token.RefreshToken)
-						viper.WriteConfig()
+					err := CacheCredentialUnlessCached(orgAPIRefreshTokenKey, *token.RefreshToken)
+					if err != nil {
+						return err
 					}
 				}
 			}
 		}
 	}
+
+	return nil
 }
 
-func InitWorkgroupContract() *nchain.Contract {
+func InitWorkgroupContract() (*nchain.Contract, error) {
 	wallet, err := nchain.CreateWallet(OrganizationAccessToken, map[string]interface{}{
 		"purpose": 44,
 	})
 	if err != nil {
-		log.Printf("failed to initialize wallet for organization; %s", err.Error())
-		os.Exit(1)
+		return nil, NewAPIError(err, "failed to initialize wallet for organization")
 	}
 
 	compiledArtifact := resolveBaselineRegistryContractArtifact()
 	if compiledArtifact == nil {
-		log.Printf("failed to deploy global baseline organization registry contract")
-		os.Exit(1)
+		return nil, errors.New("failed to deploy global baseline organization registry contract")
 	}
 
 	log.Printf("deploying global baseline organization registry contract: %s", defaultBaselineRegistryContractName)
@@ -164,51 +182,49 @@ func InitWorkgroupContract() *nchain.Contract {
 		"type": "registry",
 	})
 	if err != nil {
-		log.Printf("failed to initialize registry contract; %s", err.Error())
-		os.Exit(1)
+		return nil, NewAPIError(err, "failed to initialize registry contract")
 	}
 
 	err = RequireContract(util.StringOrNil(contract.ID.String()), nil, true)
 	if err != nil {
-		log.Printf("failed to initialize registry contract; %s", err.Error())
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to initialize registry contract; %w", err)
 	}
 
-	return contract
+	return contract, nil
 }
 
-func RegisterWorkgroupOrganization(applicationID string) {
+func RegisterWorkgroupOrganization(applicationID string) error {
 	err := RequireContract(nil, util.StringOrNil("organization-registry"), false)
 	if err != nil {
-		log.Printf("failed to initialize registry contract; %s", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("failed to initialize registry contract; %w", err)
 	}
 	err = ident.CreateApplicationOrganization(ApplicationAccessToken, applicationID, map[string]interface{}{
 		"organization_id": OrganizationID,
 	})
 	if err != nil {
-		orgs, err := ident.ListApplicationOrganizations(ApplicationAccessToken, applicationID, map[string]interface{}{
+		orgs, listErr := ident.ListApplicationOrganizations(ApplicationAccessToken, applicationID, map[string]interface{}{
 			"organization_id": OrganizationID,
 		})
-		if err == nil {
+		if listErr == nil {
 			// FIXME--
 			for _, org := range orgs {
 				if org.ID.String() == OrganizationID {
-					return
+					return nil
 				}
 			}
 			if len(orgs) > 0 && orgs[0].ID.String() == OrganizationID {
-				return
+				return nil
 			}
 		}
-		log.Printf("WARNING: organization not associated with workgroup")
-		os.Exit(1)
+		return NewAPIError(err, "organization not associated with workgroup")
 	}
+
+	return nil
 }
 
-func RequireOrganizationVault() {
+func RequireOrganizationVault() error {
 	if OrganizationAccessToken == "" {
-		return
+		return nil
 	}
 
 	// FIXME-- parameterize with --vault or similar?
@@ -216,8 +232,7 @@ func RequireOrganizationVault() {
 		"organization_id": OrganizationID,
 	})
 	if err != nil {
-		log.Printf("failed to retrieve vaults for organization: %s; %s", OrganizationID, err.Error())
-		os.Exit(1)
+		return NewAPIError(err, "failed to retrieve vaults for organization: %s", OrganizationID)
 	}
 
 	if len(vaults) > 0 {
@@ -228,21 +243,24 @@ func RequireOrganizationVault() {
 			"description": fmt.Sprintf("identity/signing keystore for organization: %s", OrganizationID),
 		})
 		if err != nil {
-			log.Printf("failed to create vault for organization: %s; %s", OrganizationID, err.Error())
-			os.Exit(1)
+			return NewAPIError(err, "failed to create vault for organization: %s", OrganizationID)
 		}
 		VaultID = vault.ID.String()
 	}
 
 	if VaultID == "" {
-		log.Printf("failed to require vault for organization: %s", OrganizationID)
-		os.Exit(1)
+		return NewNotFoundError("failed to require vault for organization: %s", OrganizationID)
 	}
+
+	return nil
 }
 
 func RequireOrganizationKeypair(spec string) (*vault.Key, error) {
 	if VaultID == "" {
-		RequireOrganizationVault()
+		err := RequireOrganizationVault()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// FIXME-- parameterize each key i.e. --secp256k1-key or similar?
@@ -250,8 +268,7 @@ func RequireOrganizationKeypair(spec string) (*vault.Key, error) {
 		"spec": spec,
 	})
 	if err != nil {
-		log.Printf("failed to retrieve %s keys for organization: %s; %s", spec, OrganizationID, err.Error())
-		return nil, err
+		return nil, NewAPIError(err, "failed to retrieve %s keys for organization: %s", spec, OrganizationID)
 	}
 
 	if len(keys) > 0 {
@@ -266,7 +283,7 @@ func RequireOrganizationKeypair(spec string) (*vault.Key, error) {
 		"usage":       "sign/verify",
 	})
 	if err != nil {
-		return nil, err
+		return nil, NewAPIError(err, "failed to create %s key for organization: %s", spec, OrganizationID)
 	}
 
 	return key, nil
@@ -326,7 +343,7 @@ func RequireContract(contractID, contractType *string, printCreationTxLink bool)
 }
 
 func resolveBaselineRegistryContractArtifact() *nchain.CompiledArtifact {
-	capabilities, err := commonutil.ResolveCapabilitiesManifest()
+	capabilities, err := resolveCapabilitiesManifest()
 	if err != nil {
 		return nil
 	}
@@ -357,53 +374,93 @@ func resolveBaselineRegistryContractArtifact() *nchain.CompiledArtifact {
 	return registryArtifact
 }
 
-// RequireOrganizationEndpoints fn is the function to call after the tunnel has been established,
-// prior to the runloop and signal handling is installed
-func RequireOrganizationEndpoints(fn func(), apiPort, messagingPort int) {
-	run := func() {
-		org, err := ident.GetOrganizationDetails(OrganizationAccessToken, OrganizationID, map[string]interface{}{})
-		if err != nil {
-			log.Printf("failed to retrieve organization: %s; %s", OrganizationID, err.Error())
-			os.Exit(1)
-		}
+func resolveCapabilitiesManifest() (map[string]interface{}, error) {
+	manifestURL := os.Getenv(CapabilitiesManifestURLEnvVar)
+	if manifestURL == "" {
+		return commonutil.ResolveCapabilitiesManifest()
+	}
 
-		if org.Metadata == nil {
-			org.Metadata = map[string]interface{}{}
-		}
+	resp, err := http.Get(manifestURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch capabilities manifest from %s; %w", manifestURL, err)
+	}
+	defer resp.Body.Close()
 
-		key, err := RequireOrganizationKeypair("secp256k1")
-		if err == nil {
-			org.Metadata["address"] = key.Address
-			ResolvedBaselineOrgAddress = *key.Address
-		}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch capabilities manifest from %s; status: %d", manifestURL, resp.StatusCode)
+	}
 
-		if APIEndpoint != "" {
-			org.Metadata["api_endpoint"] = APIEndpoint
-		} else {
-			org.Metadata["api_endpoint"] = "http://localhost:8080"
-		}
+	var capabilities map[string]interface{}
+	err = json.NewDecoder(resp.Body).Decode(&capabilities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse capabilities manifest; %w", err)
+	}
 
-		if MessagingEndpoint != "" {
-			org.Metadata["messaging_endpoint"] = MessagingEndpoint
-		} else {
-			org.Metadata["messaging_endpoint"] = "nats://localhost:4222"
-		}
+	return capabilities, nil
+}
 
-		if _, domainOk := org.Metadata["domain"].(string); !domainOk {
-			org.Metadata["domain"] = "baseline.local"
-		}
+// UpdateOrganizationEndpoints updates the metadata of the organization with its address and
+// the configured API and messaging endpoints
+func UpdateOrganizationEndpoints() error {
+	org, err := ident.GetOrganizationDetails(OrganizationAccessToken, OrganizationID, map[string]interface{}{})
+	if err != nil {
+		return NewAPIError(err, "failed to retrieve organization: %s", OrganizationID)
+	}
 
-		err = ident.UpdateOrganization(RequireUserAccessToken(), OrganizationID, map[string]interface{}{
-			"metadata": org.Metadata,
-		})
-		if err != nil {
-			log.Printf("failed to update messaging endpoint for organization: %s; %s", OrganizationID, err.Error())
-			os.Exit(1)
+	if org.Metadata == nil {
+		org.Metadata = map[string]interface{}{}
+	}
+
+	key, err := RequireOrganizationKeypair("secp256k1")
+	if err == nil {
+		org.Metadata["address"] = key.Address
+		ResolvedBaselineOrgAddress = *key.Address
+	}
+
+	if APIEndpoint != "" {
+		org.Metadata["api_endpoint"] = APIEndpoint
+	} else {
+		org.Metadata["api_endpoint"] = "http://localhost:8080"
+	}
+
+	if MessagingEndpoint != "" {
+		org.Metadata["messaging_endpoint"] = MessagingEndpoint
+	} else {
+		org.Metadata["messaging_endpoint"] = "nats://localhost:4222"
+	}
+
+	if _, domainOk := org.Metadata["domain"].(string); !domainOk {
+		org.Metadata["domain"] = "baseline.local"
+	}
+
+	token, err := RequireUserAccessToken()
+	if err != nil {
+		return err
+	}
+
+	err = ident.UpdateOrganization(token, OrganizationID, map[string]interface{}{
+		"metadata": org.Metadata,
+	})
+	if err != nil {
+		return NewAPIError(err, "failed to update messaging endpoint for organization: %s", OrganizationID)
+	}
+	return nil
+}
+
+// RequireOrganizationEndpoints fn is the function to call after the tunnel has been established,
+// prior to the runloop and signal handling is installed; the tunnel is run by the configured
+// provider until interrupted, and the endpoints of the organization are republished whenever
+// the tunnel reports a change
+func RequireOrganizationEndpoints(fn func() error, apiPort, messagingPort int) error {
+	run := func() error {
+		if err := UpdateOrganizationEndpoints(); err != nil {
+			return err
 		}
 
 		if fn != nil {
-			fn()
+			return fn()
 		}
+		return nil
 	}
 
 	if Tunnel {
@@ -414,149 +471,110 @@ func RequireOrganizationEndpoints(fn func(), apiPort, messagingPort int) {
 	if !ExposeAPITunnel && !ExposeMessagingTunnel {
 		publicIP, err := util.ResolvePublicIP()
 		if err != nil {
-			log.Printf("WARNING: failed to resolve public IP")
-			os.Exit(1)
+			return fmt.Errorf("failed to resolve public IP; %w", err)
 		}
 
 		APIEndpoint = fmt.Sprintf("http://%s:%d", *publicIP, apiPort)
 		MessagingEndpoint = fmt.Sprintf("nats://%s:%d", *publicIP, messagingPort)
 
-		run()
-	} else {
-		const runloopSleepInterval = 250 * time.Millisecond
-		const runloopTickInterval = 5000 * time.Millisecond
-
-		var (
-			cancelF     context.CancelFunc
-			closing     uint32
-			shutdownCtx context.Context
-			sigs        chan os.Signal
-		)
-
-		installSignalHandlers := func() {
-			log.Printf("installing signal handlers")
-			sigs = make(chan os.Signal, 1)
-			signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL)
-			shutdownCtx, cancelF = context.WithCancel(context.Background())
-		}
-
-		shutdown := func() {
-			if atomic.AddUint32(&closing, 1) == 1 {
-				log.Print("shutting down")
-				cancelF()
-				os.Exit(0)
-			}
-		}
-
-		shuttingDown := func() bool {
-			return (atomic.LoadUint32(&closing) > 0)
-		}
-
-		installSignalHandlers()
-
-		go func() {
-			var out bytes.Buffer
-			cmd := exec.Command("which", "ngrok")
-			cmd.Stdout = &out
-			err := cmd.Run()
-			if err == nil {
-				tunnelClient, err = gonnel.NewClient(gonnel.Options{
-					BinaryPath: strings.Trim(out.String(), "\n"),
-				})
-				if err != nil {
-					log.Printf("WARNING: failed to initialize tunnel; %s", err.Error())
-					os.Exit(1)
-				}
-
-				defer tunnelClient.Close()
+		return run()
+	}
 
-				done := make(chan bool)
-				go tunnelClient.StartServer(done)
-				<-done
+	tunnel, err := RequireTunnelProvider()
+	if err != nil {
+		return err
+	}
 
-				if ExposeAPITunnel {
-					tunnelClient.AddTunnel(&gonnel.Tunnel{
-						Proto:        gonnel.HTTP,
-						Name:         fmt.Sprintf("%s-api", OrganizationID),
-						LocalAddress: fmt.Sprintf("127.0.0.1:%d", apiPort),
-					})
-				}
+	ports := make([]*TunnelPort, 0)
+	if ExposeAPITunnel {
+		ports = append(ports, &TunnelPort{Name: TunnelEndpointAPI, Proto: "http", Scheme: "http", LocalPort: apiPort})
+	}
+	if ExposeMessagingTunnel {
+		ports = append(ports, &TunnelPort{Name: TunnelEndpointMessaging, Proto: "tcp", Scheme: "nats", LocalPort: messagingPort})
+	}
 
-				if ExposeMessagingTunnel {
-					tunnelClient.AddTunnel(&gonnel.Tunnel{
-						Proto:        gonnel.TCP,
-						Name:         fmt.Sprintf("%s-msg", OrganizationID),
-						LocalAddress: fmt.Sprintf("127.0.0.1:%d", messagingPort),
-					})
-				}
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigs)
 
-				err = tunnelClient.ConnectAll()
-				if err != nil {
-					log.Printf("WARNING: failed to initialize tunnel(s); %s", err.Error())
-					os.Exit(1)
-				}
+	ctx, cancelF := context.WithCancel(context.Background())
+	endpoints := make(chan *TunnelEndpoint)
+	tunnelErrs := make(chan error, 1)
+	go func() {
+		tunnelErrs <- tunnel.Start(ctx, ports, endpoints)
+	}()
 
-				if ExposeAPITunnel {
-					APIEndpoint = tunnelClient.Tunnel[0].RemoteAddress
-					log.Printf("established tunnel connection for API endpoint: %s\n", APIEndpoint)
-				}
+	// closeTunnel stops the tunnel and waits for the provider to release it
+	closeTunnel := func() {
+		cancelF()
+		<-tunnelErrs
+	}
 
-				if ExposeMessagingTunnel {
-					MessagingEndpoint = tunnelClient.Tunnel[len(tunnelClient.Tunnel)-1].RemoteAddress
-					log.Printf("established tunnel connection for messaging endpoint: %s\n", MessagingEndpoint)
-				}
+	// applyEndpoint sets the endpoint reported by the tunnel, returning true if it changed
+	applyEndpoint := func(endpoint *TunnelEndpoint) bool {
+		target := &APIEndpoint
+		if endpoint.Name == TunnelEndpointMessaging {
+			target = &MessagingEndpoint
+		}
+		if *target == endpoint.URL {
+			return false
+		}
+		*target = endpoint.URL
+		log.Printf("established %s tunnel connection for %s endpoint: %s\n", tunnel.Name(), endpoint.Name, endpoint.URL)
+		return true
+	}
 
-				fmt.Print("Press any key to disconnect...\n")
-				reader := bufio.NewReader(os.Stdin)
-				reader.ReadRune()
+	pending := map[string]bool{}
+	for _, port := range ports {
+		pending[port.Name] = true
+	}
 
-				tunnelClient.DisconnectAll()
-			}
-		}()
-
-		if ExposeAPITunnel {
-			startTime := time.Now()
-			for APIEndpoint == "" {
-				time.Sleep(time.Millisecond * 50)
-				if startTime.Add(requireOrganizationAPIEndpointTimeout).Before(time.Now()) {
-					log.Printf("WARNING: organization API endpoint tunnel timed out")
-					os.Exit(1)
-				}
+	timeout := time.After(requireOrganizationEndpointsTimeout)
+	for len(pending) > 0 {
+		select {
+		case endpoint := <-endpoints:
+			applyEndpoint(endpoint)
+			delete(pending, endpoint.Name)
+		case err := <-tunnelErrs:
+			cancelF()
+			if err == nil {
+				err = errors.New("tunnel closed")
 			}
+			return fmt.Errorf("failed to establish %s tunnel; %w", tunnel.Name(), err)
+		case <-timeout:
+			closeTunnel()
+			return fmt.Errorf("organization endpoint %s tunnel timed out", tunnel.Name())
+		case sig := <-sigs:
+			closeTunnel()
+			return fmt.Errorf("received signal: %s", sig)
 		}
+	}
+
+	if err := run(); err != nil {
+		closeTunnel()
+		return err
+	}
 
-		if ExposeMessagingTunnel {
-			startTime := time.Now()
-			for MessagingEndpoint == "" {
-				time.Sleep(time.Millisecond * 50)
-				if startTime.Add(requireOrganizationMessagingEndpointTimeout).Before(time.Now()) {
-					log.Printf("WARNING: organization messaging endpoint tunnel timed out")
-					os.Exit(1)
+	log.Printf("running %s tunnel; interrupt to disconnect", tunnel.Name())
+	for {
+		select {
+		case endpoint := <-endpoints:
+			if applyEndpoint(endpoint) {
+				if err := UpdateOrganizationEndpoints(); err != nil {
+					log.Printf("WARNING: failed to republish endpoints of organization %s; %s", OrganizationID, err.Error())
 				}
 			}
-		}
-
-		run()
-
-		// log.Printf("starting tunnel runloop")
-		timer := time.NewTicker(runloopTickInterval)
-		defer timer.Stop()
-
-		for !shuttingDown() {
-			select {
-			case <-timer.C:
-				// tick... no-op
-			case sig := <-sigs:
-				fmt.Printf("received signal: %s", sig)
-				shutdown()
-			case <-shutdownCtx.Done():
-				close(sigs)
-			default:
-				time.Sleep(runloopSleepInterval)
+		case err := <-tunnelErrs:
+			cancelF()
+			if err != nil {
+				return fmt.Errorf("%s tunnel failed; %w", tunnel.Name(), err)
 			}
+			return nil
+		case sig := <-sigs:
+			log.Printf("received signal: %s; shutting down", sig)
+			closeTunnel()
+			log.Printf("exiting tunnel runloop")
+			return nil
 		}
-
-		log.Printf("exiting tunnel runloop")
-		cancelF()
 	}
 }
@@ -2,14 +2,15 @@ package common
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
-	"time"
+	"strings"
 
-	"github.com/dgrijalva/jwt-go"
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/provideservices/provide-go/api/ident"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
 )
 
 const (
@@ -71,88 +72,66 @@ func InitConfig() {
 	}
 }
 
-func RequireUserAccessToken() string {
-	token := ""
-	if viper.IsSet(AccessTokenConfigKey) {
-		token = viper.GetString(AccessTokenConfigKey)
+func RequireUserAccessToken() (string, error) {
+	token, err := CachedCredential(ContextConfigKey(AccessTokenConfigKey))
+	if err != nil {
+		return "", err
 	}
 
 	if token == "" {
-		log.Printf("Authorized API access token required in prvd configuration; run 'authenticate'")
-		os.Exit(1)
-	}
-
-	if isTokenExpired(token) {
-		refreshToken()
-		token = viper.GetString(AccessTokenConfigKey)
+		return "", NewAuthError("Authorized API access token required in prvd configuration; run 'authenticate'")
 	}
 
-	return token
+	return requireFreshToken(token, ContextConfigKey(AccessTokenConfigKey), ContextConfigKey(RefreshTokenConfigKey))
 }
 
-func refreshToken() {
-	refreshToken := ""
-	if viper.IsSet(RefreshTokenConfigKey) {
-		refreshToken = viper.GetString(RefreshTokenConfigKey)
-	}
-
-	resp, err := ident.CreateToken(refreshToken, map[string]interface{}{
-		"grant_type": "refresh_token",
-	})
-	if err != nil {
-		log.Println(err)
-		os.Exit(1)
-	}
-
-	if resp != nil {
-		CacheAccessRefreshToken(resp)
-	}
-}
-
-func CacheAccessRefreshToken(token *ident.Token) {
+func CacheAccessRefreshToken(token *ident.Token) error {
 	if token.AccessToken != nil {
-		viper.Set(AccessTokenConfigKey, *token.AccessToken)
+		err := CacheCredential(ContextConfigKey(AccessTokenConfigKey), *token.AccessToken)
+		if err != nil {
+			return err
+		}
 	}
 
 	if token.RefreshToken != nil {
-		viper.Set(RefreshTokenConfigKey, *token.RefreshToken)
+		err := CacheCredential(ContextConfigKey(RefreshTokenConfigKey), *token.RefreshToken)
+		if err != nil {
+			return err
+		}
 	}
 
-	viper.WriteConfig()
+	return nil
 }
 
-func RequireApplicationToken() string {
-	var token string
+func RequireApplicationToken() (string, error) {
 	tokenKey := BuildConfigKeyWithApp(APIAccessTokenConfigKeyPartial, ApplicationID)
-	if viper.IsSet(tokenKey) {
-		token = viper.GetString(tokenKey)
+	token, err := CachedCredential(tokenKey)
+	if err != nil {
+		return "", err
 	}
 
 	if token == "" {
-		log.Printf("Authorized application API token required in prvd configuration; run 'prvd api_tokens init --application <id>'")
-		os.Exit(1)
+		return "", NewAuthError("Authorized application API token required in prvd configuration; run 'prvd api_tokens init --application <id>'")
 	}
 
-	return token
+	return requireFreshToken(token, tokenKey, BuildConfigKeyWithApp(APIRefreshTokenConfigKeyPartial, ApplicationID))
 }
 
-func RequireOrganizationToken() string {
-	var token string
+func RequireOrganizationToken() (string, error) {
 	tokenKey := BuildConfigKeyWithOrg(APIAccessTokenConfigKeyPartial, OrganizationID)
-	if viper.IsSet(tokenKey) {
-		token = viper.GetString(tokenKey)
+	token, err := CachedCredential(tokenKey)
+	if err != nil {
+		return "", err
 	}
 
 	if token == "" {
-		log.Printf("Authorized organization API token required in prvd configuration; run 'prvd api_tokens init --organization <id>'")
-		os.Exit(1)
+		return "", NewAuthError("Authorized organization API token required in prvd configuration; run 'prvd api_tokens init --organization <id>'")
 	}
 
-	return token
+	return requireFreshToken(token, tokenKey, BuildConfigKeyWithOrg(APIRefreshTokenConfigKeyPartial, OrganizationID))
 }
 
-func RequireAPIToken() string {
-	var token string
+func RequireAPIToken() (string, error) {
 	var appAPITokenKey string
 	var orgAPITokenKey string
 	if ApplicationID != "" {
@@ -160,22 +139,28 @@ func RequireAPIToken() string {
 	} else if OrganizationID != "" {
 		orgAPITokenKey = BuildConfigKeyWithOrg(APIAccessTokenConfigKeyPartial, OrganizationID)
 	}
-	if viper.IsSet(appAPITokenKey) {
-		token = viper.GetString(appAPITokenKey)
-	} else if viper.IsSet(orgAPITokenKey) {
-		token = viper.GetString(orgAPITokenKey)
-	} else {
-		token = RequireUserAccessToken()
+
+	appToken, err := CachedCredential(appAPITokenKey)
+	if err != nil {
+		return "", err
+	}
+	if appToken != "" {
+		return requireFreshToken(appToken, appAPITokenKey, BuildConfigKeyWithApp(APIRefreshTokenConfigKeyPartial, ApplicationID))
 	}
 
-	if token == "" {
-		log.Printf("Authorized API access token required in prvd configuration; run 'authenticate'")
-		os.Exit(1)
+	orgToken, err := CachedCredential(orgAPITokenKey)
+	if err != nil {
+		return "", err
 	}
-	return token
+	if orgToken != "" {
+		return requireFreshToken(orgToken, orgAPITokenKey, BuildConfigKeyWithOrg(APIRefreshTokenConfigKeyPartial, OrganizationID))
+	}
+
+	return RequireUserAccessToken()
 }
 
-// BuildConfigKeyWithApp combines the given key partial and app ID according to a consistent convention.
+// BuildConfigKeyWithApp combines the given key partial and app ID according to a consistent convention;
+// the key is scoped to the active context, if any.
 // Returns an empty string if the given appID is empty.
 // Viper's getters likewise return empty strings when passed an empty string.
 func BuildConfigKeyWithApp(keyPartial, appID string) string {
@@ -184,10 +169,11 @@ func BuildConfigKeyWithApp(keyPartial, appID string) string {
 		log.Println("An application identifier is required for this operation")
 		return ""
 	}
-	return fmt.Sprintf("%s.%s", appID, keyPartial)
+	return ContextConfigKey(fmt.Sprintf("%s.%s", appID, keyPartial))
 }
 
-// BuildConfigKeyWithOrg combines the given key partial and org ID according to a consistent convention.
+// BuildConfigKeyWithOrg combines the given key partial and org ID according to a consistent convention;
+// the key is scoped to the active context, if any.
 // Returns an empty string if the given orgID is empty.
 // Viper's getters likewise return empty strings when passed an empty string.
 func BuildConfigKeyWithOrg(keyPartial, orgID string) string {
@@ -196,10 +182,11 @@ func BuildConfigKeyWithOrg(keyPartial, orgID string) string {
 		log.Println("An organization identifier is required for this operation")
 		return ""
 	}
-	return fmt.Sprintf("%s.%s", orgID, keyPartial)
+	return ContextConfigKey(fmt.Sprintf("%s.%s", orgID, keyPartial))
 }
 
-// BuildConfigKeyWithUser combines the given key partial and user ID according to a consistent convention.
+// BuildConfigKeyWithUser combines the given key partial and user ID according to a consistent convention;
+// the key is scoped to the active context, if any.
 // Returns an empty string if the given userID is empty.
 // Viper's getters likewise return empty strings when passed an empty string.
 func BuildConfigKeyWithUser(keyPartial, userID string) string {
@@ -208,41 +195,50 @@ func BuildConfigKeyWithUser(keyPartial, userID string) string {
 		log.Println("A user identifier is required for this operation")
 		return ""
 	}
-	return fmt.Sprintf("%s.%s", userID, keyPartial)
+	return ContextConfigKey(fmt.Sprintf("%s.%s", userID, keyPartial))
 }
 
-func isTokenExpired(bearerToken string) bool {
-	token, err := jwt.Parse(bearerToken, func(_jwtToken *jwt.Token) (interface{}, error) {
-		// uncomment when enabling local verification
-		// var kid *string
-		// if kidhdr, ok := _jwtToken.Header["kid"].(string); ok {
-		// 	kid = &kidhdr
-		// }
-
-		// publicKey, _, _, _ := util.ResolveJWTKeypair(kid)
-		// if publicKey == nil {
-		// 	msg := "failed to resolve a valid JWT verification key"
-		// 	if kid != nil {
-		// 		msg = fmt.Sprintf("%s; invalid kid specified in header: %s", msg, *kid)
-		// 	} else {
-		// 		msg = fmt.Sprintf("%s; no default verification key configured", msg)
-		// 	}
-		// 	return nil, fmt.Errorf(msg)
-		// }
-
-		return nil, nil
-	})
+// updateConfig rewrites the config file using the given mutation; viper has no
+// support for removing keys, so the settings are marshaled and written directly
+func updateConfig(mutate func(map[string]interface{})) error {
+	cfg := viper.AllSettings()
+	mutate(cfg)
 
+	raw, err := yaml.Marshal(cfg)
 	if err != nil {
-		return false
+		return fmt.Errorf("failed to marshal configuration; %s", err.Error())
 	}
 
-	claims := token.Claims.(jwt.MapClaims)
-	if exp, expOk := claims["exp"].(int64); expOk {
-		expTime := time.Unix(exp, 0)
-		now := time.Now()
-		return expTime.Equal(now) || expTime.After(now)
+	err = ioutil.WriteFile(viper.ConfigFileUsed(), raw, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write configuration; %s", err.Error())
 	}
 
-	return false
+	return viper.ReadInConfig()
+}
+
+// deleteConfigKeys removes the given (dot-delimited) keys from the config file
+func deleteConfigKeys(keys ...string) error {
+	return updateConfig(func(cfg map[string]interface{}) {
+		deleteKeys(cfg, keys...)
+	})
+}
+
+// deleteKeys removes the given (dot-delimited) keys from the given settings
+func deleteKeys(cfg map[string]interface{}, keys ...string) {
+	for _, key := range keys {
+		parts := strings.Split(key, ".")
+		parent := cfg
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := parent[part].(map[string]interface{})
+			if !ok {
+				parent = nil
+				break
+			}
+			parent = child
+		}
+		if parent != nil {
+			delete(parent, parts[len(parts)-1])
+		}
+	}
 }
@@ -0,0 +1,205 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const contextsConfigKey = "contexts"
+const contextCreatedAtConfigKey = "created-at"
+
+// CurrentContextConfigKey is the config key of the name of the active context
+const CurrentContextConfigKey = "current-context"
+
+const (
+	ContextOrganizationConfigKey = "organization" // default organization ID
+	ContextWorkgroupConfigKey    = "workgroup"    // default baseline workgroup ID
+	ContextNetworkConfigKey      = "network"      // default network ID
+)
+
+// ContextName overrides the active context for a single invocation; set using the global --context flag
+var ContextName string
+
+var contextNameRegex = regexp.MustCompile("^[a-z0-9][a-z0-9_-]*$")
+
+// ContextAPIHost is an API host setting held by a context, and the environment
+// variable used to configure the corresponding provide-go client
+type ContextAPIHost struct {
+	Key   string
+	Env   string
+	Usage string
+}
+
+// ContextAPIHosts are the API host settings held by each context
+var ContextAPIHosts = []ContextAPIHost{
+	{Key: "ident-api-host", Env: "IDENT_API_HOST", Usage: "hostname of the ident API"},
+	{Key: "ident-api-scheme", Env: "IDENT_API_SCHEME", Usage: "protocol scheme of the ident API"},
+	{Key: "nchain-api-host", Env: "NCHAIN_API_HOST", Usage: "hostname of the nchain API"},
+	{Key: "nchain-api-scheme", Env: "NCHAIN_API_SCHEME", Usage: "protocol scheme of the nchain API"},
+	{Key: "vault-api-host", Env: "VAULT_API_HOST", Usage: "hostname of the vault API"},
+	{Key: "vault-api-scheme", Env: "VAULT_API_SCHEME", Usage: "protocol scheme of the vault API"},
+	{Key: "privacy-api-host", Env: "PRIVACY_API_HOST", Usage: "hostname of the privacy API"},
+	{Key: "privacy-api-scheme", Env: "PRIVACY_API_SCHEME", Usage: "protocol scheme of the privacy API"},
+	{Key: "baseline-api-host", Env: "BASELINE_API_HOST", Usage: "hostname of the baseline API"},
+	{Key: "baseline-api-scheme", Env: "BASELINE_API_SCHEME", Usage: "protocol scheme of the baseline API"},
+}
+
+// CurrentContext returns the name of the active context, or an empty string when
+// the top-level (default) configuration is in use
+func CurrentContext() string {
+	if ContextName != "" {
+		return ContextName
+	}
+	return viper.GetString(CurrentContextConfigKey)
+}
+
+// ContextConfigKey scopes the given key to the active context; the key is returned
+// as-is when no context is active
+func ContextConfigKey(key string) string {
+	name := CurrentContext()
+	if name == "" || key == "" {
+		return key
+	}
+	return contextConfigKey(name, key)
+}
+
+// ContextExists returns true if a context with the given name has been created
+func ContextExists(name string) bool {
+	_, exists := viper.GetStringMap(contextsConfigKey)[name]
+	return exists
+}
+
+// ContextSettings returns the settings held by the named context
+func ContextSettings(name string) map[string]interface{} {
+	return viper.GetStringMap(contextConfigKey(name, ""))
+}
+
+// ListContexts returns the names of all contexts, sorted
+func ListContexts() []string {
+	names := make([]string, 0)
+	for name := range viper.GetStringMap(contextsConfigKey) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyContext configures the API clients using the active context; environment
+// variables which have been explicitly set take precedence over the context
+func ApplyContext() error {
+	name := CurrentContext()
+	if name == "" {
+		return nil
+	}
+	if !ContextExists(name) {
+		return NewNotFoundError("context not found: %s; run 'prvd context list' to see available contexts", name)
+	}
+
+	for _, host := range ContextAPIHosts {
+		if os.Getenv(host.Env) != "" {
+			continue
+		}
+		if val := viper.GetString(contextConfigKey(name, host.Key)); val != "" {
+			os.Setenv(host.Env, val)
+		}
+	}
+
+	return nil
+}
+
+// CreateContext creates a context with the given name and settings
+func CreateContext(name string, settings map[string]string) error {
+	if !contextNameRegex.MatchString(name) {
+		return NewValidationError("invalid context name: %s; must contain only lowercase letters, numbers, hyphens and underscores", name)
+	}
+	if ContextExists(name) {
+		return NewValidationError("context already exists: %s", name)
+	}
+
+	// viper omits empty maps when writing the config, so every context holds at least its creation timestamp
+	ctx := map[string]interface{}{
+		contextCreatedAtConfigKey: time.Now().UTC().Format(time.RFC3339),
+	}
+	for key, val := range settings {
+		if val != "" {
+			ctx[key] = val
+		}
+	}
+
+	return updateConfig(func(cfg map[string]interface{}) {
+		contexts, _ := cfg[contextsConfigKey].(map[string]interface{})
+		if contexts == nil {
+			contexts = map[string]interface{}{}
+		}
+		contexts[name] = ctx
+		cfg[contextsConfigKey] = contexts
+	})
+}
+
+// DeleteContext deletes the named context, including any credentials it holds;
+// the top-level configuration becomes active if the named context was active
+func DeleteContext(name string) error {
+	if !ContextExists(name) {
+		return NewNotFoundError("context not found: %s", name)
+	}
+
+	// the credentials of the context are held by the credential store, which is not
+	// necessarily the config file
+	credentialStore, err := requireCredentialStore()
+	if err != nil {
+		return err
+	}
+	keys, err := credentialStore.Keys()
+	if err != nil {
+		return fmt.Errorf("failed to read credentials from %s store; %w", credentialStore.Name(), err)
+	}
+	prefix := fmt.Sprintf("%s.", contextConfigKey(name, ""))
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if err := credentialStore.Delete(key); err != nil {
+			return fmt.Errorf("failed to delete credential %s from %s store; %w", key, credentialStore.Name(), err)
+		}
+	}
+
+	return updateConfig(func(cfg map[string]interface{}) {
+		if contexts, ok := cfg[contextsConfigKey].(map[string]interface{}); ok {
+			delete(contexts, name)
+		}
+		if current, _ := cfg[CurrentContextConfigKey].(string); current == name {
+			delete(cfg, CurrentContextConfigKey)
+		}
+	})
+}
+
+// UseContext makes the named context active for subsequent invocations
+func UseContext(name string) error {
+	if !ContextExists(name) {
+		return NewNotFoundError("context not found: %s", name)
+	}
+	viper.Set(CurrentContextConfigKey, name)
+	return viper.WriteConfig()
+}
+
+// contextDefault returns the value of the given key in the active context, or an empty string
+func contextDefault(key string) string {
+	name := CurrentContext()
+	if name == "" {
+		return ""
+	}
+	return viper.GetString(contextConfigKey(name, key))
+}
+
+func contextConfigKey(name, key string) string {
+	if key == "" {
+		return fmt.Sprintf("%s.%s", contextsConfigKey, name)
+	}
+	return fmt.Sprintf("%s.%s.%s", contextsConfigKey, name, key)
+}
@@ -0,0 +1,553 @@
+package common
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// CredentialStoreConfigKey is the config key of the backend in which tokens are stored
+const CredentialStoreConfigKey = "credential-store"
+
+const (
+	CredentialStoreConfig  = "config"  // plaintext, in the config file (legacy)
+	CredentialStoreFile    = "file"    // passphrase-encrypted file
+	CredentialStoreKeyring = "keyring" // OS keyring; Secret Service or macOS keychain
+)
+
+// CredentialsPassphraseEnvVar is the environment variable from which the passphrase of the encrypted credentials file is read
+const CredentialsPassphraseEnvVar = "PROVIDE_CREDENTIALS_PASSPHRASE"
+
+const credentialsFileName = ".provide-cli.credentials"
+const credentialsFileVersion = 1
+const credentialsKeyringService = "provide-cli"
+
+// the OS keyring cannot enumerate entries, so the keys held in it are indexed in an entry of their own
+const credentialsKeyringIndexKey = "__index__"
+
+// scrypt parameters recommended for interactive logins
+const credentialsScryptN = 32768
+const credentialsScryptR = 8
+const credentialsScryptP = 1
+
+var credentialConfigKeySuffixes = []string{
+	AccessTokenConfigKey,
+	RefreshTokenConfigKey,
+	APIAccessTokenConfigKeyPartial,
+	APIRefreshTokenConfigKeyPartial,
+}
+
+var store CredentialStore
+
+// CredentialStore persists access and refresh tokens; credentials are keyed by
+// the (context-scoped) config key under which they were historically stored
+type CredentialStore interface {
+	Name() string
+	Get(key string) (string, error) // returns an empty string if no credential is stored under the key
+	Set(key, value string) error
+	Delete(key string) error
+	Keys() ([]string, error)
+}
+
+// CredentialStoreFactory returns the credential store with the given name
+func CredentialStoreFactory(name string) (CredentialStore, error) {
+	switch name {
+	case "", CredentialStoreConfig:
+		return &configCredentialStore{}, nil
+	case CredentialStoreFile:
+		path, err := credentialsFilePath()
+		if err != nil {
+			return nil, err
+		}
+		return &fileCredentialStore{path: path}, nil
+	case CredentialStoreKeyring:
+		return keyringCredentialStoreFactory()
+	default:
+		return nil, NewValidationError("unsupported credential store: %s; must be one of %s, %s or %s", name, CredentialStoreConfig, CredentialStoreFile, CredentialStoreKeyring)
+	}
+}
+
+// IsCredentialConfigKey returns true if the given config key holds a token
+func IsCredentialConfigKey(key string) bool {
+	parts := strings.Split(key, ".")
+	suffix := parts[len(parts)-1]
+	for _, credentialSuffix := range credentialConfigKeySuffixes {
+		if suffix == credentialSuffix {
+			return true
+		}
+	}
+	return false
+}
+
+// CachedCredential returns the credential stored under the given config key, or an empty string
+func CachedCredential(key string) (string, error) {
+	if key == "" {
+		return "", nil
+	}
+	credentialStore, err := requireCredentialStore()
+	if err != nil {
+		return "", err
+	}
+	val, err := credentialStore.Get(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read credential from %s store; %w", credentialStore.Name(), err)
+	}
+	return val, nil
+}
+
+// CacheCredential stores the credential under the given config key
+func CacheCredential(key, value string) error {
+	if key == "" {
+		return nil
+	}
+	credentialStore, err := requireCredentialStore()
+	if err != nil {
+		return err
+	}
+	err = credentialStore.Set(key, value)
+	if err != nil {
+		return fmt.Errorf("failed to write credential to %s store; %w", credentialStore.Name(), err)
+	}
+	return nil
+}
+
+// CacheCredentialUnlessCached stores the credential under the given config key, unless a credential is already stored under it
+func CacheCredentialUnlessCached(key, value string) error {
+	cached, err := CachedCredential(key)
+	if err != nil || cached != "" {
+		return err
+	}
+	return CacheCredential(key, value)
+}
+
+func requireCredentialStore() (CredentialStore, error) {
+	if store == nil {
+		var err error
+		store, err = CredentialStoreFactory(viper.GetString(CredentialStoreConfigKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize credential store; %w", err)
+		}
+	}
+	return store, nil
+}
+
+// configCredentialStore stores credentials in plaintext in the config file
+type configCredentialStore struct{}
+
+func (s *configCredentialStore) Name() string {
+	return CredentialStoreConfig
+}
+
+func (s *configCredentialStore) Get(key string) (string, error) {
+	return viper.GetString(key), nil
+}
+
+func (s *configCredentialStore) Set(key, value string) error {
+	viper.Set(key, value)
+	return viper.WriteConfig()
+}
+
+func (s *configCredentialStore) Delete(key string) error {
+	return deleteConfigKeys(key)
+}
+
+func (s *configCredentialStore) Keys() ([]string, error) {
+	keys := make([]string, 0)
+	for _, key := range viper.AllKeys() {
+		if IsCredentialConfigKey(key) && viper.GetString(key) != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// fileCredentialStore stores credentials in a file encrypted using a key derived from a passphrase
+type fileCredentialStore struct {
+	path        string
+	key         *[32]byte
+	salt        []byte
+	credentials map[string]string
+}
+
+// credentialsFile is the on-disk representation of the encrypted credentials
+type credentialsFile struct {
+	Version    int    `json:"version"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+func (s *fileCredentialStore) Name() string {
+	return CredentialStoreFile
+}
+
+func (s *fileCredentialStore) Get(key string) (string, error) {
+	err := s.unlock()
+	if err != nil {
+		return "", err
+	}
+	return s.credentials[key], nil
+}
+
+func (s *fileCredentialStore) Set(key, value string) error {
+	err := s.unlock()
+	if err != nil {
+		return err
+	}
+	s.credentials[key] = value
+	return s.write()
+}
+
+func (s *fileCredentialStore) Delete(key string) error {
+	err := s.unlock()
+	if err != nil {
+		return err
+	}
+	delete(s.credentials, key)
+	return s.write()
+}
+
+func (s *fileCredentialStore) Keys() ([]string, error) {
+	err := s.unlock()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0)
+	for key := range s.credentials {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// unlock decrypts the credentials file, or initializes an empty store if it does not exist
+func (s *fileCredentialStore) unlock() error {
+	if s.credentials != nil {
+		return nil
+	}
+
+	raw, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		passphrase, err := credentialsPassphrase(true)
+		if err != nil {
+			return err
+		}
+		s.salt = make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, s.salt); err != nil {
+			return fmt.Errorf("failed to generate salt; %s", err.Error())
+		}
+		s.key, err = deriveCredentialsKey(passphrase, s.salt)
+		if err != nil {
+			return err
+		}
+		s.credentials = map[string]string{}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read %s; %s", s.path, err.Error())
+	}
+
+	var file credentialsFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return fmt.Errorf("failed to parse %s; %s", s.path, err.Error())
+	}
+	if file.Version != credentialsFileVersion {
+		return fmt.Errorf("unsupported credentials file version: %d", file.Version)
+	}
+
+	salt, _ := base64.StdEncoding.DecodeString(file.Salt)
+	nonce, _ := base64.StdEncoding.DecodeString(file.Nonce)
+	ciphertext, _ := base64.StdEncoding.DecodeString(file.Ciphertext)
+	if len(salt) == 0 || len(nonce) != 24 || len(ciphertext) == 0 {
+		return fmt.Errorf("failed to parse %s; malformed credentials file", s.path)
+	}
+
+	passphrase, err := credentialsPassphrase(false)
+	if err != nil {
+		return err
+	}
+	key, err := deriveCredentialsKey(passphrase, salt)
+	if err != nil {
+		return err
+	}
+
+	var n [24]byte
+	copy(n[:], nonce)
+	plaintext, ok := secretbox.Open(nil, ciphertext, &n, key)
+	if !ok {
+		return NewAuthError("failed to decrypt credentials; invalid passphrase")
+	}
+
+	credentials := map[string]string{}
+	if err := json.Unmarshal(plaintext, &credentials); err != nil {
+		return fmt.Errorf("failed to parse decrypted credentials; %s", err.Error())
+	}
+
+	s.key = key
+	s.salt = salt
+	s.credentials = credentials
+	return nil
+}
+
+func (s *fileCredentialStore) write() error {
+	plaintext, err := json.Marshal(s.credentials)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials; %s", err.Error())
+	}
+
+	var nonce [24]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return fmt.Errorf("failed to generate nonce; %s", err.Error())
+	}
+
+	raw, err := json.MarshalIndent(&credentialsFile{
+		Version:    credentialsFileVersion,
+		Salt:       base64.StdEncoding.EncodeToString(s.salt),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce[:]),
+		Ciphertext: base64.StdEncoding.EncodeToString(secretbox.Seal(nil, plaintext, &nonce, s.key)),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials file; %s", err.Error())
+	}
+
+	// write atomically so an interrupted write never corrupts the existing credentials
+	tmp := fmt.Sprintf("%s.tmp", s.path)
+	if err := ioutil.WriteFile(tmp, raw, 0600); err != nil {
+		return fmt.Errorf("failed to write %s; %s", tmp, err.Error())
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func credentialsFilePath() (string, error) {
+	cfg := viper.ConfigFileUsed()
+	if cfg == "" {
+		return "", errors.New("failed to resolve credentials file path; no configuration file in use")
+	}
+	return filepath.Join(filepath.Dir(cfg), credentialsFileName), nil
+}
+
+func deriveCredentialsKey(passphrase string, salt []byte) (*[32]byte, error) {
+	derived, err := scrypt.Key([]byte(passphrase), salt, credentialsScryptN, credentialsScryptR, credentialsScryptP, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive credentials key; %s", err.Error())
+	}
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
+
+// credentialsPassphrase resolves the passphrase of the credentials file from the
+// environment, or by prompting; a new passphrase must be confirmed
+func credentialsPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(CredentialsPassphraseEnvVar); passphrase != "" {
+		return passphrase, nil
+	}
+	if !IsInteractive() {
+		return "", NewAuthError("credentials passphrase required; set %s when running non-interactively", CredentialsPassphraseEnvVar)
+	}
+
+	label := "Credentials Passphrase"
+	if confirm {
+		label = "New Credentials Passphrase"
+	}
+	passphrase, err := (&promptui.Prompt{Label: label, Mask: '*', Validate: MandatoryValidation}).Run()
+	if err != nil {
+		return "", err
+	}
+
+	if confirm {
+		confirmation, err := (&promptui.Prompt{Label: "Confirm Credentials Passphrase", Mask: '*'}).Run()
+		if err != nil {
+			return "", err
+		}
+		if confirmation != passphrase {
+			return "", NewValidationError("passphrases do not match")
+		}
+	}
+
+	return passphrase, nil
+}
+
+// keyringCredentialStore stores credentials in the OS keyring using the
+// secret-tool (Secret Service) or security (macOS keychain) command
+type keyringCredentialStore struct {
+	command string
+}
+
+func keyringCredentialStoreFactory() (CredentialStore, error) {
+	command := "secret-tool"
+	if runtime.GOOS == "darwin" {
+		command = "security"
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		return nil, fmt.Errorf("keyring credential store requires %s to be installed", command)
+	}
+	return &keyringCredentialStore{command: command}, nil
+}
+
+func (s *keyringCredentialStore) Name() string {
+	return CredentialStoreKeyring
+}
+
+func (s *keyringCredentialStore) Get(key string) (string, error) {
+	var args []string
+	if s.command == "security" {
+		args = []string{"find-generic-password", "-s", credentialsKeyringService, "-a", key, "-w"}
+	} else {
+		args = []string{"lookup", "service", credentialsKeyringService, "account", key}
+	}
+
+	stdout, stderr, err := s.exec(nil, args...)
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok && (stderr == "" || strings.Contains(stderr, "could not be found")) {
+			// the credential does not exist
+			return "", nil
+		}
+		return "", fmt.Errorf("%s; %s", err.Error(), stderr)
+	}
+	return strings.TrimRight(stdout, "\n"), nil
+}
+
+func (s *keyringCredentialStore) Set(key, value string) error {
+	var err error
+	var stderr string
+	if s.command == "security" {
+		// security only accepts the password as an argument, so the command is read from stdin
+		// in interactive mode; the arguments of a process are visible to other local users
+		command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", keyringQuote(credentialsKeyringService), keyringQuote(key), keyringQuote(value))
+		_, stderr, err = s.exec(strings.NewReader(command), "-i")
+		if err == nil && stderr != "" {
+			// security exits successfully in interactive mode when the command fails
+			err = errors.New("failed to add generic password")
+		}
+	} else {
+		label := fmt.Sprintf("%s: %s", credentialsKeyringService, key)
+		_, stderr, err = s.exec(strings.NewReader(value), "store", "--label", label, "service", credentialsKeyringService, "account", key)
+	}
+	if err != nil {
+		return fmt.Errorf("%s; %s", err.Error(), stderr)
+	}
+	return s.index(key, true)
+}
+
+func (s *keyringCredentialStore) Delete(key string) error {
+	var err error
+	var stderr string
+	if s.command == "security" {
+		_, stderr, err = s.exec(nil, "delete-generic-password", "-s", credentialsKeyringService, "-a", key)
+	} else {
+		_, stderr, err = s.exec(nil, "clear", "service", credentialsKeyringService, "account", key)
+	}
+	if err != nil {
+		return fmt.Errorf("%s; %s", err.Error(), stderr)
+	}
+	return s.index(key, false)
+}
+
+func (s *keyringCredentialStore) Keys() ([]string, error) {
+	index, err := s.Get(credentialsKeyringIndexKey)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0)
+	for _, key := range strings.Split(index, "\n") {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// index adds the given key to, or removes it from, the index of keys held in the keyring
+func (s *keyringCredentialStore) index(key string, present bool) error {
+	if key == credentialsKeyringIndexKey {
+		return nil
+	}
+
+	keys, err := s.Keys()
+	if err != nil {
+		return err
+	}
+
+	indexed := make([]string, 0)
+	for _, k := range keys {
+		if k != key {
+			indexed = append(indexed, k)
+		}
+	}
+	if present {
+		indexed = append(indexed, key)
+	}
+
+	if len(indexed) == 0 {
+		if len(keys) == 0 {
+			return nil
+		}
+		return s.Delete(credentialsKeyringIndexKey)
+	}
+	return s.Set(credentialsKeyringIndexKey, strings.Join(indexed, "\n"))
+}
+
+// keyringQuote quotes the given argument of a command read by security in interactive mode
+func keyringQuote(arg string) string {
+	return fmt.Sprintf("\"%s\"", strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg))
+}
+
+func (s *keyringCredentialStore) exec(stdin io.Reader, args ...string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(s.command, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stdout.String(), strings.TrimSpace(stderr.String()), err
+}
+
+// MigrateCredentials moves all tokens stored in plaintext in the config file to the named
+// credential store, which then becomes the credential store for subsequent invocations;
+// the config keys of the migrated tokens are returned
+func MigrateCredentials(name string) ([]string, error) {
+	if name == CredentialStoreConfig {
+		return nil, NewValidationError("credentials must be migrated to the %s or %s store", CredentialStoreFile, CredentialStoreKeyring)
+	}
+
+	target, err := CredentialStoreFactory(name)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, _ := (&configCredentialStore{}).Keys()
+	for _, key := range keys {
+		err := target.Set(key, viper.GetString(key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate credential %s to %s store; %s", key, target.Name(), err.Error())
+		}
+	}
+
+	// tokens are only removed from the config file once all of them have been migrated
+	err = updateConfig(func(cfg map[string]interface{}) {
+		deleteKeys(cfg, keys...)
+		cfg[CredentialStoreConfigKey] = target.Name()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	store = target
+	return keys, nil
+}
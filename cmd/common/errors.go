@@ -0,0 +1,117 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Process exit codes, by class of error
+const (
+	ExitCodeError      = 1 // unclassified failure
+	ExitCodeValidation = 2 // invalid or missing input
+	ExitCodeAuth       = 3 // missing, expired or rejected credentials
+	ExitCodeNotFound   = 4 // the requested resource does not exist
+	ExitCodeAPI        = 5 // a remote API request failed
+)
+
+// provide-go reports the HTTP status of failed requests as part of the error message
+var apiErrorStatusRegex = regexp.MustCompile(`status:? (\d{3})\b`)
+
+// classifiedError is a message and an optional underlying error
+type classifiedError struct {
+	msg string
+	err error
+}
+
+func (e *classifiedError) Error() string {
+	if e.err == nil {
+		return e.msg
+	}
+	if e.msg == "" {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("%s; %s", e.msg, e.err.Error())
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.err
+}
+
+// ValidationError indicates invalid or missing input
+type ValidationError struct{ classifiedError }
+
+// AuthError indicates missing, expired or rejected credentials
+type AuthError struct{ classifiedError }
+
+// NotFoundError indicates the requested resource does not exist
+type NotFoundError struct{ classifiedError }
+
+// APIError indicates a failed remote API request; Status is the HTTP status, if known
+type APIError struct {
+	classifiedError
+	Status int
+}
+
+// NewValidationError returns a ValidationError with the given formatted message
+func NewValidationError(format string, args ...interface{}) error {
+	return &ValidationError{classifiedError{msg: fmt.Sprintf(format, args...)}}
+}
+
+// NewAuthError returns an AuthError with the given formatted message
+func NewAuthError(format string, args ...interface{}) error {
+	return &AuthError{classifiedError{msg: fmt.Sprintf(format, args...)}}
+}
+
+// NewNotFoundError returns a NotFoundError with the given formatted message
+func NewNotFoundError(format string, args ...interface{}) error {
+	return &NotFoundError{classifiedError{msg: fmt.Sprintf(format, args...)}}
+}
+
+// NewAPIError wraps the given error returned by a remote API using the given formatted
+// message; rejected credentials and missing resources are classified as an AuthError
+// or NotFoundError, respectively, based on the HTTP status reported by provide-go
+func NewAPIError(err error, format string, args ...interface{}) error {
+	cause := classifiedError{msg: fmt.Sprintf(format, args...), err: err}
+
+	var status int
+	if err != nil {
+		if match := apiErrorStatusRegex.FindStringSubmatch(err.Error()); match != nil {
+			status, _ = strconv.Atoi(match[1])
+		}
+	}
+
+	switch status {
+	case 401, 403:
+		return &AuthError{cause}
+	case 404:
+		return &NotFoundError{cause}
+	}
+	return &APIError{cause, status}
+}
+
+// ExitCode returns the process exit code for the given error
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var missingInputErr *MissingInputError
+	var validationErr *ValidationError
+	var authErr *AuthError
+	var notFoundErr *NotFoundError
+	var apiErr *APIError
+
+	switch {
+	case errors.As(err, &missingInputErr), errors.As(err, &validationErr):
+		return ExitCodeValidation
+	case errors.As(err, &authErr):
+		return ExitCodeAuth
+	case errors.As(err, &notFoundErr):
+		return ExitCodeNotFound
+	case errors.As(err, &apiErr):
+		return ExitCodeAPI
+	}
+	return ExitCodeError
+}
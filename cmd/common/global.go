@@ -34,6 +34,9 @@ var (
 
 	Manifest *provide.Manifest
 	Verbose  bool
+
+	// Version of prvd; set at build time using -ldflags "-X github.com/provideservices/provide-cli/cmd/common.Version=..."
+	Version = "dev"
 )
 
 func init() {
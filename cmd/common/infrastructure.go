@@ -3,7 +3,6 @@ package common
 import (
 	"bufio"
 	"fmt"
-	"log"
 	"os"
 	"strings"
 
@@ -31,17 +30,23 @@ var (
 	AzureClientSecret  string
 )
 
-func InfrastructureCredentialsConfigFactory() map[string]interface{} {
+func InfrastructureCredentialsConfigFactory() (map[string]interface{}, error) {
 	var creds map[string]interface{}
 
 	if TargetID == InfrastructureTargetAWS {
-		accessKeyID, secretAccessKey := requireAWSCredentials()
+		accessKeyID, secretAccessKey, err := requireAWSCredentials()
+		if err != nil {
+			return nil, err
+		}
 		creds = map[string]interface{}{
 			"aws_access_key_id":     accessKeyID,
 			"aws_secret_access_key": secretAccessKey,
 		}
 	} else if TargetID == InfrastructureTargetAzure {
-		tenantID, clientID, clientSecret, subscriptionID := requireAzureCredentials()
+		tenantID, clientID, clientSecret, subscriptionID, err := requireAzureCredentials()
+		if err != nil {
+			return nil, err
+		}
 		creds = map[string]interface{}{
 			"azure_tenant_id":       tenantID,
 			"azure_client_id":       clientID,
@@ -50,7 +55,7 @@ func InfrastructureCredentialsConfigFactory() map[string]interface{} {
 		}
 	}
 
-	return creds
+	return creds, nil
 }
 
 func RequireInfrastructureFlags(cmd *cobra.Command, withImage bool) {
@@ -62,87 +67,77 @@ func RequireInfrastructureFlags(cmd *cobra.Command, withImage bool) {
 	}
 }
 
-func requireAWSCredentials() (string, string) {
+func requireAWSCredentials() (string, string, error) {
 	fmt.Print("AWS Access Key ID: ")
 	reader := bufio.NewReader(os.Stdin)
-	accessKeyID, err := reader.ReadString('\n')
+	accessKeyID, err := readCredential(reader, "AWS access key ID")
 	if err != nil {
-		log.Println(err)
-		os.Exit(1)
-	}
-	accessKeyID = strings.Trim(accessKeyID, "\n")
-	if accessKeyID == "" {
-		log.Println("Failed to read AWS access key ID from stdin")
-		os.Exit(1)
+		return "", "", err
 	}
 
 	fmt.Print("AWS Secret Access Key: ")
-	secretAccessKeyBytes, err := terminal.ReadPassword(0)
+	secretAccessKey, err := readSecretCredential("AWS secret access key")
 	if err != nil {
-		log.Println(err)
-		os.Exit(1)
-	}
-	secretAccessKey := strings.Trim(string(secretAccessKeyBytes[:]), "\n")
-	if secretAccessKey == "" {
-		log.Println("Failed to read AWS secret access key from stdin")
-		os.Exit(1)
+		return "", "", err
 	}
 
-	return accessKeyID, secretAccessKey
+	return accessKeyID, secretAccessKey, nil
 }
 
-func requireAzureCredentials() (string, string, string, string) {
+func requireAzureCredentials() (string, string, string, string, error) {
 	fmt.Print("Azure Tenant ID: ")
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Print("Azure Subscription ID: ")
 	reader = bufio.NewReader(os.Stdin)
-	subscriptionID, err := reader.ReadString('\n')
+	subscriptionID, err := readCredential(reader, "Azure subscription ID")
 	if err != nil {
-		log.Println(err)
-		os.Exit(1)
-	}
-	subscriptionID = strings.Trim(subscriptionID, "\n")
-	if subscriptionID == "" {
-		log.Println("Failed to read Azure subscription ID from stdin")
-		os.Exit(1)
+		return "", "", "", "", err
 	}
 
-	tenantID, err := reader.ReadString('\n')
+	tenantID, err := readCredential(reader, "Azure tenant ID")
 	if err != nil {
-		log.Println(err)
-		os.Exit(1)
-	}
-	tenantID = strings.Trim(tenantID, "\n")
-	if tenantID == "" {
-		log.Println("Failed to read Azure tenant ID from stdin")
-		os.Exit(1)
+		return "", "", "", "", err
 	}
 
 	fmt.Print("Azure Client ID: ")
 	reader = bufio.NewReader(os.Stdin)
-	clientID, err := reader.ReadString('\n')
+	clientID, err := readCredential(reader, "Azure client ID")
 	if err != nil {
-		log.Println(err)
-		os.Exit(1)
-	}
-	clientID = strings.Trim(clientID, "\n")
-	if clientID == "" {
-		log.Println("Failed to read Azure client ID from stdin")
-		os.Exit(1)
+		return "", "", "", "", err
 	}
 
 	fmt.Print("Azure Client Secret: ")
-	clientSecretBytes, err := terminal.ReadPassword(0)
+	clientSecret, err := readSecretCredential("Azure client secret")
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	return tenantID, clientID, clientSecret, subscriptionID, nil
+}
+
+// readCredential reads the named credential from the next line of the given reader
+func readCredential(reader *bufio.Reader, name string) (string, error) {
+	val, err := reader.ReadString('\n')
 	if err != nil {
-		log.Println(err)
-		os.Exit(1)
+		return "", NewValidationError("failed to read %s from stdin; %s", name, err.Error())
 	}
-	clientSecret := strings.Trim(string(clientSecretBytes[:]), "\n")
-	if clientSecret == "" {
-		log.Println("Failed to read Azure client secret from stdin")
-		os.Exit(1)
+	val = strings.Trim(val, "\n")
+	if val == "" {
+		return "", NewValidationError("failed to read %s from stdin", name)
 	}
+	return val, nil
+}
 
-	return tenantID, clientID, clientSecret, subscriptionID
+// readSecretCredential reads the named credential from the terminal without echoing it
+func readSecretCredential(name string) (string, error) {
+	raw, err := terminal.ReadPassword(0)
+	if err != nil {
+		return "", NewValidationError("failed to read %s from stdin; %s", name, err.Error())
+	}
+	val := strings.Trim(string(raw[:]), "\n")
+	if val == "" {
+		return "", NewValidationError("failed to read %s from stdin", name)
+	}
+	return val, nil
 }
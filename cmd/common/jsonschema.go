@@ -0,0 +1,306 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchemaTypes are the primitive types of a JSON schema
+var jsonSchemaTypes = []string{"array", "boolean", "integer", "null", "number", "object", "string"}
+
+// unsupportedJSONSchemaKeywords are the validation keywords which are not enforced by ValidateJSON;
+// a schema using them is rejected, rather than validating payloads which violate it
+var unsupportedJSONSchemaKeywords = map[string]bool{
+	"$ref":                  true,
+	"additionalItems":       true,
+	"allOf":                 true,
+	"anyOf":                 true,
+	"const":                 true,
+	"contains":              true,
+	"dependencies":          true,
+	"dependentRequired":     true,
+	"dependentSchemas":      true,
+	"else":                  true,
+	"format":                true,
+	"if":                    true,
+	"maxContains":           true,
+	"maxProperties":         true,
+	"minContains":           true,
+	"minProperties":         true,
+	"multipleOf":            true,
+	"not":                   true,
+	"oneOf":                 true,
+	"patternProperties":     true,
+	"prefixItems":           true,
+	"propertyNames":         true,
+	"then":                  true,
+	"unevaluatedItems":      true,
+	"unevaluatedProperties": true,
+	"uniqueItems":           true,
+}
+
+// CheckJSONSchema returns a description of each problem with the given JSON schema; validation
+// keywords which are not enforced by ValidateJSON are reported as unsupported, and annotations
+// such as title and description, as well as unknown keywords, are ignored
+func CheckJSONSchema(schema interface{}) []string {
+	return checkJSONSchema("", schema)
+}
+
+func checkJSONSchema(path string, schema interface{}) []string {
+	obj, ok := schema.(map[string]interface{})
+	if !ok {
+		if _, ok := schema.(bool); ok {
+			return nil
+		}
+		return []string{jsonSchemaProblem(path, "must be a JSON object")}
+	}
+
+	errs := make([]string, 0)
+	keywords := make([]string, 0)
+	for keyword := range obj {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	for _, keyword := range keywords {
+		val := obj[keyword]
+		keywordPath := jsonSchemaPath(path, keyword)
+
+		switch keyword {
+		case "type":
+			types, ok := val.([]interface{})
+			if !ok {
+				types = []interface{}{val}
+			}
+			for _, t := range types {
+				if s, ok := t.(string); !ok || !containsJSONSchemaType(s) {
+					errs = append(errs, jsonSchemaProblem(keywordPath, fmt.Sprintf("unsupported type: %v; must be one of %s", t, strings.Join(jsonSchemaTypes, ", "))))
+				}
+			}
+		case "properties":
+			props, ok := val.(map[string]interface{})
+			if !ok {
+				errs = append(errs, jsonSchemaProblem(keywordPath, "must be a JSON object"))
+				continue
+			}
+			names := make([]string, 0)
+			for name := range props {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				errs = append(errs, checkJSONSchema(jsonSchemaPath(keywordPath, name), props[name])...)
+			}
+		case "items", "additionalProperties":
+			errs = append(errs, checkJSONSchema(keywordPath, val)...)
+		case "required":
+			items, ok := val.([]interface{})
+			if !ok {
+				errs = append(errs, jsonSchemaProblem(keywordPath, "must be an array of property names"))
+				continue
+			}
+			for _, item := range items {
+				if _, ok := item.(string); !ok {
+					errs = append(errs, jsonSchemaProblem(keywordPath, "must be an array of property names"))
+					break
+				}
+			}
+		case "enum":
+			if items, ok := val.([]interface{}); !ok || len(items) == 0 {
+				errs = append(errs, jsonSchemaProblem(keywordPath, "must be a non-empty array"))
+			}
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum":
+			if _, ok := val.(float64); !ok {
+				errs = append(errs, jsonSchemaProblem(keywordPath, "must be a number"))
+			}
+		case "minLength", "maxLength", "minItems", "maxItems":
+			if n, ok := val.(float64); !ok || n < 0 || n != float64(int(n)) {
+				errs = append(errs, jsonSchemaProblem(keywordPath, "must be a non-negative integer"))
+			}
+		case "pattern":
+			pattern, ok := val.(string)
+			if !ok {
+				errs = append(errs, jsonSchemaProblem(keywordPath, "must be a regular expression"))
+				continue
+			}
+			if _, err := regexp.Compile(pattern); err != nil {
+				errs = append(errs, jsonSchemaProblem(keywordPath, fmt.Sprintf("invalid regular expression: %s", err.Error())))
+			}
+		default:
+			if unsupportedJSONSchemaKeywords[keyword] {
+				errs = append(errs, jsonSchemaProblem(keywordPath, "unsupported keyword; payloads would not be validated against it"))
+			}
+		}
+	}
+
+	return errs
+}
+
+// ValidateJSON returns a description of each way in which the given JSON value, as unmarshaled
+// using encoding/json, does not conform to the given JSON schema, which is expected to have
+// been checked using CheckJSONSchema
+func ValidateJSON(schema, value interface{}) []string {
+	return validateJSON("", schema, value)
+}
+
+func validateJSON(path string, schema, value interface{}) []string {
+	obj, ok := schema.(map[string]interface{})
+	if !ok {
+		if allowed, ok := schema.(bool); ok && !allowed {
+			return []string{jsonSchemaProblem(path, "not allowed")}
+		}
+		return nil
+	}
+
+	if t, ok := obj["type"]; ok {
+		types, ok := t.([]interface{})
+		if !ok {
+			types = []interface{}{t}
+		}
+		matched := false
+		names := make([]string, 0)
+		for _, t := range types {
+			name, _ := t.(string)
+			names = append(names, name)
+			if jsonType(value) == name || (name == "number" && jsonType(value) == "integer") {
+				matched = true
+			}
+		}
+		if !matched {
+			return []string{jsonSchemaProblem(path, fmt.Sprintf("must be of type %s", strings.Join(names, " or ")))}
+		}
+	}
+
+	errs := make([]string, 0)
+
+	if enum, ok := obj["enum"].([]interface{}); ok {
+		matched := false
+		for _, item := range enum {
+			if reflect.DeepEqual(item, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			errs = append(errs, jsonSchemaProblem(path, "must be one of the enumerated values"))
+		}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if min, ok := obj["minimum"].(float64); ok && v < min {
+			errs = append(errs, jsonSchemaProblem(path, fmt.Sprintf("must be at least %v", min)))
+		}
+		if max, ok := obj["maximum"].(float64); ok && v > max {
+			errs = append(errs, jsonSchemaProblem(path, fmt.Sprintf("must be at most %v", max)))
+		}
+		if min, ok := obj["exclusiveMinimum"].(float64); ok && v <= min {
+			errs = append(errs, jsonSchemaProblem(path, fmt.Sprintf("must be greater than %v", min)))
+		}
+		if max, ok := obj["exclusiveMaximum"].(float64); ok && v >= max {
+			errs = append(errs, jsonSchemaProblem(path, fmt.Sprintf("must be less than %v", max)))
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if min, ok := obj["minLength"].(float64); ok && length < min {
+			errs = append(errs, jsonSchemaProblem(path, fmt.Sprintf("must be at least %v characters", min)))
+		}
+		if max, ok := obj["maxLength"].(float64); ok && length > max {
+			errs = append(errs, jsonSchemaProblem(path, fmt.Sprintf("must be at most %v characters", max)))
+		}
+		if pattern, ok := obj["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				errs = append(errs, jsonSchemaProblem(path, fmt.Sprintf("must match pattern %s", pattern)))
+			}
+		}
+	case []interface{}:
+		if min, ok := obj["minItems"].(float64); ok && float64(len(v)) < min {
+			errs = append(errs, jsonSchemaProblem(path, fmt.Sprintf("must have at least %v items", min)))
+		}
+		if max, ok := obj["maxItems"].(float64); ok && float64(len(v)) > max {
+			errs = append(errs, jsonSchemaProblem(path, fmt.Sprintf("must have at most %v items", max)))
+		}
+		if items, ok := obj["items"]; ok {
+			for i, item := range v {
+				errs = append(errs, validateJSON(fmt.Sprintf("%s[%d]", path, i), items, item)...)
+			}
+		}
+	case map[string]interface{}:
+		if required, ok := obj["required"].([]interface{}); ok {
+			for _, name := range required {
+				if name, ok := name.(string); ok {
+					if _, ok := v[name]; !ok {
+						errs = append(errs, jsonSchemaProblem(jsonSchemaPath(path, name), "required"))
+					}
+				}
+			}
+		}
+
+		props, _ := obj["properties"].(map[string]interface{})
+		names := make([]string, 0)
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if prop, ok := props[name]; ok {
+				errs = append(errs, validateJSON(jsonSchemaPath(path, name), prop, v[name])...)
+			} else if additional, ok := obj["additionalProperties"]; ok {
+				errs = append(errs, validateJSON(jsonSchemaPath(path, name), additional, v[name])...)
+			}
+		}
+	}
+
+	return errs
+}
+
+// jsonType returns the JSON schema type of the given JSON value
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return ""
+}
+
+func containsJSONSchemaType(t string) bool {
+	for _, _t := range jsonSchemaTypes {
+		if _t == t {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonSchemaPath returns the dotted path of the given key within the schema at path
+func jsonSchemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return fmt.Sprintf("%s.%s", path, key)
+}
+
+func jsonSchemaProblem(path, problem string) string {
+	if path == "" {
+		return problem
+	}
+	return fmt.Sprintf("%s: %s", path, problem)
+}
@@ -0,0 +1,232 @@
+package common
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// unmarshalJSON unmarshals the given JSON document as encoding/json would for a schema or payload
+func unmarshalJSON(t *testing.T, doc string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(doc), &value); err != nil {
+		t.Fatalf("failed to unmarshal %s; %s", doc, err.Error())
+	}
+	return value
+}
+
+func TestCheckJSONSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   []string
+	}{
+		{
+			name:   "supported keywords",
+			schema: `{"type": "object", "required": ["po"], "additionalProperties": false, "properties": {"po": {"type": "string", "minLength": 1, "maxLength": 32, "pattern": "^po-"}, "total": {"type": ["number", "null"], "minimum": 0, "exclusiveMaximum": 1000000}, "lines": {"type": "array", "minItems": 1, "maxItems": 100, "items": {"enum": ["a", "b"]}}}}`,
+			want:   []string{},
+		},
+		{
+			name:   "annotations and unknown keywords",
+			schema: `{"$schema": "http://json-schema.org/draft-07/schema#", "$id": "purchase_order", "title": "Purchase order", "description": "an order", "default": {}, "examples": [], "x-sor": "sap"}`,
+			want:   []string{},
+		},
+		{
+			name:   "boolean schema",
+			schema: `true`,
+			want:   nil,
+		},
+		{
+			name:   "not an object",
+			schema: `"object"`,
+			want:   []string{"must be a JSON object"},
+		},
+		{
+			name:   "unsupported type",
+			schema: `{"type": ["string", "money", 1]}`,
+			want: []string{
+				"type: unsupported type: money; must be one of array, boolean, integer, null, number, object, string",
+				"type: unsupported type: 1; must be one of array, boolean, integer, null, number, object, string",
+			},
+		},
+		{
+			name:   "invalid keyword values",
+			schema: `{"properties": [], "required": ["po", 1], "enum": [], "minimum": "0", "maxLength": 1.5, "minItems": -1, "pattern": "("}`,
+			want: []string{
+				"enum: must be a non-empty array",
+				"maxLength: must be a non-negative integer",
+				"minItems: must be a non-negative integer",
+				"minimum: must be a number",
+				"pattern: invalid regular expression: error parsing regexp: missing closing ): `(`",
+				"properties: must be a JSON object",
+				"required: must be an array of property names",
+			},
+		},
+		{
+			name:   "nested problems",
+			schema: `{"properties": {"lines": {"items": {"type": "money"}}, "total": {"minimum": "0"}}, "additionalProperties": 1}`,
+			want: []string{
+				"additionalProperties: must be a JSON object",
+				"properties.lines.items.type: unsupported type: money; must be one of array, boolean, integer, null, number, object, string",
+				"properties.total.minimum: must be a number",
+			},
+		},
+		{
+			name:   "unsupported keywords",
+			schema: `{"$ref": "#/definitions/po", "allOf": [], "anyOf": [], "oneOf": [], "not": {}, "const": 1, "definitions": {"po": {}}}`,
+			want: []string{
+				"$ref: unsupported keyword; payloads would not be validated against it",
+				"allOf: unsupported keyword; payloads would not be validated against it",
+				"anyOf: unsupported keyword; payloads would not be validated against it",
+				"const: unsupported keyword; payloads would not be validated against it",
+				"not: unsupported keyword; payloads would not be validated against it",
+				"oneOf: unsupported keyword; payloads would not be validated against it",
+			},
+		},
+		{
+			name:   "nested unsupported keyword",
+			schema: `{"properties": {"issued": {"type": "string", "format": "date"}}}`,
+			want:   []string{"properties.issued.format: unsupported keyword; payloads would not be validated against it"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckJSONSchema(unmarshalJSON(t, tt.schema)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckJSONSchema(%s) = %q; want %q", tt.schema, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	const purchaseOrder = `{"type": "object", "required": ["po", "total"], "properties": {"po": {"type": "string", "pattern": "^po-"}, "total": {"type": "number", "minimum": 0}, "lines": {"type": "array", "items": {"type": "integer"}}}}`
+
+	tests := []struct {
+		name   string
+		schema string
+		value  string
+		want   []string
+	}{
+		{
+			name:   "valid",
+			schema: purchaseOrder,
+			value:  `{"po": "po-1", "total": 10.5, "lines": [1, 2]}`,
+			want:   []string{},
+		},
+		{
+			name:   "type mismatch",
+			schema: purchaseOrder,
+			value:  `[]`,
+			want:   []string{"must be of type object"},
+		},
+		{
+			name:   "one of several types",
+			schema: `{"type": ["string", "null"]}`,
+			value:  `1`,
+			want:   []string{"must be of type string or null"},
+		},
+		{
+			name:   "integer is a number",
+			schema: `{"type": "number"}`,
+			value:  `1`,
+			want:   []string{},
+		},
+		{
+			name:   "number is not an integer",
+			schema: `{"type": "integer"}`,
+			value:  `1.5`,
+			want:   []string{"must be of type integer"},
+		},
+		{
+			name:   "missing required properties",
+			schema: purchaseOrder,
+			value:  `{}`,
+			want:   []string{"po: required", "total: required"},
+		},
+		{
+			name:   "invalid properties",
+			schema: purchaseOrder,
+			value:  `{"po": "1", "total": -1, "lines": [1, "2", 3.5]}`,
+			want: []string{
+				"lines[1]: must be of type integer",
+				"lines[2]: must be of type integer",
+				"po: must match pattern ^po-",
+				"total: must be at least 0",
+			},
+		},
+		{
+			name:   "additional properties not allowed",
+			schema: `{"properties": {"po": {}}, "additionalProperties": false}`,
+			value:  `{"po": "po-1", "memo": "rush"}`,
+			want:   []string{"memo: not allowed"},
+		},
+		{
+			name:   "additional properties schema",
+			schema: `{"additionalProperties": {"type": "string"}}`,
+			value:  `{"po": "po-1", "total": 1}`,
+			want:   []string{"total: must be of type string"},
+		},
+		{
+			name:   "enum",
+			schema: `{"enum": ["open", {"state": "closed"}]}`,
+			value:  `{"state": "open"}`,
+			want:   []string{"must be one of the enumerated values"},
+		},
+		{
+			name:   "enum matched",
+			schema: `{"enum": ["open", {"state": "closed"}]}`,
+			value:  `{"state": "closed"}`,
+			want:   []string{},
+		},
+		{
+			name:   "numeric bounds",
+			schema: `{"minimum": 1, "maximum": 0, "exclusiveMinimum": 2, "exclusiveMaximum": 0}`,
+			value:  `0.5`,
+			want: []string{
+				"must be at least 1",
+				"must be at most 0",
+				"must be greater than 2",
+				"must be less than 0",
+			},
+		},
+		{
+			name:   "string length counts characters",
+			schema: `{"minLength": 2, "maxLength": 2}`,
+			value:  `"äö"`,
+			want:   []string{},
+		},
+		{
+			name:   "string length",
+			schema: `{"minLength": 3, "maxLength": 1}`,
+			value:  `"ab"`,
+			want:   []string{"must be at least 3 characters", "must be at most 1 characters"},
+		},
+		{
+			name:   "array length",
+			schema: `{"minItems": 3, "maxItems": 1}`,
+			value:  `[1, 2]`,
+			want:   []string{"must have at least 3 items", "must have at most 1 items"},
+		},
+		{
+			name:   "false schema",
+			schema: `false`,
+			value:  `1`,
+			want:   []string{"not allowed"},
+		},
+		{
+			name:   "true schema",
+			schema: `true`,
+			value:  `1`,
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateJSON(unmarshalJSON(t, tt.schema), unmarshalJSON(t, tt.value)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateJSON(%s, %s) = %q; want %q", tt.schema, tt.value, got, tt.want)
+			}
+		})
+	}
+}
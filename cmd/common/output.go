@@ -0,0 +1,136 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+const OutputFormatJSON = "json"
+const OutputFormatTable = "table"
+const OutputFormatTemplate = "template"
+const OutputFormatYAML = "yaml"
+
+// Output is the format used to render command results; set using the global --output flag
+var Output string
+
+// OutputTemplate is the Go text/template used to render command results when --output is template
+var OutputTemplate string
+
+// Table is the human-readable rendering of a command result, used when --output is table
+type Table struct {
+	headers []string
+	rows    [][]string
+}
+
+// NewTable initializes a table with the given column headers
+func NewTable(headers ...string) *Table {
+	return &Table{
+		headers: headers,
+		rows:    make([][]string, 0),
+	}
+}
+
+// AddRow appends a row to the table; values are expected in header order
+func (t *Table) AddRow(values ...string) {
+	t.rows = append(t.rows, values)
+}
+
+// Render writes the given result to stdout in the configured output format;
+// the full result is marshaled for machine-readable formats, and the table
+// is rendered otherwise
+func Render(result interface{}, table *Table) error {
+	return RenderTo(os.Stdout, result, table)
+}
+
+// RenderTo writes the given result to w in the configured output format
+func RenderTo(w io.Writer, result interface{}, table *Table) error {
+	switch strings.ToLower(Output) {
+	case OutputFormatJSON:
+		raw, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to render JSON output; %s", err.Error())
+		}
+		_, err = fmt.Fprintf(w, "%s\n", string(raw))
+		return err
+	case OutputFormatYAML:
+		generic, err := genericOutputFactory(result)
+		if err != nil {
+			return err
+		}
+		raw, err := yaml.Marshal(generic)
+		if err != nil {
+			return fmt.Errorf("failed to render YAML output; %s", err.Error())
+		}
+		_, err = w.Write(raw)
+		return err
+	case OutputFormatTemplate:
+		if OutputTemplate == "" {
+			return NewValidationError("--template is required when --output is %s", OutputFormatTemplate)
+		}
+		tmpl, err := template.New("output").Parse(OutputTemplate)
+		if err != nil {
+			return NewValidationError("failed to parse output template; %s", err.Error())
+		}
+		generic, err := genericOutputFactory(result)
+		if err != nil {
+			return err
+		}
+		return tmpl.Execute(w, generic)
+	case "", OutputFormatTable:
+		if table == nil {
+			return nil
+		}
+		return table.write(w)
+	default:
+		return NewValidationError("unsupported output format: %s; must be one of %s, %s, %s or %s", Output, OutputFormatTable, OutputFormatJSON, OutputFormatYAML, OutputFormatTemplate)
+	}
+}
+
+// IsStructuredOutput returns true when command results are to be rendered in a machine-readable format
+func IsStructuredOutput() bool {
+	format := strings.ToLower(Output)
+	return format != "" && format != OutputFormatTable
+}
+
+func (t *Table) write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(t.headers) > 0 {
+		fmt.Fprintln(tw, strings.Join(t.headers, "\t"))
+	}
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// genericOutputFactory round-trips the given result through JSON so YAML and template
+// output use the same field names as the API and the JSON output
+func genericOutputFactory(result interface{}) (interface{}, error) {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal output; %s", err.Error())
+	}
+
+	var generic interface{}
+	err = json.Unmarshal(raw, &generic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal output; %s", err.Error())
+	}
+
+	return generic, nil
+}
+
+// StringValue dereferences an optional string for tabular output
+func StringValue(str *string) string {
+	if str == nil {
+		return ""
+	}
+	return *str
+}
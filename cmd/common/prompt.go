@@ -15,6 +15,7 @@ import (
 	"github.com/provideservices/provide-go/api/nchain"
 	"github.com/provideservices/provide-go/api/vault"
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 const requireAccountSelectLabel = "Select an account"
@@ -28,6 +29,47 @@ const requireWorkgroupSelectLabel = "Select a workgroup"
 
 var commands map[string]*cobra.Command
 
+// NonInteractive disables all prompts; set using the global --non-interactive flag
+var NonInteractive bool
+
+// missingInput accumulates the required flags which were not provided while prompts are disabled
+var missingInput []string
+
+// MissingInputError is returned in place of a prompt when required input was not provided and prompts are disabled
+type MissingInputError struct {
+	Flags []string
+}
+
+func (e *MissingInputError) Error() string {
+	return fmt.Sprintf("missing required input: %s", strings.Join(e.Flags, ", "))
+}
+
+// IsInteractive returns true if the user may be prompted for input; prompts are disabled
+// using --non-interactive, and automatically when stdin is not a terminal
+func IsInteractive() bool {
+	return !NonInteractive && terminal.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// MissingInput returns an error listing every required flag which was not provided, or nil; it is
+// checked once all of the required input for a command has been resolved, so missing flags are reported at once
+func MissingInput() error {
+	if len(missingInput) == 0 {
+		return nil
+	}
+	return &MissingInputError{Flags: missingInput}
+}
+
+// requireFlag records the given flag as missing input; the Require functions then return nil, so
+// every missing flag is reported at once by MissingInput, and their errors are reserved for failures
+func requireFlag(flag string) {
+	for _, f := range missingInput {
+		if f == flag {
+			return
+		}
+	}
+	missingInput = append(missingInput, flag)
+}
+
 func normaliseCmd(cmd *cobra.Command, args []string) (string, string) {
 	flag, _ := regexp.Compile("\\[(.*)")
 	r, _ := regexp.Compile("\\--(.*)")
@@ -66,12 +108,12 @@ func CmdExists(cmd *cobra.Command, args []string) (bool, string) {
 	return commands[string(hashCmd[:])] != nil, argsCommandNormalised
 }
 
-func CmdExistsOrExit(cmd *cobra.Command, args []string) {
+func CmdExistsOrError(cmd *cobra.Command, args []string) error {
 	exists, command := CmdExists(cmd, args)
 	if !exists {
-		fmt.Println(command + " is not a valid command")
-		os.Exit(1)
+		return NewValidationError("%s is not a valid command", command)
 	}
+	return nil
 }
 
 // RequireApplication is equivalent to a required --application flag
@@ -79,9 +121,23 @@ func RequireApplication() error {
 	if ApplicationID != "" {
 		return nil
 	}
+	if !IsInteractive() {
+		requireFlag("--application")
+		return nil
+	}
 
 	opts := make([]string, 0)
-	apps, _ := ident.ListApplications(RequireUserAccessToken(), map[string]interface{}{})
+	token, err := RequireUserAccessToken()
+	if err != nil {
+		return err
+	}
+	apps, err := ident.ListApplications(token, map[string]interface{}{})
+	if err != nil {
+		return NewAPIError(err, "failed to retrieve applications")
+	}
+	if len(apps) == 0 {
+		return NewNotFoundError("no applications found")
+	}
 	for _, app := range apps {
 		opts = append(opts, *app.Name)
 	}
@@ -93,24 +149,41 @@ func RequireApplication() error {
 
 	i, _, err := prompt.Run()
 	if err != nil {
-		return err
+		return promptError(requireApplicationSelectLabel, err)
 	}
 
 	ApplicationID = apps[i].ID.String()
 	return nil
 }
 
-// RequireWorkgroup is equivalent to a required --workgroup flag
+// RequireWorkgroup is equivalent to a required --workgroup flag; defaults to the workgroup of the active context
 // (yes, this is identical to RequireApplication() with exception to the Printf content...)
 func RequireWorkgroup() error {
 	if ApplicationID != "" {
 		return nil
 	}
+	if ApplicationID = contextDefault(ContextWorkgroupConfigKey); ApplicationID != "" {
+		return nil
+	}
+	if !IsInteractive() {
+		requireFlag("--workgroup")
+		return nil
+	}
 
 	opts := make([]string, 0)
-	apps, _ := ident.ListApplications(RequireUserAccessToken(), map[string]interface{}{
+	token, err := RequireUserAccessToken()
+	if err != nil {
+		return err
+	}
+	apps, err := ident.ListApplications(token, map[string]interface{}{
 		"type": "baseline",
 	})
+	if err != nil {
+		return NewAPIError(err, "failed to retrieve workgroups")
+	}
+	if len(apps) == 0 {
+		return NewNotFoundError("no workgroups found")
+	}
 	for _, app := range apps {
 		opts = append(opts, *app.Name)
 	}
@@ -122,7 +195,7 @@ func RequireWorkgroup() error {
 
 	i, _, err := prompt.Run()
 	if err != nil {
-		return err
+		return promptError(requireWorkgroupSelectLabel, err)
 	}
 
 	ApplicationID = apps[i].ID.String()
@@ -134,9 +207,23 @@ func RequireConnector(params map[string]interface{}) error {
 	if ConnectorID != "" {
 		return nil
 	}
+	if !IsInteractive() {
+		requireFlag("--connector")
+		return nil
+	}
 
 	opts := make([]string, 0)
-	connectors, _ := nchain.ListConnectors(RequireAPIToken(), params)
+	token, err := RequireAPIToken()
+	if err != nil {
+		return err
+	}
+	connectors, err := nchain.ListConnectors(token, params)
+	if err != nil {
+		return NewAPIError(err, "failed to retrieve connectors")
+	}
+	if len(connectors) == 0 {
+		return NewNotFoundError("no connectors found")
+	}
 	for _, connector := range connectors {
 		opts = append(opts, *connector.Name)
 	}
@@ -148,21 +235,38 @@ func RequireConnector(params map[string]interface{}) error {
 
 	i, _, err := prompt.Run()
 	if err != nil {
-		return err
+		return promptError(requireConnectorSelectLabel, err)
 	}
 
 	ConnectorID = connectors[i].ID.String()
 	return nil
 }
 
-// RequireNetwork is equivalent to a required --network flag
+// RequireNetwork is equivalent to a required --network flag; defaults to the network of the active context
 func RequireNetwork() error {
 	if NetworkID != "" {
 		return nil
 	}
+	if NetworkID = contextDefault(ContextNetworkConfigKey); NetworkID != "" {
+		return nil
+	}
+	if !IsInteractive() {
+		requireFlag("--network")
+		return nil
+	}
 
 	opts := make([]string, 0)
-	networks, _ := nchain.ListNetworks(RequireAPIToken(), map[string]interface{}{})
+	token, err := RequireAPIToken()
+	if err != nil {
+		return err
+	}
+	networks, err := nchain.ListNetworks(token, map[string]interface{}{})
+	if err != nil {
+		return NewAPIError(err, "failed to retrieve networks")
+	}
+	if len(networks) == 0 {
+		return NewNotFoundError("no networks found")
+	}
 	for _, network := range networks {
 		opts = append(opts, *network.Name)
 	}
@@ -174,23 +278,40 @@ func RequireNetwork() error {
 
 	i, _, err := prompt.Run()
 	if err != nil {
-		return err
+		return promptError(requireNetworkSelectLabel, err)
 	}
 
 	NetworkID = networks[i].ID.String()
 	return nil
 }
 
-// RequirePublicNetwork is equivalent to a required --network flag; but list options filtered to show only public networks
+// RequirePublicNetwork is equivalent to a required --network flag; but list options filtered to show only public networks; defaults to the network of the active context
 func RequirePublicNetwork() error {
 	if NetworkID != "" {
 		return nil
 	}
+	if NetworkID = contextDefault(ContextNetworkConfigKey); NetworkID != "" {
+		return nil
+	}
+	if !IsInteractive() {
+		requireFlag("--network")
+		return nil
+	}
 
 	opts := make([]string, 0)
-	networks, _ := nchain.ListNetworks(RequireAPIToken(), map[string]interface{}{
+	token, err := RequireAPIToken()
+	if err != nil {
+		return err
+	}
+	networks, err := nchain.ListNetworks(token, map[string]interface{}{
 		"public": "true",
 	})
+	if err != nil {
+		return NewAPIError(err, "failed to retrieve public networks")
+	}
+	if len(networks) == 0 {
+		return NewNotFoundError("no public networks found")
+	}
 	for _, network := range networks {
 		opts = append(opts, *network.Name)
 	}
@@ -202,21 +323,38 @@ func RequirePublicNetwork() error {
 
 	i, _, err := prompt.Run()
 	if err != nil {
-		return err
+		return promptError(requireNetworkSelectLabel, err)
 	}
 
 	NetworkID = networks[i].ID.String()
 	return nil
 }
 
-// RequireOrganization is equivalent to a required --organization flag
+// RequireOrganization is equivalent to a required --organization flag; defaults to the organization of the active context
 func RequireOrganization() error {
 	if OrganizationID != "" {
 		return nil
 	}
+	if OrganizationID = contextDefault(ContextOrganizationConfigKey); OrganizationID != "" {
+		return nil
+	}
+	if !IsInteractive() {
+		requireFlag("--organization")
+		return nil
+	}
 
 	opts := make([]string, 0)
-	orgs, _ := ident.ListOrganizations(RequireUserAccessToken(), map[string]interface{}{})
+	token, err := RequireUserAccessToken()
+	if err != nil {
+		return err
+	}
+	orgs, err := ident.ListOrganizations(token, map[string]interface{}{})
+	if err != nil {
+		return NewAPIError(err, "failed to retrieve organizations")
+	}
+	if len(orgs) == 0 {
+		return NewNotFoundError("no organizations found")
+	}
 	for _, org := range orgs {
 		opts = append(opts, *org.Name)
 	}
@@ -228,7 +366,7 @@ func RequireOrganization() error {
 
 	i, _, err := prompt.Run()
 	if err != nil {
-		return err
+		return promptError(requireOrganizationSelectLabel, err)
 	}
 
 	Organization = orgs[i]
@@ -241,9 +379,23 @@ func RequireVault() error {
 	if VaultID != "" {
 		return nil
 	}
+	if !IsInteractive() {
+		requireFlag("--vault")
+		return nil
+	}
 
 	opts := make([]string, 0)
-	vaults, _ := vault.ListVaults(RequireAPIToken(), map[string]interface{}{})
+	token, err := RequireAPIToken()
+	if err != nil {
+		return err
+	}
+	vaults, err := vault.ListVaults(token, map[string]interface{}{})
+	if err != nil {
+		return NewAPIError(err, "failed to retrieve vaults")
+	}
+	if len(vaults) == 0 {
+		return NewNotFoundError("no vaults found")
+	}
 	for _, vlt := range vaults {
 		opts = append(opts, *vlt.Name)
 	}
@@ -255,7 +407,7 @@ func RequireVault() error {
 
 	i, _, err := prompt.Run()
 	if err != nil {
-		return err
+		return promptError(requireVaultSelectLabel, err)
 	}
 
 	VaultID = vaults[i].ID.String()
@@ -267,9 +419,23 @@ func RequireAccount(params map[string]interface{}) error {
 	if AccountID != "" {
 		return nil
 	}
+	if !IsInteractive() {
+		requireFlag("--account")
+		return nil
+	}
 
 	opts := make([]string, 0)
-	accounts, _ := nchain.ListAccounts(RequireAPIToken(), params)
+	token, err := RequireAPIToken()
+	if err != nil {
+		return err
+	}
+	accounts, err := nchain.ListAccounts(token, params)
+	if err != nil {
+		return NewAPIError(err, "failed to retrieve accounts")
+	}
+	if len(accounts) == 0 {
+		return NewNotFoundError("no accounts found")
+	}
 	for _, acct := range accounts {
 		opts = append(opts, *acct.PublicKey)
 	}
@@ -281,7 +447,7 @@ func RequireAccount(params map[string]interface{}) error {
 
 	i, _, err := prompt.Run()
 	if err != nil {
-		return err
+		return promptError(requireWalletSelectLabel, err)
 	}
 
 	AccountID = accounts[i].ID.String()
@@ -293,9 +459,23 @@ func RequireWallet() error {
 	if WalletID != "" {
 		return nil
 	}
+	if !IsInteractive() {
+		requireFlag("--wallet")
+		return nil
+	}
 
 	opts := make([]string, 0)
-	wallets, _ := nchain.ListWallets(RequireAPIToken(), map[string]interface{}{})
+	token, err := RequireAPIToken()
+	if err != nil {
+		return err
+	}
+	wallets, err := nchain.ListWallets(token, map[string]interface{}{})
+	if err != nil {
+		return NewAPIError(err, "failed to retrieve wallets")
+	}
+	if len(wallets) == 0 {
+		return NewNotFoundError("no wallets found")
+	}
 	for _, wallet := range wallets {
 		opts = append(opts, *wallet.PublicKey)
 	}
@@ -307,7 +487,7 @@ func RequireWallet() error {
 
 	i, _, err := prompt.Run()
 	if err != nil {
-		return err
+		return promptError(requireWalletSelectLabel, err)
 	}
 
 	WalletID = wallets[i].ID.String()
@@ -367,7 +547,11 @@ var HexValidation = func(input string) error {
 	return nil
 }
 
-func FreeInput(label string, defaultValue string, validate func(string) error) string {
+// FreeInput prompts for optional input; the default value is returned when prompts are disabled
+func FreeInput(label string, defaultValue string, validate func(string) error) (string, error) {
+	if !IsInteractive() {
+		return defaultValue, nil
+	}
 
 	var prompt = promptui.Prompt{}
 	if label == "Password" {
@@ -387,28 +571,59 @@ func FreeInput(label string, defaultValue string, validate func(string) error) s
 	}
 
 	result, err := prompt.Run()
-
 	if err != nil {
-		os.Exit(1)
-		return err.Error()
+		return "", promptError(label, err)
 	}
 
-	return result
+	return result, nil
 }
 
-func SelectInput(args []string, label string) string {
+// SelectInput prompts for an optional selection; nothing is selected when prompts are disabled
+func SelectInput(args []string, label string) (string, error) {
+	if !IsInteractive() {
+		return "", nil
+	}
+
 	prompt := promptui.Select{
 		Label: label,
 		Items: args,
 	}
 
 	_, result, err := prompt.Run()
-
 	if err != nil {
-		os.Exit(1)
-		return err.Error()
+		return "", promptError(label, err)
 	}
 
-	return result
+	return result, nil
+}
 
+// RequireFreeInput prompts for input equivalent to the given required flag; when prompts
+// are disabled, the flag is recorded as missing input and the default value is returned
+func RequireFreeInput(flag, label, defaultValue string, validate func(string) error) (string, error) {
+	if !IsInteractive() {
+		if defaultValue == "" || validate(defaultValue) != nil {
+			requireFlag(flag)
+		}
+		return defaultValue, nil
+	}
+	return FreeInput(label, defaultValue, validate)
+}
+
+// RequireSelectInput prompts for a selection equivalent to the given required flag; when
+// prompts are disabled, the flag is recorded as missing input
+func RequireSelectInput(flag string, args []string, label string) (string, error) {
+	if !IsInteractive() {
+		requireFlag(flag)
+		return "", nil
+	}
+	return SelectInput(args, label)
+}
+
+// promptError returns the error with which the prompt having the given label failed,
+// i.e., when it was cancelled
+func promptError(label string, err error) error {
+	if label == "" {
+		return fmt.Errorf("prompt failed; %w", err)
+	}
+	return fmt.Errorf("prompt failed: %s; %w", label, err)
 }
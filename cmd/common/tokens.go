@@ -0,0 +1,179 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/provideservices/provide-go/api/ident"
+)
+
+// tokenRefreshLeeway is how long before expiry a cached token is refreshed, so
+// tokens never expire in flight
+const tokenRefreshLeeway = time.Minute * 2
+
+// TokenStatus describes a cached access token; the token itself is never included
+type TokenStatus struct {
+	Key         string     `json:"key"`
+	Subject     string     `json:"subject,omitempty"`
+	Scope       string     `json:"scope,omitempty"`
+	IssuedAt    *time.Time `json:"issued_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Expired     bool       `json:"expired"`
+	Refreshable bool       `json:"refreshable"`
+}
+
+// parseTokenClaims parses the claims of the given JWT without verifying its signature;
+// verification is the responsibility of the API to which the token is presented
+func parseTokenClaims(bearerToken string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	var parser jwt.Parser
+	_, _, err := parser.ParseUnverified(bearerToken, claims)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// tokenClaimTime returns the time held by the given numeric claim, if present
+func tokenClaimTime(claims jwt.MapClaims, claim string) *time.Time {
+	var secs int64
+	switch val := claims[claim].(type) {
+	case float64:
+		secs = int64(val)
+	case json.Number:
+		secs, _ = val.Int64()
+	case int64:
+		secs = val
+	default:
+		return nil
+	}
+	t := time.Unix(secs, 0)
+	return &t
+}
+
+// isTokenExpired returns true if the given token expires within the refresh leeway;
+// tokens without an expiration (i.e., legacy API tokens) never expire
+func isTokenExpired(bearerToken string) bool {
+	claims, err := parseTokenClaims(bearerToken)
+	if err != nil {
+		return false
+	}
+
+	exp := tokenClaimTime(claims, "exp")
+	if exp == nil {
+		return false
+	}
+
+	return !time.Now().Add(tokenRefreshLeeway).Before(*exp)
+}
+
+// requireFreshToken returns the given cached token, refreshing and persisting it first
+// using the refresh token cached under refreshKey if it is about to expire
+func requireFreshToken(token, tokenKey, refreshKey string) (string, error) {
+	if !isTokenExpired(token) {
+		return token, nil
+	}
+
+	refreshToken, err := CachedCredential(refreshKey)
+	if err != nil {
+		return "", err
+	}
+	if refreshToken == "" {
+		if Verbose {
+			log.Printf("WARNING: cached token %s has expired and no refresh token is cached", tokenKey)
+		}
+		return token, nil
+	}
+
+	resp, err := ident.CreateToken(refreshToken, map[string]interface{}{
+		"grant_type": "refresh_token",
+	})
+	if err != nil {
+		return "", NewAPIError(err, "failed to refresh cached token %s", tokenKey)
+	}
+	if resp == nil || resp.AccessToken == nil {
+		return "", NewAuthError("failed to refresh cached token %s; no access token returned", tokenKey)
+	}
+
+	err = CacheCredential(tokenKey, *resp.AccessToken)
+	if err != nil {
+		return "", err
+	}
+	if resp.RefreshToken != nil {
+		err = CacheCredential(refreshKey, *resp.RefreshToken)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return *resp.AccessToken, nil
+}
+
+// CachedTokenStatuses returns the status of each access token cached in the active context
+func CachedTokenStatuses() ([]*TokenStatus, error) {
+	credentialStore, err := requireCredentialStore()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := credentialStore.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	name := CurrentContext()
+	statuses := make([]*TokenStatus, 0)
+	for _, key := range keys {
+		if name != "" && !strings.HasPrefix(key, fmt.Sprintf("%s.", contextConfigKey(name, ""))) {
+			continue
+		} else if name == "" && strings.HasPrefix(key, fmt.Sprintf("%s.", contextsConfigKey)) {
+			// tokens of named contexts are not part of the default configuration
+			continue
+		}
+
+		var refreshKey string
+		if strings.HasSuffix(key, fmt.Sprintf(".%s", APIAccessTokenConfigKeyPartial)) {
+			refreshKey = fmt.Sprintf("%s%s", strings.TrimSuffix(key, APIAccessTokenConfigKeyPartial), APIRefreshTokenConfigKeyPartial)
+		} else if key == AccessTokenConfigKey || strings.HasSuffix(key, fmt.Sprintf(".%s", AccessTokenConfigKey)) {
+			refreshKey = fmt.Sprintf("%s%s", strings.TrimSuffix(key, AccessTokenConfigKey), RefreshTokenConfigKey)
+		} else {
+			continue
+		}
+
+		token, err := CachedCredential(key)
+		if err != nil {
+			return nil, err
+		}
+		refreshToken, err := CachedCredential(refreshKey)
+		if err != nil {
+			return nil, err
+		}
+
+		status := &TokenStatus{
+			Key:         strings.TrimPrefix(key, fmt.Sprintf("%s.", contextConfigKey(name, ""))),
+			Refreshable: refreshToken != "",
+		}
+		if claims, err := parseTokenClaims(token); err == nil {
+			if sub, ok := claims["sub"].(string); ok {
+				status.Subject = sub
+			}
+			if scope, ok := claims["scope"].(string); ok {
+				status.Scope = scope
+			}
+			status.IssuedAt = tokenClaimTime(claims, "iat")
+			status.ExpiresAt = tokenClaimTime(claims, "exp")
+			status.Expired = status.ExpiresAt != nil && !time.Now().Before(*status.ExpiresAt)
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Key < statuses[j].Key
+	})
+
+	return statuses, nil
+}
@@ -0,0 +1,183 @@
+package common
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// memoryCredentialStore holds credentials in memory
+type memoryCredentialStore struct {
+	credentials map[string]string
+}
+
+func (s *memoryCredentialStore) Name() string {
+	return "memory"
+}
+
+func (s *memoryCredentialStore) Get(key string) (string, error) {
+	return s.credentials[key], nil
+}
+
+func (s *memoryCredentialStore) Set(key, value string) error {
+	s.credentials[key] = value
+	return nil
+}
+
+func (s *memoryCredentialStore) Delete(key string) error {
+	delete(s.credentials, key)
+	return nil
+}
+
+func (s *memoryCredentialStore) Keys() ([]string, error) {
+	keys := make([]string, 0)
+	for key := range s.credentials {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// testToken returns a JWT having the given claims
+func testToken(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("failed to sign token; %s", err.Error())
+	}
+	return token
+}
+
+// expiringToken returns a JWT which expires after the given duration
+func expiringToken(t *testing.T, d time.Duration) string {
+	return testToken(t, jwt.MapClaims{
+		"sub": "user:jane",
+		"exp": time.Now().Add(d).Unix(),
+	})
+}
+
+func TestIsTokenExpired(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		expired bool
+	}{
+		{name: "expired", token: expiringToken(t, -time.Hour), expired: true},
+		{name: "expiring within leeway", token: expiringToken(t, time.Minute), expired: true},
+		{name: "expiring at leeway", token: expiringToken(t, tokenRefreshLeeway), expired: true},
+		{name: "expiring after leeway", token: expiringToken(t, time.Minute*10), expired: false},
+		{name: "no exp claim", token: testToken(t, jwt.MapClaims{"sub": "user:jane"}), expired: false},
+		{name: "malformed", token: "not-a-jwt", expired: false},
+		{name: "empty", token: "", expired: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if expired := isTokenExpired(tt.token); expired != tt.expired {
+				t.Errorf("isTokenExpired() = %v; want %v", expired, tt.expired)
+			}
+		})
+	}
+}
+
+func TestRequireFreshToken(t *testing.T) {
+	const tokenKey = "access-token"
+	const refreshKey = "refresh-token"
+
+	refreshed := expiringToken(t, time.Hour)
+	expired := expiringToken(t, -time.Hour)
+	fresh := expiringToken(t, time.Hour*2)
+
+	tests := []struct {
+		name         string
+		token        string
+		refreshToken string
+		status       int // status of the token refresh response
+
+		want             string
+		wantRefresh      bool
+		wantErr          bool
+		wantRefreshToken string // refresh token cached after the call
+	}{
+		{name: "fresh", token: fresh, refreshToken: "refresh", want: fresh, wantRefreshToken: "refresh"},
+		{name: "no exp claim", token: "not-a-jwt", refreshToken: "refresh", want: "not-a-jwt", wantRefreshToken: "refresh"},
+		{name: "expired without refresh token", token: expired, want: expired},
+		{name: "expired", token: expired, refreshToken: "refresh", status: http.StatusCreated, want: refreshed, wantRefresh: true, wantRefreshToken: "rotated"},
+		{name: "within leeway", token: expiringToken(t, time.Minute), refreshToken: "refresh", status: http.StatusCreated, want: refreshed, wantRefresh: true, wantRefreshToken: "rotated"},
+		{name: "refresh rejected", token: expired, refreshToken: "refresh", status: http.StatusUnauthorized, wantRefresh: true, wantErr: true, wantRefreshToken: "refresh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refreshes := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				refreshes++
+				if r.Method != http.MethodPost || r.URL.Path != "/api/v1/tokens" {
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+				if auth := r.Header.Get("Authorization"); auth != "bearer "+tt.refreshToken && auth != "Bearer "+tt.refreshToken {
+					t.Errorf("token refreshed using %q; want the cached refresh token", auth)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"access_token":  refreshed,
+					"refresh_token": "rotated",
+				})
+			}))
+			defer srv.Close()
+			withIdentAPI(t, srv.URL)
+
+			credentials := &memoryCredentialStore{credentials: map[string]string{tokenKey: tt.token}}
+			if tt.refreshToken != "" {
+				credentials.credentials[refreshKey] = tt.refreshToken
+			}
+			withCredentialStore(t, credentials)
+
+			token, err := requireFreshToken(tt.token, tokenKey, refreshKey)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("requireFreshToken() error = %v; want error: %v", err, tt.wantErr)
+			}
+			if token != tt.want {
+				t.Errorf("requireFreshToken() = %q; want %q", token, tt.want)
+			}
+			if (refreshes > 0) != tt.wantRefresh {
+				t.Errorf("token refreshed %d times; want refresh: %v", refreshes, tt.wantRefresh)
+			}
+
+			wantToken := tt.token
+			if tt.wantRefresh && !tt.wantErr {
+				wantToken = refreshed
+			}
+			if cached := credentials.credentials[tokenKey]; cached != wantToken {
+				t.Errorf("cached token = %q; want %q", cached, wantToken)
+			}
+			if cached := credentials.credentials[refreshKey]; cached != tt.wantRefreshToken {
+				t.Errorf("cached refresh token = %q; want %q", cached, tt.wantRefreshToken)
+			}
+		})
+	}
+}
+
+// withIdentAPI points the ident client at the server with the given URL until the test completes
+func withIdentAPI(t *testing.T, rawurl string) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		t.Fatalf("failed to parse %s; %s", rawurl, err.Error())
+	}
+	t.Setenv("IDENT_API_HOST", u.Host)
+	t.Setenv("IDENT_API_SCHEME", u.Scheme)
+}
+
+// withCredentialStore makes the given credential store active until the test completes
+func withCredentialStore(t *testing.T, credentialStore CredentialStore) {
+	store = credentialStore
+	t.Cleanup(func() {
+		store = nil
+	})
+}
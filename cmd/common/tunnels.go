@@ -0,0 +1,696 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// TunnelProviderConfigKey is the config key of the provider used to expose the endpoints
+// of the organization; the tunnel settings are read from the active context, if set, and
+// otherwise from the top-level configuration
+const TunnelProviderConfigKey = "tunnel-provider"
+
+const (
+	TunnelProviderNgrok  = "ngrok"  // ngrok agent, found in PATH (default)
+	TunnelProviderSSH    = "ssh"    // reverse tunnel to an SSH bastion
+	TunnelProviderStatic = "static" // pre-provisioned public endpoints
+)
+
+// config keys of the ngrok tunnel provider
+const (
+	TunnelNgrokPathConfigKey      = "tunnel-ngrok-path"      // path of the ngrok binary; defaults to ngrok in PATH
+	TunnelNgrokAuthTokenConfigKey = "tunnel-ngrok-authtoken" // ngrok auth token; defaults to the ngrok configuration
+	TunnelNgrokRegionConfigKey    = "tunnel-ngrok-region"    // ngrok region; i.e., us
+)
+
+// config keys of the SSH tunnel provider
+const (
+	TunnelSSHHostConfigKey          = "tunnel-ssh-host"           // bastion address; i.e., bastion.example.com:22
+	TunnelSSHUserConfigKey          = "tunnel-ssh-user"           // defaults to the current user
+	TunnelSSHIdentityConfigKey      = "tunnel-ssh-identity"       // path of the private key; defaults to ~/.ssh/id_rsa
+	TunnelSSHKnownHostsConfigKey    = "tunnel-ssh-known-hosts"    // defaults to ~/.ssh/known_hosts
+	TunnelSSHPublicHostConfigKey    = "tunnel-ssh-public-host"    // host at which the forwarded ports are reachable; defaults to the bastion host
+	TunnelSSHAPIPortConfigKey       = "tunnel-ssh-api-port"       // bastion port forwarded to the API; allocated by the bastion by default
+	TunnelSSHMessagingPortConfigKey = "tunnel-ssh-messaging-port" // bastion port forwarded to the messaging endpoint; allocated by the bastion by default
+)
+
+// config keys of the static tunnel provider
+const (
+	TunnelStaticAPIEndpointConfigKey       = "tunnel-static-api-endpoint"       // public API endpoint; i.e., https://baseline.example.com
+	TunnelStaticMessagingEndpointConfigKey = "tunnel-static-messaging-endpoint" // public messaging endpoint; i.e., nats://baseline.example.com:4222
+)
+
+// names of the endpoints exposed by a tunnel
+const (
+	TunnelEndpointAPI       = "api"
+	TunnelEndpointMessaging = "messaging"
+)
+
+const ngrokStartTimeout = time.Second * 15
+const ngrokTunnelPollInterval = time.Second * 10
+const ngrokTunnelRetries = 10
+
+// ngrokAuthTokenEnvVersion is the first major version of the agent which reads NGROK_AUTHTOKEN
+const ngrokAuthTokenEnvVersion = 3
+
+var ngrokVersionPattern = regexp.MustCompile(`ngrok version (\d+)\.`)
+
+const sshTunnelDialTimeout = time.Second * 10
+const sshTunnelKeepaliveInterval = time.Second * 30
+const sshTunnelReconnectInterval = time.Second * 5
+
+// TunnelProvider exposes local ports at public endpoints
+type TunnelProvider interface {
+	Name() string
+
+	// Start exposes the given local ports; the public endpoint of each port is sent on endpoints
+	// once established, and again whenever it changes, i.e., when the tunnel is reestablished.
+	// Start blocks until ctx is done, and returns an error if the tunnel fails irrecoverably
+	Start(ctx context.Context, ports []*TunnelPort, endpoints chan<- *TunnelEndpoint) error
+}
+
+// TunnelPort is a local port exposed by a tunnel
+type TunnelPort struct {
+	Name      string // i.e., api
+	Proto     string // http or tcp
+	Scheme    string // scheme of the public endpoint when the port is exposed as-is; i.e., nats
+	LocalPort int
+}
+
+// TunnelEndpoint is the public endpoint of the named port
+type TunnelEndpoint struct {
+	Name string
+	URL  string
+}
+
+// RequireTunnelProvider returns the configured tunnel provider
+func RequireTunnelProvider() (TunnelProvider, error) {
+	return TunnelProviderFactory(TunnelSetting(TunnelProviderConfigKey))
+}
+
+// TunnelProviderFactory returns the named tunnel provider, configured using the tunnel settings
+func TunnelProviderFactory(name string) (TunnelProvider, error) {
+	switch name {
+	case "", TunnelProviderNgrok:
+		return ngrokTunnelFactory()
+	case TunnelProviderSSH:
+		return sshTunnelFactory()
+	case TunnelProviderStatic:
+		return staticTunnelFactory()
+	default:
+		return nil, NewValidationError("unsupported %s: %s; must be one of %s, %s or %s", TunnelProviderConfigKey, name, TunnelProviderNgrok, TunnelProviderSSH, TunnelProviderStatic)
+	}
+}
+
+// TunnelSetting returns the value of the given tunnel config key in the active context,
+// or in the top-level configuration
+func TunnelSetting(key string) string {
+	if val := contextDefault(key); val != "" {
+		return val
+	}
+	return viper.GetString(key)
+}
+
+// TunnelEndpointHosts returns the hosts of the endpoints exposed by the configured SSH and
+// static tunnel providers; ngrok endpoints are recognized by their domain
+func TunnelEndpointHosts() []string {
+	hosts := make([]string, 0)
+	switch TunnelSetting(TunnelProviderConfigKey) {
+	case TunnelProviderSSH:
+		if host := TunnelSetting(TunnelSSHPublicHostConfigKey); host != "" {
+			hosts = append(hosts, host)
+		} else if host, _, err := net.SplitHostPort(sshTunnelAddress(TunnelSetting(TunnelSSHHostConfigKey))); err == nil {
+			hosts = append(hosts, host)
+		}
+	case TunnelProviderStatic:
+		for _, key := range []string{TunnelStaticAPIEndpointConfigKey, TunnelStaticMessagingEndpointConfigKey} {
+			if endpoint, err := url.Parse(TunnelSetting(key)); err == nil && endpoint.Hostname() != "" {
+				hosts = append(hosts, endpoint.Hostname())
+			}
+		}
+	}
+	return hosts
+}
+
+// publishTunnelEndpoint sends the public endpoint of the named port, unless ctx is done
+func publishTunnelEndpoint(ctx context.Context, endpoints chan<- *TunnelEndpoint, name, endpoint string) {
+	select {
+	case endpoints <- &TunnelEndpoint{Name: name, URL: endpoint}:
+	case <-ctx.Done():
+	}
+}
+
+// ngrokTunnel exposes the ports using an ngrok agent, run for the lifetime of the tunnel
+type ngrokTunnel struct {
+	binaryPath string
+	authToken  string
+	region     string
+}
+
+func ngrokTunnelFactory() (TunnelProvider, error) {
+	binaryPath := TunnelSetting(TunnelNgrokPathConfigKey)
+	if binaryPath == "" {
+		path, err := exec.LookPath("ngrok")
+		if err != nil {
+			return nil, NewNotFoundError("ngrok not found in PATH; install ngrok, set %s, or set %s to %s or %s", TunnelNgrokPathConfigKey, TunnelProviderConfigKey, TunnelProviderSSH, TunnelProviderStatic)
+		}
+		binaryPath = path
+	} else if _, err := os.Stat(binaryPath); err != nil {
+		return nil, NewNotFoundError("ngrok not found: %s", binaryPath)
+	}
+
+	return &ngrokTunnel{
+		binaryPath: binaryPath,
+		authToken:  TunnelSetting(TunnelNgrokAuthTokenConfigKey),
+		region:     TunnelSetting(TunnelNgrokRegionConfigKey),
+	}, nil
+}
+
+func (t *ngrokTunnel) Name() string {
+	return TunnelProviderNgrok
+}
+
+func (t *ngrokTunnel) Start(ctx context.Context, ports []*TunnelPort, endpoints chan<- *TunnelEndpoint) error {
+	major := 0
+	if t.authToken != "" {
+		out, err := exec.Command(t.binaryPath, "version").Output()
+		if err != nil {
+			return fmt.Errorf("failed to resolve ngrok agent version; %w", err)
+		}
+		if major, err = parseNgrokMajorVersion(string(out)); err != nil {
+			return err
+		}
+	}
+
+	cmd, cleanup, err := t.command(major)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to start ngrok; %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ngrok; %w", err)
+	}
+	defer cmd.Process.Kill()
+
+	webAddr := make(chan string, 1)
+	exited := make(chan error, 1)
+	go func() {
+		var lastErr string
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			var entry struct {
+				Msg  string `json:"msg"`
+				Addr string `json:"addr"`
+				Err  string `json:"err"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				continue
+			}
+			if entry.Msg == "starting web service" && entry.Addr != "" {
+				select {
+				case webAddr <- entry.Addr:
+				default:
+				}
+			}
+			if entry.Err != "" && entry.Err != "<nil>" {
+				lastErr = entry.Err
+			}
+		}
+		err := cmd.Wait()
+		if lastErr != "" {
+			err = fmt.Errorf("%s", lastErr)
+		}
+		exited <- err
+	}()
+
+	var agent *ngrokAgent
+	select {
+	case addr := <-webAddr:
+		agent = &ngrokAgent{addr: addr}
+	case err := <-exited:
+		return fmt.Errorf("ngrok exited before starting; %v", err)
+	case <-time.After(ngrokStartTimeout):
+		return fmt.Errorf("ngrok failed to start within %s", ngrokStartTimeout)
+	case <-ctx.Done():
+		return nil
+	}
+
+	published := map[string]string{}
+	open := func(port *TunnelPort) error {
+		var publicURL string
+		var err error
+		for attempt := 0; attempt < ngrokTunnelRetries; attempt++ {
+			publicURL, err = agent.create(t.tunnelName(port), port.Proto, port.LocalPort)
+			if err == nil {
+				break
+			}
+			time.Sleep(time.Second)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to establish ngrok tunnel for %s endpoint; %w", port.Name, err)
+		}
+		if published[port.Name] != publicURL {
+			published[port.Name] = publicURL
+			publishTunnelEndpoint(ctx, endpoints, port.Name, publicURL)
+		}
+		return nil
+	}
+
+	for _, port := range ports {
+		if err := open(port); err != nil {
+			return err
+		}
+	}
+
+	// the agent reconnects to ngrok on its own, but tunnels may be dropped or assigned
+	// a new public URL in the process; they are reestablished and republished
+	ticker := time.NewTicker(ngrokTunnelPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			for _, port := range ports {
+				agent.delete(t.tunnelName(port))
+			}
+			return nil
+		case err := <-exited:
+			return fmt.Errorf("ngrok exited; %v", err)
+		case <-ticker.C:
+			for _, port := range ports {
+				publicURL, err := agent.lookup(t.tunnelName(port))
+				if err != nil {
+					log.Printf("WARNING: failed to inspect ngrok tunnel for %s endpoint; %s", port.Name, err.Error())
+					continue
+				}
+				if publicURL == "" {
+					log.Printf("ngrok tunnel for %s endpoint was closed; reestablishing", port.Name)
+					if err := open(port); err != nil {
+						log.Printf("WARNING: %s", err.Error())
+					}
+				} else if published[port.Name] != publicURL {
+					published[port.Name] = publicURL
+					publishTunnelEndpoint(ctx, endpoints, port.Name, publicURL)
+				}
+			}
+		}
+	}
+}
+
+// tunnelName returns the name of the ngrok tunnel of the given port
+// command returns the command which runs the agent, having the given major version, and a func
+// which removes any file written for it; the auth token is passed in the environment to agents
+// which read NGROK_AUTHTOKEN, and in a config file readable only by the current user to earlier
+// agents, rather than in the arguments, so it is not visible to other users in the process list
+func (t *ngrokTunnel) command(major int) (*exec.Cmd, func(), error) {
+	args := []string{"start", "--none", "--log=stdout", "--log-format=json"}
+	if t.region != "" {
+		args = append(args, fmt.Sprintf("--region=%s", t.region))
+	}
+
+	cleanup := func() {}
+	var env []string
+	if t.authToken != "" && major >= ngrokAuthTokenEnvVersion {
+		env = append(os.Environ(), fmt.Sprintf("NGROK_AUTHTOKEN=%s", t.authToken))
+	} else if t.authToken != "" {
+		config, err := ioutil.TempFile("", "prvd-ngrok-*.yml")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to write ngrok config; %w", err)
+		}
+		cleanup = func() {
+			os.Remove(config.Name())
+		}
+		_, err = fmt.Fprintf(config, "authtoken: %s\n", strconv.Quote(t.authToken))
+		if closeErr := config.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to write ngrok config; %w", err)
+		}
+		args = append(args, fmt.Sprintf("--config=%s", config.Name()))
+	}
+
+	cmd := exec.Command(t.binaryPath, args...)
+	cmd.Env = env
+	return cmd, cleanup, nil
+}
+
+// parseNgrokMajorVersion returns the major version of the agent from the output of ngrok version,
+// i.e., ngrok version 3.1.0
+func parseNgrokMajorVersion(out string) (int, error) {
+	match := ngrokVersionPattern.FindStringSubmatch(out)
+	if match == nil {
+		return 0, fmt.Errorf("failed to parse ngrok agent version: %s", strings.TrimSpace(out))
+	}
+	return strconv.Atoi(match[1])
+}
+
+func (t *ngrokTunnel) tunnelName(port *TunnelPort) string {
+	if OrganizationID == "" {
+		return port.Name
+	}
+	return fmt.Sprintf("%s-%s", OrganizationID, port.Name)
+}
+
+// ngrokAgent is a client of the local API of a running ngrok agent
+type ngrokAgent struct {
+	addr string
+}
+
+// create starts a tunnel to the given local port and returns its public URL
+func (a *ngrokAgent) create(name, proto string, localPort int) (string, error) {
+	params := map[string]interface{}{
+		"addr":  strconv.Itoa(localPort),
+		"proto": proto,
+		"name":  name,
+	}
+	if proto == "http" {
+		params["bind_tls"] = true
+	}
+
+	raw, _ := json.Marshal(params)
+	resp, err := http.Post(fmt.Sprintf("http://%s/api/tunnels", a.addr), "application/json", bytes.NewReader(raw))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	return a.publicURL(resp)
+}
+
+// lookup returns the public URL of the named tunnel, or an empty string if it is closed
+func (a *ngrokAgent) lookup(name string) (string, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%s/api/tunnels/%s", a.addr, url.PathEscape(name)))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	return a.publicURL(resp)
+}
+
+// delete closes the named tunnel
+func (a *ngrokAgent) delete(name string) {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("http://%s/api/tunnels/%s", a.addr, url.PathEscape(name)), nil)
+	if err != nil {
+		return
+	}
+	if resp, err := http.DefaultClient.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
+
+func (a *ngrokAgent) publicURL(resp *http.Response) (string, error) {
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("ngrok agent returned %s; %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var tunnel struct {
+		PublicURL string `json:"public_url"`
+	}
+	if err := json.Unmarshal(body, &tunnel); err != nil {
+		return "", err
+	}
+	return tunnel.PublicURL, nil
+}
+
+// sshTunnel exposes the ports using remote port forwarding on an SSH bastion; the bastion
+// must permit remote forwarding on all interfaces, i.e., using GatewayPorts clientspecified
+type sshTunnel struct {
+	addr        string
+	publicHost  string
+	config      *ssh.ClientConfig
+	remotePorts map[string]int
+}
+
+func sshTunnelFactory() (TunnelProvider, error) {
+	host := TunnelSetting(TunnelSSHHostConfigKey)
+	if host == "" {
+		return nil, NewValidationError("%s is required when %s is %s", TunnelSSHHostConfigKey, TunnelProviderConfigKey, TunnelProviderSSH)
+	}
+	addr := sshTunnelAddress(host)
+
+	username := TunnelSetting(TunnelSSHUserConfigKey)
+	if username == "" {
+		current, err := user.Current()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve current user; %w", err)
+		}
+		username = current.Username
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	identity := TunnelSetting(TunnelSSHIdentityConfigKey)
+	if identity == "" {
+		identity = filepath.Join(home, ".ssh", "id_rsa")
+	}
+	key, err := ioutil.ReadFile(identity)
+	if err != nil {
+		return nil, NewNotFoundError("failed to read SSH identity %s; set %s", identity, TunnelSSHIdentityConfigKey)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, NewValidationError("failed to parse SSH identity %s; passphrase-protected keys are not supported; %s", identity, err.Error())
+	}
+
+	knownHostsPath := TunnelSetting(TunnelSSHKnownHostsConfigKey)
+	if knownHostsPath == "" {
+		knownHostsPath = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsPath)
+	if err != nil {
+		return nil, NewNotFoundError("failed to read SSH known hosts %s; set %s; %s", knownHostsPath, TunnelSSHKnownHostsConfigKey, err.Error())
+	}
+
+	publicHost := TunnelSetting(TunnelSSHPublicHostConfigKey)
+	if publicHost == "" {
+		publicHost, _, _ = net.SplitHostPort(addr)
+	}
+
+	remotePorts := map[string]int{}
+	for name, configKey := range map[string]string{
+		TunnelEndpointAPI:       TunnelSSHAPIPortConfigKey,
+		TunnelEndpointMessaging: TunnelSSHMessagingPortConfigKey,
+	} {
+		if val := TunnelSetting(configKey); val != "" {
+			port, err := strconv.Atoi(val)
+			if err != nil || port < 0 || port > 65535 {
+				return nil, NewValidationError("invalid %s: %s; must be a port number", configKey, val)
+			}
+			remotePorts[name] = port
+		}
+	}
+
+	return &sshTunnel{
+		addr:       addr,
+		publicHost: publicHost,
+		config: &ssh.ClientConfig{
+			User:            username,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         sshTunnelDialTimeout,
+		},
+		remotePorts: remotePorts,
+	}, nil
+}
+
+// sshTunnelAddress returns the given bastion host with the default SSH port, unless it has a port
+func sshTunnelAddress(host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(host, "22")
+}
+
+func (t *sshTunnel) Name() string {
+	return TunnelProviderSSH
+}
+
+func (t *sshTunnel) Start(ctx context.Context, ports []*TunnelPort, endpoints chan<- *TunnelEndpoint) error {
+	published := map[string]string{}
+	publish := func(name, endpoint string) {
+		if published[name] != endpoint {
+			published[name] = endpoint
+			publishTunnelEndpoint(ctx, endpoints, name, endpoint)
+		}
+	}
+
+	for {
+		err := t.forward(ctx, ports, publish)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if len(published) == 0 {
+			// the tunnel was never established, so the configuration is assumed to be invalid
+			return fmt.Errorf("failed to establish SSH tunnel to %s; %w", t.addr, err)
+		}
+
+		log.Printf("WARNING: SSH tunnel to %s disconnected; reconnecting in %s; %s", t.addr, sshTunnelReconnectInterval, err.Error())
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(sshTunnelReconnectInterval):
+		}
+	}
+}
+
+// forward connects to the bastion and forwards a bastion port to each of the given local
+// ports until the connection is lost or ctx is done
+func (t *sshTunnel) forward(ctx context.Context, ports []*TunnelPort, publish func(name, endpoint string)) error {
+	client, err := ssh.Dial("tcp", t.addr, t.config)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	for _, port := range ports {
+		listener, err := client.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", t.remotePorts[port.Name]))
+		if err != nil {
+			return fmt.Errorf("failed to forward bastion port for %s endpoint; %w", port.Name, err)
+		}
+		go acceptTunnelConnections(listener, port.LocalPort)
+
+		remotePort := listener.Addr().(*net.TCPAddr).Port
+		publish(port.Name, fmt.Sprintf("%s://%s", port.Scheme, net.JoinHostPort(t.publicHost, strconv.Itoa(remotePort))))
+	}
+
+	disconnected := make(chan error, 1)
+	go func() {
+		disconnected <- client.Wait()
+	}()
+
+	keepalive := time.NewTicker(sshTunnelKeepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-disconnected:
+			if err == nil {
+				err = io.EOF
+			}
+			return err
+		case <-keepalive.C:
+			if _, _, err := client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// acceptTunnelConnections proxies each connection accepted by the given listener to the local port
+func acceptTunnelConnections(listener net.Listener, localPort int) {
+	defer listener.Close()
+	for {
+		remote, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer remote.Close()
+			local, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", localPort))
+			if err != nil {
+				log.Printf("WARNING: failed to connect tunneled connection to local port %d; %s", localPort, err.Error())
+				return
+			}
+			defer local.Close()
+
+			done := make(chan struct{}, 2)
+			go func() {
+				io.Copy(local, remote)
+				done <- struct{}{}
+			}()
+			go func() {
+				io.Copy(remote, local)
+				done <- struct{}{}
+			}()
+			<-done
+		}()
+	}
+}
+
+// staticTunnel publishes pre-provisioned public endpoints, i.e., a load balancer or port
+// forwarding rule managed outside of prvd which routes to the local ports
+type staticTunnel struct {
+	endpoints map[string]string
+}
+
+func staticTunnelFactory() (TunnelProvider, error) {
+	endpoints := map[string]string{}
+	for name, configKey := range map[string]string{
+		TunnelEndpointAPI:       TunnelStaticAPIEndpointConfigKey,
+		TunnelEndpointMessaging: TunnelStaticMessagingEndpointConfigKey,
+	} {
+		val := TunnelSetting(configKey)
+		if val == "" {
+			continue
+		}
+		if endpoint, err := url.Parse(val); err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+			return nil, NewValidationError("invalid %s: %s; must be a URL", configKey, val)
+		}
+		endpoints[name] = val
+	}
+
+	return &staticTunnel{
+		endpoints: endpoints,
+	}, nil
+}
+
+func (t *staticTunnel) Name() string {
+	return TunnelProviderStatic
+}
+
+func (t *staticTunnel) Start(ctx context.Context, ports []*TunnelPort, endpoints chan<- *TunnelEndpoint) error {
+	for _, port := range ports {
+		if _, ok := t.endpoints[port.Name]; !ok {
+			return NewValidationError("tunnel-static-%s-endpoint is required to expose the %s endpoint when %s is %s", port.Name, port.Name, TunnelProviderConfigKey, TunnelProviderStatic)
+		}
+	}
+	for _, port := range ports {
+		publishTunnelEndpoint(ctx, endpoints, port.Name, t.endpoints[port.Name])
+	}
+
+	<-ctx.Done()
+	return nil
+}
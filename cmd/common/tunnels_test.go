@@ -0,0 +1,114 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseNgrokMajorVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    int
+		wantErr bool
+	}{
+		{name: "v2", out: "ngrok version 2.3.40\n", want: 2},
+		{name: "v3", out: "ngrok version 3.1.0\n", want: 3},
+		{name: "unrecognized", out: "command not found\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNgrokMajorVersion(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseNgrokMajorVersion(%q) error = %v; want error: %v", tt.out, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseNgrokMajorVersion(%q) = %d; want %d", tt.out, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNgrokCommand(t *testing.T) {
+	tunnel := &ngrokTunnel{binaryPath: "ngrok", authToken: "secret-token", region: "eu"}
+
+	t.Run("v3", func(t *testing.T) {
+		cmd, cleanup, err := tunnel.command(3)
+		if err != nil {
+			t.Fatalf("command() error = %v", err)
+		}
+		defer cleanup()
+
+		if strings.Contains(strings.Join(cmd.Args, " "), "secret-token") || strings.Contains(strings.Join(cmd.Args, " "), "--config") {
+			t.Errorf("command() args = %q; want the auth token in the environment", cmd.Args)
+		}
+		if !containsEnv(cmd.Env, "NGROK_AUTHTOKEN=secret-token") {
+			t.Errorf("command() env does not set NGROK_AUTHTOKEN")
+		}
+	})
+
+	t.Run("v2", func(t *testing.T) {
+		cmd, cleanup, err := tunnel.command(2)
+		if err != nil {
+			t.Fatalf("command() error = %v", err)
+		}
+
+		var config string
+		for _, arg := range cmd.Args {
+			if strings.HasPrefix(arg, "--config=") {
+				config = strings.TrimPrefix(arg, "--config=")
+			}
+			if strings.Contains(arg, "secret-token") {
+				t.Errorf("command() args = %q; want the auth token in a config file", cmd.Args)
+			}
+		}
+		if config == "" {
+			t.Fatalf("command() args = %q; want --config", cmd.Args)
+		}
+		if cmd.Env != nil {
+			t.Errorf("command() env = %q; want the inherited environment", cmd.Env)
+		}
+
+		info, err := os.Stat(config)
+		if err != nil {
+			t.Fatalf("failed to stat %s; %s", config, err.Error())
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("%s mode = %s; want -rw-------", config, info.Mode().Perm())
+		}
+		raw, _ := ioutil.ReadFile(config)
+		if string(raw) != "authtoken: \"secret-token\"\n" {
+			t.Errorf("%s = %q", config, raw)
+		}
+
+		cleanup()
+		if _, err := os.Stat(config); !os.IsNotExist(err) {
+			t.Errorf("%s not removed", config)
+		}
+	})
+
+	t.Run("without auth token", func(t *testing.T) {
+		cmd, cleanup, err := (&ngrokTunnel{binaryPath: "ngrok"}).command(0)
+		if err != nil {
+			t.Fatalf("command() error = %v", err)
+		}
+		defer cleanup()
+
+		want := "ngrok start --none --log=stdout --log-format=json"
+		if strings.Join(cmd.Args, " ") != want || cmd.Env != nil {
+			t.Errorf("command() = %q, env %q; want %q", cmd.Args, cmd.Env, want)
+		}
+	})
+}
+
+func containsEnv(env []string, envvar string) bool {
+	for _, e := range env {
+		if e == envvar {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the prvd configuration",
+	Long: `Manage the prvd configuration and the storage of credentials.
+
+Tokens are stored in the credential store named by the credential-store configuration key:
+
+	- config: plaintext in the configuration file (default)
+	- file: encrypted using a passphrase, in ~/.provide-cli.credentials; set PROVIDE_CREDENTIALS_PASSPHRASE when running non-interactively
+	- keyring: the OS keyring, using secret-tool (Secret Service) or security (macOS keychain)
+
+The endpoints of a local baseline stack are exposed to the WAN using the tunnel provider named by the
+tunnel-provider configuration key; tunnel settings may also be set within a context:
+
+	- ngrok: an ngrok agent (default); optionally set tunnel-ngrok-path, tunnel-ngrok-authtoken and tunnel-ngrok-region
+	- ssh: a reverse tunnel to the SSH bastion set by tunnel-ssh-host; optionally set tunnel-ssh-user, tunnel-ssh-identity,
+	  tunnel-ssh-known-hosts, tunnel-ssh-public-host, tunnel-ssh-api-port and tunnel-ssh-messaging-port
+	- static: the pre-provisioned endpoints set by tunnel-static-api-endpoint and tunnel-static-messaging-endpoint`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
+
+		return generalPrompt(cmd, args, "")
+	},
+}
+
+func init() {
+	ConfigCmd.AddCommand(migrateCredentialsCmd)
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var credentialStore string
+
+var migrateCredentialsCmd = &cobra.Command{
+	Use:   "migrate-credentials --store file",
+	Short: "Move tokens out of the configuration file",
+	Long: `Move all access and refresh tokens stored in plaintext in the configuration file to an
+encrypted file or the OS keyring; the chosen store is used for all subsequent commands`,
+	RunE: migrateCredentials,
+}
+
+func migrateCredentials(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepMigrateCredentials)
+}
+
+func migrateCredentialsRun(cmd *cobra.Command, args []string) error {
+	keys, err := common.MigrateCredentials(credentialStore)
+	if err != nil {
+		return fmt.Errorf("failed to migrate credentials; %w", err)
+	}
+
+	result := map[string]interface{}{
+		"store":    credentialStore,
+		"migrated": keys,
+	}
+	table := common.NewTable("KEY", "STORE")
+	for _, key := range keys {
+		table.AddRow(key, credentialStore)
+	}
+	if err := common.Render(result, table); err != nil {
+		return fmt.Errorf("failed to render migrated credentials; %w", err)
+	}
+	return nil
+}
+
+func init() {
+	migrateCredentialsCmd.Flags().StringVar(&credentialStore, "store", "", "credential store to which tokens are migrated; one of file or keyring")
+}
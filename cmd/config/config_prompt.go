@@ -0,0 +1,39 @@
+package config
+
+import (
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+const promptStepMigrateCredentials = "Migrate Credentials"
+
+var emptyPromptArgs = []string{promptStepMigrateCredentials}
+var emptyPromptLabel = "What would you like to do"
+
+var credentialStorePromptArgs = []string{common.CredentialStoreFile, common.CredentialStoreKeyring}
+var credentialStorePromptLabel = "Where would you like to store credentials"
+
+// General Endpoints
+func generalPrompt(cmd *cobra.Command, args []string, step string) error {
+	var err error
+
+	switch step {
+	case promptStepMigrateCredentials:
+		if credentialStore == "" {
+			if credentialStore, err = common.RequireSelectInput("--store", credentialStorePromptArgs, credentialStorePromptLabel); err != nil {
+				return err
+			}
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return migrateCredentialsRun(cmd, args)
+	case "":
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
+	}
+	return nil
+}
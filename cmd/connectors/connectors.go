@@ -1,8 +1,6 @@
 package connectors
 
 import (
-	"os"
-
 	"github.com/provideservices/provide-cli/cmd/common"
 	"github.com/spf13/cobra"
 )
@@ -18,16 +16,12 @@ var ConnectorsCmd = &cobra.Command{
 	Long: `Connectors are adapters that connect external arbitrary infrastructure with Provide.
 
 This API allows you to provision load balanced, cloud-agnostic infrastructure for your distributed system.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		common.CmdExistsOrExit(cmd, args)
-
-		generalPrompt(cmd, args, "")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
 
-		defer func() {
-			if r := recover(); r != nil {
-				os.Exit(1)
-			}
-		}()
+		return generalPrompt(cmd, args, "")
 	},
 }
 
@@ -2,8 +2,6 @@ package connectors
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/nchain"
@@ -15,21 +13,24 @@ var connectorsDeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete a specific connector",
 	Long:  `Delete a specific connector by identifier and teardown any associated infrastructure`,
-	Run:   deleteConnector,
+	RunE:  deleteConnector,
 }
 
-func deleteConnector(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
-	err := provide.DeleteConnector(token, common.ConnectorID)
+func deleteConnector(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
 	if err != nil {
-		log.Printf("Failed to delete connector with id: %s; %s", common.ConnectorID, err.Error())
-		os.Exit(1)
+		return err
+	}
+	err = provide.DeleteConnector(token, common.ConnectorID)
+	if err != nil {
+		return common.NewAPIError(err, "Failed to delete connector with id: %s", common.ConnectorID)
 	}
 	// if status != 204 {
 	// 	log.Printf("Failed to delete connector with id: %s; received status: %d", common.ConnectorID, status)
 	// 	os.Exit(1)
 	// }
 	fmt.Printf("Deleted connector with id: %s", common.ConnectorID)
+	return nil
 }
 
 func init() {
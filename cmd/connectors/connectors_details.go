@@ -3,8 +3,6 @@ package connectors
 import (
 	"encoding/json"
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/nchain"
@@ -16,28 +14,43 @@ var connectorsDetailsCmd = &cobra.Command{
 	Use:   "details",
 	Short: "Retrieve details for a specific connector",
 	Long:  `Retrieve details for a specific connector by identifier, scoped to the authorized API token`,
-	Run:   fetchConnectorDetails,
+	RunE:  fetchConnectorDetails,
 }
 
-func fetchConnectorDetails(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func fetchConnectorDetails(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{}
 	connector, err := provide.GetConnectorDetails(token, common.ConnectorID, params)
 	if err != nil {
-		log.Printf("Failed to retrieve details for connector with id: %s; %s", common.ConnectorID, err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to retrieve details for connector with id: %s", common.ConnectorID)
 	}
 	// if status != 200 {
 	// 	log.Printf("Failed to retrieve details for connector with id: %s; received status: %d", common.ConnectorID, status)
 	// 	os.Exit(1)
 	// }
+	table := common.NewTable("ID", "NAME", "TYPE", "API URL")
+	table.AddRow(connector.ID.String(), common.StringValue(connector.Name), common.StringValue(connector.Type), connectorAPIURL(connector))
+	if err := common.Render(connector, table); err != nil {
+		return fmt.Errorf("Failed to render connector details; %w", err)
+	}
+	return nil
+}
+
+// connectorAPIURL returns the configured API url for connector types which expose one
+func connectorAPIURL(connector *provide.Connector) string {
+	if connector.Type == nil || *connector.Type != connectorTypeIPFS || connector.Config == nil {
+		return ""
+	}
+
 	var config map[string]interface{}
 	json.Unmarshal(*connector.Config, &config)
-	result := fmt.Sprintf("%s\t%s\t%s", connector.ID.String(), *connector.Name, *connector.Type)
-	if *connector.Type == connectorTypeIPFS {
-		result = fmt.Sprintf("%s\t%s", result, config["api_url"])
+	if apiURL, apiURLOk := config["api_url"].(string); apiURLOk {
+		return apiURL
 	}
-	fmt.Printf("%s\n", result)
+	return ""
 }
 
 func init() {
@@ -2,8 +2,6 @@ package connectors
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/nchain"
@@ -23,7 +21,7 @@ var connectorsInitCmd = &cobra.Command{
 	Use:   "init --name 'my storage connector' --type ipfs --network 024ff1ef-7369-4dee-969c-1918c6edb5d4",
 	Short: "Initialize a new connector",
 	Long:  `Initialize a new connector and orchestrate any related resources`,
-	Run:   createConnector,
+	RunE:  createConnector,
 }
 
 func securityConfigFactory() map[string]interface{} {
@@ -44,9 +42,14 @@ func securityConfigFactory() map[string]interface{} {
 	return nil
 }
 
-func connectorConfigFactory() map[string]interface{} {
+func connectorConfigFactory() (map[string]interface{}, error) {
+	creds, err := common.InfrastructureCredentialsConfigFactory()
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := map[string]interface{}{
-		"credentials":   common.InfrastructureCredentialsConfigFactory(),
+		"credentials":   creds,
 		"common.Image":  common.Image,
 		"common.Region": common.Region,
 		"target_id":     common.TargetID,
@@ -72,24 +75,34 @@ func connectorConfigFactory() map[string]interface{} {
 		}
 	}
 
-	return cfg
+	return cfg, nil
 }
 
-func createConnector(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func createConnector(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
+	cfg, err := connectorConfigFactory()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{
 		"name":       connectorName,
 		"network_id": common.NetworkID,
 		"type":       connectorType,
-		"config":     connectorConfigFactory(),
+		"config":     cfg,
 	}
 	connector, err := provide.CreateConnector(token, params)
 	if err != nil {
-		log.Printf("Failed to initialize connector; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to initialize connector")
 	}
-	result := fmt.Sprintf("%s\t%s\n", connector.ID.String(), *connector.Name)
-	fmt.Print(result)
+	table := common.NewTable("ID", "NAME")
+	table.AddRow(connector.ID.String(), common.StringValue(connector.Name))
+	if err := common.Render(connector, table); err != nil {
+		return fmt.Errorf("Failed to render connector; %w", err)
+	}
+	return nil
 }
 
 func init() {
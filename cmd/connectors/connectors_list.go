@@ -1,10 +1,7 @@
 package connectors
 
 import (
-	"encoding/json"
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/nchain"
@@ -16,34 +13,35 @@ var connectorsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Retrieve a list of connectors",
 	Long:  `Retrieve a list of connectors scoped to the authorized API token`,
-	Run:   listConnectors,
+	RunE:  listConnectors,
 }
 
-func listConnectors(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func listConnectors(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{}
 	if common.ApplicationID != "" {
 		params["application_id"] = common.ApplicationID
 	}
 	connectors, err := provide.ListConnectors(token, params)
 	if err != nil {
-		log.Printf("Failed to retrieve connectors list; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to retrieve connectors list")
 	}
 	// if status != 200 {
 	// 	log.Printf("Failed to retrieve connectors list; received status: %d", status)
 	// 	os.Exit(1)
 	// }
+	table := common.NewTable("ID", "NAME", "TYPE", "API URL")
 	for i := range connectors {
 		connector := connectors[i]
-		var config map[string]interface{}
-		json.Unmarshal(*connector.Config, &config)
-		result := fmt.Sprintf("%s\t%s\t%s", connector.ID.String(), *connector.Name, *connector.Type)
-		if *connector.Type == connectorTypeIPFS {
-			result = fmt.Sprintf("%s\t%s", result, config["api_url"])
-		}
-		fmt.Printf("%s\n", result)
+		table.AddRow(connector.ID.String(), common.StringValue(connector.Name), common.StringValue(connector.Type), connectorAPIURL(connector))
+	}
+	if err := common.Render(connectors, table); err != nil {
+		return fmt.Errorf("Failed to render connectors list; %w", err)
 	}
+	return nil
 }
 
 func init() {
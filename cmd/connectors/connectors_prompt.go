@@ -16,50 +16,87 @@ var emptyPromptArgs = []string{promptStepInit, promptStepList, promptStepDetails
 var emptyPromptLabel = "What would you like to do"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, currentStep string) {
+func generalPrompt(cmd *cobra.Command, args []string, currentStep string) error {
+	var err error
+
 	switch step := currentStep; step {
 	case promptStepInit:
 		if connectorName == "" {
-			connectorName = common.FreeInput("Connector Name", "", common.MandatoryValidation)
+			if connectorName, err = common.RequireFreeInput("--name", "Connector Name", "", common.MandatoryValidation); err != nil {
+				return err
+			}
 		}
 		if connectorType == "" {
-			connectorType = common.FreeInput("Connector Type", "", common.MandatoryValidation)
+			if connectorType, err = common.RequireFreeInput("--type", "Connector Type", "", common.MandatoryValidation); err != nil {
+				return err
+			}
 		}
 		if common.ApplicationID == "" {
-			common.RequireApplication()
+			if err := common.RequireApplication(); err != nil {
+				return err
+			}
 		}
 		if common.NetworkID == "" {
-			common.RequirePublicNetwork()
+			if err := common.RequirePublicNetwork(); err != nil {
+				return err
+			}
 		}
 		if optional {
 			if ipfsAPIPort == 5001 {
-				result := common.FreeInput("IPFS API Port", "5001", common.NumberValidation)
+				result, err := common.FreeInput("IPFS API Port", "5001", common.NumberValidation)
+				if err != nil {
+					return err
+				}
 				ipfsAPIPort, _ = strconv.ParseUint(result, 10, 64)
 			}
 			if ipfsGatewayPort == 8080 {
-				result := common.FreeInput("IPFS Gateway Port", "8080", common.NumberValidation)
+				result, err := common.FreeInput("IPFS Gateway Port", "8080", common.NumberValidation)
+				if err != nil {
+					return err
+				}
 				ipfsGatewayPort, _ = strconv.ParseUint(result, 10, 64)
 			}
 		}
-		createConnector(cmd, args)
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return createConnector(cmd, args)
 	case promptStepList:
 		if optional {
-			common.RequireApplication()
+			if err := common.RequireApplication(); err != nil {
+				return err
+			}
 		}
-		listConnectors(cmd, args)
+		return listConnectors(cmd, args)
 	case promptStepDetails:
-		common.RequireConnector(map[string]interface{}{})
-		fetchConnectorDetails(cmd, args)
+		if err := common.RequireConnector(map[string]interface{}{}); err != nil {
+			return err
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return fetchConnectorDetails(cmd, args)
 	case promptStepDelete:
 		if common.ConnectorID == "" {
-			common.RequireConnector(map[string]interface{}{})
+			if err := common.RequireConnector(map[string]interface{}{}); err != nil {
+				return err
+			}
 		}
 		if common.ApplicationID == "" {
-			common.RequireApplication()
+			if err := common.RequireApplication(); err != nil {
+				return err
+			}
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
 		}
-		deleteConnector(cmd, args)
+		return deleteConnector(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
@@ -0,0 +1,32 @@
+package context
+
+import (
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var ContextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Manage configuration contexts",
+	Long: `Create and switch between named configuration contexts.
+
+Each context holds its own API hosts, user and API tokens and default organization,
+workgroup and network; i.e., a production install, a staging install and a local
+baseline stack can each be configured as a separate context.
+
+Use the global --context flag to run a single command in a context other than the active one.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
+
+		return generalPrompt(cmd, args, "")
+	},
+}
+
+func init() {
+	ContextCmd.AddCommand(contextListCmd)
+	ContextCmd.AddCommand(contextUseCmd)
+	ContextCmd.AddCommand(contextCreateCmd)
+	ContextCmd.AddCommand(contextDeleteCmd)
+}
@@ -0,0 +1,67 @@
+package context
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var apiHosts map[string]*string
+var organizationID string
+var workgroupID string
+var networkID string
+var use bool
+
+var contextCreateCmd = &cobra.Command{
+	Use:   "create <name> --ident-api-host localhost:8081 --ident-api-scheme http",
+	Short: "Create a new context",
+	Long: `Create a named context holding API hosts and default organization, workgroup and network.
+
+API hosts which are not set use the provide-go defaults, unless set in the environment.
+Tokens are cached in the context by running 'prvd authenticate' once it is active.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: createContext,
+}
+
+func createContext(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		name = args[0]
+	}
+	return generalPrompt(cmd, args, promptStepCreate)
+}
+
+func createContextRun(cmd *cobra.Command, args []string) error {
+	settings := map[string]string{
+		common.ContextOrganizationConfigKey: organizationID,
+		common.ContextWorkgroupConfigKey:    workgroupID,
+		common.ContextNetworkConfigKey:      networkID,
+	}
+	for key, val := range apiHosts {
+		settings[key] = *val
+	}
+
+	err := common.CreateContext(name, settings)
+	if err != nil {
+		return fmt.Errorf("failed to create context; %w", err)
+	}
+	log.Printf("created context: %s", name)
+
+	if use {
+		return useContextRun(cmd, args)
+	}
+	return nil
+}
+
+func init() {
+	apiHosts = map[string]*string{}
+	for _, host := range common.ContextAPIHosts {
+		apiHosts[host.Key] = contextCreateCmd.Flags().String(host.Key, "", host.Usage)
+	}
+
+	contextCreateCmd.Flags().StringVar(&organizationID, "organization", "", "default organization identifier")
+	contextCreateCmd.Flags().StringVar(&workgroupID, "workgroup", "", "default baseline workgroup identifier")
+	contextCreateCmd.Flags().StringVar(&networkID, "network", "", "default network identifier")
+	contextCreateCmd.Flags().BoolVar(&use, "use", false, "switch to the context once created")
+}
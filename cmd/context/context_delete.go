@@ -0,0 +1,33 @@
+package context
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var contextDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a context",
+	Long:  `Delete the named context, including any tokens it holds; the default configuration becomes active if the named context was active`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  deleteContext,
+}
+
+func deleteContext(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		name = args[0]
+	}
+	return generalPrompt(cmd, args, promptStepDelete)
+}
+
+func deleteContextRun(cmd *cobra.Command, args []string) error {
+	err := common.DeleteContext(name)
+	if err != nil {
+		return fmt.Errorf("failed to delete context; %w", err)
+	}
+	log.Printf("deleted context: %s", name)
+	return nil
+}
@@ -0,0 +1,80 @@
+package context
+
+import (
+	"fmt"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+// contextSummary is the rendering of a context; credentials are never included
+type contextSummary struct {
+	Name     string            `json:"name"`
+	Current  bool              `json:"current"`
+	Settings map[string]string `json:"settings"`
+}
+
+var contextListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Retrieve a list of contexts",
+	Long:  `Retrieve a list of the configured contexts; the active context is marked with an asterisk`,
+	RunE:  listContexts,
+}
+
+func listContexts(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepList)
+}
+
+func listContextsRun(cmd *cobra.Command, args []string) error {
+	current := common.CurrentContext()
+
+	contexts := make([]*contextSummary, 0)
+	table := common.NewTable("CURRENT", "NAME", "IDENT API", "ORGANIZATION", "WORKGROUP", "NETWORK")
+	for _, ctxName := range common.ListContexts() {
+		summary := contextSummaryFactory(ctxName, current)
+		contexts = append(contexts, summary)
+
+		marker := ""
+		if summary.Current {
+			marker = "*"
+		}
+		table.AddRow(
+			marker,
+			summary.Name,
+			summary.Settings["ident-api-host"],
+			summary.Settings[common.ContextOrganizationConfigKey],
+			summary.Settings[common.ContextWorkgroupConfigKey],
+			summary.Settings[common.ContextNetworkConfigKey],
+		)
+	}
+
+	if err := common.Render(contexts, table); err != nil {
+		return fmt.Errorf("failed to render contexts list; %w", err)
+	}
+	return nil
+}
+
+func contextSummaryFactory(ctxName, current string) *contextSummary {
+	settings := map[string]string{}
+	raw := common.ContextSettings(ctxName)
+
+	keys := []string{
+		common.ContextOrganizationConfigKey,
+		common.ContextWorkgroupConfigKey,
+		common.ContextNetworkConfigKey,
+	}
+	for _, host := range common.ContextAPIHosts {
+		keys = append(keys, host.Key)
+	}
+	for _, key := range keys {
+		if val, ok := raw[key].(string); ok && val != "" {
+			settings[key] = val
+		}
+	}
+
+	return &contextSummary{
+		Name:     ctxName,
+		Current:  ctxName == current,
+		Settings: settings,
+	}
+}
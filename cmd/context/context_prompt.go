@@ -0,0 +1,63 @@
+package context
+
+import (
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+const promptStepCreate = "Create"
+const promptStepDelete = "Delete"
+const promptStepList = "List"
+const promptStepUse = "Use"
+
+var emptyPromptArgs = []string{promptStepList, promptStepUse, promptStepCreate, promptStepDelete}
+var emptyPromptLabel = "What would you like to do"
+
+const requireContextSelectLabel = "Select a context"
+
+// General Endpoints
+func generalPrompt(cmd *cobra.Command, args []string, step string) error {
+	var err error
+
+	switch step {
+	case promptStepCreate:
+		if name == "" {
+			if name, err = common.RequireFreeInput("<name>", "Context Name", "", common.MandatoryValidation); err != nil {
+				return err
+			}
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return createContextRun(cmd, args)
+	case promptStepDelete:
+		if name == "" {
+			if name, err = common.RequireSelectInput("<name>", common.ListContexts(), requireContextSelectLabel); err != nil {
+				return err
+			}
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return deleteContextRun(cmd, args)
+	case promptStepList:
+		return listContextsRun(cmd, args)
+	case promptStepUse:
+		if name == "" {
+			if name, err = common.RequireSelectInput("<name>", common.ListContexts(), requireContextSelectLabel); err != nil {
+				return err
+			}
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return useContextRun(cmd, args)
+	case "":
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package context
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/spf13/cobra"
+)
+
+var name string
+
+var contextUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Switch to a context",
+	Long:  `Make the named context active for all subsequent commands`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  useContext,
+}
+
+func useContext(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		name = args[0]
+	}
+	return generalPrompt(cmd, args, promptStepUse)
+}
+
+func useContextRun(cmd *cobra.Command, args []string) error {
+	err := common.UseContext(name)
+	if err != nil {
+		return fmt.Errorf("failed to switch context; %w", err)
+	}
+	log.Printf("switched to context: %s", name)
+	return nil
+}
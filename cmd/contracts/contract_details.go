@@ -2,8 +2,6 @@ package contracts
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/nchain"
@@ -15,23 +13,29 @@ var contractsDetailsCmd = &cobra.Command{
 	Use:   "details",
 	Short: "Retrieve a specific smart contract",
 	Long:  `Retrieve details for a specific smart contract by identifier, scoped to the authorized API token`,
-	Run:   fetchContractDetails,
+	RunE:  fetchContractDetails,
 }
 
-func fetchContractDetails(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func fetchContractDetails(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{}
 	contract, err := provide.GetContractDetails(token, common.ContractID, params)
 	if err != nil {
-		log.Printf("Failed to retrieve details for contract with id: %s; %s", common.ContractID, err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to retrieve details for contract with id: %s", common.ContractID)
 	}
 	// if status != 200 {
 	// 	log.Printf("Failed to retrieve details for contract with id: %s; %s", common.ContractID, resp)
 	// 	os.Exit(1)
 	// }
-	result := fmt.Sprintf("%s\t%s\n", contract.ID.String(), *contract.Name)
-	fmt.Print(result)
+	table := common.NewTable("ID", "NAME")
+	table.AddRow(contract.ID.String(), common.StringValue(contract.Name))
+	if err := common.Render(contract, table); err != nil {
+		return fmt.Errorf("Failed to render contract details; %w", err)
+	}
+	return nil
 }
 
 func init() {
@@ -1,8 +1,6 @@
 package contracts
 
 import (
-	"os"
-
 	"github.com/provideservices/provide-cli/cmd/common"
 	"github.com/spf13/cobra"
 )
@@ -17,16 +15,12 @@ var ContractsCmd = &cobra.Command{
 	Use:   "contracts",
 	Short: "Manage smart contracts",
 	Long:  `Compile and deploy smart contracts locally from source or execute previously-deployed contracts`,
-	Run: func(cmd *cobra.Command, args []string) {
-		common.CmdExistsOrExit(cmd, args)
-
-		generalPrompt(cmd, args, "")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
 
-		defer func() {
-			if r := recover(); r != nil {
-				os.Exit(1)
-			}
-		}()
+		return generalPrompt(cmd, args, "")
 	},
 }
 
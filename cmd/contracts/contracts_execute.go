@@ -2,8 +2,6 @@ package contracts
 
 import (
 	"fmt"
-	"log"
-	"os"
 	"strings"
 
 	"github.com/provideservices/provide-cli/cmd/common"
@@ -21,15 +19,17 @@ var contractsExecuteCmd = &cobra.Command{
 	Use:   "execute --contract 0x5E250bB077ec836915155229E83d187715266167 --method vote --argv [] --value 0 --wallet 0x8A70B0C7E9896ac7025279a2Da240aEBD17A0cA3",
 	Short: "Execute a smart contract",
 	Long:  `Execute a smart contract method on a specific specific contract`,
-	Run:   executeContract,
+	RunE:  executeContract,
 }
 
-func executeContract(cmd *cobra.Command, args []string) {
+func executeContract(cmd *cobra.Command, args []string) error {
 	if common.AccountID == "" && common.WalletID == "" {
-		fmt.Println("Cannot execute a contract without a specified signer.")
-		os.Exit(1)
+		return common.NewValidationError("cannot execute a contract without a specified signer")
+	}
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
 	}
-	token := common.RequireAPIToken()
 	params := map[string]interface{}{
 		"method": contractExecMethod,
 		"params": contractExecParams,
@@ -47,11 +47,14 @@ func executeContract(cmd *cobra.Command, args []string) {
 	}
 	resp, err := provide.ExecuteContract(token, common.ContractID, params)
 	if err != nil {
-		log.Printf("Failed to execute contract with id: %s; %s", common.ContractID, err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to execute contract with id: %s", common.ContractID)
 	}
 
-	fmt.Printf("Successfully executed tx for asynchronous contract execution; tx ref: %s", *resp.Reference)
+	table := common.NewTable("REFERENCE")
+	table.AddRow(common.StringValue(resp.Reference))
+	if err := common.Render(resp, table); err != nil {
+		return fmt.Errorf("Failed to render contract execution; %w", err)
+	}
 
 	// if status == 200 {
 	// 	execution := resp.(map[string]interface{})
@@ -64,6 +67,7 @@ func executeContract(cmd *cobra.Command, args []string) {
 	// } else if status >= 400 {
 	// 	fmt.Printf("Failed to execute contract; %d response: %s", status, resp)
 	// }
+	return nil
 }
 
 func init() {
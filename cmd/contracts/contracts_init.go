@@ -2,8 +2,6 @@ package contracts
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/nchain"
@@ -18,7 +16,7 @@ var contractsInitCmd = &cobra.Command{
 	Use:   "init --name 'Registry' --network 024ff1ef-7369-4dee-969c-1918c6edb5d4",
 	Short: "Initialize a new smart contract",
 	Long:  `Initialize a new smart contract on behalf of a specific application; this operation may result in the contract being deployed`,
-	Run:   createContract,
+	RunE:  createContract,
 }
 
 func compiledArtifactFactory() map[string]interface{} {
@@ -51,12 +49,14 @@ func contractParamsFactory() map[string]interface{} {
 	return params
 }
 
-func createContract(cmd *cobra.Command, args []string) {
+func createContract(cmd *cobra.Command, args []string) error {
 	if common.WalletID == "" {
-		fmt.Println("Cannot create a contract without a specified signer.")
-		os.Exit(1)
+		return common.NewValidationError("cannot create a contract without a specified signer")
+	}
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
 	}
-	token := common.RequireAPIToken()
 	params := map[string]interface{}{
 		"name":           contractName,
 		"network_id":     common.NetworkID,
@@ -66,12 +66,15 @@ func createContract(cmd *cobra.Command, args []string) {
 	}
 	contract, err := provide.CreateContract(token, params)
 	if err != nil {
-		log.Printf("Failed to initialize application; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to initialize application")
 	}
 	common.ContractID = contract.ID.String()
-	result := fmt.Sprintf("%s\t%s\n", contract.ID.String(), *contract.Name)
-	fmt.Print(result)
+	table := common.NewTable("ID", "NAME")
+	table.AddRow(contract.ID.String(), common.StringValue(contract.Name))
+	if err := common.Render(contract, table); err != nil {
+		return fmt.Errorf("Failed to render contract; %w", err)
+	}
+	return nil
 }
 
 func init() {
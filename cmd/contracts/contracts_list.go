@@ -2,8 +2,6 @@ package contracts
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/nchain"
@@ -15,26 +13,32 @@ var contractsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Retrieve a list of contracts",
 	Long:  `Retrieve a list of contracts scoped to the authorized API token`,
-	Run:   listContracts,
+	RunE:  listContracts,
 }
 
-func listContracts(cmd *cobra.Command, args []string) {
+func listContracts(cmd *cobra.Command, args []string) error {
 
-	token := common.RequireAPIToken()
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{}
 	if common.ApplicationID != "" {
 		params["application_id"] = common.ApplicationID
 	}
 	contracts, err := provide.ListContracts(token, params)
 	if err != nil {
-		log.Printf("Failed to retrieve contracts list; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to retrieve contracts list")
 	}
+	table := common.NewTable("ID", "ADDRESS", "NAME")
 	for i := range contracts {
 		contract := contracts[i]
-		result := fmt.Sprintf("%s\t%s\t%s\n", contract.ID.String(), *contract.Address, *contract.Name)
-		fmt.Print(result)
+		table.AddRow(contract.ID.String(), common.StringValue(contract.Address), common.StringValue(contract.Name))
+	}
+	if err := common.Render(contracts, table); err != nil {
+		return fmt.Errorf("Failed to render contracts list; %w", err)
 	}
+	return nil
 }
 
 func init() {
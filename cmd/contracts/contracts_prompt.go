@@ -14,36 +14,60 @@ var emptyPromptArgs = []string{promptStepExecute, promptStepList}
 var emptyPromptLabel = "What would you like to do"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, currentStep string) {
+func generalPrompt(cmd *cobra.Command, args []string, currentStep string) error {
+	var err error
+
 	switch step := currentStep; step {
 	case promptStepExecute:
 		if contractExecMethod == "" {
-			contractExecMethod = common.FreeInput("Method", "", common.MandatoryValidation)
+			if contractExecMethod, err = common.RequireFreeInput("--method", "Method", "", common.MandatoryValidation); err != nil {
+				return err
+			}
 		}
 		if common.ContractID == "" {
-			common.ContractID = common.FreeInput("Contract ID", "", common.MandatoryValidation)
+			if common.ContractID, err = common.RequireFreeInput("--contract", "Contract ID", "", common.MandatoryValidation); err != nil {
+				return err
+			}
 		}
 		if optional {
 			if common.AccountID == "" {
-				common.RequireAccount(map[string]interface{}{})
+				if err := common.RequireAccount(map[string]interface{}{}); err != nil {
+					return err
+				}
 			}
 			if common.WalletID == "" {
-				common.RequireWallet()
+				if err := common.RequireWallet(); err != nil {
+					return err
+				}
 			}
 			if contractExecValue == 0 {
-				result := common.FreeInput("Value", "0", common.NumberValidation)
+				result, err := common.FreeInput("Value", "0", common.NumberValidation)
+				if err != nil {
+					return err
+				}
 				contractExecValue, _ = strconv.ParseUint(result, 10, 64)
 
 			}
 		}
-		executeContract(cmd, args)
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return executeContract(cmd, args)
 	case promptStepList:
 		if optional {
-			common.RequireApplication()
+			if err := common.RequireApplication(); err != nil {
+				return err
+			}
 		}
 	case "":
-		listContracts(cmd, args)
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		if err := listContracts(cmd, args); err != nil {
+			return err
+		}
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
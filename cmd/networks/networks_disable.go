@@ -2,8 +2,6 @@ package networks
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/nchain"
@@ -15,23 +13,26 @@ var networksDisableCmd = &cobra.Command{
 	Use:   "disable",
 	Short: "Disable a specific network",
 	Long:  `Disable a specific network by identifier`,
-	Run:   disableNetwork,
+	RunE:  disableNetwork,
 }
 
-func disableNetwork(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
-	err := provide.UpdateNetwork(token, common.NetworkID, map[string]interface{}{
+func disableNetwork(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
+	err = provide.UpdateNetwork(token, common.NetworkID, map[string]interface{}{
 		"enabled": false,
 	})
 	if err != nil {
-		log.Printf("Failed to disable network with id: %s; %s", common.NetworkID, err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to disable network with id: %s", common.NetworkID)
 	}
 	// if status != 204 {
 	// 	log.Printf("Failed to disable network with id: %s; received status: %d", common.NetworkID, status)
 	// 	os.Exit(1)
 	// }
 	fmt.Printf("Disabled network with id: %s", common.NetworkID)
+	return nil
 }
 
 func init() {
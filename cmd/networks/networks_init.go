@@ -3,8 +3,6 @@ package networks
 import (
 	"encoding/json"
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/nchain"
@@ -22,25 +20,35 @@ var networksInitCmd = &cobra.Command{
 	Use:   "init --name 'whiteblock testnet",
 	Short: "Initialize a new network",
 	Long:  `Initialize a new network with options`,
-	Run:   CreateNetwork,
+	RunE:  CreateNetwork,
 }
 
 // CreateNetwork configures a new peer-to-peer network;
 // see https://docs.provide.services/microservices/goldmine/#create-a-network
-func CreateNetwork(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func CreateNetwork(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
+	cfg, err := configFactory()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{
 		"name":   networkName,
-		"config": configFactory(),
+		"config": cfg,
 	}
 	network, err := provide.CreateNetwork(token, params)
 	if err != nil {
-		log.Printf("Failed to initialize network; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to initialize network")
 	}
 	common.NetworkID = network.ID.String()
-	result := fmt.Sprintf("%s\t%s\n", network.ID.String(), *network.Name)
-	fmt.Print(result)
+	table := common.NewTable("ID", "NAME")
+	table.AddRow(network.ID.String(), common.StringValue(network.Name))
+	if err := common.Render(network, table); err != nil {
+		return fmt.Errorf("Failed to render network; %w", err)
+	}
+	return nil
 }
 
 func init() {
@@ -63,13 +71,12 @@ func init() {
 	// networksInitCmd.MarkFlagRequired("protocol")
 }
 
-func configFactory() map[string]interface{} {
+func configFactory() (map[string]interface{}, error) {
 	var chainspec map[string]interface{}
 	if common.EngineID == "clique" {
 		chainspec = cliqueChainspecFactory()
 	} else {
-		log.Printf("Failed to initialize network; additional chainspec factories should be implemented")
-		os.Exit(1)
+		return nil, common.NewValidationError("Failed to initialize network; additional chainspec factories should be implemented")
 	}
 
 	return map[string]interface{}{
@@ -79,7 +86,7 @@ func configFactory() map[string]interface{} {
 		"native_currency": nativeCurrency,
 		"platform":        platform,
 		"protocol_id":     protocolID,
-	}
+	}, nil
 }
 
 func cliqueChainspecFactory() map[string]interface{} {
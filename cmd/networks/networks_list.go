@@ -2,8 +2,6 @@ package networks
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/nchain"
@@ -17,25 +15,31 @@ var networksListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Retrieve a list of networks",
 	Long:  `Retrieve a list of networks scoped to the authorized API token`,
-	Run:   listNetworks,
+	RunE:  listNetworks,
 }
 
-func listNetworks(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func listNetworks(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{}
 	if public {
 		params["public"] = "true"
 	}
 	networks, err := provide.ListNetworks(token, params)
 	if err != nil {
-		log.Printf("Failed to retrieve networks list; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to retrieve networks list")
 	}
+	table := common.NewTable("ID", "NAME")
 	for i := range networks {
 		network := networks[i]
-		result := fmt.Sprintf("%s\t%s\n", network.ID.String(), *network.Name)
-		fmt.Print(result)
+		table.AddRow(network.ID.String(), common.StringValue(network.Name))
+	}
+	if err := common.Render(networks, table); err != nil {
+		return fmt.Errorf("Failed to render networks list; %w", err)
 	}
+	return nil
 }
 
 func init() {
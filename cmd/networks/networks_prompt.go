@@ -16,37 +16,61 @@ var publicPromptArgs = []string{"Yes", "No"}
 var publicPromptLabel = "Would you like the network to be public"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, currentStep string) {
+func generalPrompt(cmd *cobra.Command, args []string, currentStep string) error {
+	var err error
+
 	switch step := currentStep; step {
 	case promptStepInit:
 		// Validation non-null
 		if chain == "" {
-			chain = common.FreeInput("Chain", "", common.NoValidation)
+			if chain, err = common.FreeInput("Chain", "", common.NoValidation); err != nil {
+				return err
+			}
 		}
 		if nativeCurrency == "" {
-			nativeCurrency = common.FreeInput("Native Currency", "", common.NoValidation)
+			if nativeCurrency, err = common.FreeInput("Native Currency", "", common.NoValidation); err != nil {
+				return err
+			}
 		}
 		if platform == "" {
-			platform = common.FreeInput("Platform", "", common.NoValidation)
+			if platform, err = common.FreeInput("Platform", "", common.NoValidation); err != nil {
+				return err
+			}
 		}
 		if protocolID == "" {
-			protocolID = common.FreeInput("Protocol ID", "", common.NoValidation)
+			if protocolID, err = common.FreeInput("Protocol ID", "", common.NoValidation); err != nil {
+				return err
+			}
 		}
 		if networkName == "" {
-			networkName = common.FreeInput("Network Name", "", common.NoValidation)
+			if networkName, err = common.FreeInput("Network Name", "", common.NoValidation); err != nil {
+				return err
+			}
 		}
-		CreateNetwork(cmd, args)
+		return CreateNetwork(cmd, args)
 	case promptStepList:
 		if optional {
-			result := common.SelectInput(publicPromptArgs, publicPromptLabel)
+			result, err := common.SelectInput(publicPromptArgs, publicPromptLabel)
+			if err != nil {
+				return err
+			}
 			public = result == "Yes"
 		}
-		listNetworks(cmd, args)
+		return listNetworks(cmd, args)
 	case promptStepDisable:
-		common.RequireNetwork()
-		disableNetwork(cmd, args)
+		if err := common.RequireNetwork(); err != nil {
+			return err
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return disableNetwork(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
@@ -11,14 +11,14 @@ var nodesDeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete a specific node",
 	Long:  `Delete a specific node by identifier and teardown any associated infrastructure`,
-	Run:   deleteNode,
+	RunE:  deleteNode,
 }
 
-func deleteNode(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepDelete)
+func deleteNode(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepDelete)
 }
 
-func deleteNodeRun(cmd *cobra.Command, args []string) {
+func deleteNodeRun(cmd *cobra.Command, args []string) error {
 	// FIXME!!!
 
 	// token := common.RequireAPIToken()
@@ -32,6 +32,7 @@ func deleteNodeRun(cmd *cobra.Command, args []string) {
 	// 	os.Exit(1)
 	// }
 	// fmt.Printf("Deleted node with id: %s", common.NodeID)
+	return nil
 }
 
 func init() {
@@ -1,8 +1,6 @@
 package nodes
 
 import (
-	"log"
-	"os"
 	"strconv"
 	"strings"
 
@@ -20,17 +18,17 @@ var nodesInitCmd = &cobra.Command{
 	Use:   "init --network 024ff1ef-7369-4dee-969c-1918c6edb5d4 --image redis --provider docker --region us-east-1 --role redis --target aws",
 	Short: "Initialize a new node",
 	Long:  `Initialize a new node with options`,
-	Run:   CreateNode,
+	RunE:  CreateNode,
 }
 
-func CreateNode(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepInit)
+func CreateNode(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepInit)
 }
 func nodeEnvConfigFactory() map[string]interface{} {
 	return map[string]interface{}{}
 }
 
-func nodeSecurityConfigFactory() map[string]interface{} {
+func nodeSecurityConfigFactory() (map[string]interface{}, error) {
 	tcpIngress := make([]uint, 0)
 	udpIngress := make([]uint, 0)
 
@@ -38,8 +36,7 @@ func nodeSecurityConfigFactory() map[string]interface{} {
 		if port != "" {
 			portInt, err := strconv.Atoi(port)
 			if err != nil {
-				log.Printf("Invalid tcp ingress port: %s", port)
-				os.Exit(1)
+				return nil, common.NewValidationError("Invalid tcp ingress port: %s", port)
 			}
 			tcpIngress = append(tcpIngress, uint(portInt))
 		}
@@ -49,8 +46,7 @@ func nodeSecurityConfigFactory() map[string]interface{} {
 		if port != "" {
 			portInt, err := strconv.Atoi(port)
 			if err != nil {
-				log.Printf("Invalid udp ingress port: %s", port)
-				os.Exit(1)
+				return nil, common.NewValidationError("Invalid udp ingress port: %s", port)
 			}
 			udpIngress = append(udpIngress, uint(portInt))
 		}
@@ -76,12 +72,17 @@ func nodeSecurityConfigFactory() map[string]interface{} {
 		cfg["health_check"] = healthCheck
 	}
 
-	return cfg
+	return cfg, nil
 }
 
-func nodeConfigFactory() map[string]interface{} {
+func nodeConfigFactory() (map[string]interface{}, error) {
+	creds, err := common.InfrastructureCredentialsConfigFactory()
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := map[string]interface{}{
-		"credentials": common.InfrastructureCredentialsConfigFactory(),
+		"credentials": creds,
 		"entrypoint":  nil,
 		"env":         nodeEnvConfigFactory(),
 		"image":       common.Image,
@@ -99,17 +100,20 @@ func nodeConfigFactory() map[string]interface{} {
 	// 	cfg["resources"] = resources
 	// }
 
-	securityCfg := nodeSecurityConfigFactory()
+	securityCfg, err := nodeSecurityConfigFactory()
+	if err != nil {
+		return nil, err
+	}
 	if securityCfg != nil {
 		cfg["security"] = securityCfg
 	}
 
-	return cfg
+	return cfg, nil
 }
 
 // CreateNode deploys a node to an existing peer-to-peer network;
 // see https://docs.provide.services/microservices/goldmine/#deploy-network-node
-func CreateNodeRun(cmd *cobra.Command, args []string) {
+func CreateNodeRun(cmd *cobra.Command, args []string) error {
 	// FIXME
 	// token := common.RequireAPIToken()
 	// params := map[string]interface{}{
@@ -123,6 +127,7 @@ func CreateNodeRun(cmd *cobra.Command, args []string) {
 	// common.NodeID = node.ID.String().(string)
 	// result := fmt.Sprintf("%s\t%s\n", node.ID.String(), *node.Name)
 	// fmt.Print(result)
+	return nil
 }
 
 func init() {
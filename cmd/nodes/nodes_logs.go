@@ -14,14 +14,14 @@ var nodesLogsCmd = &cobra.Command{
 	Use:   "logs",
 	Short: "Retrieve logs for a node",
 	Long:  `Retrieve paginated log output for a specific node by identifier`,
-	Run:   nodeLogs,
+	RunE:  nodeLogs,
 }
 
-func nodeLogs(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepLogs)
+func nodeLogs(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepLogs)
 }
 
-func nodeLogsRun(cmd *cobra.Command, args []string) {
+func nodeLogsRun(cmd *cobra.Command, args []string) error {
 	// FIXME
 	// token := common.RequireAPIToken()
 	// resp, err := provide.GetNetworkNodeLogs(token, common.NetworkID, common.NodeID, map[string]interface{}{
@@ -45,6 +45,7 @@ func nodeLogsRun(cmd *cobra.Command, args []string) {
 	// if nextToken, nextTokenOk := logsResponse["next_token"].(string); nextTokenOk {
 	// 	fmt.Printf("next token: %s", nextToken)
 	// }
+	return nil
 }
 
 func init() {
@@ -16,63 +16,106 @@ var emptyPromptArgs = []string{promptStepInit, promptStepLogs, promptStepDelete}
 var emptyPromptLabel = "What would you like to do"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, currentStep string) {
+func generalPrompt(cmd *cobra.Command, args []string, currentStep string) error {
+	var err error
+
 	switch step := currentStep; step {
 	case promptStepInit:
 		if common.NetworkID == "" {
-			common.RequirePublicNetwork()
+			if err := common.RequirePublicNetwork(); err != nil {
+				return err
+			}
 		}
 		if common.Image == "" {
-			common.Image = common.FreeInput("Image", "", common.MandatoryValidation)
+			if common.Image, err = common.RequireFreeInput("--image", "Image", "", common.MandatoryValidation); err != nil {
+				return err
+			}
 		}
 		if role == "" {
-			role = common.FreeInput("Role", "", common.MandatoryValidation)
+			if role, err = common.RequireFreeInput("--role", "Role", "", common.MandatoryValidation); err != nil {
+				return err
+			}
 		}
 		if optional {
 			fmt.Println("Optional Flags:")
 			if common.HealthCheckPath == "" {
-				common.HealthCheckPath = common.FreeInput("Health Check Path", "", common.NoValidation)
+				if common.HealthCheckPath, err = common.FreeInput("Health Check Path", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if common.TCPIngressPorts == "" {
-				common.TCPIngressPorts = common.FreeInput("TCP Ingress Ports", "", common.NoValidation)
+				if common.TCPIngressPorts, err = common.FreeInput("TCP Ingress Ports", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if common.UDPIngressPorts == "" {
-				common.UDPIngressPorts = common.FreeInput("UDP Ingress Ports", "", common.NoValidation)
+				if common.UDPIngressPorts, err = common.FreeInput("UDP Ingress Ports", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if common.TaskRole == "" {
-				common.TaskRole = common.FreeInput("Task Role", "", common.NoValidation)
+				if common.TaskRole, err = common.FreeInput("Task Role", "", common.NoValidation); err != nil {
+					return err
+				}
 
 			}
 		}
-		CreateNodeRun(cmd, args)
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return CreateNodeRun(cmd, args)
 	case promptStepDelete:
 		if common.NetworkID == "" {
-			common.RequirePublicNetwork()
+			if err := common.RequirePublicNetwork(); err != nil {
+				return err
+			}
 		}
 		if common.NodeID == "" {
-			common.NodeID = common.FreeInput("Node ID", "", common.MandatoryValidation)
+			if common.NodeID, err = common.RequireFreeInput("--node", "Node ID", "", common.MandatoryValidation); err != nil {
+				return err
+			}
 		}
-		deleteNodeRun(cmd, args)
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return deleteNodeRun(cmd, args)
 	case promptStepLogs:
 		if common.NetworkID == "" {
-			common.RequirePublicNetwork()
+			if err := common.RequirePublicNetwork(); err != nil {
+				return err
+			}
 		}
 		if common.NodeID == "" {
-			common.NodeID = common.FreeInput("Node ID", "", common.MandatoryValidation)
+			if common.NodeID, err = common.RequireFreeInput("--node", "Node ID", "", common.MandatoryValidation); err != nil {
+				return err
+			}
 		}
 		// Validation Number
 		if page == 1 {
-			result := common.FreeInput("Page", "1", common.MandatoryNumberValidation)
+			result, err := common.FreeInput("Page", "1", common.MandatoryNumberValidation)
+			if err != nil {
+				return err
+			}
 			page, _ = strconv.ParseUint(result, 10, 64)
 		}
 		// Validation Number
 		if rpp == 100 {
-			result := common.FreeInput("RPP", "100", common.MandatoryValidation)
+			result, err := common.FreeInput("RPP", "100", common.MandatoryValidation)
+			if err != nil {
+				return err
+			}
 			rpp, _ = strconv.ParseUint(result, 10, 64)
 		}
-		nodeLogsRun(cmd, args)
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return nodeLogsRun(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
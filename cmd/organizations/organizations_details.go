@@ -2,8 +2,6 @@ package organizations
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/ident"
@@ -15,30 +13,36 @@ var organizationsDetailsCmd = &cobra.Command{
 	Use:   "details",
 	Short: "Retrieve a specific organization",
 	Long:  `Retrieve details for a specific organization by identifier, scoped to the authorized API token`,
-	Run:   fetchOrganizationDetails,
+	RunE:  fetchOrganizationDetails,
 }
 
-func fetchOrganizationDetails(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepDetails)
+func fetchOrganizationDetails(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepDetails)
 }
 
-func fetchOrganizationDetailsRun(cmd *cobra.Command, args []string) {
+func fetchOrganizationDetailsRun(cmd *cobra.Command, args []string) error {
 	if common.OrganizationID == "" {
-		generalPrompt(cmd, args, "Details")
+		return generalPrompt(cmd, args, "Details")
+	}
+	token, err := common.RequireUserAccessToken()
+	if err != nil {
+		return err
 	}
-	token := common.RequireUserAccessToken()
 	params := map[string]interface{}{}
 	organization, err := provide.GetOrganizationDetails(token, common.OrganizationID, params)
 	if err != nil {
-		log.Printf("Failed to retrieve details for organization with id: %s; %s", common.OrganizationID, err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to retrieve details for organization with id: %s", common.OrganizationID)
 	}
 	// if status != 200 {
 	// 	log.Printf("Failed to retrieve details for organization with id: %s; %s", common.OrganizationID, organization)
 	// 	os.Exit(1)
 	// }
-	result := fmt.Sprintf("%s\t%s\n", organization.ID, *organization.Name)
-	fmt.Print(result)
+	table := common.NewTable("ID", "NAME")
+	table.AddRow(organization.ID.String(), common.StringValue(organization.Name))
+	if err := common.Render(organization, table); err != nil {
+		return fmt.Errorf("Failed to render organization details; %w", err)
+	}
+	return nil
 }
 
 func init() {
@@ -1,8 +1,7 @@
 package organizations
 
 import (
-	"log"
-	"os"
+	"fmt"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/ident"
@@ -16,7 +15,7 @@ var organizationsInitCmd = &cobra.Command{
 	Use:   "init --name 'Acme Inc.'",
 	Short: "Initialize a new organization",
 	Long:  `Initialize a new organization`,
-	Run:   createOrganization,
+	RunE:  createOrganization,
 }
 
 func organizationConfigFactory() map[string]interface{} {
@@ -26,24 +25,31 @@ func organizationConfigFactory() map[string]interface{} {
 
 	return cfg
 }
-func createOrganization(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepInit)
+func createOrganization(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepInit)
 }
 
-func createOrganizationRun(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func createOrganizationRun(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{
 		"name":   organizationName,
 		"config": organizationConfigFactory(),
 	}
 	organization, err := provide.CreateOrganization(token, params)
 	if err != nil {
-		log.Printf("Failed to initialize organization; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to initialize organization")
 	}
 
 	common.OrganizationID = organization.ID.String()
-	log.Printf("initialized organization: %s\t%s\n", organizationName, common.OrganizationID)
+	table := common.NewTable("ID", "NAME")
+	table.AddRow(organization.ID.String(), common.StringValue(organization.Name))
+	if err := common.Render(organization, table); err != nil {
+		return fmt.Errorf("Failed to render organization; %w", err)
+	}
+	return nil
 }
 
 func init() {
@@ -2,8 +2,6 @@ package organizations
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/ident"
@@ -15,28 +13,34 @@ var organizationsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Retrieve a list of organizations",
 	Long:  `Retrieve a list of organizations scoped to the authorized API token`,
-	Run:   listOrganizations,
+	RunE:  listOrganizations,
 }
 
-func listOrganizations(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepList)
+func listOrganizations(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepList)
 }
 
-func listOrganizationsRun(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func listOrganizationsRun(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{}
 	organizations, err := provide.ListOrganizations(token, params)
 	if err != nil {
-		log.Printf("Failed to retrieve organizations list; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to retrieve organizations list")
 	}
+	table := common.NewTable("ID", "NAME", "ADDRESS")
 	for i := range organizations {
 		organization := organizations[i]
 		address := "0x"
 		if addr, addrOk := organization.Metadata["address"].(string); addrOk {
 			address = addr
 		}
-		result := fmt.Sprintf("%s\t%s\t%s\n", organization.ID.String(), *organization.Name, address)
-		fmt.Print(result)
+		table.AddRow(organization.ID.String(), common.StringValue(organization.Name), address)
+	}
+	if err := common.Render(organizations, table); err != nil {
+		return fmt.Errorf("Failed to render organizations list; %w", err)
 	}
+	return nil
 }
@@ -13,18 +13,36 @@ var emptyPromptArgs = []string{promptStepInit, promptStepList, promptStepDetails
 var emptyPromptLabel = "What would you like to do"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, step string) {
+func generalPrompt(cmd *cobra.Command, args []string, step string) error {
+	var err error
+
 	switch step {
 	case promptStepInit:
-		organizationName = common.FreeInput("Organization Name", "", common.MandatoryValidation)
-		createOrganizationRun(cmd, args)
+		if organizationName == "" {
+			if organizationName, err = common.RequireFreeInput("--name", "Organization Name", "", common.MandatoryValidation); err != nil {
+				return err
+			}
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return createOrganizationRun(cmd, args)
 	case promptStepList:
-		listOrganizationsRun(cmd, args)
+		return listOrganizationsRun(cmd, args)
 	case promptStepDetails:
-		common.RequireOrganization()
-		fetchOrganizationDetailsRun(cmd, args)
+		if err := common.RequireOrganization(); err != nil {
+			return err
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return fetchOrganizationDetailsRun(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
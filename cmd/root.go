@@ -9,9 +9,12 @@ import (
 	"github.com/provideservices/provide-cli/cmd/accounts"
 	"github.com/provideservices/provide-cli/cmd/api_tokens"
 	"github.com/provideservices/provide-cli/cmd/applications"
+	"github.com/provideservices/provide-cli/cmd/auth"
 	"github.com/provideservices/provide-cli/cmd/baseline"
 	"github.com/provideservices/provide-cli/cmd/common"
+	"github.com/provideservices/provide-cli/cmd/config"
 	"github.com/provideservices/provide-cli/cmd/connectors"
+	"github.com/provideservices/provide-cli/cmd/context"
 	"github.com/provideservices/provide-cli/cmd/contracts"
 	"github.com/provideservices/provide-cli/cmd/networks"
 	"github.com/provideservices/provide-cli/cmd/nodes"
@@ -30,28 +33,75 @@ var rootCmd = &cobra.Command{
 The Provide CLI exposes low-code tools to manage network, application and organization resources.
 
 Run with the --help flag to see available options`, common.ASCIIBanner),
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := requirePromptsUnlessNonInteractive(cmd, args); err != nil {
+			return err
+		}
+		return applyContext(cmd)
+	},
 }
 
-// Execute the default command path
+// applyContext configures the active context, unless the command manages contexts
+func applyContext(cmd *cobra.Command) error {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == context.ContextCmd {
+			return nil
+		}
+	}
+	return common.ApplyContext()
+}
+
+// requirePromptsUnlessNonInteractive rejects invocations which can only be completed by prompting
+// while prompts are disabled; i.e., a command menu or the --optional flag prompts
+func requirePromptsUnlessNonInteractive(cmd *cobra.Command, args []string) error {
+	if common.IsInteractive() {
+		return nil
+	}
+	for _, name := range []string{"optional", "Optional"} {
+		if optional := cmd.Flags().Lookup(name); optional != nil && optional.Changed {
+			return common.NewValidationError("--%s prompts for optional flags and cannot be used when running non-interactively", name)
+		}
+	}
+	if cmd.HasAvailableSubCommands() && len(args) == 0 {
+		return common.NewValidationError("%s requires a subcommand when running non-interactively; run '%s --help' for usage", cmd.CommandPath(), cmd.CommandPath())
+	}
+	return nil
+}
+
+// Execute the default command path; errors are reported on stderr and mapped to the
+// process exit code corresponding to their class
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(common.ExitCode(err))
 	}
 }
 
 func init() {
 	cobra.OnInitialize(common.InitConfig)
 
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return common.NewValidationError("%s; run '%s --help' for usage", err.Error(), cmd.CommandPath())
+	})
+
 	rootCmd.PersistentFlags().BoolVarP(&common.Verbose, "verbose", "v", false, "enable verbose output")
 	rootCmd.PersistentFlags().StringVarP(&common.CfgFile, "config", "c", "", "config file (default is $HOME/.provide-cli.yaml)")
+	rootCmd.PersistentFlags().StringVar(&common.ContextName, "context", "", "name of the context to use for this command; defaults to the active context")
+	rootCmd.PersistentFlags().BoolVar(&common.NonInteractive, "non-interactive", false, "never prompt for input; fail listing any missing required flags instead (default when stdin is not a terminal)")
+	rootCmd.PersistentFlags().StringVarP(&common.Output, "output", "o", common.OutputFormatTable, "output format; one of table, json, yaml or template")
+	rootCmd.PersistentFlags().StringVar(&common.OutputTemplate, "template", "", "Go text/template used to render results when --output is template; fields use their JSON names")
 
 	rootCmd.AddCommand(accounts.AccountsCmd)
 	rootCmd.AddCommand(api_tokens.APITokensCmd)
 	rootCmd.AddCommand(applications.ApplicationsCmd)
+	rootCmd.AddCommand(auth.AuthCmd)
 	rootCmd.AddCommand(users.AuthenticateCmd)
 	rootCmd.AddCommand(baseline.BaselineCmd)
+	rootCmd.AddCommand(config.ConfigCmd)
 	rootCmd.AddCommand(connectors.ConnectorsCmd)
+	rootCmd.AddCommand(context.ContextCmd)
 	rootCmd.AddCommand(contracts.ContractsCmd)
 	rootCmd.AddCommand(networks.NetworksCmd)
 	rootCmd.AddCommand(nodes.NodesCmd)
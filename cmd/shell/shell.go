@@ -3,6 +3,7 @@ package shell
 import (
 	"bytes"
 	"fmt"
+	"log"
 	"os"
 	"os/exec"
 	"strings"
@@ -76,6 +77,11 @@ Run with the --help flag to see available options`, common.ASCIIBanner),
 }
 
 func shell(cmd *cobra.Command, args []string) {
+	if !common.IsInteractive() {
+		log.Printf("the interactive shell requires a terminal; run prvd commands directly when running non-interactively")
+		return
+	}
+
 	mutex = &sync.Mutex{}
 	wg = &sync.WaitGroup{}
 
@@ -2,13 +2,11 @@ package users
 
 import (
 	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/ident"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // authenticateCmd represents the authenticate command
@@ -17,29 +15,50 @@ var AuthenticateCmd = &cobra.Command{
 	Short: "Authenticate using your credentials",
 	Long: `Authenticate using user credentials and receive a
 valid access/refresh token pair which can be used to make API calls.`,
-	Run: authenticate,
+	RunE: authenticate,
 }
 
-func authenticate(cmd *cobra.Command, args []string) {
-	email = common.FreeInput("Email", "", common.MandatoryValidation)
-	passwd = common.FreeInput("Password", "", common.MandatoryValidation)
+func authenticate(cmd *cobra.Command, args []string) error {
+	var err error
+
+	if email == "" {
+		if email, err = common.RequireFreeInput("--email", "Email", "", common.MandatoryValidation); err != nil {
+			return err
+		}
+	}
+	if passwd == "" {
+		if passwd, err = common.RequireFreeInput("--password", "Password", "", common.MandatoryValidation); err != nil {
+			return err
+		}
+	}
+	if err := common.MissingInput(); err != nil {
+		return err
+	}
 
 	resp, err := provide.Authenticate(email, passwd)
 	if err != nil {
-		log.Println(err)
-		os.Exit(1)
+		return common.NewAPIError(err, "failed to authenticate")
 	}
 
 	if resp.Token.AccessToken != nil && resp.Token.RefreshToken != nil {
-		common.CacheAccessRefreshToken(resp.Token)
+		if err := common.CacheAccessRefreshToken(resp.Token); err != nil {
+			return err
+		}
 	} else if resp.Token.Token != nil {
-		cacheAPIToken(*resp.Token.Token)
+		if err := cacheAPIToken(*resp.Token.Token); err != nil {
+			return err
+		}
 	}
 
 	log.Printf("Authentication successful")
+	return nil
+}
+
+func cacheAPIToken(token string) error {
+	return common.CacheCredential(common.ContextConfigKey(common.AccessTokenConfigKey), token)
 }
 
-func cacheAPIToken(token string) {
-	viper.Set(common.AccessTokenConfigKey, token)
-	viper.WriteConfig()
+func init() {
+	AuthenticateCmd.Flags().StringVar(&email, "email", "", "email address of the user")
+	AuthenticateCmd.Flags().StringVar(&passwd, "password", "", "password of the user; prompted for when omitted")
 }
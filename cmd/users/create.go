@@ -2,8 +2,6 @@ package users
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/ident"
@@ -16,7 +14,7 @@ var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new user",
 	Long:  `Create a new user in the configured ident instance; defaults to ident.provide.services.`,
-	Run:   create,
+	RunE:  create,
 }
 
 var firstName string
@@ -24,11 +22,32 @@ var lastName string
 var email string
 var passwd string
 
-func create(cmd *cobra.Command, args []string) {
-	firstName = common.FreeInput("First Name", "", common.MandatoryValidation)
-	lastName = common.FreeInput("Last Name", "", common.MandatoryValidation)
-	email = common.FreeInput("Email", "", common.MandatoryValidation)
-	passwd = common.FreeInput("Password", "", common.MandatoryValidation)
+func create(cmd *cobra.Command, args []string) error {
+	var err error
+
+	if firstName == "" {
+		if firstName, err = common.RequireFreeInput("--first-name", "First Name", "", common.MandatoryValidation); err != nil {
+			return err
+		}
+	}
+	if lastName == "" {
+		if lastName, err = common.RequireFreeInput("--last-name", "Last Name", "", common.MandatoryValidation); err != nil {
+			return err
+		}
+	}
+	if email == "" {
+		if email, err = common.RequireFreeInput("--email", "Email", "", common.MandatoryValidation); err != nil {
+			return err
+		}
+	}
+	if passwd == "" {
+		if passwd, err = common.RequireFreeInput("--password", "Password", "", common.MandatoryValidation); err != nil {
+			return err
+		}
+	}
+	if err := common.MissingInput(); err != nil {
+		return err
+	}
 
 	resp, err := provide.CreateUser("", map[string]interface{}{
 		"email":      email,
@@ -37,15 +56,25 @@ func create(cmd *cobra.Command, args []string) {
 		"last_name":  lastName,
 	})
 	if err != nil {
-		log.Println(err)
-		os.Exit(1)
+		return common.NewAPIError(err, "failed to create user")
 	}
 
 	_, err = provide.Authenticate(email, passwd)
 	if err != nil {
-		log.Println(err)
-		os.Exit(1)
+		return common.NewAPIError(err, "failed to authenticate user")
+	}
+
+	table := common.NewTable("ID", "EMAIL")
+	table.AddRow(resp.ID.String(), resp.Email)
+	if err := common.Render(resp, table); err != nil {
+		return fmt.Errorf("failed to render user; %w", err)
 	}
+	return nil
+}
 
-	fmt.Printf("created user: %s", resp.ID.String())
+func init() {
+	createCmd.Flags().StringVar(&firstName, "first-name", "", "first name of the user")
+	createCmd.Flags().StringVar(&lastName, "last-name", "", "last name of the user")
+	createCmd.Flags().StringVar(&email, "email", "", "email address of the user")
+	createCmd.Flags().StringVar(&passwd, "password", "", "password of the user; prompted for when omitted")
 }
@@ -12,14 +12,18 @@ var emptyPromptArgs = []string{promptStepCreate, promptStepAuthenticate}
 var emptyPromptLabel = "What would you like to do"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, currentStep string) {
+func generalPrompt(cmd *cobra.Command, args []string, currentStep string) error {
 	switch step := currentStep; step {
 	case promptStepAuthenticate:
-		authenticate(cmd, args)
+		return authenticate(cmd, args)
 	case promptStepCreate:
-		create(cmd, args)
+		return create(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
@@ -1,8 +1,6 @@
 package keys
 
 import (
-	"os"
-
 	"github.com/spf13/cobra"
 )
 
@@ -14,14 +12,8 @@ var KeysCmd = &cobra.Command{
 Supports symmetric and asymmetric key specs with encrypt/decrypt and sign/verify operations.
 
 Docs: https://docs.provide.services/vault/api-reference/keys`,
-	Run: func(cmd *cobra.Command, args []string) {
-		generalPrompt(cmd, args, "")
-
-		defer func() {
-			if r := recover(); r != nil {
-				os.Exit(1)
-			}
-		}()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return generalPrompt(cmd, args, "")
 	},
 }
 
@@ -2,8 +2,6 @@ package keys
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	vault "github.com/provideservices/provide-go/api/vault"
@@ -21,15 +19,18 @@ var keysInitCmd = &cobra.Command{
 	Use:   "init --name 'My Key' --description 'not your keys, not your crypto'",
 	Short: "Create a new key",
 	Long:  `Initialize a new key`,
-	Run:   createKey,
+	RunE:  createKey,
 }
 
-func createKey(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepInit)
+func createKey(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepInit)
 }
 
-func createKeyRun(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func createKeyRun(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{
 		"name":        name,
 		"description": description,
@@ -39,11 +40,14 @@ func createKeyRun(cmd *cobra.Command, args []string) {
 	}
 	vlt, err := vault.CreateKey(token, common.VaultID, params)
 	if err != nil {
-		log.Printf("failed to create key in vault: %s; %s", common.VaultID, err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "failed to create key in vault: %s", common.VaultID)
+	}
+	table := common.NewTable("ID", "NAME", "DESCRIPTION")
+	table.AddRow(vlt.ID.String(), common.StringValue(vlt.Name), common.StringValue(vlt.Description))
+	if err := common.Render(vlt, table); err != nil {
+		return fmt.Errorf("failed to render key; %w", err)
 	}
-	result := fmt.Sprintf("%s\t%s\t%s\n", vlt.ID.String(), *vlt.Name, *vlt.Description)
-	fmt.Print(result)
+	return nil
 }
 
 func init() {
@@ -53,6 +57,7 @@ func init() {
 	keysInitCmd.Flags().StringVar(&keytype, "type", "", "key type; must be symmetric or asymmetric")
 	keysInitCmd.Flags().StringVar(&keyusage, "usage", "", "intended usage for the key; must be encrypt/decrypt or sign/verify")
 
+	keysInitCmd.Flags().StringVar(&common.VaultID, "vault", "", "identifier of the vault in which the key will be created")
 	keysInitCmd.Flags().StringVar(&common.ApplicationID, "application", "", "application identifier for which the key will be created")
 	keysInitCmd.Flags().StringVar(&common.OrganizationID, "organization", "", "organization identifier for which the key will be created")
 }
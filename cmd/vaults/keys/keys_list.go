@@ -2,8 +2,6 @@ package keys
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	vault "github.com/provideservices/provide-go/api/vault"
@@ -15,15 +13,18 @@ var keysListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Retrieve a list of keys",
 	Long:  `Retrieve a list of keys scoped to the authorized API token`,
-	Run:   listKeys,
+	RunE:  listKeys,
 }
 
-func listKeys(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepList)
+func listKeys(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepList)
 }
 
-func listKeysRun(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func listKeysRun(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{}
 	if common.ApplicationID != "" {
 		params["application_id"] = common.ApplicationID
@@ -33,14 +34,17 @@ func listKeysRun(cmd *cobra.Command, args []string) {
 	}
 	resp, err := vault.ListKeys(token, common.VaultID, params)
 	if err != nil {
-		log.Printf("failed to retrieve keys list; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "failed to retrieve keys list")
 	}
+	table := common.NewTable("ID", "NAME", "DESCRIPTION")
 	for i := range resp {
 		vlt := resp[i]
-		result := fmt.Sprintf("%s\t%s\t%s\n", vlt.ID.String(), *vlt.Name, *vlt.Description)
-		fmt.Print(result)
+		table.AddRow(vlt.ID.String(), common.StringValue(vlt.Name), common.StringValue(vlt.Description))
+	}
+	if err := common.Render(resp, table); err != nil {
+		return fmt.Errorf("failed to render keys list; %w", err)
 	}
+	return nil
 }
 
 func init() {
@@ -2,7 +2,6 @@ package keys
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/manifoldco/promptui"
 	"github.com/provideservices/provide-cli/cmd/common"
@@ -18,25 +17,38 @@ const promptStepInit = "Initialize"
 const promptStepList = "List"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, currentStep string) {
+func generalPrompt(cmd *cobra.Command, args []string, currentStep string) error {
 	if common.VaultID == "" {
-		common.RequireVault()
+		if err := common.RequireVault(); err != nil {
+			return err
+		}
 	}
 
 	switch step := currentStep; step {
 	case promptStepInit:
-		promptInit(cmd, args)
-		createKeyRun(cmd, args)
+		if err := promptInit(cmd, args); err != nil {
+			return err
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return createKeyRun(cmd, args)
 	case promptStepList:
-		promptList(cmd, args)
-		listKeysRun(cmd, args)
+		if err := promptList(cmd, args); err != nil {
+			return err
+		}
+		if err := common.MissingInput(); err != nil {
+			return err
+		}
+		return listKeysRun(cmd, args)
 	case "":
-		emptyPrompt(cmd, args)
+		return emptyPrompt(cmd, args)
 	}
 
+	return nil
 }
 
-func emptyPrompt(cmd *cobra.Command, args []string) {
+func emptyPrompt(cmd *cobra.Command, args []string) error {
 	prompt := promptui.Select{
 		Label: "What would you like to do",
 		Items: []string{promptStepInit, promptStepList},
@@ -45,16 +57,15 @@ func emptyPrompt(cmd *cobra.Command, args []string) {
 	_, result, err := prompt.Run()
 
 	if err != nil {
-		os.Exit(1)
-		return
+		return err
 	}
 
 	promptArgs = append(promptArgs, result)
 
-	generalPrompt(cmd, args, result)
+	return generalPrompt(cmd, args, result)
 }
 
-func flagPrompt(cmd *cobra.Command, args []string) bool {
+func flagPrompt(cmd *cobra.Command, args []string) (bool, error) {
 	flagPrompt := promptui.Select{
 		Label: "Would you like to set Optional Flags?",
 		Items: []string{"No", "Yes"},
@@ -63,58 +74,92 @@ func flagPrompt(cmd *cobra.Command, args []string) bool {
 	_, flagResult, err := flagPrompt.Run()
 
 	if err != nil {
-		os.Exit(1)
-		return false
+		return false, err
 	}
 
-	return flagResult == "Yes"
+	return flagResult == "Yes", nil
 }
 
-func promptInit(cmd *cobra.Command, args []string) {
-	if common.ApplicationID == "" {
-		applicationIDFlagPrompt()
-	}
-	if common.OrganizationID == "" {
-		organizationidFlagPrompt()
+func promptInit(cmd *cobra.Command, args []string) error {
+	if common.IsInteractive() {
+		if common.ApplicationID == "" {
+			if err := applicationIDFlagPrompt(); err != nil {
+				return err
+			}
+		}
+		if common.OrganizationID == "" {
+			if err := organizationidFlagPrompt(); err != nil {
+				return err
+			}
+		}
 	}
 	if keytype == "" {
-		keyTypePrompt()
+		if err := keyTypePrompt(); err != nil {
+			return err
+		}
 	}
 	if keyusage == "" {
-		keyUsagePrompt()
+		if err := keyUsagePrompt(); err != nil {
+			return err
+		}
 	}
 	if name == "" {
-		nameFlagPrompt()
+		if err := nameFlagPrompt(); err != nil {
+			return err
+		}
 	}
 	if description == "" {
-		descriptionFlagPrompt()
+		if err := descriptionFlagPrompt(); err != nil {
+			return err
+		}
 	}
 	if keyspec == "" {
-		keySpecPrompt()
+		if err := keySpecPrompt(); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func promptList(cmd *cobra.Command, args []string) {
+func promptList(cmd *cobra.Command, args []string) error {
+	if !common.IsInteractive() {
+		// application and organization are optional filters
+		return nil
+	}
 	if common.ApplicationID == "" {
-		applicationIDFlagPrompt()
+		if err := applicationIDFlagPrompt(); err != nil {
+			return err
+		}
 	}
 	if common.OrganizationID == "" {
-		organizationidFlagPrompt()
+		if err := organizationidFlagPrompt(); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func optionalFlagsList(cmd *cobra.Command, args []string) {
+func optionalFlagsList(cmd *cobra.Command, args []string) error {
 	fmt.Println("Optional Flags:")
 	if common.ApplicationID == "" {
-		applicationIDFlagPrompt()
+		if err := applicationIDFlagPrompt(); err != nil {
+			return err
+		}
 	}
 	if common.OrganizationID == "" {
-		applicationIDFlagPrompt()
+		if err := applicationIDFlagPrompt(); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 // Optional Flags For Init Key
-func nameFlagPrompt() {
+func nameFlagPrompt() error {
+	if !common.IsInteractive() {
+		return nil
+	}
+
 	validate := func(input string) error {
 		return nil
 	}
@@ -127,14 +172,18 @@ func nameFlagPrompt() {
 	result, err := prompt.Run()
 
 	if err != nil {
-		os.Exit(1)
-		return
+		return err
 	}
 
 	name = result
+	return nil
 }
 
-func descriptionFlagPrompt() {
+func descriptionFlagPrompt() error {
+	if !common.IsInteractive() {
+		return nil
+	}
+
 	validate := func(input string) error {
 		return nil
 	}
@@ -147,74 +196,63 @@ func descriptionFlagPrompt() {
 	result, err := prompt.Run()
 
 	if err != nil {
-		os.Exit(1)
-		return
+		return err
 	}
 
 	description = result
+	return nil
 }
 
-func keySpecPrompt() {
+func keySpecPrompt() error {
+	specs := []string{
+		vault.KeySpecAES256GCM,
+		vault.KeySpecECCBabyJubJub,
+		vault.KeySpecChaCha20,
+		vault.KeySpecECCC25519,
+		vault.KeySpecECCBIP39,
+		vault.KeySpecECCEd25519,
+		vault.KeySpecECCSecp256k1,
+		vault.KeySpecRSA2048,
+		vault.KeySpecRSA3072,
+		vault.KeySpecRSA4096,
+	}
+	if !common.IsInteractive() {
+		_, err := common.RequireSelectInput("--spec", specs, "Spec")
+		return err
+	}
+
 	prompt := promptui.Select{
 		Label: "Spec",
-		Items: []string{
-			vault.KeySpecAES256GCM,
-			vault.KeySpecECCBabyJubJub,
-			vault.KeySpecChaCha20,
-			vault.KeySpecECCC25519,
-			vault.KeySpecECCBIP39,
-			vault.KeySpecECCEd25519,
-			vault.KeySpecECCSecp256k1,
-			vault.KeySpecRSA2048,
-			vault.KeySpecRSA3072,
-			vault.KeySpecRSA4096,
-		},
+		Items: specs,
 	}
 
 	shell.MarshalPromptIO(&prompt)
 	_, result, err := prompt.Run()
 	if err != nil {
-		return
+		return err
 	}
 
 	keyspec = result
+	return nil
 }
 
-func keyTypePrompt() {
-	prompt := promptui.Select{
-		Label: "Type",
-		Items: []string{"symmetric", "asymmetric"},
-	}
-
-	_, result, err := prompt.Run()
-	if err != nil {
-		os.Exit(1)
-		return
-	}
-
-	keytype = result
+func keyTypePrompt() error {
+	var err error
+	keytype, err = common.RequireSelectInput("--type", []string{"symmetric", "asymmetric"}, "Type")
+	return err
 }
 
-func keyUsagePrompt() {
-	prompt := promptui.Select{
-		Label: "Usage",
-		Items: []string{"encrypt/decrypt", "sign/verify"},
-	}
-
-	_, result, err := prompt.Run()
-	if err != nil {
-		os.Exit(1)
-		return
-	}
-
-	keyusage = result
+func keyUsagePrompt() error {
+	var err error
+	keyusage, err = common.RequireSelectInput("--usage", []string{"encrypt/decrypt", "sign/verify"}, "Usage")
+	return err
 }
 
 // Optional Flag For List Keys
-func applicationIDFlagPrompt() {
-	common.RequireApplication()
+func applicationIDFlagPrompt() error {
+	return common.RequireApplication()
 }
 
-func organizationidFlagPrompt() {
-	common.RequireOrganization()
+func organizationidFlagPrompt() error {
+	return common.RequireOrganization()
 }
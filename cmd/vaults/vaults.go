@@ -1,8 +1,6 @@
 package vaults
 
 import (
-	"os"
-
 	"github.com/spf13/cobra"
 
 	"github.com/provideservices/provide-cli/cmd/common"
@@ -17,16 +15,12 @@ var VaultsCmd = &cobra.Command{
 Vaults support select symmetric and asymmetric key specs for encrypt/decrypt and sign/verify operations.
 
 Docs: https://docs.provide.services/vault`,
-	Run: func(cmd *cobra.Command, args []string) {
-		common.CmdExistsOrExit(cmd, args)
-
-		generalPrompt(cmd, args, "")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := common.CmdExistsOrError(cmd, args); err != nil {
+			return err
+		}
 
-		defer func() {
-			if r := recover(); r != nil {
-				os.Exit(1)
-			}
-		}()
+		return generalPrompt(cmd, args, "")
 	},
 }
 
@@ -2,8 +2,6 @@ package vaults
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/vault"
@@ -19,27 +17,33 @@ var vaultsInitCmd = &cobra.Command{
 	Use:   "init --name 'My Vault' --description 'not your keys, not your crypto'",
 	Short: "Create a new vault",
 	Long:  `Initialize a new vault`,
-	Run:   createVault,
+	RunE:  createVault,
 }
 
-func createVault(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepInit)
+func createVault(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepInit)
 }
 
-func createVaultRun(cmd *cobra.Command, args []string) {
+func createVaultRun(cmd *cobra.Command, args []string) error {
 
-	token := common.RequireAPIToken()
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{
 		"name":        name,
 		"description": description,
 	}
 	vlt, err := provide.CreateVault(token, params)
 	if err != nil {
-		log.Printf("Failed to genereate HD wallet; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to genereate HD wallet")
+	}
+	table := common.NewTable("ID", "NAME", "DESCRIPTION")
+	table.AddRow(vlt.ID.String(), common.StringValue(vlt.Name), common.StringValue(vlt.Description))
+	if err := common.Render(vlt, table); err != nil {
+		return fmt.Errorf("failed to render vault; %w", err)
 	}
-	result := fmt.Sprintf("%s\t%s\t%s\n", vlt.ID.String(), *vlt.Name, *vlt.Description)
-	fmt.Print(result)
+	return nil
 }
 
 func init() {
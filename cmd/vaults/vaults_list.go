@@ -2,8 +2,6 @@ package vaults
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/vault"
@@ -15,15 +13,18 @@ var vaultsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Retrieve a list of vaults",
 	Long:  `Retrieve a list of vaults scoped to the authorized API token`,
-	Run:   listVaults,
+	RunE:  listVaults,
 }
 
-func listVaults(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepList)
+func listVaults(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepList)
 }
 
-func listVaultsRun(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func listVaultsRun(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{}
 	if common.ApplicationID != "" {
 		params["application_id"] = common.ApplicationID
@@ -33,14 +34,17 @@ func listVaultsRun(cmd *cobra.Command, args []string) {
 	}
 	resp, err := provide.ListVaults(token, params)
 	if err != nil {
-		log.Printf("failed to retrieve vaults list; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "failed to retrieve vaults list")
 	}
+	table := common.NewTable("ID", "NAME", "DESCRIPTION")
 	for i := range resp {
 		vlt := resp[i]
-		result := fmt.Sprintf("%s\t%s\t%s\n", vlt.ID.String(), *vlt.Name, *vlt.Description)
-		fmt.Print(result)
+		table.AddRow(vlt.ID.String(), common.StringValue(vlt.Name), common.StringValue(vlt.Description))
+	}
+	if err := common.Render(resp, table); err != nil {
+		return fmt.Errorf("failed to render vaults list; %w", err)
 	}
+	return nil
 }
 
 func init() {
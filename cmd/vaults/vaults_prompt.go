@@ -14,38 +14,56 @@ var emptyPromptArgs = []string{promptStepInit, promptStepList}
 var emptyPromptLabel = "What would you like to do"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, currentStep string) {
+func generalPrompt(cmd *cobra.Command, args []string, currentStep string) error {
+	var err error
+
 	switch step := currentStep; step {
 	case promptStepInit:
 		if optional {
 			fmt.Println("Optional Flags:")
 			if description == "" {
-				description = common.FreeInput("Vault Description", "", common.NoValidation)
+				if description, err = common.FreeInput("Vault Description", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if name == "" {
-				name = common.FreeInput("Vault Name", "", common.NoValidation)
+				if name, err = common.FreeInput("Vault Name", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if common.ApplicationID == "" {
-				common.RequireApplication()
+				if err := common.RequireApplication(); err != nil {
+					return err
+				}
 			}
 			if common.OrganizationID == "" {
-				common.RequireOrganization()
+				if err := common.RequireOrganization(); err != nil {
+					return err
+				}
 			}
 		}
-		createVaultRun(cmd, args)
+		return createVaultRun(cmd, args)
 	case promptStepList:
 		if optional {
 			fmt.Println("Optional Flags:")
 			if common.ApplicationID == "" {
-				common.RequireApplication()
+				if err := common.RequireApplication(); err != nil {
+					return err
+				}
 			}
 			if common.OrganizationID == "" {
-				common.RequireOrganization()
+				if err := common.RequireOrganization(); err != nil {
+					return err
+				}
 			}
 		}
-		listVaultsRun(cmd, args)
+		return listVaultsRun(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
@@ -2,9 +2,8 @@ package wallets
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/nchain"
@@ -23,50 +22,58 @@ var walletsInitCmd = &cobra.Command{
 	Use:   "init [--non-custodial|-nc]",
 	Short: "Generate a new HD wallet for deterministically managing accounts, signing transactions and storing value",
 	Long:  `Initialize a new HD wallet, which may be managed by Provide or you`,
-	Run:   CreateWallet,
+	RunE:  CreateWallet,
 }
 
-func CreateWallet(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepInit)
+func CreateWallet(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepInit)
 }
 
-func CreateWalletRun(cmd *cobra.Command, args []string) {
+func CreateWalletRun(cmd *cobra.Command, args []string) error {
 	if nonCustodial {
-		createDecentralizedWallet()
-		return
+		return createDecentralizedWallet()
 	}
-	createManagedWallet(cmd, args)
+	return createManagedWallet(cmd, args)
 }
 
-func createDecentralizedWallet() {
+func createDecentralizedWallet() error {
 	publicKey, privateKey, err := providecrypto.EVMGenerateKeyPair()
 	if err != nil {
-		log.Printf("Failed to genereate decentralized HD wallet; %s", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("Failed to genereate decentralized HD wallet; %w", err)
 	}
 	secret := hex.EncodeToString(providecrypto.FromECDSA(privateKey))
 	walletJSON, err := providecrypto.EVMMarshalEncryptedKey(providecrypto.HexToAddress(*publicKey), privateKey, secret)
 	if err != nil {
-		log.Printf("Failed to genereate decentralized HD wallet; %s", err.Error())
-		os.Exit(1)
+		return fmt.Errorf("Failed to genereate decentralized HD wallet; %w", err)
 	}
-	result := fmt.Sprintf("%s\t%s\n", *publicKey, string(walletJSON))
-	fmt.Print(result)
+	table := common.NewTable("PUBLIC KEY", "KEYSTORE")
+	table.AddRow(*publicKey, string(walletJSON))
+	err = common.Render(map[string]interface{}{
+		"public_key": *publicKey,
+		"keystore":   json.RawMessage(walletJSON),
+	}, table)
+	if err != nil {
+		return fmt.Errorf("Failed to render decentralized HD wallet; %w", err)
+	}
+	return nil
 }
 
-func createManagedWallet(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func createManagedWallet(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{
 		"purpose": purpose,
 	}
 
 	wallet, err := provide.CreateWallet(token, params)
 	if err != nil {
-		log.Printf("Failed to genereate HD wallet; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to genereate HD wallet")
 	}
 	common.WalletID = wallet.ID.String()
-	result := fmt.Sprintf("Wallet %s\t%s\n", wallet.ID.String(), *wallet.PublicKey)
+	table := common.NewTable("ID", "PUBLIC KEY")
+	table.AddRow(wallet.ID.String(), common.StringValue(wallet.PublicKey))
 
 	if common.ApplicationID != "" {
 		appWalletKey := common.BuildConfigKeyWithApp(common.WalletConfigKeyPartial, common.ApplicationID)
@@ -82,7 +89,10 @@ func createManagedWallet(cmd *cobra.Command, args []string) {
 		}
 	}
 
-	fmt.Print(result)
+	if err := common.Render(wallet, table); err != nil {
+		return fmt.Errorf("Failed to render HD wallet; %w", err)
+	}
+	return nil
 }
 
 func init() {
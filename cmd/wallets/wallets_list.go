@@ -2,8 +2,6 @@ package wallets
 
 import (
 	"fmt"
-	"log"
-	"os"
 
 	"github.com/provideservices/provide-cli/cmd/common"
 	provide "github.com/provideservices/provide-go/api/nchain"
@@ -15,30 +13,36 @@ var walletsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Retrieve a list of custodial HD wallets",
 	Long:  `Retrieve a list of HD wallets scoped to the authorized API token`,
-	Run:   listWallets,
+	RunE:  listWallets,
 }
 
-func listWallets(cmd *cobra.Command, args []string) {
-	generalPrompt(cmd, args, promptStepList)
+func listWallets(cmd *cobra.Command, args []string) error {
+	return generalPrompt(cmd, args, promptStepList)
 }
 
-func listWalletsRun(cmd *cobra.Command, args []string) {
-	token := common.RequireAPIToken()
+func listWalletsRun(cmd *cobra.Command, args []string) error {
+	token, err := common.RequireAPIToken()
+	if err != nil {
+		return err
+	}
 	params := map[string]interface{}{}
 	if common.ApplicationID != "" {
 		params["application_id"] = common.ApplicationID
 	}
 	resp, err := provide.ListWallets(token, params)
 	if err != nil {
-		log.Printf("Failed to retrieve wallets list; %s", err.Error())
-		os.Exit(1)
+		return common.NewAPIError(err, "Failed to retrieve wallets list")
 	}
+	table := common.NewTable("ID", "PUBLIC KEY")
 	for i := range resp {
 		wallet := resp[i]
-		result := fmt.Sprintf("%s\t%s\n", wallet.ID.String(), *wallet.PublicKey)
-		// FIXME-- when wallet.Name exists... result = fmt.Sprintf("Wallet %s\t%s - %s\n", wallet.Name, wallet.ID.String(), *wallet.Address)
-		fmt.Print(result)
+		table.AddRow(wallet.ID.String(), common.StringValue(wallet.PublicKey))
+		// FIXME-- when wallet.Name exists... add a NAME column
+	}
+	if err := common.Render(resp, table); err != nil {
+		return fmt.Errorf("Failed to render wallets list; %w", err)
 	}
+	return nil
 }
 
 func init() {
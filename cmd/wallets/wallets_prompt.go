@@ -23,35 +23,55 @@ var emptyPromptArgs = []string{promptStepInit, promptStepList}
 var emptyPromptLabel = "What would you like to do"
 
 // General Endpoints
-func generalPrompt(cmd *cobra.Command, args []string, step string) {
+func generalPrompt(cmd *cobra.Command, args []string, step string) error {
+	var err error
+
 	switch step {
 	case promptStepInit:
-		common.SelectInput(walletTypePromptArgs, walletTypeLabel)
-		generalPrompt(cmd, args, promptStepCustody)
+		if _, err := common.SelectInput(walletTypePromptArgs, walletTypeLabel); err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, promptStepCustody)
 	case promptStepCustody:
 		if optional {
 			fmt.Println("Optional Flags:")
 			if !nonCustodial {
-				nonCustodial = common.SelectInput(custodyPromptArgs, custodyPromptLabel) == "Yes"
+				result, err := common.SelectInput(custodyPromptArgs, custodyPromptLabel)
+				if err != nil {
+					return err
+				}
+				nonCustodial = result == "Yes"
 			}
 			if walletName == "" {
-				walletName = common.FreeInput("Wallet Name", "", common.NoValidation)
+				if walletName, err = common.FreeInput("Wallet Name", "", common.NoValidation); err != nil {
+					return err
+				}
 			}
 			if purpose == 44 {
-				purpose, _ = strconv.Atoi(common.FreeInput("Wallet Purpose", "44", common.NumberValidation))
+				result, err := common.FreeInput("Wallet Purpose", "44", common.NumberValidation)
+				if err != nil {
+					return err
+				}
+				purpose, _ = strconv.Atoi(result)
 			}
 		}
-		CreateWalletRun(cmd, args)
+		return CreateWalletRun(cmd, args)
 	case promptStepList:
 		if optional {
 			fmt.Println("Optional Flags:")
 			if common.ApplicationID == "" {
-				common.RequireApplication()
+				if err := common.RequireApplication(); err != nil {
+					return err
+				}
 			}
 		}
-		listWalletsRun(cmd, args)
+		return listWalletsRun(cmd, args)
 	case "":
-		result := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
-		generalPrompt(cmd, args, result)
+		result, err := common.SelectInput(emptyPromptArgs, emptyPromptLabel)
+		if err != nil {
+			return err
+		}
+		return generalPrompt(cmd, args, result)
 	}
+	return nil
 }
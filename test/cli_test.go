@@ -0,0 +1,497 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/provideservices/provide-cli/test/mockapi"
+)
+
+// fixtures shared by the golden tests; each builds on the previous
+var signup = []step{
+	{args: []string{"users", "create", "--first-name", "Jane", "--last-name", "Doe", "--email", "jane@example.com", "--password", "secret"}},
+}
+
+var login = with(signup, step{
+	args: []string{"authenticate", "--email", "jane@example.com", "--password", "secret"},
+})
+
+var withOrganization = with(login, step{
+	args: []string{"organizations", "init", "--name", "Acme"},
+	save: "org",
+})
+
+var withApplication = with(withOrganization, step{
+	args: []string{"applications", "init", "--name", "Widgets", "--network", "{{network}}", "--without-account", "--without-wallet"},
+	save: "app",
+})
+
+var withVault = with(withOrganization, step{
+	args: []string{"vaults", "init", "--name", "Keystore", "--description", "signing keys", "--organization", "{{org}}"},
+	save: "vault",
+})
+
+var withWorkgroup = with(withOrganization, step{
+	args: []string{"baseline", "workgroups", "init", "--name", "Supply Chain", "--network", "{{network}}", "--organization", "{{org}}"},
+}, step{
+	args: []string{"baseline", "workgroups", "list"},
+	save: "workgroup",
+})
+
+// with returns the given fixture extended with the given steps
+func with(fixture []step, steps ...step) []step {
+	return append(append([]step{}, fixture...), steps...)
+}
+
+// cliTestCase runs prvd with the given args, after running each setup step, and
+// compares the result with testdata/<name>.golden
+type cliTestCase struct {
+	name  string
+	setup []step
+	args  []string
+	env   []string
+
+	// prepare runs after the setup steps; i.e., to seed the mock API or to save ids
+	// which are not rendered by the setup steps
+	prepare func(c *cli)
+}
+
+// the docker-backed baseline stack commands and the interactive shell are not covered;
+// infrastructure credentials are read from a terminal, so connectors and nodes are
+// only initialized up to the credentials prompt
+var cliTestCases = []cliTestCase{
+	// users and authentication
+	{name: "users_create", args: []string{"users", "create", "--first-name", "Jane", "--last-name", "Doe", "--email", "jane@example.com", "--password", "secret"}},
+	{name: "users_create_missing_flags", args: []string{"users", "create", "--email", "jane@example.com"}},
+	{name: "authenticate", setup: signup, args: []string{"authenticate", "--email", "jane@example.com", "--password", "secret"}},
+	{name: "authenticate_invalid_credentials", setup: signup, args: []string{"authenticate", "--email", "jane@example.com", "--password", "wrong"}},
+	{name: "auth_status", setup: login, args: []string{"auth", "status"}},
+	{name: "unauthenticated", args: []string{"applications", "list"}},
+
+	// organizations
+	{name: "organizations_init", setup: login, args: []string{"organizations", "init", "--name", "Acme"}},
+	{name: "organizations_list", setup: withOrganization, args: []string{"organizations", "list"}},
+	{name: "organizations_details", setup: withOrganization, args: []string{"organizations", "details", "--organization", "{{org}}"}},
+
+	// applications
+	{name: "applications_init", setup: login, args: []string{"applications", "init", "--name", "Widgets", "--network", "{{network}}"}},
+	{name: "applications_list", setup: withApplication, args: []string{"applications", "list"}},
+	{name: "applications_details", setup: withApplication, args: []string{"applications", "details", "--application", "{{app}}"}},
+	{name: "applications_details_not_found", setup: login, args: []string{"applications", "details", "--application", "00000000-0000-4000-8000-999999999999"}},
+
+	// api tokens
+	{name: "api_tokens_init", setup: login, args: []string{"api_tokens", "init"}},
+	{name: "api_tokens_init_application", setup: withApplication, args: []string{"api_tokens", "init", "--application", "{{app}}"}},
+	{name: "api_tokens_init_organization", setup: withOrganization, args: []string{"api_tokens", "init", "--organization", "{{org}}", "--offline-access"}},
+	{name: "api_tokens_list", setup: with(withApplication, step{args: []string{"api_tokens", "init", "--application", "{{app}}"}}), args: []string{"api_tokens", "list"}},
+
+	// accounts and wallets
+	{name: "accounts_init", setup: withApplication, args: []string{"accounts", "init", "--name", "signer", "--network", "{{network}}", "--application", "{{app}}"}},
+	{name: "accounts_list", setup: with(withApplication, step{args: []string{"accounts", "init", "--network", "{{network}}", "--application", "{{app}}"}}), args: []string{"accounts", "list", "--application", "{{app}}"}},
+	{name: "wallets_init", setup: login, args: []string{"wallets", "init", "--purpose", "44"}},
+	{name: "wallets_list", setup: with(login, step{args: []string{"wallets", "init"}}), args: []string{"wallets", "list"}},
+
+	// vaults and keys
+	{name: "vaults_init", setup: withOrganization, args: []string{"vaults", "init", "--name", "Keystore", "--description", "signing keys", "--organization", "{{org}}"}},
+	{name: "vaults_list", setup: withVault, args: []string{"vaults", "list", "--organization", "{{org}}"}},
+	{name: "vaults_keys_init", setup: withVault, args: []string{"vaults", "keys", "init", "--vault", "{{vault}}", "--name", "signer", "--description", "signing key", "--type", "asymmetric", "--usage", "sign/verify", "--spec", "secp256k1"}},
+	{name: "vaults_keys_list", setup: with(withVault, step{args: []string{"vaults", "keys", "init", "--vault", "{{vault}}", "--name", "signer", "--description", "signing key", "--type", "asymmetric", "--usage", "sign/verify", "--spec", "secp256k1"}}), args: []string{"vaults", "keys", "list", "--vault", "{{vault}}"}},
+
+	// networks
+	{name: "networks_init", setup: login, args: []string{"networks", "init", "--name", "Testnet", "--chain", "testnet", "--engine", "poa", "--platform", "evm", "--protocol", "poa", "--native-currency", "TEST"}},
+	{name: "networks_list", setup: login, args: []string{"networks", "list"}},
+	{name: "networks_disable", setup: login, args: []string{"networks", "disable", "--network", "{{network}}"}},
+
+	// connectors
+	{name: "connectors_init", setup: withApplication, args: []string{"connectors", "init", "--name", "ipfs", "--type", "ipfs", "--application", "{{app}}", "--network", "{{network}}", "--provider", "docker", "--target", "aws"}},
+	{name: "connectors_list", setup: withApplication, args: []string{"connectors", "list", "--application", "{{app}}"}, prepare: seedConnector},
+	{name: "connectors_details", setup: withApplication, args: []string{"connectors", "details", "--connector", "{{connector}}"}, prepare: seedConnector},
+	{name: "connectors_delete", setup: withApplication, args: []string{"connectors", "delete", "--connector", "{{connector}}", "--application", "{{app}}"}, prepare: seedConnector},
+
+	// contracts
+	{name: "contracts_list", setup: withApplication, args: []string{"contracts", "list", "--application", "{{app}}"}, prepare: seedContract},
+	{name: "contracts_execute", setup: with(withApplication, step{args: []string{"wallets", "init"}, save: "wallet"}), args: []string{"contracts", "execute", "--contract", "{{contract}}", "--method", "vote", "--wallet", "{{wallet}}"}, prepare: seedContract},
+	{name: "contracts_execute_without_signer", setup: login, args: []string{"contracts", "execute", "--contract", "{{contract}}", "--method", "vote"}, prepare: seedContract},
+
+	// nodes
+	{name: "nodes_init", setup: login, args: []string{"nodes", "init", "--network", "{{network}}", "--role", "peer", "--image", "provide/nchain", "--provider", "docker", "--target", "aws"}},
+	{name: "nodes_logs", setup: login, args: []string{"nodes", "logs", "--network", "{{network}}", "--node", "00000000-0000-4000-8000-999999999999", "--page", "1", "--rpp", "100"}},
+	{name: "nodes_delete", setup: login, args: []string{"nodes", "delete", "--network", "{{network}}", "--node", "00000000-0000-4000-8000-999999999999"}},
+
+	// contexts and configuration
+	{name: "context_create", args: []string{"context", "create", "staging", "--ident-api-host", "ident.staging.example.com"}},
+	{name: "context_list", setup: []step{{args: []string{"context", "create", "staging"}}}, args: []string{"context", "list"}},
+	{name: "context_use", setup: []step{{args: []string{"context", "create", "staging"}}}, args: []string{"context", "use", "staging"}},
+	{name: "context_use_not_found", args: []string{"context", "use", "production"}},
+	{name: "context_delete", setup: []step{{args: []string{"context", "create", "staging"}}}, args: []string{"context", "delete", "staging"}},
+	{name: "context_delete_credentials", setup: with([]step{{args: []string{"context", "create", "staging"}}, {args: []string{"context", "use", "staging"}}}, login...), args: []string{"--context", "staging", "auth", "status"}, env: []string{"PROVIDE_CREDENTIALS_PASSPHRASE=secret"}, prepare: deleteContextCredentials},
+	{name: "config_migrate_credentials", setup: login, args: []string{"config", "migrate-credentials", "--store", "file"}, env: []string{"PROVIDE_CREDENTIALS_PASSPHRASE=secret"}},
+
+	// baseline stack definition files
+	{name: "baseline_stack_config_init", args: []string{"baseline", "stack", "config", "init"}},
+	{name: "baseline_stack_config_init_exists", setup: []step{{args: []string{"baseline", "stack", "config", "init"}}}, args: []string{"baseline", "stack", "config", "init"}},
+	{name: "baseline_stack_config_validate", setup: []step{{args: []string{"baseline", "stack", "config", "init"}}}, args: []string{"baseline", "stack", "config", "validate"}},
+	{name: "baseline_stack_config_validate_invalid", args: []string{"baseline", "stack", "config", "validate", "-f", "invalid-stack.yaml"}, prepare: writeInvalidStackDefinition},
+	{name: "baseline_stack_run_file_not_found", args: []string{"baseline", "stack", "run", "-f", "missing.yaml"}},
+	{name: "baseline_stack_run_invalid_startup_timeout", setup: withWorkgroup, args: []string{"baseline", "stack", "run", "--startup-timeout", "0s", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--sor", "ephemeral"}},
+	{name: "baseline_stack_run_invalid_mock_sor", setup: withWorkgroup, args: []string{"baseline", "stack", "run", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--mock-sor", "dynamics365"}},
+	{name: "baseline_stack_run_mock_sor_conflict", setup: withWorkgroup, args: []string{"baseline", "stack", "run", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--sor", "sap", "--mock-sor", "servicenow"}},
+	{name: "baseline_stack_run_unsupported_sor", setup: withWorkgroup, args: []string{"baseline", "stack", "run", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--sor", "oracle"}},
+	{name: "baseline_stack_run_sor_credentials_incomplete", setup: withWorkgroup, args: []string{"baseline", "stack", "run", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--sor", "snow", "--servicenow-api-username", "admin"}},
+	{name: "baseline_stack_sor_list", args: []string{"baseline", "stack", "sor", "list"}},
+	{name: "baseline_stack_export_compose", setup: withWorkgroup, args: []string{"baseline", "stack", "export", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--sor", "ephemeral", "--organization-refresh-token", "refresh-token", "--jwt-signer-public-key", "jwt-signer-public-key", "--nats-auth-token", "testtoken", "--nats-tls=false"}},
+	{name: "baseline_stack_export_k8s", setup: withWorkgroup, args: []string{"baseline", "stack", "export", "--format", "k8s", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--sor", "ephemeral", "--organization-refresh-token", "refresh-token", "--jwt-signer-public-key", "jwt-signer-public-key", "--nats-auth-token", "testtoken", "--nats-tls=false", "--with-local-vault"}},
+	{name: "baseline_stack_export_nats_tls_key_missing", setup: withWorkgroup, args: []string{"baseline", "stack", "export", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--sor", "ephemeral", "--nats-tls-cert", "server.pem"}},
+	{name: "baseline_stack_export_invalid_format", args: []string{"baseline", "stack", "export", "--format", "helm"}},
+	{name: "baseline_stack_restore_not_found", args: []string{"baseline", "stack", "restore", "--in", "missing.tar.gz"}},
+	{name: "baseline_stack_restore_invalid", args: []string{"baseline", "stack", "restore", "--in", "invalid.tar.gz"}, prepare: writeInvalidBackup},
+	{name: "baseline_stack_run_lockfile_not_found", args: []string{"baseline", "stack", "run", "--lockfile", "missing.lock"}},
+	{name: "baseline_stack_images_save_invalid_lockfile", args: []string{"baseline", "stack", "images", "save", "--lockfile", "invalid.lock"}, prepare: writeInvalidImageLock},
+	{name: "baseline_stack_images_load_not_found", args: []string{"baseline", "stack", "images", "load", "--in", "missing.tar"}},
+	{name: "baseline_stack_logs_invalid_service", args: []string{"baseline", "stack", "logs", "--service", "api,ledger"}},
+	{name: "baseline_stack_logs_invalid_since", args: []string{"baseline", "stack", "logs", "--since", "yesterday"}},
+	{name: "baseline_stack_doctor_nats_tls_key_missing", setup: withWorkgroup, args: []string{"baseline", "stack", "doctor", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--nats-tls-cert", "server.pem"}},
+	{name: "baseline_stack_simulate_invalid_parties", args: []string{"baseline", "stack", "simulate", "--parties", "1"}},
+
+	// baseline
+	{name: "baseline_workgroups_init", setup: withOrganization, args: []string{"baseline", "workgroups", "init", "--name", "Supply Chain", "--network", "{{network}}", "--organization", "{{org}}"}},
+	{name: "baseline_workgroups_list", setup: withWorkgroup, args: []string{"baseline", "workgroups", "list"}},
+	{name: "baseline_workgroups_join", setup: with(withWorkgroup, step{args: []string{"organizations", "init", "--name", "Globex"}, save: "counterparty"}), args: []string{"baseline", "workgroups", "join", "--organization", "{{counterparty}}", "--jwt", "{{invite}}"}, prepare: vendInvite},
+	{name: "baseline_workgroups_join_invalid_jwt", setup: withOrganization, args: []string{"baseline", "workgroups", "join", "--organization", "{{org}}", "--jwt", "invalid"}},
+	{name: "baseline_participants_invite", setup: withWorkgroup, args: []string{"baseline", "workgroups", "participants", "invite", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--name", "Globex", "--email", "ops@globex.example.com"}},
+	{name: "baseline_participants_list", setup: with(withWorkgroup, step{args: []string{"baseline", "workgroups", "participants", "invite", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--name", "Globex", "--email", "ops@globex.example.com"}}), args: []string{"baseline", "workgroups", "participants", "list", "--workgroup", "{{workgroup}}", "--organization", "{{org}}"}},
+	{name: "baseline_workflows_init", setup: withWorkgroup, args: []string{"baseline", "workflows", "init", "-f", "workflow.yaml", "--workgroup", "{{workgroup}}", "--organization", "{{org}}"}, prepare: writeWorkflowDefinition},
+	{name: "baseline_workflows_init_invalid", setup: withWorkgroup, args: []string{"baseline", "workflows", "init", "-f", "invalid-workflow.yaml", "--workgroup", "{{workgroup}}", "--organization", "{{org}}"}, prepare: writeInvalidWorkflowDefinition},
+	{name: "baseline_workflows_init_unresolved_participant", setup: withWorkgroup, args: []string{"baseline", "workflows", "init", "-f", "workflow.yaml", "--workgroup", "{{workgroup}}", "--organization", "{{org}}"}, prepare: writeWorkflowDefinitionFiles},
+	{name: "baseline_workflows_init_rollback", setup: withWorkgroup, args: []string{"baseline", "workflows", "init", "-f", "workflow.yaml", "--workgroup", "{{workgroup}}", "--organization", "{{org}}"}, prepare: writeRejectedWorkflowDefinition},
+	{name: "baseline_workflows_init_exists", setup: withWorkgroup, args: []string{"baseline", "workflows", "init", "-f", "workflow.yaml", "--workgroup", "{{workgroup}}", "--organization", "{{org}}"}, prepare: initWorkflow},
+	{name: "baseline_workflows_list", setup: withWorkgroup, args: []string{"baseline", "workflows", "list", "--workgroup", "{{workgroup}}", "--organization", "{{org}}"}, prepare: initWorkflow},
+	{name: "baseline_workflows_details", setup: withWorkgroup, args: []string{"baseline", "workflows", "details", "--workflow", "{{workflow}}", "--organization", "{{org}}"}, prepare: initWorkflow},
+	{name: "baseline_workflows_diff", setup: withWorkgroup, args: []string{"baseline", "workflows", "diff", "-f", "workflow.yaml", "--workgroup", "{{workgroup}}", "--organization", "{{org}}"}, prepare: initWorkflow},
+	{name: "baseline_workflows_diff_changed", setup: withWorkgroup, args: []string{"baseline", "workflows", "diff", "-f", "workflow-changed.yaml", "--workgroup", "{{workgroup}}", "--organization", "{{org}}"}, prepare: writeChangedWorkflowDefinition},
+	{name: "baseline_workflows_messages_list", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "list", "--workgroup", "{{workgroup}}", "--organization", "{{org}}"}, prepare: seedMessages},
+	{name: "baseline_workflows_messages_list_type_since", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "list", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--type", "purchase_order", "--since", "2021-06-01T00:00:00Z"}, prepare: seedMessages},
+	{name: "baseline_workflows_messages_list_counterparty_until", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "list", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--counterparty", "0x5e250bb077ec836915155229e83d187715266167", "--until", "2021-06-15T00:00:00Z"}, prepare: seedMessages},
+	{name: "baseline_workflows_messages_list_limit", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "list", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--limit", "2"}, prepare: seedMessages},
+	{name: "baseline_workflows_messages_list_cursor", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "list", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--limit", "2", "--cursor", "MjAyMS0wNi0wMVQwMDowMDowMFp8M2I5ZjNjMmUtNmY3YS00ZDhlLTljMWItMmE0ZTVmNmE3YjAy"}, prepare: seedMessages},
+	{name: "baseline_workflows_messages_list_invalid_since", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "list", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--since", "yesterday"}},
+	{name: "baseline_workflows_messages_send", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "send", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--id", "po-1", "--type", "purchase_order", "--data", `{"total":100}`}},
+	{name: "baseline_workflows_messages_send_recipients", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "send", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--id", "po-1", "--type", "purchase_order", "--data", `{"total":100}`, "--recipients", "{{org}}", "--mapping", "mapping.ndjson"}, prepare: writeWorkflowDefinition},
+	{name: "baseline_workflows_messages_list_recipients", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "list", "--workgroup", "{{workgroup}}", "--organization", "{{org}}"}, prepare: sendMessageToRecipient},
+	{name: "baseline_workflows_messages_send_recipient_not_found", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "send", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--id", "po-1", "--type", "purchase_order", "--data", `{"total":100}`, "--recipients", "5d3c6a9e-0c0a-4c33-a4b5-1f3b2a3e4d5c"}},
+	{name: "baseline_workflows_messages_send_without_baseline_id", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "send", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--id", mockapi.UnidentifiedObjectID, "--type", "purchase_order", "--data", `{"total":100}`}},
+	{name: "baseline_workflows_messages_send_file", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "send", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--workflow", "{{workflow}}", "--id", "po-1", "--type", "purchase_order", "--file", "po-1.json"}, prepare: writeMessagePayload},
+	{name: "baseline_workflows_messages_send_stdin_empty", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "send", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--id", "po-1", "--type", "purchase_order", "--stdin"}},
+	{name: "baseline_workflows_messages_send_invalid_payload", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "send", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--workflow", "{{workflow}}", "--id", "inv-1", "--type", "invoice", "--data", `{"po":1,"total":-5}`}, prepare: initWorkflow},
+	{name: "baseline_workflows_messages_send_batch", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "send", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--workflow", "{{workflow}}", "--batch", "records.ndjson", "--concurrency", "1", "--mapping", "mapping.ndjson"}, prepare: writeMessageBatch},
+	{name: "baseline_workflows_messages_send_batch_resume", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "send", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--workflow", "{{workflow}}", "--batch", "records.ndjson.resume", "--concurrency", "1"}, prepare: sendMessageBatch},
+	{name: "baseline_workflows_messages_send_batch_resumed", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "send", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--workflow", "{{workflow}}", "--batch", "records.ndjson.resume", "--concurrency", "1"}, prepare: fixMessageBatch},
+	{name: "baseline_workflows_messages_send_batch_resume_default_type", setup: withWorkgroup, args: []string{"baseline", "workflows", "messages", "send", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--workflow", "{{workflow}}", "--batch", "invoices.ndjson.resume", "--concurrency", "1"}, prepare: sendUntypedMessageBatch},
+}
+
+// seedContract seeds the mock with a deployed contract, saved as {{contract}}
+func seedContract(c *cli) {
+	c.values["contract"] = c.api.Seed("contracts", map[string]interface{}{
+		"name":       "Ballot",
+		"network_id": mockapi.PublicNetworkID,
+		"address":    "0x5E250bB077ec836915155229E83d187715266167",
+		"type":       "contract",
+	})
+}
+
+// seedConnector seeds the mock with a connector of the application created by
+// withApplication, saved as {{connector}}
+func seedConnector(c *cli) {
+	c.values["connector"] = c.api.Seed("connectors", map[string]interface{}{
+		"name":           "ipfs",
+		"type":           "ipfs",
+		"application_id": c.values["app"],
+		"network_id":     mockapi.PublicNetworkID,
+		"status":         "available",
+	})
+}
+
+// seedMessages seeds the mock with a verified, a pending and a rejected message baselined in
+// the workgroup created by withWorkgroup
+func seedMessages(c *cli) {
+	for _, msg := range []map[string]interface{}{
+		{
+			"baseline_id": "3b9f3c2e-6f7a-4d8e-9c1b-2a4e5f6a7b01",
+			"id":          "po-1",
+			"type":        "purchase_order",
+			"status":      "verified",
+			"commitment":  "0x9f2c4e1a7b3d5f60",
+			"recipients":  []interface{}{map[string]interface{}{"address": "0x5E250bB077ec836915155229E83d187715266167"}},
+			"created_at":  "2021-05-01T00:00:00Z",
+			"updated_at":  "2021-05-01T00:05:00Z",
+		},
+		{
+			"baseline_id": "3b9f3c2e-6f7a-4d8e-9c1b-2a4e5f6a7b02",
+			"id":          "inv-1",
+			"type":        "invoice",
+			"status":      "pending",
+			"sender":      "0x5E250bB077ec836915155229E83d187715266167",
+			"created_at":  "2021-06-01T00:00:00Z",
+		},
+		{
+			"baseline_id": "3b9f3c2e-6f7a-4d8e-9c1b-2a4e5f6a7b03",
+			"id":          "po-2",
+			"type":        "purchase_order",
+			"status":      "rejected",
+			"proof":       "0x17a3b8c2d4e6f801",
+			"recipients":  []interface{}{map[string]interface{}{"address": "0x1f9061B953bBa0E36BF50F21876132DcF276fC6e"}},
+			"created_at":  "2021-07-01T00:00:00Z",
+			"updated_at":  "2021-07-02T00:00:00Z",
+		},
+	} {
+		msg["workgroup_id"] = c.values["workgroup"]
+		c.api.Seed("objects", msg)
+	}
+}
+
+// writeMessagePayload initializes workflowDefinition, then writes a purchase order which
+// conforms to the schema of its step as po-1.json
+func writeMessagePayload(c *cli) {
+	initWorkflow(c)
+	c.writeFile("po-1.json", `{"id": "po-1", "total": 100}`)
+}
+
+// writeMessageBatch initializes workflowDefinition, then writes a batch of records as
+// records.ndjson; the third record does not conform to the schema of its step, and the
+// fourth cannot be parsed
+func writeMessageBatch(c *cli) {
+	initWorkflow(c)
+	c.writeFile("records.ndjson", `{"id": "po-1", "type": "purchase_order", "payload": {"id": "po-1", "total": 100}}
+{"id": "inv-1", "type": "invoice", "payload": {"po": "po-1", "total": 100}}
+
+{"id": "inv-2", "type": "invoice", "payload": {"po": "po-1", "total": -5}}
+{"id": "inv-3", "type": "invoice", "payload":
+`)
+}
+
+// sendMessageBatch sends the batch written by writeMessageBatch, which writes the records
+// which failed to records.ndjson.resume
+func sendMessageBatch(c *cli) {
+	writeMessageBatch(c)
+	c.run(c.expand([]string{"baseline", "workflows", "messages", "send", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--workflow", "{{workflow}}", "--batch", "records.ndjson"})...)
+}
+
+// sendMessageToRecipient sends a purchase order to the organization of the workgroup, having
+// registered its address
+func sendMessageToRecipient(c *cli) {
+	writeWorkflowDefinition(c)
+	c.run(c.expand([]string{"baseline", "workflows", "messages", "send", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--id", "po-1", "--type", "purchase_order", "--data", `{"total":100}`, "--recipients", "{{org}}"})...)
+}
+
+// sendUntypedMessageBatch sends a batch of invoices which do not declare their type using
+// --type invoice; the second record does not conform to the schema of its step, so it is written
+// to invoices.ndjson.resume
+func sendUntypedMessageBatch(c *cli) {
+	initWorkflow(c)
+	c.writeFile("invoices.ndjson", `{"id": "inv-1", "payload": {"po": "po-1", "total": 100}}
+{"id": "inv-2", "payload": {"po": "po-1", "total": -5}}
+`)
+	c.run(c.expand([]string{"baseline", "workflows", "messages", "send", "--workgroup", "{{workgroup}}", "--organization", "{{org}}", "--workflow", "{{workflow}}", "--batch", "invoices.ndjson", "--type", "invoice"})...)
+}
+
+// fixMessageBatch sends the batch written by writeMessageBatch, then corrects the records
+// which failed in records.ndjson.resume
+func fixMessageBatch(c *cli) {
+	sendMessageBatch(c)
+	c.writeFile("records.ndjson.resume", `{"id": "inv-2", "type": "invoice", "payload": {"po": "po-1", "total": 5}}
+{"id": "inv-3", "type": "invoice", "payload": {"po": "po-1", "total": 10}}
+`)
+}
+
+// writeInvalidBackup writes an archive which is not a stack backup as invalid.tar.gz
+func writeInvalidBackup(c *cli) {
+	c.writeFile("invalid.tar.gz", "not a backup")
+}
+
+// writeInvalidImageLock writes an image lockfile with an unsupported version and an unpinned image as invalid.lock
+func writeInvalidImageLock(c *cli) {
+	c.writeFile("invalid.lock", `version: 2
+images:
+  - image: provide/baseline
+    digest: provide/baseline:latest
+    id: sha256:3f1b2c
+`)
+}
+
+// writeInvalidStackDefinition writes a stack definition with a problem in each section as invalid-stack.yaml
+func writeInvalidStackDefinition(c *cli) {
+	c.writeFile("invalid-stack.yaml", `version: 2
+name: "acme stack"
+workgroup:
+  id: not-a-uuid
+services:
+  api:
+    port: 4222
+  nats:
+    port: 4222
+  ident:
+    scheme: ftp
+sor:
+  id: oracle
+log_level: verbose
+`)
+}
+
+// workflowDefinition is a workflow definition having a step verified by a circuit which is
+// created, and a step verified by a contract; the organization is that of withWorkgroup
+const workflowDefinition = `version: 1
+name: Procure to Pay
+description: purchase orders and invoices
+participants:
+  - name: Acme
+    organization: %s
+    role: buyer
+  - name: Globex
+    address: "0x5E250bB077ec836915155229E83d187715266167"
+    role: seller
+steps:
+  - name: purchase order
+    type: purchase_order
+    schema_file: purchase_order.json
+    senders: [buyer]
+    privacy:
+      circuit:
+        identifier: purchase_order
+  - name: invoice
+    type: invoice
+    schema:
+      type: object
+      required: [po, total]
+      properties:
+        po:
+          type: string
+        total:
+          type: number
+          minimum: 0
+    senders: [seller]
+    require_finality: true
+    privacy:
+      verifier: "0x1f9061B953bBa0E36BF50F21876132DcF276fC6e"
+`
+
+// writeWorkflowDefinition registers the address of the organization of withWorkgroup, as
+// 'prvd baseline stack run' does, and writes the workflow definition
+func writeWorkflowDefinition(c *cli) {
+	org := c.api.Find("organizations", "id", c.values["org"])
+	org["metadata"].(map[string]interface{})["address"] = "0x8A3b0D6e1F9c2E4a7B5d3C1e9F0a2B4c6D8e0F1a"
+	writeWorkflowDefinitionFiles(c)
+}
+
+// writeWorkflowDefinitionFiles writes workflowDefinition as workflow.yaml, and the schema of its
+// purchase order step as purchase_order.json
+func writeWorkflowDefinitionFiles(c *cli) {
+	c.writeFile("workflow.yaml", fmt.Sprintf(workflowDefinition, c.values["org"]))
+	c.writeFile("purchase_order.json", `{"type": "object", "required": ["id"], "properties": {"id": {"type": "string"}, "total": {"type": "number"}}}`)
+}
+
+// initWorkflow initializes workflowDefinition in the workgroup created by withWorkgroup,
+// saved as {{workflow}}
+func initWorkflow(c *cli) {
+	writeWorkflowDefinition(c)
+	c.setup(step{
+		args: []string{"baseline", "workflows", "init", "-f", "workflow.yaml", "--workgroup", "{{workgroup}}", "--organization", "{{org}}"},
+		save: "workflow",
+	})
+}
+
+// writeChangedWorkflowDefinition initializes workflowDefinition, then writes a revision of it
+// as workflow-changed.yaml
+func writeChangedWorkflowDefinition(c *cli) {
+	initWorkflow(c)
+	changed := strings.NewReplacer(
+		"role: seller", "role: supplier",
+		"senders: [seller]", "senders: [supplier]",
+		"minimum: 0", "minimum: 1",
+		"    require_finality: true\n", "",
+	).Replace(fmt.Sprintf(workflowDefinition, c.values["org"]))
+	changed += `  - name: receipt
+    type: goods_receipt
+    schema:
+      type: object
+    senders: [buyer]
+    privacy:
+      circuit_id: 7c3a1e1f-5b1e-4f0c-9a51-3a3b9f4bd0e1
+`
+	c.writeFile("workflow-changed.yaml", changed)
+}
+
+// writeRejectedWorkflowDefinition writes workflowDefinition as workflow.yaml, having an invoice
+// step which is rejected by the mock once the workflow and its purchase order step are created
+func writeRejectedWorkflowDefinition(c *cli) {
+	writeWorkflowDefinition(c)
+	c.writeFile("workflow.yaml", strings.Replace(fmt.Sprintf(workflowDefinition, c.values["org"]), "name: invoice", "name: "+mockapi.RejectedWorkstepName, 1))
+}
+
+// writeInvalidWorkflowDefinition writes a workflow definition with a problem in each section as invalid-workflow.yaml
+func writeInvalidWorkflowDefinition(c *cli) {
+	c.writeFile("invalid-workflow.yaml", `version: 2
+workgroup: not-a-uuid
+participants:
+  - name: Acme
+    organization: not-a-uuid
+    role: buyer
+  - name: Acme
+    role: seller
+steps:
+  - name: purchase order
+    schema:
+      type: money
+      properties:
+        total:
+          minLength: -1
+        issued:
+          type: string
+          format: date
+    senders: [carrier]
+    privacy:
+      circuit_id: 7c3a1e1f-5b1e-4f0c-9a51-3a3b9f4bd0e1
+      verifier: "0x1f9061B953bBa0E36BF50F21876132DcF276fC6e"
+  - name: invoice
+    type: invoice
+    schema_file: missing.json
+    senders: [seller]
+    privacy:
+      circuit: {}
+`)
+}
+
+// deleteContextCredentials migrates the tokens of the active staging context to the file
+// credential store, then deletes and recreates the context
+func deleteContextCredentials(c *cli) {
+	c.env = []string{"PROVIDE_CREDENTIALS_PASSPHRASE=secret"}
+	c.setup(
+		step{args: []string{"config", "migrate-credentials", "--store", "file"}},
+		step{args: []string{"context", "delete", "staging"}},
+		step{args: []string{"context", "create", "staging"}},
+	)
+}
+
+// vendInvite saves an invitation to the workgroup created by withWorkgroup as {{invite}}
+func vendInvite(c *cli) {
+	c.values["invite"] = c.api.VendInvite(c.values["workgroup"])
+}
+
+func TestCLI(t *testing.T) {
+	for _, tc := range cliTestCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := newCLI(t)
+			c.setup(tc.setup...)
+			if tc.prepare != nil {
+				tc.prepare(c)
+			}
+
+			args := c.expand(tc.args)
+			c.env = tc.env
+			c.assertGolden(tc.name, args, c.run(args...))
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/provideservices/provide-cli/test/mockapi"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata with the actual output")
+
+// prvd is the path of the binary under test, built once by TestMain
+var prvd string
+
+var logTimestampRegex = regexp.MustCompile(`(?m)^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} `)
+var jwtRegex = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+var argRefRegex = regexp.MustCompile(`\{\{(\w+)\}\}`)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	dir, err := ioutil.TempDir("", "prvd-test")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create build directory; %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	prvd = filepath.Join(dir, "prvd")
+	build := exec.Command("go", "build", "-o", prvd, ".")
+	build.Dir = ".."
+	build.Stdout = os.Stdout
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build prvd; %s\n", err.Error())
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// step is a single invocation of prvd
+type step struct {
+	args []string
+
+	// save names the id of the resource rendered by the step, or of the first resource
+	// when a list is rendered; the step is run with --output json and later steps may
+	// reference the id as {{name}}
+	save string
+}
+
+// result of a single invocation of prvd
+type result struct {
+	stdout   string
+	stderr   string
+	exitCode int
+}
+
+// cli runs prvd against a dedicated mock API using an isolated home directory
+type cli struct {
+	t      *testing.T
+	api    *mockapi.Server
+	home   string
+	values map[string]string
+
+	// env is appended to the environment of subsequent invocations
+	env []string
+}
+
+func newCLI(t *testing.T) *cli {
+	home, err := ioutil.TempDir("", "prvd-home")
+	if err != nil {
+		t.Fatalf("failed to create home directory; %s", err.Error())
+	}
+
+	c := &cli{
+		t:      t,
+		api:    mockapi.NewServer(),
+		home:   home,
+		values: map[string]string{"network": mockapi.PublicNetworkID},
+	}
+	t.Cleanup(func() {
+		c.api.Close()
+		os.RemoveAll(home)
+	})
+	return c
+}
+
+// expand replaces references to saved ids in the given args
+func (c *cli) expand(args []string) []string {
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		expanded[i] = argRefRegex.ReplaceAllStringFunc(arg, func(ref string) string {
+			name := argRefRegex.FindStringSubmatch(ref)[1]
+			val, ok := c.values[name]
+			if !ok {
+				c.t.Fatalf("reference to unsaved value: %s", ref)
+			}
+			return val
+		})
+	}
+	return expanded
+}
+
+func (c *cli) run(args ...string) *result {
+	cmd := exec.Command(prvd, args...)
+	cmd.Dir = c.home
+	cmd.Env = append([]string{
+		fmt.Sprintf("HOME=%s", c.home),
+		fmt.Sprintf("PATH=%s", os.Getenv("PATH")),
+	}, c.api.Env()...)
+	cmd.Env = append(cmd.Env, c.env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	res := &result{}
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			c.t.Fatalf("failed to run prvd; %s", err.Error())
+		}
+		res.exitCode = exitErr.ExitCode()
+	}
+	res.stdout = c.normalize(stdout.String())
+	res.stderr = c.normalize(stderr.String())
+	return res
+}
+
+// setup runs each of the given steps, failing the test unless each succeeds
+func (c *cli) setup(steps ...step) {
+	for _, s := range steps {
+		args := c.expand(s.args)
+		if s.save != "" {
+			args = append(args, "--output", "json")
+		}
+
+		res := c.run(args...)
+		if res.exitCode != 0 {
+			c.t.Fatalf("setup step failed: prvd %s; exit status: %d\n%s%s", strings.Join(args, " "), res.exitCode, res.stdout, res.stderr)
+		}
+
+		if s.save != "" {
+			var resource struct {
+				ID string `json:"id"`
+			}
+			var resources []*struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal([]byte(res.stdout), &resources); err == nil && len(resources) > 0 {
+				resource.ID = resources[0].ID
+			} else {
+				json.Unmarshal([]byte(res.stdout), &resource)
+			}
+			if resource.ID == "" {
+				c.t.Fatalf("setup step did not render a resource id: prvd %s\n%s", strings.Join(args, " "), res.stdout)
+			}
+			c.values[s.save] = resource.ID
+		}
+	}
+}
+
+// writeFile writes the given content to the named file in the home directory
+func (c *cli) writeFile(name, content string) {
+	if err := ioutil.WriteFile(filepath.Join(c.home, name), []byte(content), 0644); err != nil {
+		c.t.Fatalf("failed to write %s; %s", name, err.Error())
+	}
+}
+
+// normalize strips output which varies between runs
+func (c *cli) normalize(out string) string {
+	out = logTimestampRegex.ReplaceAllString(out, "")
+	out = jwtRegex.ReplaceAllString(out, "<jwt>")
+	out = strings.ReplaceAll(out, c.api.Host(), "<mockapi>")
+	out = strings.ReplaceAll(out, c.home, "$HOME")
+	return out
+}
+
+// assertGolden compares the result of running the given args with testdata/<name>.golden
+func (c *cli) assertGolden(name string, args []string, res *result) {
+	actual := fmt.Sprintf("$ prvd %s\n-- stdout --\n%s-- stderr --\n%s-- exit status: %d --\n", c.normalize(strings.Join(args, " ")), res.stdout, res.stderr, res.exitCode)
+
+	path := filepath.Join("testdata", fmt.Sprintf("%s.golden", name))
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			c.t.Fatalf("failed to create testdata directory; %s", err.Error())
+		}
+		if err := ioutil.WriteFile(path, []byte(actual), 0644); err != nil {
+			c.t.Fatalf("failed to write golden file %s; %s", path, err.Error())
+		}
+		return
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		c.t.Fatalf("failed to read golden file %s; run go test with -update to create it; %s", path, err.Error())
+	}
+	if string(expected) != actual {
+		c.t.Errorf("output does not match %s; run go test with -update if the change is intended\n--- expected\n%s\n--- actual\n%s", path, expected, actual)
+	}
+}
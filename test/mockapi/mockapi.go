@@ -0,0 +1,660 @@
+// Package mockapi provides an in-memory fake of the Provide ident, nchain, vault and
+// baseline APIs which implements the endpoints called by prvd, such that the CLI can
+// be exercised end-to-end without network access
+package mockapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const basePath = "/api/v1/"
+
+// CapabilitiesManifestPath is the path at which the mock serves the capabilities manifest
+const CapabilitiesManifestPath = "/capabilities/provide-capabilities-manifest.json"
+
+// PublicNetworkID is the id of the public network with which the mock is seeded
+const PublicNetworkID = "66d44f30-9092-4182-a3c4-bc02736d6ae5"
+
+// RejectedWorkstepName is the name of a workstep which the mock rejects, so the rollback of a
+// partially initialized workflow can be exercised
+const RejectedWorkstepName = "rejected"
+
+// UnidentifiedObjectID is the id of a business object which the mock accepts without returning
+// its baseline id, as the baseline API does when it responds without a body
+const UnidentifiedObjectID = "unidentified"
+
+// createdAt is the fixed creation timestamp of every record created by the mock
+const createdAt = "2021-01-01T00:00:00Z"
+
+type record map[string]interface{}
+
+// Server is a fake Provide API; ids are assigned sequentially so responses are deterministic
+// given the same sequence of requests
+type Server struct {
+	*httptest.Server
+
+	mutex       sync.Mutex
+	seq         int
+	collections map[string][]record // keyed by collection uri; i.e., applications or vaults/:id/keys
+	passwords   map[string]string   // keyed by email
+	subjects    map[string]string   // subject of each vended bearer token, keyed by token
+	refresh     map[string]bool     // true for each vended refresh token
+	requests    []string
+}
+
+// NewServer starts a mock API server seeded with a public network
+func NewServer() *Server {
+	s := &Server{
+		collections: map[string][]record{},
+		passwords:   map[string]string{},
+		subjects:    map[string]string{},
+		refresh:     map[string]bool{},
+	}
+	s.collections["networks"] = []record{
+		{
+			"id":         PublicNetworkID,
+			"created_at": createdAt,
+			"name":       "Ethereum Ropsten testnet",
+			"chain_id":   "0x3",
+			"enabled":    true,
+		},
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Host returns the host:port on which the mock is listening
+func (s *Server) Host() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+// Env returns the environment which points provide-go, and prvd, at the mock
+func (s *Server) Env() []string {
+	env := make([]string, 0)
+	for _, svc := range []string{"IDENT", "NCHAIN", "VAULT", "PRIVACY", "BASELINE"} {
+		env = append(env, fmt.Sprintf("%s_API_HOST=%s", svc, s.Host()))
+		env = append(env, fmt.Sprintf("%s_API_SCHEME=http", svc))
+	}
+	env = append(env, fmt.Sprintf("PROVIDE_CAPABILITIES_MANIFEST_URL=%s%s", s.URL, CapabilitiesManifestPath))
+	return env
+}
+
+// Requests returns the method and uri of each request received, in order
+func (s *Server) Requests() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+// Seed adds a record to the given collection, returning its id; the params may override the
+// id and creation timestamp which are otherwise assigned to the record
+func (s *Server) Seed(collection string, params map[string]interface{}) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rec := s.newRecord(params)
+	for _, key := range []string{"id", "created_at"} {
+		if val, ok := params[key]; ok {
+			rec[key] = val
+		}
+	}
+	if collection == "contracts" {
+		s.deploy(rec)
+	}
+	s.collections[collection] = append(s.collections[collection], rec)
+	return rec["id"].(string)
+}
+
+// Find returns the first record in the given collection having the given value, or nil
+func (s *Server) Find(collection, key, val string) map[string]interface{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.find(collection, key, val)
+}
+
+// VendInvite returns an invitation to the given workgroup, as vended by the invitor's
+// organization using its RSA vault key
+func (s *Server) VendInvite(workgroupID string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	registryContractAddress := ""
+	if registry := s.find("contracts", "type", "organization-registry"); registry != nil {
+		registryContractAddress, _ = registry["address"].(string)
+	}
+
+	return vendJWT(s.nextID(), "invite@example.com", map[string]interface{}{
+		"baseline": map[string]interface{}{
+			"invitor_organization_address": address(workgroupID),
+			"registry_contract_address":    registryContractAddress,
+			"workgroup_id":                 workgroupID,
+		},
+	})
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.requests = append(s.requests, fmt.Sprintf("%s %s", r.Method, r.URL.Path))
+
+	switch {
+	case r.URL.Path == CapabilitiesManifestPath:
+		respond(w, http.StatusOK, capabilitiesManifest())
+		return
+	case r.Method == http.MethodGet && r.URL.Path == "/.well-known/keys":
+		// the JWKs are served at the root of the ident API
+		respond(w, http.StatusOK, []record{{"kid": signingKeyFingerprint, "use": "sig", "public_key": signingPublicKeyPEM, "fingerprint": signingKeyFingerprint}})
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, basePath) {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+	uri := strings.Trim(strings.TrimPrefix(r.URL.Path, basePath), "/")
+
+	params := map[string]interface{}{}
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			respondWithError(w, http.StatusUnprocessableEntity, fmt.Sprintf("failed to parse request body; %s", err.Error()))
+			return
+		}
+	} else {
+		for name := range r.URL.Query() {
+			params[name] = r.URL.Query().Get(name)
+		}
+	}
+
+	// unauthenticated endpoints
+	switch {
+	case r.Method == http.MethodPost && uri == "authenticate":
+		s.authenticate(w, params)
+		return
+	case r.Method == http.MethodPost && uri == "users":
+		s.createUser(w, params)
+		return
+	case r.Method == http.MethodPost && uri == "tokens" && params["grant_type"] == "refresh_token":
+		s.refreshAccessToken(w, params)
+		return
+	}
+
+	subject, ok := s.authorize(r)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.get(w, uri, params)
+	case http.MethodPost:
+		s.create(w, subject, uri, params)
+	case http.MethodPut:
+		s.update(w, uri, params)
+	case http.MethodDelete:
+		s.delete(w, uri)
+	default:
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// authorize returns the subject of the bearer token presented with the request
+func (s *Server) authorize(r *http.Request) (string, bool) {
+	parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return "", false
+	}
+	subject, ok := s.subjects[parts[1]]
+	if !ok || s.refresh[parts[1]] {
+		return "", false
+	}
+	return subject, true
+}
+
+func (s *Server) nextID() string {
+	s.seq++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.seq)
+}
+
+func (s *Server) vendToken(subject string, refresh bool) string {
+	claims := map[string]interface{}{}
+	if refresh {
+		claims["scope"] = "offline_access"
+	}
+	token := vendJWT(s.nextID(), subject, claims)
+	s.subjects[token] = subject
+	s.refresh[token] = refresh
+	return token
+}
+
+func (s *Server) authenticate(w http.ResponseWriter, params map[string]interface{}) {
+	email, _ := params["email"].(string)
+	passwd, _ := params["password"].(string)
+	if pw, ok := s.passwords[email]; !ok || pw != passwd {
+		respondWithError(w, http.StatusUnauthorized, "authentication failed with given credentials")
+		return
+	}
+
+	user := s.find("users", "email", email)
+	subject := fmt.Sprintf("user:%s", user["id"])
+	respond(w, http.StatusCreated, map[string]interface{}{
+		"user": user,
+		"token": record{
+			"id":            s.nextID(),
+			"access_token":  s.vendToken(subject, false),
+			"refresh_token": s.vendToken(subject, true),
+			"scope":         "offline_access",
+		},
+	})
+}
+
+func (s *Server) createUser(w http.ResponseWriter, params map[string]interface{}) {
+	email, _ := params["email"].(string)
+	passwd, _ := params["password"].(string)
+	if email == "" || passwd == "" {
+		respondWithError(w, http.StatusUnprocessableEntity, "email and password are required")
+		return
+	}
+	if _, exists := s.passwords[email]; exists {
+		respondWithError(w, http.StatusConflict, fmt.Sprintf("user exists: %s", email))
+		return
+	}
+
+	user := s.newRecord(params)
+	delete(user, "password")
+	user["name"] = strings.TrimSpace(fmt.Sprintf("%v %v", params["first_name"], params["last_name"]))
+	s.passwords[email] = passwd
+	s.collections["users"] = append(s.collections["users"], user)
+	respond(w, http.StatusCreated, user)
+}
+
+func (s *Server) refreshAccessToken(w http.ResponseWriter, params map[string]interface{}) {
+	refreshToken, _ := params["refresh_token"].(string)
+	subject, ok := s.subjects[refreshToken]
+	if !ok || !s.refresh[refreshToken] {
+		respondWithError(w, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+	respond(w, http.StatusCreated, record{
+		"id":           s.nextID(),
+		"access_token": s.vendToken(subject, false),
+		"expires_in":   3600,
+	})
+}
+
+// createToken vends a token on behalf of the authorized subject, or the application or
+// organization given in the params
+func (s *Server) createToken(w http.ResponseWriter, subject string, params map[string]interface{}) {
+	if appID, ok := params["application_id"].(string); ok && appID != "" {
+		if s.find("applications", "id", appID) == nil {
+			respondWithError(w, http.StatusNotFound, fmt.Sprintf("application not found: %s", appID))
+			return
+		}
+		subject = fmt.Sprintf("application:%s", appID)
+	} else if orgID, ok := params["organization_id"].(string); ok && orgID != "" {
+		if s.find("organizations", "id", orgID) == nil {
+			respondWithError(w, http.StatusNotFound, fmt.Sprintf("organization not found: %s", orgID))
+			return
+		}
+		subject = fmt.Sprintf("organization:%s", orgID)
+	}
+
+	token := s.newRecord(params)
+	delete(token, "grant_type")
+	s.collections["tokens"] = append(s.collections["tokens"], token)
+
+	resp := record{}
+	for k, v := range token {
+		resp[k] = v
+	}
+	if params["scope"] == "offline_access" {
+		resp["access_token"] = s.vendToken(subject, false)
+		resp["refresh_token"] = s.vendToken(subject, true)
+	} else if strings.HasPrefix(subject, "user:") {
+		resp["access_token"] = s.vendToken(subject, false)
+	} else {
+		resp["token"] = s.vendToken(subject, false)
+	}
+	respond(w, http.StatusCreated, resp)
+}
+
+func (s *Server) get(w http.ResponseWriter, uri string, params map[string]interface{}) {
+	segments := strings.Split(uri, "/")
+
+	if len(segments)%2 == 0 {
+		collection := strings.Join(segments[0:len(segments)-1], "/")
+		rec := s.find(collection, "id", segments[len(segments)-1])
+		if rec == nil {
+			respondWithError(w, http.StatusNotFound, "not found")
+			return
+		}
+		respond(w, http.StatusOK, rec)
+		return
+	}
+
+	records := s.collections[uri]
+	if len(segments) == 3 && segments[0] == "applications" && segments[2] == "organizations" {
+		// associations are resolved so changes to the organization are reflected
+		resolved := make([]record, 0)
+		for _, assoc := range records {
+			if org := s.find("organizations", "id", assoc["id"].(string)); org != nil {
+				resolved = append(resolved, org)
+			}
+		}
+		records = resolved
+	}
+
+	matches := make([]record, 0)
+	for _, rec := range records {
+		if matchesParams(rec, params) {
+			matches = append(matches, rec)
+		}
+	}
+	respond(w, http.StatusOK, matches)
+}
+
+func (s *Server) create(w http.ResponseWriter, subject, uri string, params map[string]interface{}) {
+	segments := strings.Split(uri, "/")
+
+	switch {
+	case uri == "tokens":
+		s.createToken(w, subject, params)
+		return
+	case uri == "invitations":
+		s.createInvitation(w, params)
+		return
+	case uri == "objects" && params["id"] == UnidentifiedObjectID:
+		w.WriteHeader(http.StatusAccepted)
+		return
+	case uri == "objects":
+		// the object is recorded by its id in the system of record, as a baselined message
+		rec := s.newRecord(params)
+		rec["baseline_id"] = rec["id"]
+		rec["id"] = params["id"]
+		rec["status"] = "pending"
+		s.collections[uri] = append(s.collections[uri], rec)
+		respond(w, http.StatusAccepted, record{"baseline_id": rec["baseline_id"]})
+		return
+	case uri == "workgroups":
+		respond(w, http.StatusOK, s.newRecord(params))
+		return
+	case uri == "worksteps" && params["name"] == RejectedWorkstepName:
+		respondWithError(w, http.StatusUnprocessableEntity, "workstep rejected")
+		return
+	case len(segments) == 3 && segments[0] == "contracts" && segments[2] == "execute":
+		if s.find("contracts", "id", segments[1]) == nil {
+			respondWithError(w, http.StatusNotFound, "contract not found")
+			return
+		}
+		respond(w, http.StatusAccepted, record{"confidence": 1, "ref": s.nextID()})
+		return
+	case len(segments) == 3 && segments[0] == "applications" && segments[2] == "organizations":
+		orgID, _ := params["organization_id"].(string)
+		if s.find("applications", "id", segments[1]) == nil || s.find("organizations", "id", orgID) == nil {
+			respondWithError(w, http.StatusNotFound, "not found")
+			return
+		}
+		if s.find(uri, "id", orgID) == nil {
+			s.collections[uri] = append(s.collections[uri], record{"id": orgID})
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case len(segments) == 5 && segments[0] == "vaults" && segments[2] == "keys" && segments[4] == "sign":
+		s.sign(w, segments[1], segments[3], params)
+		return
+	case len(segments) == 3 && segments[0] == "vaults" && segments[2] == "keys":
+		if s.find("vaults", "id", segments[1]) == nil {
+			respondWithError(w, http.StatusNotFound, "vault not found")
+			return
+		}
+	case len(segments) != 1:
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	rec := s.newRecord(params)
+	scope := strings.SplitN(subject, ":", 2)
+	switch scope[0] {
+	case "user":
+		if _, ok := rec["user_id"]; !ok {
+			rec["user_id"] = scope[1]
+		}
+	case "application", "organization":
+		key := fmt.Sprintf("%s_id", scope[0])
+		if _, ok := rec[key]; !ok {
+			rec[key] = scope[1]
+		}
+	}
+
+	id := rec["id"].(string)
+	switch segments[0] {
+	case "accounts":
+		rec["address"] = address(id)
+		rec["public_key"] = publicKey(id)
+	case "contracts":
+		s.deploy(rec)
+	case "networks":
+		if _, ok := rec["enabled"]; !ok {
+			rec["enabled"] = true
+		}
+	case "organizations":
+		if _, ok := rec["metadata"]; !ok {
+			rec["metadata"] = map[string]interface{}{}
+		}
+	case "vaults":
+		if len(segments) == 3 {
+			rec["vault_id"] = segments[1]
+			switch spec, _ := rec["spec"].(string); {
+			case strings.HasPrefix(spec, "RSA-"):
+				rec["public_key"] = signingPublicKeyPEM
+				rec["fingerprint"] = signingKeyFingerprint
+			case spec == "secp256k1":
+				rec["address"] = address(id)
+				rec["public_key"] = publicKey(id)
+			case spec != "":
+				rec["public_key"] = publicKey(id)
+			}
+		}
+	case "wallets":
+		rec["public_key"] = fmt.Sprintf("xpub%s", digest(id)[0:40])
+	}
+
+	s.collections[uri] = append(s.collections[uri], rec)
+	switch uri {
+	case "workflows", "worksteps":
+		// the baseline API responds to the creation of workflows and worksteps with 200
+		respond(w, http.StatusOK, rec)
+	default:
+		respond(w, http.StatusCreated, rec)
+	}
+}
+
+// deploy assigns an address and a deployment transaction to the given contract; deploying
+// a registry contract also deploys the organization registry, as nchain does
+func (s *Server) deploy(contract record) {
+	tx := record{
+		"id":         s.nextID(),
+		"created_at": createdAt,
+		"network_id": contract["network_id"],
+		"hash":       fmt.Sprintf("0x%s", digest(contract["id"].(string))),
+		"status":     "success",
+	}
+	s.collections["transactions"] = append(s.collections["transactions"], tx)
+
+	contract["transaction_id"] = tx["id"]
+	if addr, _ := contract["address"].(string); addr == "" || addr == "0x" {
+		contract["address"] = address(contract["id"].(string))
+	}
+
+	if contract["type"] == "registry" {
+		registry := s.newRecord(map[string]interface{}{
+			"application_id": contract["application_id"],
+			"contract_id":    contract["id"],
+			"name":           "OrgRegistry",
+			"network_id":     contract["network_id"],
+			"transaction_id": tx["id"],
+			"type":           "organization-registry",
+		})
+		registry["address"] = address(registry["id"].(string))
+		s.collections["contracts"] = append(s.collections["contracts"], registry)
+	}
+}
+
+func (s *Server) createInvitation(w http.ResponseWriter, params map[string]interface{}) {
+	appID, _ := params["application_id"].(string)
+	if s.find("applications", "id", appID) == nil {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("application not found: %s", appID))
+		return
+	}
+
+	invitation := s.newRecord(map[string]interface{}{
+		"email": params["email"],
+	})
+	uri := fmt.Sprintf("applications/%s/invitations", appID)
+	s.collections[uri] = append(s.collections[uri], invitation)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) sign(w http.ResponseWriter, vaultID, keyID string, params map[string]interface{}) {
+	key := s.find(fmt.Sprintf("vaults/%s/keys", vaultID), "id", keyID)
+	if key == nil {
+		respondWithError(w, http.StatusNotFound, "key not found")
+		return
+	}
+
+	msg, _ := params["message"].(string)
+	signature := digest(fmt.Sprintf("%s:%s", keyID, msg))
+	if spec, _ := key["spec"].(string); strings.HasPrefix(spec, "RSA-") {
+		var err error
+		signature, err = sign(msg)
+		if err != nil {
+			respondWithError(w, http.StatusUnprocessableEntity, fmt.Sprintf("failed to sign message; %s", err.Error()))
+			return
+		}
+	}
+	respond(w, http.StatusCreated, record{"signature": signature})
+}
+
+func (s *Server) update(w http.ResponseWriter, uri string, params map[string]interface{}) {
+	segments := strings.Split(uri, "/")
+	if len(segments)%2 != 0 {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	rec := s.find(strings.Join(segments[0:len(segments)-1], "/"), "id", segments[len(segments)-1])
+	if rec == nil {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+	for k, v := range params {
+		rec[k] = v
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) delete(w http.ResponseWriter, uri string) {
+	segments := strings.Split(uri, "/")
+	if len(segments)%2 != 0 {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	collection := strings.Join(segments[0:len(segments)-1], "/")
+	for i, rec := range s.collections[collection] {
+		if rec["id"] == segments[len(segments)-1] {
+			s.collections[collection] = append(s.collections[collection][:i], s.collections[collection][i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	respondWithError(w, http.StatusNotFound, "not found")
+}
+
+func (s *Server) newRecord(params map[string]interface{}) record {
+	rec := record{}
+	for k, v := range params {
+		rec[k] = v
+	}
+	rec["id"] = s.nextID()
+	rec["created_at"] = createdAt
+	return rec
+}
+
+func (s *Server) find(collection, key, val string) record {
+	for _, rec := range s.collections[collection] {
+		if fmt.Sprintf("%v", rec[key]) == val {
+			return rec
+		}
+	}
+	return nil
+}
+
+// matchesParams returns true if the record matches each of the given query params
+// which it holds; params naming fields the record does not hold are ignored
+func matchesParams(rec record, params map[string]interface{}) bool {
+	keys := make([]string, 0)
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if val, ok := rec[k]; ok && fmt.Sprintf("%v", val) != fmt.Sprintf("%v", params[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+func capabilitiesManifest() map[string]interface{} {
+	return map[string]interface{}{
+		"baseline": map[string]interface{}{
+			"contracts": []interface{}{
+				map[string]interface{}{
+					"name":     "Shuttle",
+					"abi":      []interface{}{},
+					"bytecode": "0x",
+					"raw":      map[string]interface{}{},
+				},
+			},
+		},
+	}
+}
+
+func digest(val string) string {
+	hash := sha256.Sum256([]byte(val))
+	return hex.EncodeToString(hash[:])
+}
+
+func address(id string) string {
+	return fmt.Sprintf("0x%s", digest(id)[0:40])
+}
+
+func publicKey(id string) string {
+	return fmt.Sprintf("0x04%s", digest(fmt.Sprintf("public:%s", id)))
+}
+
+func respond(w http.ResponseWriter, status int, resp interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func respondWithError(w http.ResponseWriter, status int, msg string) {
+	respond(w, status, map[string]interface{}{
+		"errors": []map[string]interface{}{
+			{"message": msg, "status": status},
+		},
+	})
+}